@@ -0,0 +1,81 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package acceptor
+
+import (
+	"net"
+	"sync"
+
+	"github.com/topfreegames/pitaya/v2/constants"
+)
+
+// PipeAcceptor accepts in-memory connections created with Dial, it allows
+// clients to connect to a server running in the same process without sockets
+type PipeAcceptor struct {
+	connChan chan PlayerConn
+	die      chan struct{}
+	stopOnce sync.Once
+}
+
+// NewPipeAcceptor creates a new instance of pipe acceptor
+func NewPipeAcceptor() *PipeAcceptor {
+	return &PipeAcceptor{
+		connChan: make(chan PlayerConn),
+		die:      make(chan struct{}),
+	}
+}
+
+// Dial creates a connection to the acceptor, addr is ignored and exists so
+// Dial can be used where a dial function is expected
+func (a *PipeAcceptor) Dial(addr string) (net.Conn, error) {
+	clientConn, serverConn := net.Pipe()
+	select {
+	case a.connChan <- &tcpPlayerConn{Conn: serverConn}:
+		return clientConn, nil
+	case <-a.die:
+		clientConn.Close()
+		serverConn.Close()
+		return nil, constants.ErrAcceptorStopped
+	}
+}
+
+// GetAddr returns the addr of the acceptor
+func (a *PipeAcceptor) GetAddr() string {
+	return "pipe"
+}
+
+// GetConnChan gets a connection channel
+func (a *PipeAcceptor) GetConnChan() chan PlayerConn {
+	return a.connChan
+}
+
+// ListenAndServe blocks until the acceptor is stopped, connections are
+// created by Dial
+func (a *PipeAcceptor) ListenAndServe() {
+	<-a.die
+}
+
+// Stop stops the acceptor
+func (a *PipeAcceptor) Stop() {
+	a.stopOnce.Do(func() {
+		close(a.die)
+	})
+}
@@ -0,0 +1,42 @@
+package acceptor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/topfreegames/pitaya/v2/conn/packet"
+	"github.com/topfreegames/pitaya/v2/constants"
+	"github.com/topfreegames/pitaya/v2/helpers"
+)
+
+func TestPipeAcceptorDial(t *testing.T) {
+	t.Parallel()
+	a := NewPipeAcceptor()
+	defer a.Stop()
+	go a.ListenAndServe()
+
+	connChan := make(chan PlayerConn)
+	go func() {
+		connChan <- <-a.GetConnChan()
+	}()
+	clientConn, err := a.Dial(a.GetAddr())
+	assert.NoError(t, err)
+	defer clientConn.Close()
+	serverConn := helpers.ShouldEventuallyReceive(t, connChan).(PlayerConn)
+	defer serverConn.Close()
+
+	msg := []byte{packet.Data, 0x00, 0x00, 0x02, 0x01, 0x02}
+	go clientConn.Write(msg)
+	b, err := serverConn.GetNextMessage()
+	assert.NoError(t, err)
+	assert.Equal(t, msg, b)
+}
+
+func TestPipeAcceptorDialStopped(t *testing.T) {
+	t.Parallel()
+	a := NewPipeAcceptor()
+	a.Stop()
+	a.Stop()
+	_, err := a.Dial(a.GetAddr())
+	assert.Equal(t, constants.ErrAcceptorStopped, err)
+}
@@ -0,0 +1,160 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package acceptorwrapper
+
+import (
+	"crypto/ed25519"
+	"sync"
+
+	"github.com/topfreegames/pitaya/v2/acceptor"
+	"github.com/topfreegames/pitaya/v2/conn/codec"
+	"github.com/topfreegames/pitaya/v2/conn/packet"
+	"github.com/topfreegames/pitaya/v2/constants"
+)
+
+// EncryptedConn encrypts the packets of a connection. The client starts by
+// sending a key exchange packet with its X25519 public key, which is answered
+// with the public key of the server signed with the static ed25519 key of the
+// server, so that clients pinning it know they talk to the server. Both ends
+// then encrypt the data of every packet, including the handshake, with the
+// keys derived from the exchanged ones.
+type EncryptedConn struct {
+	acceptor.PlayerConn
+	required     bool
+	started      bool
+	serverKey    ed25519.PrivateKey
+	encoder      codec.PacketEncoder
+	decoder      codec.PacketDecoder
+	cipherMutex  sync.RWMutex
+	packetCipher *codec.PacketCipher
+	writeMutex   sync.Mutex // encrypted packets are written in the order of their sequence numbers
+}
+
+// NewEncryptedConn returns an encrypted connection signing its key exchanges
+// with serverKey, when required is set the connections that don't start with
+// a key exchange are closed
+func NewEncryptedConn(conn acceptor.PlayerConn, required bool, serverKey ed25519.PrivateKey) *EncryptedConn {
+	return &EncryptedConn{
+		PlayerConn: conn,
+		required:   required,
+		serverKey:  serverKey,
+		encoder:    codec.NewPomeloPacketEncoder(),
+		decoder:    codec.NewPomeloPacketDecoder(),
+	}
+}
+
+// GetNextMessage reads the next packet of the connection, decrypting its data
+// once the keys were exchanged
+func (e *EncryptedConn) GetNextMessage() (msg []byte, err error) {
+	for {
+		msg, err := e.PlayerConn.GetNextMessage()
+		if err != nil {
+			return nil, err
+		}
+		typ := packet.Type(msg[0])
+
+		if !e.started {
+			e.started = true
+			if typ == packet.KeyExchange {
+				if err := e.exchangeKeys(msg[codec.HeadLength:]); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if e.required {
+				return nil, constants.ErrKeyExchangeRequired
+			}
+		}
+
+		// keys are only exchanged once, at the start of the connection
+		if typ == packet.KeyExchange {
+			return nil, packet.ErrWrongPomeloPacketType
+		}
+
+		c := e.cipher()
+		if c == nil {
+			return msg, nil
+		}
+		data, err := c.Open(msg[codec.HeadLength:])
+		if err != nil {
+			return nil, err
+		}
+		return e.encoder.Encode(typ, data)
+	}
+}
+
+// Write writes packets to the connection, encrypting their data once the keys
+// were exchanged
+func (e *EncryptedConn) Write(b []byte) (int, error) {
+	c := e.cipher()
+	if c == nil {
+		return e.PlayerConn.Write(b)
+	}
+
+	packets, err := e.decoder.Decode(b)
+	if err != nil {
+		return 0, err
+	}
+
+	e.writeMutex.Lock()
+	defer e.writeMutex.Unlock()
+	buf := make([]byte, 0, len(b))
+	for _, p := range packets {
+		encoded, err := e.encoder.Encode(p.Type, c.Seal(p.Data))
+		if err != nil {
+			return 0, err
+		}
+		buf = append(buf, encoded...)
+	}
+	if _, err := e.PlayerConn.Write(buf); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (e *EncryptedConn) exchangeKeys(clientPublicKey []byte) error {
+	keyExchange, err := codec.NewKeyExchange()
+	if err != nil {
+		return err
+	}
+	c, err := keyExchange.Cipher(clientPublicKey, true)
+	if err != nil {
+		return err
+	}
+	p, err := e.encoder.Encode(packet.KeyExchange, keyExchange.SignedPublicKey(clientPublicKey, e.serverKey))
+	if err != nil {
+		return err
+	}
+	if _, err := e.PlayerConn.Write(p); err != nil {
+		return err
+	}
+
+	e.cipherMutex.Lock()
+	defer e.cipherMutex.Unlock()
+	e.packetCipher = c
+	return nil
+}
+
+func (e *EncryptedConn) cipher() *codec.PacketCipher {
+	e.cipherMutex.RLock()
+	defer e.cipherMutex.RUnlock()
+	return e.packetCipher
+}
@@ -0,0 +1,210 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package acceptorwrapper
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/topfreegames/pitaya/v2/conn/codec"
+	"github.com/topfreegames/pitaya/v2/conn/packet"
+	"github.com/topfreegames/pitaya/v2/constants"
+	"github.com/topfreegames/pitaya/v2/mocks"
+)
+
+func encodePacket(t *testing.T, typ packet.Type, data []byte) []byte {
+	t.Helper()
+	p, err := codec.NewPomeloPacketEncoder().Encode(typ, data)
+	assert.NoError(t, err)
+	return p
+}
+
+func newServerKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	return pub, key
+}
+
+// exchangeKeys exchanges keys with e, checking that they are signed with
+// serverKey, and returns the cipher of the client
+func exchangeKeys(t *testing.T, mockConn *mocks.MockPlayerConn, e *EncryptedConn, serverKey ed25519.PublicKey) *codec.PacketCipher {
+	t.Helper()
+	keys, err := codec.NewKeyExchange()
+	assert.NoError(t, err)
+
+	var signed []byte
+	mockConn.EXPECT().GetNextMessage().Return(encodePacket(t, packet.KeyExchange, keys.PublicKey()), nil)
+	mockConn.EXPECT().Write(gomock.Any()).Do(func(b []byte) {
+		assert.Equal(t, byte(packet.KeyExchange), b[0])
+		signed = b[codec.HeadLength:]
+	})
+
+	// the handshake is encrypted too
+	var c *codec.PacketCipher
+	handshake := []byte(`{"sys":{}}`)
+	mockConn.EXPECT().GetNextMessage().DoAndReturn(func() ([]byte, error) {
+		serverPublicKey, err := keys.VerifySignedPublicKey(signed, serverKey)
+		assert.NoError(t, err)
+		c, err = keys.Cipher(serverPublicKey, false)
+		assert.NoError(t, err)
+		return encodePacket(t, packet.Handshake, c.Seal(handshake)), nil
+	})
+
+	msg, err := e.GetNextMessage()
+	assert.NoError(t, err)
+	assert.Equal(t, encodePacket(t, packet.Handshake, handshake), msg)
+	return c
+}
+
+func TestEncryptedConnGetNextMessage(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockConn := mocks.NewMockPlayerConn(ctrl)
+	pub, key := newServerKey(t)
+	e := NewEncryptedConn(mockConn, true, key)
+	c := exchangeKeys(t, mockConn, e, pub)
+
+	sealed := encodePacket(t, packet.Data, c.Seal([]byte("hello")))
+	mockConn.EXPECT().GetNextMessage().Return(sealed, nil)
+	msg, err := e.GetNextMessage()
+	assert.NoError(t, err)
+	assert.Equal(t, encodePacket(t, packet.Data, []byte("hello")), msg)
+
+	// replayed packets are rejected
+	mockConn.EXPECT().GetNextMessage().Return(sealed, nil)
+	_, err = e.GetNextMessage()
+	assert.Equal(t, codec.ErrPacketSequence, err)
+
+	// plain packets are rejected after the key exchange
+	mockConn.EXPECT().GetNextMessage().Return(encodePacket(t, packet.Heartbeat, []byte{}), nil)
+	_, err = e.GetNextMessage()
+	assert.Equal(t, codec.ErrPacketTampered, err)
+}
+
+func TestEncryptedConnSignedByServerKey(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockConn := mocks.NewMockPlayerConn(ctrl)
+	_, key := newServerKey(t)
+	e := NewEncryptedConn(mockConn, true, key)
+
+	keys, err := codec.NewKeyExchange()
+	assert.NoError(t, err)
+	var signed []byte
+	mockConn.EXPECT().GetNextMessage().Return(encodePacket(t, packet.KeyExchange, keys.PublicKey()), nil)
+	mockConn.EXPECT().Write(gomock.Any()).Do(func(b []byte) {
+		signed = b[codec.HeadLength:]
+	})
+	mockConn.EXPECT().GetNextMessage().Return(nil, constants.ErrConnectionClosed)
+	_, err = e.GetNextMessage()
+	assert.Equal(t, constants.ErrConnectionClosed, err)
+
+	// clients pinning another key reject the exchange
+	otherPub, _ := newServerKey(t)
+	_, err = keys.VerifySignedPublicKey(signed, otherPub)
+	assert.Equal(t, codec.ErrInvalidSignature, err)
+}
+
+func TestEncryptedConnWrite(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockConn := mocks.NewMockPlayerConn(ctrl)
+	pub, key := newServerKey(t)
+	e := NewEncryptedConn(mockConn, false, key)
+	c := exchangeKeys(t, mockConn, e, pub)
+
+	heartbeat := encodePacket(t, packet.Heartbeat, []byte{})
+	data := encodePacket(t, packet.Data, []byte("hello"))
+	b := append(append([]byte{}, heartbeat...), data...)
+	mockConn.EXPECT().Write(gomock.Any()).DoAndReturn(func(written []byte) (int, error) {
+		packets, err := codec.NewPomeloPacketDecoder().Decode(written)
+		assert.NoError(t, err)
+		assert.Len(t, packets, 2)
+		assert.Equal(t, packet.Type(packet.Heartbeat), packets[0].Type)
+		assert.Equal(t, packet.Type(packet.Data), packets[1].Type)
+		opened, err := c.Open(packets[0].Data)
+		assert.NoError(t, err)
+		assert.Empty(t, opened)
+		opened, err = c.Open(packets[1].Data)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("hello"), opened)
+		return len(written), nil
+	})
+
+	n, err := e.Write(b)
+	assert.NoError(t, err)
+	assert.Equal(t, len(b), n)
+}
+
+func TestEncryptedConnPlain(t *testing.T) {
+	t.Parallel()
+
+	tables := map[string]struct {
+		required bool
+		err      error
+	}{
+		"test_plain_allowed":  {false, nil},
+		"test_plain_rejected": {true, constants.ErrKeyExchangeRequired},
+	}
+
+	for name, table := range tables {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockConn := mocks.NewMockPlayerConn(ctrl)
+			_, key := newServerKey(t)
+			e := NewEncryptedConn(mockConn, table.required, key)
+
+			handshake := encodePacket(t, packet.Handshake, []byte{})
+			mockConn.EXPECT().GetNextMessage().Return(handshake, nil)
+			msg, err := e.GetNextMessage()
+			assert.Equal(t, table.err, err)
+			if table.err != nil {
+				return
+			}
+			assert.Equal(t, handshake, msg)
+
+			// keys can't be exchanged after the start of the connection
+			mockConn.EXPECT().GetNextMessage().Return(encodePacket(t, packet.KeyExchange, make([]byte, 32)), nil)
+			_, err = e.GetNextMessage()
+			assert.Equal(t, packet.ErrWrongPomeloPacketType, err)
+
+			data := encodePacket(t, packet.Data, []byte("hello"))
+			mockConn.EXPECT().Write(data).Return(len(data), nil)
+			_, err = e.Write(data)
+			assert.NoError(t, err)
+		})
+	}
+}
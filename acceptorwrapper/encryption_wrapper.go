@@ -0,0 +1,61 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package acceptorwrapper
+
+import (
+	"io/ioutil"
+
+	"github.com/topfreegames/pitaya/v2/acceptor"
+	"github.com/topfreegames/pitaya/v2/config"
+	"github.com/topfreegames/pitaya/v2/conn/codec"
+)
+
+// EncryptionWrapper encrypts the packets of each connection
+// received whose client exchanges keys before the handshake
+type EncryptionWrapper struct {
+	BaseWrapper
+}
+
+// NewEncryptionWrapper returns an instance of *EncryptionWrapper signing the
+// key exchanges with the ed25519 key read from c.KeyFile
+func NewEncryptionWrapper(c config.EncryptionConfig) (*EncryptionWrapper, error) {
+	data, err := ioutil.ReadFile(c.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	serverKey, err := codec.ParseServerKey(data)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &EncryptionWrapper{}
+	e.BaseWrapper = NewBaseWrapper(func(conn acceptor.PlayerConn) acceptor.PlayerConn {
+		return NewEncryptedConn(conn, c.Required, serverKey)
+	})
+
+	return e, nil
+}
+
+// Wrap saves acceptor as an attribute
+func (e *EncryptionWrapper) Wrap(a acceptor.Acceptor) acceptor.Acceptor {
+	e.Acceptor = a
+	return e
+}
@@ -22,11 +22,9 @@ package agent
 
 import (
 	"context"
-	gojson "encoding/json"
 	e "errors"
 	"fmt"
 	"net"
-	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -34,6 +32,7 @@ import (
 
 	opentracing "github.com/opentracing/opentracing-go"
 
+	"github.com/topfreegames/pitaya/v2/config"
 	"github.com/topfreegames/pitaya/v2/conn/codec"
 	"github.com/topfreegames/pitaya/v2/conn/message"
 	"github.com/topfreegames/pitaya/v2/conn/packet"
@@ -45,16 +44,14 @@ import (
 	"github.com/topfreegames/pitaya/v2/protos"
 	"github.com/topfreegames/pitaya/v2/serialize"
 	"github.com/topfreegames/pitaya/v2/session"
+	"github.com/topfreegames/pitaya/v2/timer"
 	"github.com/topfreegames/pitaya/v2/tracing"
 	"github.com/topfreegames/pitaya/v2/util"
-	"github.com/topfreegames/pitaya/v2/util/compression"
 )
 
 var (
 	// hbd contains the heartbeat packet data
-	hbd []byte
-	// hrd contains the handshake response data
-	hrd  []byte
+	hbd  []byte
 	once sync.Once
 )
 
@@ -72,19 +69,38 @@ type (
 		chStopWrite        chan struct{}     // stop writing messages
 		chStopOrder        chan struct{}     // stop ordering messages
 		closeMutex         sync.Mutex
-		conn               net.Conn            // low-level conn fd
-		decoder            codec.PacketDecoder // binary decoder
-		encoder            codec.PacketEncoder // binary encoder
+		conn               net.Conn               // low-level conn fd
+		decoder            codec.PacketDecoder    // binary decoder
+		encoder            codec.PacketEncoder    // binary encoder
+		handshakeConfig    config.HandshakeConfig // settings the clients can negotiate
 		heartbeatTimeout   time.Duration
-		lastAt             int64 // last heartbeat unix time stamp
+		lastAt             int64       // last heartbeat unix time stamp
+		clock              timer.Clock // clock of the heartbeats
 		messageEncoder     message.Encoder
 		messagesBufferSize int // size of the pending messages buffer
 		metricsReporters   []metrics.Reporter
 		serializer         serialize.Serializer    // message serializer
+		serializers        serialize.Serializers   // serializers the client can negotiate
+		codecMutex         sync.RWMutex            // protect the negotiated serializer and message encoder
 		state              int32                   // current agent state
 		curMsgID           uint                    // cur request msg id
 		pushDelay          map[uint][]pendingWrite // push message delay
 		pushDelayMID       uint                    // push message msg id
+		pushFreeze         config.PushFreezeConfig // limits of the frozen pushes
+		frozenCount        int                     // number of frozen pushes
+		freezeMutex        sync.Mutex              // protect the frozen pushes state
+		resume             *resumeRegistry         // resumable agents, nil if resume is disabled
+		resumeToken        string                  // token sent to the client for resuming the session
+		resuming           *agentImpl              // suspended agent whose session is being resumed
+		resumedBy          *agentImpl              // agent that resumed the session of this agent
+		suspendMutex       sync.Mutex
+		suspended          []pendingWrite     // pushes kept while the agent is suspended
+		unordered          []pendingWrite     // pushes not sent to chSend when the agent stopped
+		failedWrite        *pendingWrite      // message that failed to be written in the conn
+		chParked           chan struct{}      // closed when the pending pushes of a suspended agent were kept
+		chWriteDone        chan struct{}      // closed when the write goroutine exits
+		ctx                context.Context    // canceled when the agent is closed
+		cancel             context.CancelFunc // cancels the handlers in flight
 	}
 
 	pendingMessage struct {
@@ -97,10 +113,11 @@ type (
 	}
 
 	pendingWrite struct {
-		ctx  context.Context
-		data []byte
-		err  error
-		msg  *message.Message
+		ctx      context.Context
+		data     []byte
+		err      error
+		msg      *message.Message
+		frozenAt time.Time // when the push was frozen waiting for a response
 	}
 
 	// Agent corresponds to a user and is used for storing raw Conn information
@@ -116,15 +133,23 @@ type (
 		SetLastAt()
 		SetStatus(state int32)
 		Handle()
+		Disconnect()
+		ResumeSession(token string) error
+		GetFreezeState() FreezeState
+		Context() context.Context
+		Reconnect(addr string) error
+		PendingWrites() int
 		IPVersion() string
-		SendHandshakeResponse() error
+		SendHandshakeResponse(data *session.HandshakeData) error
 		SendRequest(ctx context.Context, serverID, route string, v interface{}) (*protos.Response, error)
 		AnswerWithError(ctx context.Context, mid uint, err error)
+		DecodeMessage(data []byte) (*message.Message, error)
 	}
 
 	// AgentFactory factory for creating Agent instances
 	AgentFactory interface {
 		CreateAgent(conn net.Conn) Agent
+		Reconnect(conn net.Conn, addr string) error
 	}
 
 	agentFactoryImpl struct {
@@ -137,6 +162,11 @@ type (
 		messagesBufferSize int // size of the pending messages buffer
 		metricsReporters   []metrics.Reporter
 		serializer         serialize.Serializer // message serializer
+		serializers        serialize.Serializers
+		resume             *resumeRegistry
+		pushFreeze         config.PushFreezeConfig
+		handshake          config.HandshakeConfig
+		clock              timer.Clock
 	}
 )
 
@@ -146,11 +176,16 @@ func NewAgentFactory(
 	decoder codec.PacketDecoder,
 	encoder codec.PacketEncoder,
 	serializer serialize.Serializer,
+	serializers serialize.Serializers,
 	heartbeatTimeout time.Duration,
 	messageEncoder message.Encoder,
 	messagesBufferSize int,
 	sessionPool session.SessionPool,
 	metricsReporters []metrics.Reporter,
+	resumeConfig config.SessionResumeConfig,
+	pushFreezeConfig config.PushFreezeConfig,
+	handshakeConfig config.HandshakeConfig,
+	clock timer.Clock,
 ) AgentFactory {
 	return &agentFactoryImpl{
 		appDieChan:         appDieChan,
@@ -162,12 +197,24 @@ func NewAgentFactory(
 		sessionPool:        sessionPool,
 		metricsReporters:   metricsReporters,
 		serializer:         serializer,
+		serializers:        serializers,
+		resume:             newResumeRegistry(resumeConfig),
+		pushFreeze:         pushFreezeConfig,
+		handshake:          handshakeConfig,
+		clock:              clock,
 	}
 }
 
 // CreateAgent returns a new agent
 func (f *agentFactoryImpl) CreateAgent(conn net.Conn) Agent {
-	return newAgent(conn, f.decoder, f.encoder, f.serializer, f.heartbeatTimeout, f.messagesBufferSize, f.appDieChan, f.messageEncoder, f.metricsReporters, f.sessionPool)
+	a := newAgent(conn, f.decoder, f.encoder, f.serializer, f.heartbeatTimeout, f.messagesBufferSize, f.appDieChan, f.messageEncoder, f.metricsReporters, f.sessionPool)
+	a.(*agentImpl).resume = f.resume
+	a.(*agentImpl).pushFreeze = f.pushFreeze
+	a.(*agentImpl).handshakeConfig = f.handshake
+	a.(*agentImpl).serializers = f.serializers
+	a.(*agentImpl).clock = f.clock
+	a.SetLastAt()
+	return a
 }
 
 // NewAgent create new agent instance
@@ -183,14 +230,15 @@ func newAgent(
 	metricsReporters []metrics.Reporter,
 	sessionPool session.SessionPool,
 ) Agent {
-	// initialize heartbeat and handshake data on first user connection
-	serializerName := serializer.GetName()
-
+	// initialize heartbeat data on first user connection
 	once.Do(func() {
-		hbdEncode(heartbeatTime, packetEncoder, messageEncoder.IsCompressionEnabled(), serializerName)
+		hbdEncode(packetEncoder)
 	})
 
+	ctx, cancel := context.WithCancel(context.Background())
 	a := &agentImpl{
+		ctx:                ctx,
+		cancel:             cancel,
 		appDieChan:         dieChan,
 		chDie:              make(chan struct{}),
 		chSend:             make(chan pendingWrite, messagesBufferSize),
@@ -198,13 +246,17 @@ func newAgent(
 		chStopHeartbeat:    make(chan struct{}),
 		chStopWrite:        make(chan struct{}),
 		chStopOrder:        make(chan struct{}),
+		chParked:           make(chan struct{}),
+		chWriteDone:        make(chan struct{}),
 		pushDelay:          make(map[uint][]pendingWrite),
 		messagesBufferSize: messagesBufferSize,
 		conn:               conn,
 		decoder:            packetDecoder,
 		encoder:            packetEncoder,
+		handshakeConfig:    *config.NewDefaultHandshakeConfig(),
 		heartbeatTimeout:   heartbeatTime,
-		lastAt:             time.Now().Unix(),
+		lastAt:             timer.Now().Unix(),
+		clock:              timer.GlobalClock(),
 		serializer:         serializer,
 		state:              constants.StatusStart,
 		messageEncoder:     messageEncoder,
@@ -220,9 +272,9 @@ func newAgent(
 }
 
 func (a *agentImpl) getMessageFromPendingMessage(pm pendingMessage) (*message.Message, error) {
-	payload, err := util.SerializeOrRaw(a.serializer, pm.payload)
+	payload, err := util.SerializeOrRaw(a.getSerializer(), pm.payload)
 	if err != nil {
-		payload, err = util.GetErrorPayload(a.serializer, err)
+		payload, err = util.GetErrorPayload(a.getSerializer(), err)
 		if err != nil {
 			return nil, err
 		}
@@ -240,11 +292,13 @@ func (a *agentImpl) getMessageFromPendingMessage(pm pendingMessage) (*message.Me
 	return m, nil
 }
 
-func (a *agentImpl) packetEncodeMessage(m *message.Message) ([]byte, error) {
-	em, err := a.messageEncoder.Encode(m)
+func (a *agentImpl) packetEncodeMessage(ctx context.Context, m *message.Message) ([]byte, error) {
+	size := len(m.Data)
+	em, err := a.getMessageEncoder().Encode(m)
 	if err != nil {
 		return nil, err
 	}
+	a.reportCompressionRatio(ctx, m, size)
 
 	// packet encode
 	p, err := a.encoder.Encode(packet.Data, em)
@@ -275,7 +329,7 @@ func (a *agentImpl) send(pendingMsg pendingMessage) (err error) {
 	}
 
 	// packet encode
-	p, err := a.packetEncodeMessage(m)
+	p, err := a.packetEncodeMessage(pendingMsg.ctx, m)
 	if err != nil {
 		return err
 	}
@@ -287,7 +341,7 @@ func (a *agentImpl) send(pendingMsg pendingMessage) (err error) {
 	}
 
 	if pendingMsg.err {
-		pWrite.err = util.GetErrorFromPayload(a.serializer, m.Data)
+		pWrite.err = util.GetErrorFromPayload(a.getSerializer(), m.Data)
 	}
 
 	// chSend is never closed so we need this to don't block if agent is already closed
@@ -305,8 +359,11 @@ func (a *agentImpl) GetSession() session.Session {
 
 // Push implementation for NetworkEntity interface
 func (a *agentImpl) Push(ctx context.Context, route string, v interface{}) error {
-	if a.GetStatus() == constants.StatusClosed {
+	switch a.GetStatus() {
+	case constants.StatusClosed:
 		return errors.NewError(constants.ErrBrokenPipe, errors.ErrClientClosedRequest)
+	case constants.StatusSuspended:
+		return a.pushSuspended(ctx, route, v)
 	}
 
 	// switch d := v.(type) {
@@ -318,10 +375,17 @@ func (a *agentImpl) Push(ctx context.Context, route string, v interface{}) error
 	// 		a.Session.ID(), a.Session.UID(), route, v)
 	// }
 
-	midVar := pcontext.GetRelationMsgIdFromContext(ctx, a.Session.UID())
-	mid := uint(midVar)
+	return a.send(pendingMessage{ctx: ctx, typ: message.Push, route: route, payload: v, mid: a.relatedMsgID(ctx)})
+}
 
-	return a.send(pendingMessage{ctx: ctx, typ: message.Push, route: route, payload: v, mid: mid})
+// relatedMsgID returns the id of the request that originated a push or kick,
+// requests made by another session of the same user are not related to it
+func (a *agentImpl) relatedMsgID(ctx context.Context) uint {
+	data := pcontext.GetRelationDataFromContextByUID(ctx, a.Session.UID())
+	if data.SessID != 0 && data.SessID != a.Session.ID() {
+		return 0
+	}
+	return uint(data.MsgID)
 }
 
 // ResponseMID implementation for NetworkEntity interface
@@ -331,7 +395,7 @@ func (a *agentImpl) ResponseMID(ctx context.Context, mid uint, v interface{}, is
 	if len(isError) > 0 {
 		err = isError[0]
 	}
-	if a.GetStatus() == constants.StatusClosed {
+	if status := a.GetStatus(); status == constants.StatusClosed || status == constants.StatusSuspended {
 		return errors.NewError(constants.ErrBrokenPipe, errors.ErrClientClosedRequest)
 	}
 
@@ -358,10 +422,12 @@ func (a *agentImpl) ResponseMID(ctx context.Context, mid uint, v interface{}, is
 func (a *agentImpl) Close() error {
 	a.closeMutex.Lock()
 	defer a.closeMutex.Unlock()
-	if a.GetStatus() == constants.StatusClosed {
+	status := a.GetStatus()
+	if status == constants.StatusClosed {
 		return constants.ErrCloseClosedSession
 	}
 	a.SetStatus(constants.StatusClosed)
+	a.cancel()
 
 	logger.Log.Debugf("Session closed, ID=%d, UID=%s, IP=%s",
 		a.Session.ID(), a.Session.UID(), a.conn.RemoteAddr())
@@ -371,18 +437,31 @@ func (a *agentImpl) Close() error {
 	case <-a.chDie:
 		// expect
 	default:
-		close(a.chStopWrite)
-		close(a.chStopOrder)
-		close(a.chStopHeartbeat)
+		// a suspended agent already stopped its goroutines
+		if status != constants.StatusSuspended {
+			close(a.chStopWrite)
+			close(a.chStopOrder)
+			close(a.chStopHeartbeat)
+		}
 		close(a.chDie)
 		a.onSessionClosed(a.Session)
 	}
 
+	if a.resume != nil {
+		a.resume.remove(a.resumeToken, a)
+	}
+
 	metrics.ReportNumberOfConnectedClients(a.metricsReporters, a.sessionPool.GetSessionCount())
 
 	return a.conn.Close()
 }
 
+// Context returns the context of the requests of the client, it is canceled
+// when the agent is closed so that the handlers in flight can stop early
+func (a *agentImpl) Context() context.Context {
+	return a.ctx
+}
+
 // RemoteAddr implementation for NetworkEntity interface
 // returns the remote network address.
 func (a *agentImpl) RemoteAddr() net.Addr {
@@ -394,6 +473,26 @@ func (a *agentImpl) String() string {
 	return fmt.Sprintf("Remote=%s, LastTime=%d", a.conn.RemoteAddr().String(), atomic.LoadInt64(&a.lastAt))
 }
 
+// DecodeMessage decodes a message sent by the client, which must not be
+// compressed or be compressed with the compression negotiated in the handshake
+func (a *agentImpl) DecodeMessage(data []byte) (*message.Message, error) {
+	return message.DecodeWithCompression(data, a.getMessageEncoder().Compression())
+}
+
+// getSerializer returns the serializer negotiated in the handshake
+func (a *agentImpl) getSerializer() serialize.Serializer {
+	a.codecMutex.RLock()
+	defer a.codecMutex.RUnlock()
+	return a.serializer
+}
+
+// getMessageEncoder returns the message encoder negotiated in the handshake
+func (a *agentImpl) getMessageEncoder() message.Encoder {
+	a.codecMutex.RLock()
+	defer a.codecMutex.RUnlock()
+	return a.messageEncoder
+}
+
 // GetStatus gets the status
 func (a *agentImpl) GetStatus() int32 {
 	return atomic.LoadInt32(&a.state)
@@ -401,14 +500,18 @@ func (a *agentImpl) GetStatus() int32 {
 
 // Kick sends a kick packet to a client
 func (a *agentImpl) Kick(ctx context.Context) error {
-	midVar := pcontext.GetRelationMsgIdFromContext(ctx, a.Session.UID())
-	mid := uint(midVar)
+	mid := a.relatedMsgID(ctx)
 
 	if a.GetStatus() == constants.StatusClosed {
 		logger.Log.Debugf("can't send kick, session has closed, SessionID=%d, UID=%s", a.Session.ID(), a.Session.UID())
 		return nil
 	}
 
+	if a.GetStatus() == constants.StatusSuspended {
+		logger.Log.Debugf("can't send kick, session is suspended, SessionID=%d, UID=%s", a.Session.ID(), a.Session.UID())
+		return nil
+	}
+
 	fn := func() error {
 		// packet encode
 		p, err := a.encoder.Encode(packet.Kick, nil)
@@ -435,7 +538,7 @@ func (a *agentImpl) Kick(ctx context.Context) error {
 
 // SetLastAt sets the last at to now
 func (a *agentImpl) SetLastAt() {
-	atomic.StoreInt64(&a.lastAt, time.Now().Unix())
+	atomic.StoreInt64(&a.lastAt, a.clock.Now().Unix())
 }
 
 // SetStatus sets the agent status
@@ -474,17 +577,17 @@ func (a *agentImpl) IPVersion() string {
 }
 
 func (a *agentImpl) heartbeat() {
-	ticker := time.NewTicker(a.heartbeatTimeout)
+	ticker := a.clock.NewTicker(a.heartbeatTimeout)
 
 	defer func() {
 		ticker.Stop()
-		a.Close()
+		a.stop()
 	}()
 
 	for {
 		select {
-		case <-ticker.C:
-			deadline := time.Now().Add(-2 * a.heartbeatTimeout).Unix()
+		case <-ticker.C():
+			deadline := a.clock.Now().Add(-2 * a.heartbeatTimeout).Unix()
 			if atomic.LoadInt64(&a.lastAt) < deadline {
 				logger.Log.Debugf("Session heartbeat timeout, LastTime=%d, Deadline=%d", atomic.LoadInt64(&a.lastAt), deadline)
 				return
@@ -522,16 +625,73 @@ func (a *agentImpl) onSessionClosed(s session.Session) {
 	}
 }
 
-// SendHandshakeResponse sends a handshake response
-func (a *agentImpl) SendHandshakeResponse() error {
-	_, err := a.conn.Write(hrd)
+// SendHandshakeResponse negotiates the settings of the session with the
+// handshake data of the client and sends them in the handshake response,
+// clients whose settings are not supported get an error response instead
+func (a *agentImpl) SendHandshakeResponse(data *session.HandshakeData) error {
+	// the session is handed over even on failure so that it is closed along with this agent
+	if a.resuming != nil {
+		defer a.completeResume()
+	}
+
+	compressionName := a.getMessageEncoder().Compression()
+	serializers := []string{a.getSerializer().GetName()}
+	for _, name := range a.serializers.Names() {
+		if !contains(serializers, name) {
+			serializers = append(serializers, name)
+		}
+	}
+	h, err := negotiateHandshake(data, a.handshakeConfig, serializers, compressionName)
+	if err != nil {
+		// rejected clients are answered in the serializer they prefer
+		var preferred string
+		if len(data.Sys.Serializers) > 0 {
+			preferred = data.Sys.Serializers[0]
+		}
+		p, encodeErr := encodeHandshakeError(a.encoder, err.(*errors.Error), isProtobufHandshake(data, preferred))
+		if encodeErr == nil {
+			_, encodeErr = a.conn.Write(p)
+		}
+		if encodeErr != nil {
+			logger.Log.Warnf("Failed to send handshake error: %s", encodeErr.Error())
+		}
+		return err
+	}
+	a.codecMutex.Lock()
+	if h.compression != compressionName {
+		a.messageEncoder = message.NewMessagesEncoderWithCodec(message.GetCodec(h.compression))
+	}
+	if h.serializer != a.serializer.GetName() {
+		a.serializer = a.serializers.Get(h.serializer)
+	}
+	serializer := a.serializer
+	a.codecMutex.Unlock()
+	a.Session.SetSerializer(serializer)
+
+	// resumable sessions get their own token on every handshake
+	sys := h.sys(data.Sys.DictionaryID)
+	var token string
+	if a.resume != nil {
+		token = newResumeToken()
+		sys["resumeToken"] = token
+		sys["resumed"] = a.resuming != nil
+	}
+	p, err := encodeHandshakeResponse(a.heartbeatTimeout, a.encoder, h.compression == message.CompressionDeflate, h.serializer, sys, isProtobufHandshake(data, h.serializer))
+	if err == nil {
+		_, err = a.conn.Write(p)
+	}
+	if err == nil && a.resume != nil {
+		a.resumeToken = token
+		a.resume.register(token, a)
+	}
 	return err
 }
 
 func (a *agentImpl) write() {
 	// clean func
 	defer func() {
-		a.Close()
+		close(a.chWriteDone)
+		a.stop()
 	}()
 
 	for {
@@ -539,6 +699,12 @@ func (a *agentImpl) write() {
 		case pWrite := <-a.chSend:
 			// close agent if low-level Conn broken
 			if _, err := a.conn.Write(pWrite.data); err != nil {
+				if a.resume != nil && pWrite.msg != nil && pWrite.msg.Type == message.Push {
+					// kept to be sent again if the session is resumed
+					a.failedWrite = &pWrite
+					logger.Log.Debugf("Failed to write in conn: %s", err.Error())
+					return
+				}
 				tracing.FinishSpan(pWrite.ctx, err)
 				metrics.ReportTimingFromCtx(pWrite.ctx, a.metricsReporters, handlerType, err)
 				logger.Log.Errorf("Failed to write in conn: %s", err.Error())
@@ -573,7 +739,7 @@ func (a *agentImpl) AnswerWithError(ctx context.Context, mid uint, err error) {
 			tracing.LogError(s, err.Error())
 		}
 	}
-	p, e := util.GetErrorPayload(a.serializer, err)
+	p, e := util.GetErrorPayload(a.getSerializer(), err)
 	if e != nil {
 		logger.Log.Errorf("error answering the user with an error: %s", e.Error())
 		return
@@ -584,36 +750,8 @@ func (a *agentImpl) AnswerWithError(ctx context.Context, mid uint, err error) {
 	}
 }
 
-func hbdEncode(heartbeatTimeout time.Duration, packetEncoder codec.PacketEncoder, dataCompression bool, serializerName string) {
-	hData := map[string]interface{}{
-		"code": 200,
-		"sys": map[string]interface{}{
-			"heartbeat":  heartbeatTimeout.Seconds(),
-			"dict":       message.GetDictionary(),
-			"serializer": serializerName,
-		},
-	}
-	data, err := gojson.Marshal(hData)
-	if err != nil {
-		panic(err)
-	}
-
-	if dataCompression {
-		compressedData, err := compression.DeflateData(data)
-		if err != nil {
-			panic(err)
-		}
-
-		if len(compressedData) < len(data) {
-			data = compressedData
-		}
-	}
-
-	hrd, err = packetEncoder.Encode(packet.Handshake, data)
-	if err != nil {
-		panic(err)
-	}
-
+func hbdEncode(packetEncoder codec.PacketEncoder) {
+	var err error
 	hbd, err = packetEncoder.Encode(packet.Heartbeat, nil)
 	if err != nil {
 		panic(err)
@@ -632,15 +770,51 @@ func (a *agentImpl) reportChannelSize() {
 	}
 }
 
+// reportCompressionRatio reports the size of the encoded data of a message
+// relative to its size before compression, responses are reported with the
+// route of their request
+func (a *agentImpl) reportCompressionRatio(ctx context.Context, m *message.Message, size int) {
+	if size == 0 || len(a.metricsReporters) == 0 {
+		return
+	}
+	encoder := a.getMessageEncoder()
+	if !encoder.IsCompressionEnabled() {
+		return
+	}
+	route := m.Route
+	if route == "" && ctx != nil {
+		if r, ok := pcontext.GetFromPropagateCtx(ctx, constants.RouteKey).(string); ok {
+			route = r
+		}
+	}
+	tags := map[string]string{"route": route, "compression": encoder.Compression()}
+	for _, mr := range a.metricsReporters {
+		if err := mr.ReportSummary(metrics.CompressionRatio, tags, float64(len(m.Data))/float64(size)); err != nil {
+			logger.Log.Warnf("failed to report compression ratio: %s", err.Error())
+		}
+	}
+}
+
 func (a *agentImpl) ordered() {
 	// clean func
 	defer func() {
-		a.Close()
+		a.stop()
 	}()
 
 	send := func(pWrite pendingWrite) {
+		// once stopped the remaining messages are kept in order for a resumed session
+		select {
+		case <-a.chStopOrder:
+			a.unordered = append(a.unordered, pWrite)
+			return
+		default:
+		}
+
 		select {
 		case a.chSend <- pWrite:
+		case <-a.chStopOrder:
+			a.unordered = append(a.unordered, pWrite)
+			return
 		case <-a.chDie:
 		}
 
@@ -654,72 +828,73 @@ func (a *agentImpl) ordered() {
 				a.Session.ID(), a.Session.UID(), msg.ID, msg.Route, len(msg.Data))
 		}
 	}
+	var expire <-chan time.Time
+	if a.pushFreeze.MaxAge > 0 {
+		ticker := time.NewTicker(a.pushFreeze.MaxAge / 2)
+		defer ticker.Stop()
+		expire = ticker.C
+	}
+
 	for {
 		select {
 		case pWrite := <-a.chOrder:
 			m := pWrite.msg
 
 			if m.Type == message.Push && m.ID > 0 && m.ID > a.curMsgID {
-				tID := m.ID
-				a.pushDelay[tID] = append(a.pushDelay[tID], pWrite)
-				a.pushDelayMID = tID
-				logger.Log.Debugf("freeze push msg, ID=%d, UID=%s, relation.id=%v route=%s",
-					a.Session.ID(), a.Session.UID(), tID, m.Route)
+				a.freeze(m.ID, pWrite, send)
 				continue
 			}
 
-			if m.Type == message.Push && a.pushDelayMID > 0 {
-				tID := a.pushDelayMID
-				a.pushDelay[tID] = append(a.pushDelay[tID], pWrite)
-				logger.Log.Debugf("freeze push msg, ID=%d, UID=%s, relation.id=%v route=%s len=%d",
-					a.Session.ID(), a.Session.UID(), tID, m.Route, len(a.pushDelay[tID]))
-				continue
+			if m.Type == message.Push {
+				if mid := a.frozenMsgID(); mid > 0 {
+					a.freeze(mid, pWrite, send)
+					continue
+				}
 			}
 
 			send(pWrite)
 
 			if m.Type == message.Response {
-				a.curMsgID = m.ID
-				if val, ok := a.pushDelay[m.ID]; ok {
+				a.setCurMsgID(m.ID)
+				if val := a.unfreeze(m.ID, freezeReasonResponse); val != nil {
 					logger.Log.Debugf("restore push msg, ID=%d, UID=%s, relation.id=%v len=%d",
-						a.Session.ID(), a.Session.UID(), a.pushDelayMID, len(a.pushDelay[m.ID]))
+						a.Session.ID(), a.Session.UID(), m.ID, len(val))
 					for _, v := range val {
 						logger.Log.Debugf("restore push msg, ID=%d, UID=%s, relation.id=%v route=%s",
 							a.Session.ID(), a.Session.UID(), m.ID, v.msg.Route)
 						send(v)
 					}
-					delete(a.pushDelay, m.ID)
-					a.pushDelayMID = 0
+					a.setFrozenMsgID(0)
 				}
 
-				if len(a.pushDelay) > 0 {
-					keys := make([]uint, 0, len(a.pushDelay))
-					for key := range a.pushDelay {
-						keys = append(keys, key)
+				a.freezeMutex.Lock()
+				keys := a.frozenIDs()
+				a.freezeMutex.Unlock()
+				for _, id := range keys {
+					if id >= a.curMsgID {
+						break
 					}
-					sort.Slice(keys, func(i, j int) bool {
-						return keys[i] < keys[j]
-					})
-					for _, id := range keys {
-						if id >= a.curMsgID {
-							break
-						}
 
-						if val, ok := a.pushDelay[id]; ok {
-							logger.Log.Debugf("restore old push msg, ID=%d, UID=%s, relation.id=%v len=%d",
-								a.Session.ID(), a.Session.UID(), a.pushDelayMID, len(a.pushDelay[id]))
-							for _, v := range val {
-								logger.Log.Debugf("restore old push msg, ID=%d, UID=%s, relation.id=%v route=%s",
-									a.Session.ID(), a.Session.UID(), id, v.msg.Route)
-								send(v)
-							}
-							delete(a.pushDelay, id)
-							a.pushDelayMID = 0
+					if val := a.unfreeze(id, freezeReasonResponse); val != nil {
+						logger.Log.Debugf("restore old push msg, ID=%d, UID=%s, relation.id=%v len=%d",
+							a.Session.ID(), a.Session.UID(), id, len(val))
+						for _, v := range val {
+							logger.Log.Debugf("restore old push msg, ID=%d, UID=%s, relation.id=%v route=%s",
+								a.Session.ID(), a.Session.UID(), id, v.msg.Route)
+							send(v)
 						}
+						a.setFrozenMsgID(0)
 					}
 				}
 			}
+		case now := <-expire:
+			a.expireFrozen(now, send)
 		case <-a.chStopOrder:
+			if a.GetStatus() == constants.StatusSuspended {
+				a.park()
+			} else {
+				a.dropFrozen(a.takeAllFrozen(freezeReasonClosed), freezeReasonClosed)
+			}
 			return
 		}
 	}
@@ -84,6 +84,7 @@ func NewRemote(
 		return nil, err
 	}
 	_ = s.Set(constants.FrontendSessionID, sess.GetId())
+	s.SetSerializer(serializer)
 	a.Session = s
 
 	return a, nil
@@ -212,6 +213,7 @@ func (a *Remote) sendPush(m pendingMessage, userID string, sv *cluster.Server) (
 		Uid:           a.Session.UID(),
 		Data:          payload,
 		RelationMsgId: uint64(pcontext.GetRelationMsgIdFromContext(m.ctx, a.Session.UID())),
+		SessionId:     pcontext.GetSessionIdFromContext(m.ctx, a.Session.UID()),
 	}
 	return a.rpcClient.SendPush(userID, sv, push)
 }
@@ -160,7 +160,7 @@ func TestAgentRemotePush(t *testing.T) {
 			if table.err != constants.ErrNoUIDBind {
 				mockSD.EXPECT().GetServer(fSvID).Return(cluster.NewServer(fSvID, "connector", true), nil)
 			}
-			err = remote.Push(route, table.data)
+			err = remote.Push(context.Background(), route, table.data)
 			assert.Equal(t, table.err, err)
 		})
 	}
@@ -33,6 +33,8 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/topfreegames/pitaya/v2/config"
+	"github.com/topfreegames/pitaya/v2/conn/codec"
 	codecmocks "github.com/topfreegames/pitaya/v2/conn/codec/mocks"
 	"github.com/topfreegames/pitaya/v2/conn/message"
 	messagemocks "github.com/topfreegames/pitaya/v2/conn/message/mocks"
@@ -45,6 +47,7 @@ import (
 	metricsmocks "github.com/topfreegames/pitaya/v2/metrics/mocks"
 	"github.com/topfreegames/pitaya/v2/mocks"
 	"github.com/topfreegames/pitaya/v2/protos"
+	"github.com/topfreegames/pitaya/v2/relation"
 	serializemocks "github.com/topfreegames/pitaya/v2/serialize/mocks"
 	"github.com/topfreegames/pitaya/v2/session"
 )
@@ -70,7 +73,6 @@ func TestNewAgent(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockSerializer := serializemocks.NewMockSerializer(ctrl)
-	mockSerializer.EXPECT().GetName().Times(2)
 
 	mockMetricsReporter := metricsmocks.NewMockReporter(ctrl)
 	mockEncoder := codecmocks.NewMockPacketEncoder(ctrl)
@@ -80,11 +82,6 @@ func TestNewAgent(t *testing.T) {
 
 	mockConn := mocks.NewMockPlayerConn(ctrl)
 
-	mockEncoder.EXPECT().Encode(gomock.Any(), gomock.Not(gomock.Nil())).Do(
-		func(typ packet.Type, d []byte) {
-			// cannot compare inside the expect because they are equivalent but not equal
-			assert.EqualValues(t, packet.Handshake, typ)
-		})
 	mockEncoder.EXPECT().Encode(gomock.Any(), gomock.Nil()).Do(
 		func(typ packet.Type, d []byte) {
 			assert.EqualValues(t, packet.Heartbeat, typ)
@@ -138,8 +135,6 @@ func TestKick(t *testing.T) {
 	mockConn.EXPECT().Write(gomock.Any()).Return(0, nil)
 	messageEncoder := message.NewMessagesEncoder(false)
 
-	mockSerializer.EXPECT().GetName()
-
 	sessionPool := session.NewSessionPool()
 	ag := newAgent(mockConn, mockDecoder, mockEncoder, mockSerializer, hbTime, 10, dieChan, messageEncoder, nil, sessionPool)
 	c := context.Background()
@@ -170,13 +165,12 @@ func TestAgentSend(t *testing.T) {
 			messageEncoder := message.NewMessagesEncoder(false)
 
 			mockConn := mocks.NewMockPlayerConn(ctrl)
-			mockSerializer.EXPECT().GetName()
 			sessionPool := session.NewSessionPool()
 			ag := newAgent(mockConn, mockDecoder, mockEncoder, mockSerializer, hbTime, 10, dieChan, messageEncoder, nil, sessionPool).(*agentImpl)
 			assert.NotNil(t, ag)
 
 			if table.err != nil {
-				close(ag.chSend)
+				close(ag.chOrder)
 			}
 			pm := pendingMessage{}
 
@@ -192,10 +186,11 @@ func TestAgentSend(t *testing.T) {
 				ctx:  nil,
 				data: expectedBytes,
 				err:  nil,
+				msg:  &message.Message{Data: expectedBytes},
 			}
 
 			if table.err == nil {
-				recv := helpers.ShouldEventuallyReceive(t, ag.chSend).(pendingWrite)
+				recv := helpers.ShouldEventuallyReceive(t, ag.chOrder).(pendingWrite)
 				assert.Equal(t, expectedWrite, recv)
 			}
 		})
@@ -214,15 +209,17 @@ func TestAgentSendSerializeErr(t *testing.T) {
 	mockMetricsReporters := []metrics.Reporter{mockMetricsReporter}
 	sessionPool := session.NewSessionPool()
 	ag := &agentImpl{ // avoid heartbeat and handshake to fully test serialize
-		conn:             mockConn,
-		chSend:           make(chan pendingWrite, 1),
-		encoder:          mockEncoder,
-		heartbeatTimeout: time.Second,
-		lastAt:           time.Now().Unix(),
-		serializer:       mockSerializer,
-		messageEncoder:   messageEncoder,
-		metricsReporters: mockMetricsReporters,
-		Session:          sessionPool.NewSession(nil, true),
+		conn:               mockConn,
+		chSend:             make(chan pendingWrite, 1),
+		chOrder:            make(chan pendingWrite, 1),
+		messagesBufferSize: 1,
+		encoder:            mockEncoder,
+		heartbeatTimeout:   time.Second,
+		lastAt:             time.Now().Unix(),
+		serializer:         mockSerializer,
+		messageEncoder:     messageEncoder,
+		metricsReporters:   mockMetricsReporters,
+		Session:            sessionPool.NewSession(nil, true),
 	}
 
 	ctx := getCtxWithRequestKeys()
@@ -264,6 +261,8 @@ func TestAgentSendSerializeErr(t *testing.T) {
 	go ag.write()
 	mockMetricsReporter.EXPECT().ReportGauge(gomock.Any(), gomock.Any(), gomock.Any())
 	ag.send(expected)
+	// the ordered goroutine is not running
+	ag.chSend <- <-ag.chOrder
 	wg.Wait()
 
 }
@@ -273,7 +272,6 @@ func TestAgentPushFailsIfClosedAgent(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockSerializer := serializemocks.NewMockSerializer(ctrl)
-	mockSerializer.EXPECT().GetName()
 
 	mockEncoder := codecmocks.NewMockPacketEncoder(ctrl)
 	heartbeatAndHandshakeMocks(mockEncoder)
@@ -283,7 +281,7 @@ func TestAgentPushFailsIfClosedAgent(t *testing.T) {
 	ag := newAgent(nil, nil, mockEncoder, mockSerializer, time.Second, 10, nil, messageEncoder, nil, sessionPool).(*agentImpl)
 	assert.NotNil(t, ag)
 	ag.state = constants.StatusClosed
-	err := ag.Push("", nil)
+	err := ag.Push(context.Background(), "", nil)
 	assert.Equal(t, e.NewError(constants.ErrBrokenPipe, e.ErrClientClosedRequest), err)
 }
 
@@ -312,7 +310,6 @@ func TestAgentPushStruct(t *testing.T) {
 			mockConn := mocks.NewMockPlayerConn(ctrl)
 			mockMetricsReporters := []metrics.Reporter{mockMetricsReporter}
 			mockMetricsReporter.EXPECT().ReportGauge(metrics.ConnectedClients, gomock.Any(), gomock.Any())
-			mockSerializer.EXPECT().GetName()
 			sessionPool := session.NewSessionPool()
 			ag := newAgent(mockConn, mockDecoder, mockEncoder, mockSerializer, hbTime, 10, dieChan, messageEncoder, mockMetricsReporters, sessionPool).(*agentImpl)
 			assert.NotNil(t, ag)
@@ -327,18 +324,18 @@ func TestAgentPushStruct(t *testing.T) {
 			assert.NoError(t, err)
 			mockSerializer.EXPECT().Marshal(table.data).Return(expectedBytes, nil)
 			mockEncoder.EXPECT().Encode(packet.Type(packet.Data), em).Return(expectedBytes, nil)
-			expectedWrite := pendingWrite{ctx: nil, data: expectedBytes, err: nil}
+			expectedWrite := pendingWrite{ctx: context.Background(), data: expectedBytes, err: nil, msg: msg}
 
 			if table.err != nil {
-				close(ag.chSend)
+				close(ag.chOrder)
 			}
 
 			mockMetricsReporter.EXPECT().ReportGauge(metrics.ChannelCapacity, gomock.Any(), float64(10))
-			err = ag.Push(msg.Route, table.data)
+			err = ag.Push(context.Background(), msg.Route, table.data)
 			assert.Equal(t, table.err, err)
 
 			if table.err == nil {
-				recvData := helpers.ShouldEventuallyReceive(t, ag.chSend).(pendingWrite)
+				recvData := helpers.ShouldEventuallyReceive(t, ag.chOrder).(pendingWrite)
 				assert.Equal(t, expectedWrite, recvData)
 			}
 		})
@@ -371,7 +368,6 @@ func TestAgentPush(t *testing.T) {
 			mockConn := mocks.NewMockPlayerConn(ctrl)
 			mockMetricsReporters := []metrics.Reporter{mockMetricsReporter}
 			mockMetricsReporter.EXPECT().ReportGauge(metrics.ConnectedClients, gomock.Any(), gomock.Any())
-			mockSerializer.EXPECT().GetName()
 			sessionPool := session.NewSessionPool()
 			ag := newAgent(mockConn, mockDecoder, mockEncoder, mockSerializer, hbTime, 10, dieChan, messageEncoder, mockMetricsReporters, sessionPool).(*agentImpl)
 			assert.NotNil(t, ag)
@@ -385,18 +381,18 @@ func TestAgentPush(t *testing.T) {
 			em, err := messageEncoder.Encode(msg)
 			assert.NoError(t, err)
 			mockEncoder.EXPECT().Encode(packet.Type(packet.Data), em).Return(expectedBytes, nil)
-			expectedWrite := pendingWrite{ctx: nil, data: expectedBytes, err: nil}
+			expectedWrite := pendingWrite{ctx: context.Background(), data: expectedBytes, err: nil, msg: msg}
 
 			if table.err != nil {
-				close(ag.chSend)
+				close(ag.chOrder)
 			}
 
 			mockMetricsReporter.EXPECT().ReportGauge(metrics.ChannelCapacity, gomock.Any(), float64(10))
-			err = ag.Push(msg.Route, table.data)
+			err = ag.Push(context.Background(), msg.Route, table.data)
 			assert.Equal(t, table.err, err)
 
 			if table.err == nil {
-				recvData := helpers.ShouldEventuallyReceive(t, ag.chSend).(pendingWrite)
+				recvData := helpers.ShouldEventuallyReceive(t, ag.chOrder).(pendingWrite)
 				assert.Equal(t, expectedWrite, recvData)
 			}
 		})
@@ -418,27 +414,19 @@ func TestAgentPushFullChannel(t *testing.T) {
 	mockConn := mocks.NewMockPlayerConn(ctrl)
 	mockMetricsReporters := []metrics.Reporter{mockMetricsReporter}
 	mockMetricsReporter.EXPECT().ReportGauge(metrics.ConnectedClients, gomock.Any(), gomock.Any())
-	mockSerializer.EXPECT().GetName()
 	sessionPool := session.NewSessionPool()
 	ag := newAgent(mockConn, mockDecoder, mockEncoder, mockSerializer, hbTime, 0, dieChan, messageEncoder, mockMetricsReporters, sessionPool).(*agentImpl)
 	assert.NotNil(t, ag)
 
 	mockMetricsReporter.EXPECT().ReportGauge(metrics.ChannelCapacity, gomock.Any(), float64(0))
+	// the agent is closed as its network is busy
+	mockMetricsReporter.EXPECT().ReportGauge(metrics.ConnectedClients, gomock.Any(), gomock.Any())
+	mockConn.EXPECT().RemoteAddr()
+	mockConn.EXPECT().Close()
 
-	msg := &message.Message{
-		Route: "route",
-		Data:  []byte("data"),
-		Type:  message.Push,
-	}
-	em, err := messageEncoder.Encode(msg)
+	err := ag.Push(context.Background(), "route", []byte("data"))
 	assert.NoError(t, err)
-
-	mockEncoder.EXPECT().Encode(packet.Type(packet.Data), em)
-	go func() {
-		err := ag.Push(msg.Route, []byte("data"))
-		assert.NoError(t, err)
-	}()
-	helpers.ShouldEventuallyReceive(t, ag.chSend)
+	assert.Equal(t, constants.StatusClosed, ag.GetStatus())
 }
 
 func TestAgentResponseMIDFailsIfClosedAgent(t *testing.T) {
@@ -451,7 +439,6 @@ func TestAgentResponseMIDFailsIfClosedAgent(t *testing.T) {
 	mockMetricsReporter := metricsmocks.NewMockReporter(ctrl)
 
 	mockSerializer := serializemocks.NewMockSerializer(ctrl)
-	mockSerializer.EXPECT().GetName()
 
 	mockMetricsReporters := []metrics.Reporter{mockMetricsReporter}
 	mockMetricsReporter.EXPECT().ReportGauge(metrics.ConnectedClients, gomock.Any(), gomock.Any())
@@ -498,7 +485,6 @@ func TestAgentResponseMID(t *testing.T) {
 			mockConn := mocks.NewMockPlayerConn(ctrl)
 			mockMetricsReporters := []metrics.Reporter{mockMetricsReporter}
 			mockMetricsReporter.EXPECT().ReportGauge(metrics.ConnectedClients, gomock.Any(), gomock.Any())
-			mockSerializer.EXPECT().GetName()
 			sessionPool := session.NewSessionPool()
 			ag := newAgent(mockConn, mockDecoder, mockEncoder, mockSerializer, hbTime, 10, dieChan, messageEncoder, mockMetricsReporters, sessionPool).(*agentImpl)
 			assert.NotNil(t, ag)
@@ -510,7 +496,7 @@ func TestAgentResponseMID(t *testing.T) {
 			}
 			if table.mid != 0 {
 				if table.err != nil {
-					close(ag.chSend)
+					close(ag.chOrder)
 				}
 			}
 			if reflect.TypeOf(table.data) != reflect.TypeOf([]byte{}) {
@@ -526,7 +512,7 @@ func TestAgentResponseMID(t *testing.T) {
 			assert.Equal(t, table.err, err)
 
 			if table.err == nil {
-				recv := helpers.ShouldEventuallyReceive(t, ag.chSend).(pendingWrite)
+				recv := helpers.ShouldEventuallyReceive(t, ag.chOrder).(pendingWrite)
 				assert.Equal(t, expected.ctx, recv.ctx)
 				assert.Equal(t, expected.data, recv.data)
 				if table.msgErr {
@@ -552,17 +538,18 @@ func TestAgentResponseMIDFullChannel(t *testing.T) {
 	mockConn := mocks.NewMockPlayerConn(ctrl)
 	mockMetricsReporters := []metrics.Reporter{mockMetricsReporter}
 	mockMetricsReporter.EXPECT().ReportGauge(metrics.ConnectedClients, gomock.Any(), gomock.Any())
-	mockSerializer.EXPECT().GetName()
-	mockEncoder.EXPECT().Encode(packet.Type(packet.Data), gomock.Any())
 	sessionPool := session.NewSessionPool()
 	ag := newAgent(mockConn, mockDecoder, mockEncoder, mockSerializer, hbTime, 0, dieChan, messageEncoder, mockMetricsReporters, sessionPool).(*agentImpl)
 	assert.NotNil(t, ag)
 	mockMetricsReporters[0].(*metricsmocks.MockReporter).EXPECT().ReportGauge(metrics.ChannelCapacity, gomock.Any(), float64(0))
-	go func() {
-		err := ag.ResponseMID(nil, 1, []byte("data"))
-		assert.NoError(t, err)
-	}()
-	helpers.ShouldEventuallyReceive(t, ag.chSend)
+	// the agent is closed as its network is busy
+	mockMetricsReporter.EXPECT().ReportGauge(metrics.ConnectedClients, gomock.Any(), gomock.Any())
+	mockConn.EXPECT().RemoteAddr()
+	mockConn.EXPECT().Close()
+
+	err := ag.ResponseMID(getCtxWithRequestKeys(), 1, []byte("data"))
+	assert.NoError(t, err)
+	assert.Equal(t, constants.StatusClosed, ag.GetStatus())
 }
 
 func TestAgentCloseFailsIfAlreadyClosed(t *testing.T) {
@@ -573,7 +560,6 @@ func TestAgentCloseFailsIfAlreadyClosed(t *testing.T) {
 	heartbeatAndHandshakeMocks(mockEncoder)
 	mockMessageEncoder := messagemocks.NewMockEncoder(ctrl)
 	mockSerializer := serializemocks.NewMockSerializer(ctrl)
-	mockSerializer.EXPECT().GetName()
 
 	sessionPool := session.NewSessionPool()
 	ag := newAgent(nil, nil, mockEncoder, mockSerializer, time.Second, 10, nil, mockMessageEncoder, nil, sessionPool).(*agentImpl)
@@ -592,7 +578,6 @@ func TestAgentClose(t *testing.T) {
 	heartbeatAndHandshakeMocks(mockEncoder)
 	mockMessageEncoder := messagemocks.NewMockEncoder(ctrl)
 	mockSerializer := serializemocks.NewMockSerializer(ctrl)
-	mockSerializer.EXPECT().GetName()
 
 	sessionPool := session.NewSessionPool()
 	ag := newAgent(mockConn, nil, mockEncoder, mockSerializer, time.Second, 0, nil, mockMessageEncoder, nil, sessionPool).(*agentImpl)
@@ -640,7 +625,6 @@ func TestAgentRemoteAddr(t *testing.T) {
 	heartbeatAndHandshakeMocks(mockEncoder)
 	mockMessageEncoder := messagemocks.NewMockEncoder(ctrl)
 	mockSerializer := serializemocks.NewMockSerializer(ctrl)
-	mockSerializer.EXPECT().GetName()
 
 	sessionPool := session.NewSessionPool()
 	ag := newAgent(mockConn, nil, mockEncoder, mockSerializer, time.Second, 0, nil, mockMessageEncoder, nil, sessionPool)
@@ -661,7 +645,6 @@ func TestAgentString(t *testing.T) {
 	heartbeatAndHandshakeMocks(mockEncoder)
 	mockMessageEncoder := messagemocks.NewMockEncoder(ctrl)
 	mockSerializer := serializemocks.NewMockSerializer(ctrl)
-	mockSerializer.EXPECT().GetName()
 
 	sessionPool := session.NewSessionPool()
 	ag := newAgent(mockConn, nil, mockEncoder, mockSerializer, time.Second, 0, nil, mockMessageEncoder, nil, sessionPool).(*agentImpl)
@@ -693,7 +676,6 @@ func TestAgentGetStatus(t *testing.T) {
 			mockMessageEncoder := messagemocks.NewMockEncoder(ctrl)
 
 			mockSerializer := serializemocks.NewMockSerializer(ctrl)
-			mockSerializer.EXPECT().GetName()
 
 			sessionPool := session.NewSessionPool()
 			ag := newAgent(mockConn, nil, mockEncoder, mockSerializer, time.Second, 0, nil, mockMessageEncoder, nil, sessionPool).(*agentImpl)
@@ -715,7 +697,6 @@ func TestAgentSetLastAt(t *testing.T) {
 	heartbeatAndHandshakeMocks(mockEncoder)
 	mockMessageEncoder := messagemocks.NewMockEncoder(ctrl)
 	mockSerializer := serializemocks.NewMockSerializer(ctrl)
-	mockSerializer.EXPECT().GetName()
 
 	sessionPool := session.NewSessionPool()
 	ag := newAgent(nil, nil, mockEncoder, mockSerializer, time.Second, 0, nil, mockMessageEncoder, nil, sessionPool).(*agentImpl)
@@ -744,7 +725,6 @@ func TestAgentSetStatus(t *testing.T) {
 			heartbeatAndHandshakeMocks(mockEncoder)
 			mockMessageEncoder := messagemocks.NewMockEncoder(ctrl)
 			mockSerializer := serializemocks.NewMockSerializer(ctrl)
-			mockSerializer.EXPECT().GetName()
 
 			sessionPool := session.NewSessionPool()
 			ag := newAgent(nil, nil, mockEncoder, mockSerializer, time.Second, 0, nil, mockMessageEncoder, nil, sessionPool).(*agentImpl)
@@ -763,7 +743,6 @@ func TestOnSessionClosed(t *testing.T) {
 	heartbeatAndHandshakeMocks(mockEncoder)
 	mockMessageEncoder := messagemocks.NewMockEncoder(ctrl)
 	mockSerializer := serializemocks.NewMockSerializer(ctrl)
-	mockSerializer.EXPECT().GetName()
 	sessionPool := session.NewSessionPool()
 	ag := newAgent(nil, nil, mockEncoder, mockSerializer, time.Second, 0, nil, mockMessageEncoder, nil, sessionPool).(*agentImpl)
 
@@ -785,7 +764,6 @@ func TestOnSessionClosedRecoversIfPanic(t *testing.T) {
 	heartbeatAndHandshakeMocks(mockEncoder)
 	mockMessageEncoder := messagemocks.NewMockEncoder(ctrl)
 	mockSerializer := serializemocks.NewMockSerializer(ctrl)
-	mockSerializer.EXPECT().GetName()
 	sessionPool := session.NewSessionPool()
 	ag := newAgent(nil, nil, mockEncoder, mockSerializer, time.Second, 0, nil, mockMessageEncoder, nil, sessionPool).(*agentImpl)
 
@@ -818,18 +796,20 @@ func TestAgentSendHandshakeResponse(t *testing.T) {
 			defer ctrl.Finish()
 
 			mockConn := mocks.NewMockPlayerConn(ctrl)
-			mockEncoder := codecmocks.NewMockPacketEncoder(ctrl)
-			heartbeatAndHandshakeMocks(mockEncoder)
+			packetEncoder := codec.NewPomeloPacketEncoder()
 			mockMessageEncoder := messagemocks.NewMockEncoder(ctrl)
+			mockMessageEncoder.EXPECT().Compression().Return("")
 			mockSerializer := serializemocks.NewMockSerializer(ctrl)
-			mockSerializer.EXPECT().GetName()
+			mockSerializer.EXPECT().GetName().Return("json").AnyTimes()
 
 			sessionPool := session.NewSessionPool()
-			ag := newAgent(mockConn, nil, mockEncoder, mockSerializer, time.Second, 0, nil, mockMessageEncoder, nil, sessionPool)
+			ag := newAgent(mockConn, nil, packetEncoder, mockSerializer, time.Second, 0, nil, mockMessageEncoder, nil, sessionPool)
 			assert.NotNil(t, ag)
 
+			hrd, err := encodeHandshakeResponse(time.Second, packetEncoder, false, "json", nil, false)
+			assert.NoError(t, err)
 			mockConn.EXPECT().Write(hrd).Return(0, table.err)
-			err := ag.SendHandshakeResponse()
+			err = ag.SendHandshakeResponse(&session.HandshakeData{})
 			assert.Equal(t, table.err, err)
 		})
 	}
@@ -856,7 +836,6 @@ func TestAnswerWithError(t *testing.T) {
 			mockEncoder := codecmocks.NewMockPacketEncoder(ctrl)
 			heartbeatAndHandshakeMocks(mockEncoder)
 			messageEncoder := message.NewMessagesEncoder(false)
-			mockSerializer.EXPECT().GetName()
 			sessionPool := session.NewSessionPool()
 			ag := newAgent(nil, nil, mockEncoder, mockSerializer, time.Second, 1, nil, messageEncoder, nil, sessionPool).(*agentImpl)
 			assert.NotNil(t, ag)
@@ -865,9 +844,9 @@ func TestAnswerWithError(t *testing.T) {
 			if table.getPayloadErr == nil {
 				mockEncoder.EXPECT().Encode(packet.Type(packet.Data), gomock.Any())
 			}
-			ag.AnswerWithError(nil, uint(rand.Int()), errors.New("something went wrong"))
+			ag.AnswerWithError(getCtxWithRequestKeys(), uint(rand.Int()), errors.New("something went wrong"))
 			if table.err == nil {
-				helpers.ShouldEventuallyReceive(t, ag.chSend)
+				helpers.ShouldEventuallyReceive(t, ag.chOrder)
 			}
 		})
 	}
@@ -882,7 +861,6 @@ func TestAgentHeartbeat(t *testing.T) {
 	heartbeatAndHandshakeMocks(mockEncoder)
 	mockConn := mocks.NewMockPlayerConn(ctrl)
 	mockMessageEncoder := messagemocks.NewMockEncoder(ctrl)
-	mockSerializer.EXPECT().GetName()
 	sessionPool := session.NewSessionPool()
 	ag := newAgent(mockConn, nil, mockEncoder, mockSerializer, 1*time.Second, 1, nil, mockMessageEncoder, nil, sessionPool).(*agentImpl)
 	assert.NotNil(t, ag)
@@ -917,7 +895,6 @@ func TestAgentHeartbeatExitsIfConnError(t *testing.T) {
 	heartbeatAndHandshakeMocks(mockEncoder)
 	mockConn := mocks.NewMockPlayerConn(ctrl)
 	mockMessageEncoder := messagemocks.NewMockEncoder(ctrl)
-	mockSerializer.EXPECT().GetName()
 	sessionPool := session.NewSessionPool()
 	ag := newAgent(mockConn, nil, mockEncoder, mockSerializer, 1*time.Second, 1, nil, mockMessageEncoder, nil, sessionPool).(*agentImpl)
 	assert.NotNil(t, ag)
@@ -957,7 +934,6 @@ func TestAgentHeartbeatExitsOnStopHeartbeat(t *testing.T) {
 	mockConn.EXPECT().RemoteAddr().MaxTimes(1)
 	mockConn.EXPECT().Close().MaxTimes(1)
 
-	mockSerializer.EXPECT().GetName()
 	sessionPool := session.NewSessionPool()
 	ag := newAgent(mockConn, nil, mockEncoder, mockSerializer, 1*time.Second, 1, nil, messageEncoder, nil, sessionPool).(*agentImpl)
 	assert.NotNil(t, ag)
@@ -1014,7 +990,6 @@ func TestAgentHandle(t *testing.T) {
 	heartbeatAndHandshakeMocks(mockEncoder)
 	mockConn := mocks.NewMockPlayerConn(ctrl)
 	messageEncoder := message.NewMessagesEncoder(false)
-	mockSerializer.EXPECT().GetName()
 	sessionPool := session.NewSessionPool()
 	ag := newAgent(mockConn, nil, mockEncoder, mockSerializer, 1*time.Second, 1, nil, messageEncoder, nil, sessionPool).(*agentImpl)
 	assert.NotNil(t, ag)
@@ -1065,7 +1040,6 @@ func TestNatsRPCServerReportMetrics(t *testing.T) {
 	mockMetricsReporters := []metrics.Reporter{mockMetricsReporter}
 	mockConn := mocks.NewMockPlayerConn(ctrl)
 	mockMetricsReporter.EXPECT().ReportGauge(metrics.ConnectedClients, gomock.Any(), gomock.Any())
-	mockSerializer.EXPECT().GetName()
 	sessionPool := session.NewSessionPool()
 	ag := newAgent(mockConn, mockDecoder, mockEncoder, mockSerializer, hbTime, 10, dieChan, messageEncoder, mockMetricsReporters, sessionPool).(*agentImpl)
 	assert.NotNil(t, ag)
@@ -1108,3 +1082,43 @@ func TestIPVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestAgentRelatedMsgID(t *testing.T) {
+	ag := newFreezeAgent(config.PushFreezeConfig{}, nil)
+	assert.NoError(t, ag.Session.Bind(context.Background(), "uid"))
+	sessionID := ag.Session.ID()
+
+	tables := []struct {
+		name string
+		data map[string]relation.Data
+		mid  uint
+	}{
+		{"no_relation", nil, 0},
+		{"same_session", map[string]relation.Data{"uid": {MsgID: 3, SessID: sessionID}}, 3},
+		{"unknown_session", map[string]relation.Data{"uid": {MsgID: 3}}, 3},
+		{"another_session", map[string]relation.Data{"uid": {MsgID: 3, SessID: sessionID + 1}}, 0},
+		{"another_user", map[string]relation.Data{"other": {MsgID: 3, SessID: sessionID}}, 0},
+	}
+
+	for _, table := range tables {
+		t.Run(table.name, func(t *testing.T) {
+			ctx := context.Background()
+			if table.data != nil {
+				ctx = context.WithValue(ctx, constants.MsgRelationKey, table.data)
+			}
+			assert.Equal(t, table.mid, ag.relatedMsgID(ctx))
+		})
+	}
+}
+
+func TestAgentCloseCancelsContext(t *testing.T) {
+	f := newResumableAgentFactory(session.NewSessionPool(), 0, 0)
+	ag, clientConn, _ := connectResumableAgent(t, f)
+	defer clientConn.Close()
+
+	ctx := ag.Context()
+	assert.NoError(t, ctx.Err())
+
+	ag.Close()
+	assert.Equal(t, context.Canceled, ctx.Err())
+}
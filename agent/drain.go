@@ -0,0 +1,86 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package agent
+
+import (
+	"encoding/json"
+	"net"
+
+	"github.com/topfreegames/pitaya/v2/conn/codec"
+	"github.com/topfreegames/pitaya/v2/conn/packet"
+	"github.com/topfreegames/pitaya/v2/constants"
+	"github.com/topfreegames/pitaya/v2/logger"
+)
+
+// ReconnectData is sent with the reconnect packet, when Addr is empty the
+// client should reconnect to the address it used before
+type ReconnectData struct {
+	Addr string `json:"addr,omitempty"`
+}
+
+func encodeReconnect(encoder codec.PacketEncoder, addr string) ([]byte, error) {
+	data, err := json.Marshal(&ReconnectData{Addr: addr})
+	if err != nil {
+		return nil, err
+	}
+	return encoder.Encode(packet.Reconnect, data)
+}
+
+// Reconnect asks the client of a connection that won't be handled by this
+// server to reconnect, it doesn't close the connection
+func (f *agentFactoryImpl) Reconnect(conn net.Conn, addr string) error {
+	p, err := encodeReconnect(f.encoder, addr)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Write(p)
+	return err
+}
+
+// Reconnect asks the client to reconnect, e.g. because the server is draining
+func (a *agentImpl) Reconnect(addr string) error {
+	switch a.GetStatus() {
+	case constants.StatusClosed, constants.StatusSuspended:
+		logger.Log.Debugf("can't send reconnect, session is not connected, SessionID=%d, UID=%s", a.Session.ID(), a.Session.UID())
+		return nil
+	}
+
+	p, err := encodeReconnect(a.encoder, addr)
+	if err != nil {
+		return err
+	}
+
+	// chSend is never closed so we need this to don't block if agent is already closed
+	select {
+	case a.chSend <- pendingWrite{data: p}:
+	case <-a.chDie:
+	}
+	return nil
+}
+
+// PendingWrites returns the number of messages queued or frozen by the agent
+// that were not written to the client yet
+func (a *agentImpl) PendingWrites() int {
+	a.freezeMutex.Lock()
+	frozen := a.frozenCount
+	a.freezeMutex.Unlock()
+	return len(a.chSend) + len(a.chOrder) + frozen
+}
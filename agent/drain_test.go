@@ -0,0 +1,80 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package agent
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/topfreegames/pitaya/v2/config"
+	"github.com/topfreegames/pitaya/v2/conn/packet"
+	"github.com/topfreegames/pitaya/v2/constants"
+	"github.com/topfreegames/pitaya/v2/helpers"
+	"github.com/topfreegames/pitaya/v2/session"
+)
+
+func reconnectData(t *testing.T, packets chan *packet.Packet) ReconnectData {
+	p := helpers.ShouldEventuallyReceive(t, packets).(*packet.Packet)
+	assert.EqualValues(t, packet.Reconnect, p.Type)
+	data := ReconnectData{}
+	assert.NoError(t, json.Unmarshal(p.Data, &data))
+	return data
+}
+
+func TestAgentFactoryReconnect(t *testing.T) {
+	f := newResumableAgentFactory(session.NewSessionPool(), 0, 0)
+	serverConn, clientConn := net.Pipe()
+	defer clientConn.Close()
+	packets := readPackets(clientConn)
+
+	go func() {
+		assert.NoError(t, f.Reconnect(serverConn, "other:3250"))
+		serverConn.Close()
+	}()
+	assert.Equal(t, ReconnectData{Addr: "other:3250"}, reconnectData(t, packets))
+}
+
+func TestAgentReconnect(t *testing.T) {
+	f := newResumableAgentFactory(session.NewSessionPool(), 0, 0)
+	ag, clientConn, packets := connectResumableAgent(t, f)
+	defer clientConn.Close()
+
+	ag.SetStatus(constants.StatusWorking)
+	assert.NoError(t, ag.Reconnect(""))
+	assert.Equal(t, ReconnectData{}, reconnectData(t, packets))
+
+	ag.Close()
+	assert.NoError(t, ag.Reconnect(""))
+}
+
+func TestAgentPendingWrites(t *testing.T) {
+	ag := newFreezeAgent(config.PushFreezeConfig{}, nil)
+	assert.Equal(t, 0, ag.PendingWrites())
+
+	ag.freeze(1, frozenPush("a", 1), func(pendingWrite) {})
+	assert.Equal(t, 1, ag.PendingWrites())
+
+	ag.chSend <- pendingWrite{}
+	ag.chOrder <- frozenPush("b", 0)
+	assert.Equal(t, 3, ag.PendingWrites())
+}
@@ -0,0 +1,280 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package agent
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/topfreegames/pitaya/v2/config"
+	"github.com/topfreegames/pitaya/v2/constants"
+	"github.com/topfreegames/pitaya/v2/logger"
+	"github.com/topfreegames/pitaya/v2/metrics"
+	"github.com/topfreegames/pitaya/v2/session"
+	"github.com/topfreegames/pitaya/v2/tracing"
+)
+
+const (
+	freezeReasonResponse  = "response"
+	freezeReasonMaxAge    = "max_age"
+	freezeReasonMaxCount  = "max_count"
+	freezeReasonSuspended = "suspended"
+	freezeReasonClosed    = "closed"
+)
+
+// frozenPushes is the number of pushes frozen among all agents
+var frozenPushes int64
+
+type (
+	// FreezeState describes the pushes of a session that are frozen waiting
+	// for the response of the request they are related to
+	FreezeState struct {
+		CurMsgID    uint          `json:"curMsgId"`
+		FrozenMsgID uint          `json:"frozenMsgId"`
+		Count       int           `json:"count"`
+		Groups      []FrozenGroup `json:"groups"`
+	}
+
+	// FrozenGroup contains the pushes frozen waiting for a single response
+	FrozenGroup struct {
+		MsgID  uint          `json:"msgId"`
+		Count  int           `json:"count"`
+		Age    time.Duration `json:"age"`
+		Routes []string      `json:"routes"`
+	}
+)
+
+// GetFreezeState returns the pushes frozen by the agent of a frontend session,
+// it can be used for debugging sessions that stopped receiving pushes
+func GetFreezeState(s session.Session) (FreezeState, error) {
+	a, ok := s.GetEntity().(Agent)
+	if !ok {
+		return FreezeState{}, constants.ErrNotAgentSession
+	}
+	return a.GetFreezeState(), nil
+}
+
+// GetFreezeState returns the pushes currently frozen by the agent
+func (a *agentImpl) GetFreezeState() FreezeState {
+	a.freezeMutex.Lock()
+	defer a.freezeMutex.Unlock()
+
+	state := FreezeState{
+		CurMsgID:    a.curMsgID,
+		FrozenMsgID: a.pushDelayMID,
+		Count:       a.frozenCount,
+		Groups:      make([]FrozenGroup, 0, len(a.pushDelay)),
+	}
+	now := time.Now()
+	for _, id := range a.frozenIDs() {
+		pWrites := a.pushDelay[id]
+		group := FrozenGroup{
+			MsgID:  id,
+			Count:  len(pWrites),
+			Routes: make([]string, 0, len(pWrites)),
+		}
+		if len(pWrites) > 0 {
+			group.Age = now.Sub(pWrites[0].frozenAt)
+		}
+		for _, pWrite := range pWrites {
+			group.Routes = append(group.Routes, pWrite.msg.Route)
+		}
+		state.Groups = append(state.Groups, group)
+	}
+	return state
+}
+
+// frozenIDs returns the relation ids of the frozen pushes in ascending order,
+// must be called holding freezeMutex
+func (a *agentImpl) frozenIDs() []uint {
+	keys := make([]uint, 0, len(a.pushDelay))
+	for key := range a.pushDelay {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+func (a *agentImpl) setCurMsgID(mid uint) {
+	a.freezeMutex.Lock()
+	defer a.freezeMutex.Unlock()
+	a.curMsgID = mid
+}
+
+// frozenMsgID returns the relation id the new pushes are frozen for
+func (a *agentImpl) frozenMsgID() uint {
+	a.freezeMutex.Lock()
+	defer a.freezeMutex.Unlock()
+	return a.pushDelayMID
+}
+
+func (a *agentImpl) setFrozenMsgID(mid uint) {
+	a.freezeMutex.Lock()
+	defer a.freezeMutex.Unlock()
+	a.pushDelayMID = mid
+}
+
+// freeze holds a push until the response of the request with the given id is sent
+func (a *agentImpl) freeze(id uint, pWrite pendingWrite, send func(pendingWrite)) {
+	pWrite.frozenAt = time.Now()
+
+	a.freezeMutex.Lock()
+	a.pushDelay[id] = append(a.pushDelay[id], pWrite)
+	a.pushDelayMID = id
+	a.frozenCount++
+	count := a.frozenCount
+	a.freezeMutex.Unlock()
+
+	a.reportFrozenPushes(atomic.AddInt64(&frozenPushes, 1))
+	logger.Log.Debugf("freeze push msg, ID=%d, UID=%s, relation.id=%v route=%s len=%d",
+		a.Session.ID(), a.Session.UID(), id, pWrite.msg.Route, count)
+
+	if max := a.pushFreeze.MaxCount; max > 0 && count > max {
+		a.releaseOldestFrozen(max, send)
+	}
+}
+
+// unfreeze removes the pushes frozen for the given relation id
+func (a *agentImpl) unfreeze(id uint, reason string) []pendingWrite {
+	a.freezeMutex.Lock()
+	pWrites, ok := a.pushDelay[id]
+	if ok {
+		delete(a.pushDelay, id)
+		a.frozenCount -= len(pWrites)
+	}
+	a.freezeMutex.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	a.reportFrozenPushes(atomic.AddInt64(&frozenPushes, -int64(len(pWrites))))
+	now := time.Now()
+	for _, mr := range a.metricsReporters {
+		for _, pWrite := range pWrites {
+			if err := mr.ReportSummary(metrics.PushFreezeTime, map[string]string{"reason": reason}, float64(now.Sub(pWrite.frozenAt).Nanoseconds())); err != nil {
+				logger.Log.Warnf("failed to report push freeze time: %s", err.Error())
+			}
+		}
+	}
+	return pWrites
+}
+
+// releaseFrozen applies the freeze policy to the pushes frozen for the
+// given relation id after one of the limits was reached
+func (a *agentImpl) releaseFrozen(id uint, reason string, send func(pendingWrite)) {
+	a.freezeMutex.Lock()
+	if a.pushDelayMID == id {
+		a.pushDelayMID = 0
+	}
+	a.freezeMutex.Unlock()
+
+	pWrites := a.unfreeze(id, reason)
+	if a.pushFreeze.Policy == config.PushFreezeDrop {
+		a.dropFrozen(pWrites, reason)
+		return
+	}
+
+	logger.Log.Warnf("releasing frozen push msgs before response, ID=%d, UID=%s, relation.id=%v len=%d reason=%s",
+		a.Session.ID(), a.Session.UID(), id, len(pWrites), reason)
+	for _, pWrite := range pWrites {
+		send(pWrite)
+	}
+}
+
+func (a *agentImpl) dropFrozen(pWrites []pendingWrite, reason string) {
+	if len(pWrites) == 0 {
+		return
+	}
+
+	logger.Log.Warnf("dropping frozen push msgs, ID=%d, UID=%s, len=%d reason=%s",
+		a.Session.ID(), a.Session.UID(), len(pWrites), reason)
+	for _, pWrite := range pWrites {
+		tracing.FinishSpan(pWrite.ctx, constants.ErrFrozenPushDropped)
+		metrics.ReportTimingFromCtx(pWrite.ctx, a.metricsReporters, handlerType, constants.ErrFrozenPushDropped)
+	}
+	for _, mr := range a.metricsReporters {
+		if err := mr.ReportCount(metrics.DroppedPushes, map[string]string{"reason": reason}, float64(len(pWrites))); err != nil {
+			logger.Log.Warnf("failed to report dropped pushes: %s", err.Error())
+		}
+	}
+}
+
+// releaseOldestFrozen applies the freeze policy to the oldest frozen pushes
+// until there are no more than max pushes frozen
+func (a *agentImpl) releaseOldestFrozen(max int, send func(pendingWrite)) {
+	for {
+		a.freezeMutex.Lock()
+		ids := a.frozenIDs()
+		count := a.frozenCount
+		a.freezeMutex.Unlock()
+
+		if count <= max || len(ids) == 0 {
+			return
+		}
+		a.releaseFrozen(ids[0], freezeReasonMaxCount, send)
+	}
+}
+
+// expireFrozen applies the freeze policy to the pushes frozen for longer
+// than the max age, keeping the order of the relation ids
+func (a *agentImpl) expireFrozen(now time.Time, send func(pendingWrite)) {
+	a.freezeMutex.Lock()
+	ids := a.frozenIDs()
+	expired := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		pWrites := a.pushDelay[id]
+		if len(pWrites) > 0 && now.Sub(pWrites[0].frozenAt) < a.pushFreeze.MaxAge {
+			break
+		}
+		expired = append(expired, id)
+	}
+	a.freezeMutex.Unlock()
+
+	for _, id := range expired {
+		a.releaseFrozen(id, freezeReasonMaxAge, send)
+	}
+}
+
+// takeAllFrozen removes every frozen push in the order they would be sent
+func (a *agentImpl) takeAllFrozen(reason string) []pendingWrite {
+	a.freezeMutex.Lock()
+	ids := a.frozenIDs()
+	a.pushDelayMID = 0
+	a.freezeMutex.Unlock()
+
+	pWrites := make([]pendingWrite, 0)
+	for _, id := range ids {
+		pWrites = append(pWrites, a.unfreeze(id, reason)...)
+	}
+	return pWrites
+}
+
+func (a *agentImpl) reportFrozenPushes(count int64) {
+	for _, mr := range a.metricsReporters {
+		if err := mr.ReportGauge(metrics.FrozenPushes, map[string]string{}, float64(count)); err != nil {
+			logger.Log.Warnf("failed to report frozen pushes: %s", err.Error())
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package agent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/topfreegames/pitaya/v2/config"
+	"github.com/topfreegames/pitaya/v2/conn/codec"
+	"github.com/topfreegames/pitaya/v2/conn/message"
+	"github.com/topfreegames/pitaya/v2/constants"
+	"github.com/topfreegames/pitaya/v2/metrics"
+	metricsmocks "github.com/topfreegames/pitaya/v2/metrics/mocks"
+	serializejson "github.com/topfreegames/pitaya/v2/serialize/json"
+	"github.com/topfreegames/pitaya/v2/session"
+)
+
+func newFreezeAgent(pushFreeze config.PushFreezeConfig, metricsReporters []metrics.Reporter) *agentImpl {
+	ag := newAgent(nil, nil, codec.NewPomeloPacketEncoder(), serializejson.NewSerializer(), time.Second, 10, nil,
+		message.NewMessagesEncoder(false), nil, session.NewSessionPool()).(*agentImpl)
+	ag.metricsReporters = metricsReporters
+	ag.pushFreeze = pushFreeze
+	return ag
+}
+
+func frozenPush(route string, mid uint) pendingWrite {
+	return pendingWrite{msg: &message.Message{Type: message.Push, Route: route, ID: mid}}
+}
+
+func sentRoutes(sent []pendingWrite) []string {
+	routes := make([]string, 0, len(sent))
+	for _, pWrite := range sent {
+		routes = append(routes, pWrite.msg.Route)
+	}
+	return routes
+}
+
+func TestAgentFreezeMaxCount(t *testing.T) {
+	tables := []struct {
+		name   string
+		policy string
+		sent   []string
+	}{
+		{"release", config.PushFreezeRelease, []string{"a", "b"}},
+		{"drop", config.PushFreezeDrop, []string{}},
+	}
+
+	for _, table := range tables {
+		t.Run(table.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockMetricsReporter := metricsmocks.NewMockReporter(ctrl)
+			mockMetricsReporter.EXPECT().ReportGauge(metrics.FrozenPushes, gomock.Any(), gomock.Any()).AnyTimes()
+			mockMetricsReporter.EXPECT().ReportSummary(metrics.PushFreezeTime, map[string]string{"reason": freezeReasonMaxCount}, gomock.Any()).Times(2)
+			if table.policy == config.PushFreezeDrop {
+				mockMetricsReporter.EXPECT().ReportCount(metrics.DroppedPushes, map[string]string{"reason": freezeReasonMaxCount}, float64(2))
+			}
+
+			ag := newFreezeAgent(config.PushFreezeConfig{MaxCount: 2, Policy: table.policy}, []metrics.Reporter{mockMetricsReporter})
+			sent := make([]pendingWrite, 0)
+			send := func(pWrite pendingWrite) { sent = append(sent, pWrite) }
+
+			ag.freeze(1, frozenPush("a", 1), send)
+			ag.freeze(1, frozenPush("b", 1), send)
+			assert.Len(t, sent, 0)
+
+			ag.freeze(2, frozenPush("c", 2), send)
+			assert.Equal(t, table.sent, sentRoutes(sent))
+
+			state := ag.GetFreezeState()
+			assert.Equal(t, 1, state.Count)
+			assert.Equal(t, uint(2), state.FrozenMsgID)
+			assert.Len(t, state.Groups, 1)
+			assert.Equal(t, uint(2), state.Groups[0].MsgID)
+			assert.Equal(t, []string{"c"}, state.Groups[0].Routes)
+		})
+	}
+}
+
+func TestAgentExpireFrozen(t *testing.T) {
+	ag := newFreezeAgent(config.PushFreezeConfig{MaxAge: time.Minute, Policy: config.PushFreezeRelease}, nil)
+	sent := make([]pendingWrite, 0)
+	send := func(pWrite pendingWrite) { sent = append(sent, pWrite) }
+
+	ag.freeze(1, frozenPush("a", 1), send)
+	ag.freeze(2, frozenPush("b", 2), send)
+	ag.freeze(3, frozenPush("c", 3), send)
+
+	now := time.Now()
+	ag.pushDelay[1][0].frozenAt = now.Add(-2 * time.Minute)
+	ag.pushDelay[2][0].frozenAt = now
+	ag.pushDelay[3][0].frozenAt = now.Add(-2 * time.Minute)
+
+	// group 3 is kept frozen after group 2 to keep the order
+	ag.expireFrozen(now, send)
+	assert.Equal(t, []string{"a"}, sentRoutes(sent))
+	assert.Equal(t, uint(3), ag.pushDelayMID)
+
+	ag.expireFrozen(now.Add(2*time.Minute), send)
+	assert.Equal(t, []string{"a", "b", "c"}, sentRoutes(sent))
+	assert.Equal(t, uint(0), ag.pushDelayMID)
+	assert.Equal(t, 0, ag.GetFreezeState().Count)
+}
+
+func TestAgentTakeAllFrozen(t *testing.T) {
+	ag := newFreezeAgent(config.PushFreezeConfig{}, nil)
+	send := func(pWrite pendingWrite) { assert.Fail(t, "push should not be sent") }
+
+	ag.freeze(3, frozenPush("c", 3), send)
+	ag.freeze(1, frozenPush("a", 1), send)
+	ag.freeze(1, frozenPush("b", 1), send)
+
+	assert.Equal(t, []string{"a", "b", "c"}, sentRoutes(ag.takeAllFrozen(freezeReasonClosed)))
+	assert.Equal(t, FreezeState{Groups: []FrozenGroup{}}, ag.GetFreezeState())
+}
+
+func TestGetFreezeState(t *testing.T) {
+	ag := newFreezeAgent(config.PushFreezeConfig{}, nil)
+	ag.curMsgID = 1
+	ag.freeze(2, frozenPush("a", 2), func(pendingWrite) {})
+
+	state, err := GetFreezeState(ag.Session)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), state.CurMsgID)
+	assert.Equal(t, uint(2), state.FrozenMsgID)
+	assert.Equal(t, 1, state.Count)
+	assert.Len(t, state.Groups, 1)
+	assert.Equal(t, 1, state.Groups[0].Count)
+	assert.Equal(t, []string{"a"}, state.Groups[0].Routes)
+
+	s := session.NewSessionPool().NewSession(nil, false)
+	_, err = GetFreezeState(s)
+	assert.Equal(t, constants.ErrNotAgentSession, err)
+}
@@ -0,0 +1,246 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package agent
+
+import (
+	gojson "encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/topfreegames/pitaya/v2/config"
+	"github.com/topfreegames/pitaya/v2/conn/codec"
+	"github.com/topfreegames/pitaya/v2/conn/message"
+	"github.com/topfreegames/pitaya/v2/conn/packet"
+	"github.com/topfreegames/pitaya/v2/constants"
+	"github.com/topfreegames/pitaya/v2/errors"
+	"github.com/topfreegames/pitaya/v2/protos"
+	"github.com/topfreegames/pitaya/v2/session"
+	"github.com/topfreegames/pitaya/v2/util/compression"
+)
+
+// protobufSerializer is the serializer whose clients get the handshake
+// response in protobuf instead of JSON
+const protobufSerializer = "protobuf"
+
+// handshake holds the settings negotiated with the client of a session
+type handshake struct {
+	version     int
+	serializer  string
+	compression string
+}
+
+// negotiateHandshake chooses the settings of a session from the ones accepted
+// by the client, the first of serializers and compression are the server defaults
+func negotiateHandshake(
+	data *session.HandshakeData,
+	conf config.HandshakeConfig,
+	serializers []string,
+	compression string,
+) (*handshake, error) {
+	version := data.Sys.ProtocolVersion
+	if version < conf.MinVersion || version < 0 {
+		return nil, errors.NewError(constants.ErrProtocolVersionNotSupported, errors.ErrBadRequestCode, map[string]string{
+			"minVersion": strconv.Itoa(conf.MinVersion),
+			"maxVersion": strconv.Itoa(constants.HandshakeProtocolVersion),
+		})
+	}
+	if version > constants.HandshakeProtocolVersion {
+		version = constants.HandshakeProtocolVersion
+	}
+	h := &handshake{version: version, serializer: serializers[0], compression: compression}
+
+	if len(data.Sys.Serializers) > 0 {
+		h.serializer = ""
+		for _, s := range data.Sys.Serializers {
+			if contains(serializers, s) {
+				h.serializer = s
+				break
+			}
+		}
+		if h.serializer == "" {
+			return nil, errors.NewError(constants.ErrSerializerNotSupported, errors.ErrBadRequestCode, map[string]string{
+				"serializers": strings.Join(serializers, ","),
+			})
+		}
+	}
+
+	if len(data.Sys.Compressions) > 0 {
+		var supported []string
+		for _, c := range conf.Compressions {
+			if message.IsCompressionSupported(c) {
+				supported = append(supported, c)
+			}
+		}
+		h.compression = ""
+		for _, c := range data.Sys.Compressions {
+			if contains(supported, c) {
+				h.compression = c
+				break
+			}
+		}
+		if h.compression == "" {
+			return nil, errors.NewError(constants.ErrCompressionNotSupported, errors.ErrBadRequestCode, map[string]string{
+				"compressions": strings.Join(supported, ","),
+			})
+		}
+	}
+	return h, nil
+}
+
+// sys returns the negotiated settings sent in the handshake response, clients
+// on version 0 don't get them. The dictionary of the compression is sent
+// unless the client already has it.
+func (h *handshake) sys(clientDictionaryID uint32) map[string]interface{} {
+	sys := map[string]interface{}{}
+	if h.version > 0 {
+		sys["protocolVersion"] = h.version
+		sys["compression"] = h.compression
+		if codec, ok := message.GetCodec(h.compression).(message.DictionaryCodec); ok {
+			sys["dictionaryId"] = codec.DictionaryID()
+			if codec.DictionaryID() != clientDictionaryID {
+				sys["dictionary"] = codec.Dictionary()
+			}
+		}
+	}
+	return sys
+}
+
+// isProtobufHandshake tells whether the handshake response of a client is
+// encoded in protobuf, which only happens for the clients that negotiate the
+// serializer since the others may not know of it
+func isProtobufHandshake(data *session.HandshakeData, serializer string) bool {
+	return len(data.Sys.Serializers) > 0 && serializer == protobufSerializer
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeHandshakeResponse encodes the response accepting a handshake, in
+// protobuf when useProtobuf is set and in JSON otherwise
+func encodeHandshakeResponse(heartbeatTimeout time.Duration, packetEncoder codec.PacketEncoder, dataCompression bool, serializerName string, extraSys map[string]interface{}, useProtobuf bool) ([]byte, error) {
+	var data []byte
+	var err error
+	if useProtobuf {
+		data, err = proto.Marshal(&protos.HandshakeResponse{
+			Code: 200,
+			Sys:  newHandshakeSys(heartbeatTimeout, serializerName, extraSys),
+		})
+	} else {
+		sys := map[string]interface{}{
+			"heartbeat":  heartbeatTimeout.Seconds(),
+			"dict":       message.GetDictionary(),
+			"serializer": serializerName,
+		}
+		for k, v := range extraSys {
+			sys[k] = v
+		}
+		hData := map[string]interface{}{
+			"code": 200,
+			"sys":  sys,
+		}
+		data, err = gojson.Marshal(hData)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if dataCompression {
+		compressedData, err := compression.DeflateData(data)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(compressedData) < len(data) {
+			data = compressedData
+		}
+	}
+
+	return packetEncoder.Encode(packet.Handshake, data)
+}
+
+// newHandshakeSys builds the protobuf sys of the handshake response from the
+// same settings sent in JSON
+func newHandshakeSys(heartbeatTimeout time.Duration, serializerName string, extraSys map[string]interface{}) *protos.HandshakeSys {
+	sys := &protos.HandshakeSys{
+		Heartbeat:  heartbeatTimeout.Seconds(),
+		Dict:       map[string]uint32{},
+		Serializer: serializerName,
+	}
+	for route, code := range message.GetDictionary() {
+		sys.Dict[route] = uint32(code)
+	}
+	for k, v := range extraSys {
+		switch k {
+		case "protocolVersion":
+			sys.ProtocolVersion = int32(v.(int))
+		case "compression":
+			sys.Compression = v.(string)
+		case "dictionaryId":
+			sys.DictionaryId = v.(uint32)
+		case "dictionary":
+			sys.Dictionary = v.([]byte)
+		case "resumeToken":
+			sys.ResumeToken = v.(string)
+		case "resumed":
+			sys.Resumed = v.(bool)
+		}
+	}
+	return sys
+}
+
+// encodeHandshakeError encodes the response rejecting a handshake, the error
+// has the same fields of the error payloads of the handlers
+func encodeHandshakeError(packetEncoder codec.PacketEncoder, err *errors.Error, useProtobuf bool) ([]byte, error) {
+	var data []byte
+	var e error
+	if useProtobuf {
+		data, e = proto.Marshal(&protos.HandshakeResponse{
+			Code: 400,
+			Error: &protos.HandshakeError{
+				Code:     err.Code,
+				Msg:      err.Message,
+				Metadata: err.Metadata,
+			},
+		})
+	} else {
+		hData := map[string]interface{}{
+			"code": 400,
+			"error": map[string]interface{}{
+				"code":     err.Code,
+				"msg":      err.Message,
+				"metadata": err.Metadata,
+			},
+		}
+		data, e = gojson.Marshal(hData)
+	}
+	if e != nil {
+		return nil, e
+	}
+	return packetEncoder.Encode(packet.Handshake, data)
+}
@@ -0,0 +1,292 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/topfreegames/pitaya/v2/config"
+	"github.com/topfreegames/pitaya/v2/conn/codec"
+	"github.com/topfreegames/pitaya/v2/conn/message"
+	"github.com/topfreegames/pitaya/v2/conn/packet"
+	"github.com/topfreegames/pitaya/v2/constants"
+	pcontext "github.com/topfreegames/pitaya/v2/context"
+	"github.com/topfreegames/pitaya/v2/errors"
+	"github.com/topfreegames/pitaya/v2/helpers"
+	"github.com/topfreegames/pitaya/v2/metrics"
+	metricsmocks "github.com/topfreegames/pitaya/v2/metrics/mocks"
+	"github.com/topfreegames/pitaya/v2/protos"
+	"github.com/topfreegames/pitaya/v2/serialize"
+	serializejson "github.com/topfreegames/pitaya/v2/serialize/json"
+	"github.com/topfreegames/pitaya/v2/serialize/protobuf"
+	"github.com/topfreegames/pitaya/v2/session"
+	"github.com/topfreegames/pitaya/v2/timer"
+	"github.com/topfreegames/pitaya/v2/util/compression"
+)
+
+func TestNegotiateHandshake(t *testing.T) {
+	conf := config.HandshakeConfig{MinVersion: 0, Compressions: []string{"deflate", "zstd", "brotli", "none"}}
+	tables := []struct {
+		name        string
+		sys         session.HandshakeClientData
+		conf        config.HandshakeConfig
+		version     int
+		serializer  string
+		compression string
+		err         error
+	}{
+		{"legacy", session.HandshakeClientData{}, conf, 0, "json", "deflate", nil},
+		{"version", session.HandshakeClientData{ProtocolVersion: 1}, conf, 1, "json", "deflate", nil},
+		{"newer_version", session.HandshakeClientData{ProtocolVersion: 5}, conf, constants.HandshakeProtocolVersion, "json", "deflate", nil},
+		{"old_version", session.HandshakeClientData{}, config.HandshakeConfig{MinVersion: 1}, 0, "", "", constants.ErrProtocolVersionNotSupported},
+		{"serializer", session.HandshakeClientData{Serializers: []string{"msgpack", "protobuf", "json"}}, conf, 0, "protobuf", "deflate", nil},
+		{"unsupported_serializer", session.HandshakeClientData{Serializers: []string{"msgpack"}}, conf, 0, "", "", constants.ErrSerializerNotSupported},
+		{"compression", session.HandshakeClientData{Compressions: []string{"none", "deflate"}}, conf, 0, "json", "none", nil},
+		{"zstd_compression", session.HandshakeClientData{Compressions: []string{"zstd", "deflate"}}, conf, 0, "json", "zstd", nil},
+		{"unknown_compression", session.HandshakeClientData{Compressions: []string{"brotli", "deflate"}}, conf, 0, "json", "deflate", nil},
+		{"unsupported_compression", session.HandshakeClientData{Compressions: []string{"brotli"}}, conf, 0, "", "", constants.ErrCompressionNotSupported},
+	}
+
+	for _, table := range tables {
+		t.Run(table.name, func(t *testing.T) {
+			h, err := negotiateHandshake(&session.HandshakeData{Sys: table.sys}, table.conf, []string{"json", "protobuf"}, "deflate")
+			if table.err != nil {
+				assert.Nil(t, h)
+				assert.IsType(t, &errors.Error{}, err)
+				assert.Equal(t, errors.ErrBadRequestCode, err.(*errors.Error).Code)
+				assert.Equal(t, table.err.Error(), err.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, table.version, h.version)
+			assert.Equal(t, table.serializer, h.serializer)
+			assert.Equal(t, table.compression, h.compression)
+		})
+	}
+}
+
+func newHandshakeAgent(t *testing.T, conf config.HandshakeConfig, serializers ...serialize.Serializer) (*agentImpl, net.Conn, chan *packet.Packet) {
+	f := NewAgentFactory(nil, codec.NewPomeloPacketDecoder(), codec.NewPomeloPacketEncoder(), serializejson.NewSerializer(), serializers,
+		time.Second, message.NewMessagesEncoder(true), 10, session.NewSessionPool(), nil, config.SessionResumeConfig{}, config.PushFreezeConfig{}, conf, timer.GlobalClock())
+	serverConn, clientConn := net.Pipe()
+	ag := f.CreateAgent(serverConn).(*agentImpl)
+	return ag, clientConn, readPackets(clientConn)
+}
+
+func TestAgentSendHandshakeResponseNegotiated(t *testing.T) {
+	ag, clientConn, packets := newHandshakeAgent(t, *config.NewDefaultHandshakeConfig())
+	defer clientConn.Close()
+
+	data := &session.HandshakeData{Sys: session.HandshakeClientData{
+		ProtocolVersion: constants.HandshakeProtocolVersion,
+		Compressions:    []string{"none"},
+	}}
+	assert.NoError(t, ag.SendHandshakeResponse(data))
+	sys := handshakeSys(t, packets)
+	assert.Equal(t, float64(constants.HandshakeProtocolVersion), sys["protocolVersion"])
+	assert.Equal(t, "none", sys["compression"])
+	assert.Equal(t, "json", sys["serializer"])
+	assert.False(t, ag.messageEncoder.IsCompressionEnabled())
+}
+
+func TestAgentDecodeMessageNegotiatedCompression(t *testing.T) {
+	ag, clientConn, packets := newHandshakeAgent(t, config.HandshakeConfig{Compressions: []string{"deflate", "zstd", "none"}})
+	defer clientConn.Close()
+
+	data := &session.HandshakeData{Sys: session.HandshakeClientData{Compressions: []string{"zstd"}}}
+	assert.NoError(t, ag.SendHandshakeResponse(data))
+	handshakeSys(t, packets)
+
+	payload := bytes.Repeat([]byte(`{"message":"hello"}`), 10)
+	for name, err := range map[string]error{"zstd": nil, "none": nil, "deflate": message.ErrUnexpectedCodec} {
+		encoded, encodeErr := message.NewMessagesEncoderWithCodec(message.GetCodec(name)).Encode(&message.Message{Type: message.Notify, Route: "room.room.message", Data: payload})
+		assert.NoError(t, encodeErr)
+		m, decodeErr := ag.DecodeMessage(encoded)
+		assert.Equal(t, err, decodeErr, name)
+		if err == nil {
+			assert.Equal(t, payload, m.Data)
+		}
+	}
+}
+
+func TestAgentSendHandshakeResponseSerializer(t *testing.T) {
+	ag, clientConn, packets := newHandshakeAgent(t, *config.NewDefaultHandshakeConfig(), protobuf.NewSerializer())
+	defer clientConn.Close()
+
+	data := &session.HandshakeData{Sys: session.HandshakeClientData{Serializers: []string{"protobuf", "json"}}}
+	assert.NoError(t, ag.SendHandshakeResponse(data))
+	res := handshakeProtoResponse(t, packets)
+	assert.EqualValues(t, 200, res.Code)
+	assert.Equal(t, "protobuf", res.Sys.Serializer)
+	assert.Equal(t, time.Second.Seconds(), res.Sys.Heartbeat)
+	assert.Equal(t, "protobuf", ag.serializer.GetName())
+	assert.Equal(t, "protobuf", ag.Session.GetSerializer().GetName())
+}
+
+func TestAgentSendHandshakeResponseWhileEncoding(t *testing.T) {
+	ag, clientConn, packets := newHandshakeAgent(t, *config.NewDefaultHandshakeConfig(), protobuf.NewSerializer())
+	defer clientConn.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			m, err := ag.getMessageFromPendingMessage(pendingMessage{typ: message.Push, route: "some.route", payload: []byte("data")})
+			assert.NoError(t, err)
+			_, err = ag.packetEncodeMessage(context.Background(), m)
+			assert.NoError(t, err)
+		}
+	}()
+
+	data := &session.HandshakeData{Sys: session.HandshakeClientData{
+		Serializers:  []string{"protobuf"},
+		Compressions: []string{"none"},
+	}}
+	assert.NoError(t, ag.SendHandshakeResponse(data))
+	handshakeProtoResponse(t, packets)
+	<-done
+	assert.Equal(t, "protobuf", ag.getSerializer().GetName())
+	assert.False(t, ag.getMessageEncoder().IsCompressionEnabled())
+}
+
+func TestAgentSendHandshakeResponseDictionary(t *testing.T) {
+	dictionary, err := ioutil.ReadFile("../conn/message/fixtures/zstd.dict")
+	assert.NoError(t, err)
+	codec, err := message.NewZstdDictionaryCodec(dictionary)
+	assert.NoError(t, err)
+	assert.NoError(t, message.RegisterCodec(codec))
+
+	conf := config.HandshakeConfig{Compressions: []string{"deflate", "zstd-dict", "none"}}
+	tables := []struct {
+		name         string
+		dictionaryID uint32
+		dictionary   interface{}
+	}{
+		{"without_dictionary", 0, base64.StdEncoding.EncodeToString(dictionary)},
+		{"with_dictionary", codec.DictionaryID(), nil},
+	}
+	for _, table := range tables {
+		t.Run(table.name, func(t *testing.T) {
+			ag, clientConn, packets := newHandshakeAgent(t, conf)
+			defer clientConn.Close()
+
+			data := &session.HandshakeData{Sys: session.HandshakeClientData{
+				ProtocolVersion: constants.HandshakeProtocolVersion,
+				Compressions:    []string{"zstd-dict", "deflate"},
+				DictionaryID:    table.dictionaryID,
+			}}
+			assert.NoError(t, ag.SendHandshakeResponse(data))
+			sys := handshakeSys(t, packets)
+			assert.Equal(t, "zstd-dict", sys["compression"])
+			assert.Equal(t, float64(codec.DictionaryID()), sys["dictionaryId"])
+			assert.Equal(t, table.dictionary, sys["dictionary"])
+			assert.Equal(t, "zstd-dict", ag.messageEncoder.Compression())
+		})
+	}
+}
+
+func TestAgentSendHandshakeResponseRejected(t *testing.T) {
+	ag, clientConn, packets := newHandshakeAgent(t, config.HandshakeConfig{MinVersion: 1})
+	defer clientConn.Close()
+
+	err := ag.SendHandshakeResponse(&session.HandshakeData{})
+	assert.Equal(t, constants.ErrProtocolVersionNotSupported.Error(), err.Error())
+
+	p := helpers.ShouldEventuallyReceive(t, packets).(*packet.Packet)
+	assert.EqualValues(t, packet.Handshake, p.Type)
+	res := struct {
+		Code  int `json:"code"`
+		Error struct {
+			Code     string            `json:"code"`
+			Msg      string            `json:"msg"`
+			Metadata map[string]string `json:"metadata"`
+		} `json:"error"`
+	}{}
+	assert.NoError(t, json.Unmarshal(p.Data, &res))
+	assert.Equal(t, 400, res.Code)
+	assert.Equal(t, errors.ErrBadRequestCode, res.Error.Code)
+	assert.Equal(t, constants.ErrProtocolVersionNotSupported.Error(), res.Error.Msg)
+	assert.Equal(t, "1", res.Error.Metadata["minVersion"])
+	assert.True(t, ag.messageEncoder.IsCompressionEnabled())
+}
+
+func TestAgentSendHandshakeResponseRejectedProtobuf(t *testing.T) {
+	ag, clientConn, packets := newHandshakeAgent(t, *config.NewDefaultHandshakeConfig())
+	defer clientConn.Close()
+
+	data := &session.HandshakeData{Sys: session.HandshakeClientData{Serializers: []string{"protobuf"}}}
+	err := ag.SendHandshakeResponse(data)
+	assert.Equal(t, constants.ErrSerializerNotSupported.Error(), err.Error())
+
+	res := handshakeProtoResponse(t, packets)
+	assert.EqualValues(t, 400, res.Code)
+	assert.Equal(t, errors.ErrBadRequestCode, res.Error.Code)
+	assert.Equal(t, constants.ErrSerializerNotSupported.Error(), res.Error.Msg)
+	assert.Equal(t, "json", res.Error.Metadata["serializers"])
+}
+
+func handshakeProtoResponse(t *testing.T, packets chan *packet.Packet) *protos.HandshakeResponse {
+	p := helpers.ShouldEventuallyReceive(t, packets).(*packet.Packet)
+	assert.EqualValues(t, packet.Handshake, p.Type)
+	data := p.Data
+	if compression.IsCompressed(data) {
+		var err error
+		data, err = compression.InflateData(data)
+		assert.NoError(t, err)
+	}
+	res := &protos.HandshakeResponse{}
+	assert.NoError(t, proto.Unmarshal(data, res))
+	return res
+}
+
+func TestAgentReportCompressionRatio(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reporter := metricsmocks.NewMockReporter(ctrl)
+	ag := &agentImpl{
+		messageEncoder:   message.NewMessagesEncoderWithCodec(message.GetCodec(message.CompressionZstd)),
+		metricsReporters: []metrics.Reporter{reporter},
+	}
+
+	ctx := pcontext.AddToPropagateCtx(context.Background(), constants.RouteKey, "room.room.join")
+	m := &message.Message{Type: message.Response, ID: 1, Data: bytes.Repeat([]byte(`{"ok":true}`), 10)}
+	reporter.EXPECT().ReportSummary(metrics.CompressionRatio, map[string]string{"route": "room.room.join", "compression": "zstd"}, gomock.Any()).
+		Do(func(_ string, _ map[string]string, ratio float64) {
+			assert.Less(t, ratio, 1.0)
+		})
+	_, err := ag.messageEncoder.Encode(m)
+	assert.NoError(t, err)
+	ag.reportCompressionRatio(ctx, m, 110)
+
+	// messages without data are not reported
+	ag.reportCompressionRatio(ctx, &message.Message{Type: message.Push, Route: "room.room.push"}, 0)
+}
@@ -6,50 +6,52 @@ package mocks
 
 import (
 	context "context"
+	net "net"
+	reflect "reflect"
+
 	gomock "github.com/golang/mock/gomock"
 	agent "github.com/topfreegames/pitaya/v2/agent"
+	message "github.com/topfreegames/pitaya/v2/conn/message"
 	protos "github.com/topfreegames/pitaya/v2/protos"
 	session "github.com/topfreegames/pitaya/v2/session"
-	net "net"
-	reflect "reflect"
 )
 
-// MockAgent is a mock of Agent interface
+// MockAgent is a mock of Agent interface.
 type MockAgent struct {
 	ctrl     *gomock.Controller
 	recorder *MockAgentMockRecorder
 }
 
-// MockAgentMockRecorder is the mock recorder for MockAgent
+// MockAgentMockRecorder is the mock recorder for MockAgent.
 type MockAgentMockRecorder struct {
 	mock *MockAgent
 }
 
-// NewMockAgent creates a new mock instance
+// NewMockAgent creates a new mock instance.
 func NewMockAgent(ctrl *gomock.Controller) *MockAgent {
 	mock := &MockAgent{ctrl: ctrl}
 	mock.recorder = &MockAgentMockRecorder{mock}
 	return mock
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use
+// EXPECT returns an object that allows the caller to indicate expected use.
 func (m *MockAgent) EXPECT() *MockAgentMockRecorder {
 	return m.recorder
 }
 
-// AnswerWithError mocks base method
+// AnswerWithError mocks base method.
 func (m *MockAgent) AnswerWithError(arg0 context.Context, arg1 uint, arg2 error) {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "AnswerWithError", arg0, arg1, arg2)
 }
 
-// AnswerWithError indicates an expected call of AnswerWithError
+// AnswerWithError indicates an expected call of AnswerWithError.
 func (mr *MockAgentMockRecorder) AnswerWithError(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnswerWithError", reflect.TypeOf((*MockAgent)(nil).AnswerWithError), arg0, arg1, arg2)
 }
 
-// Close mocks base method
+// Close mocks base method.
 func (m *MockAgent) Close() error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Close")
@@ -57,13 +59,68 @@ func (m *MockAgent) Close() error {
 	return ret0
 }
 
-// Close indicates an expected call of Close
+// Close indicates an expected call of Close.
 func (mr *MockAgentMockRecorder) Close() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockAgent)(nil).Close))
 }
 
-// GetSession mocks base method
+// Context mocks base method.
+func (m *MockAgent) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockAgentMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockAgent)(nil).Context))
+}
+
+// DecodeMessage mocks base method.
+func (m *MockAgent) DecodeMessage(arg0 []byte) (*message.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecodeMessage", arg0)
+	ret0, _ := ret[0].(*message.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DecodeMessage indicates an expected call of DecodeMessage.
+func (mr *MockAgentMockRecorder) DecodeMessage(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecodeMessage", reflect.TypeOf((*MockAgent)(nil).DecodeMessage), arg0)
+}
+
+// Disconnect mocks base method.
+func (m *MockAgent) Disconnect() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Disconnect")
+}
+
+// Disconnect indicates an expected call of Disconnect.
+func (mr *MockAgentMockRecorder) Disconnect() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disconnect", reflect.TypeOf((*MockAgent)(nil).Disconnect))
+}
+
+// GetFreezeState mocks base method.
+func (m *MockAgent) GetFreezeState() agent.FreezeState {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFreezeState")
+	ret0, _ := ret[0].(agent.FreezeState)
+	return ret0
+}
+
+// GetFreezeState indicates an expected call of GetFreezeState.
+func (mr *MockAgentMockRecorder) GetFreezeState() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFreezeState", reflect.TypeOf((*MockAgent)(nil).GetFreezeState))
+}
+
+// GetSession mocks base method.
 func (m *MockAgent) GetSession() session.Session {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetSession")
@@ -71,13 +128,13 @@ func (m *MockAgent) GetSession() session.Session {
 	return ret0
 }
 
-// GetSession indicates an expected call of GetSession
+// GetSession indicates an expected call of GetSession.
 func (mr *MockAgentMockRecorder) GetSession() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockAgent)(nil).GetSession))
 }
 
-// GetStatus mocks base method
+// GetStatus mocks base method.
 func (m *MockAgent) GetStatus() int32 {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetStatus")
@@ -85,25 +142,25 @@ func (m *MockAgent) GetStatus() int32 {
 	return ret0
 }
 
-// GetStatus indicates an expected call of GetStatus
+// GetStatus indicates an expected call of GetStatus.
 func (mr *MockAgentMockRecorder) GetStatus() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatus", reflect.TypeOf((*MockAgent)(nil).GetStatus))
 }
 
-// Handle mocks base method
+// Handle mocks base method.
 func (m *MockAgent) Handle() {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "Handle")
 }
 
-// Handle indicates an expected call of Handle
+// Handle indicates an expected call of Handle.
 func (mr *MockAgentMockRecorder) Handle() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Handle", reflect.TypeOf((*MockAgent)(nil).Handle))
 }
 
-// IPVersion mocks base method
+// IPVersion mocks base method.
 func (m *MockAgent) IPVersion() string {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "IPVersion")
@@ -111,13 +168,13 @@ func (m *MockAgent) IPVersion() string {
 	return ret0
 }
 
-// IPVersion indicates an expected call of IPVersion
+// IPVersion indicates an expected call of IPVersion.
 func (mr *MockAgentMockRecorder) IPVersion() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IPVersion", reflect.TypeOf((*MockAgent)(nil).IPVersion))
 }
 
-// Kick mocks base method
+// Kick mocks base method.
 func (m *MockAgent) Kick(arg0 context.Context) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Kick", arg0)
@@ -125,27 +182,55 @@ func (m *MockAgent) Kick(arg0 context.Context) error {
 	return ret0
 }
 
-// Kick indicates an expected call of Kick
+// Kick indicates an expected call of Kick.
 func (mr *MockAgentMockRecorder) Kick(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Kick", reflect.TypeOf((*MockAgent)(nil).Kick), arg0)
 }
 
-// Push mocks base method
-func (m *MockAgent) Push(arg0 string, arg1 interface{}) error {
+// PendingWrites mocks base method.
+func (m *MockAgent) PendingWrites() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PendingWrites")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// PendingWrites indicates an expected call of PendingWrites.
+func (mr *MockAgentMockRecorder) PendingWrites() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingWrites", reflect.TypeOf((*MockAgent)(nil).PendingWrites))
+}
+
+// Push mocks base method.
+func (m *MockAgent) Push(arg0 context.Context, arg1 string, arg2 interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Push", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Push indicates an expected call of Push.
+func (mr *MockAgentMockRecorder) Push(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Push", reflect.TypeOf((*MockAgent)(nil).Push), arg0, arg1, arg2)
+}
+
+// Reconnect mocks base method.
+func (m *MockAgent) Reconnect(arg0 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Push", arg0, arg1)
+	ret := m.ctrl.Call(m, "Reconnect", arg0)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Push indicates an expected call of Push
-func (mr *MockAgentMockRecorder) Push(arg0, arg1 interface{}) *gomock.Call {
+// Reconnect indicates an expected call of Reconnect.
+func (mr *MockAgentMockRecorder) Reconnect(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Push", reflect.TypeOf((*MockAgent)(nil).Push), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reconnect", reflect.TypeOf((*MockAgent)(nil).Reconnect), arg0)
 }
 
-// RemoteAddr mocks base method
+// RemoteAddr mocks base method.
 func (m *MockAgent) RemoteAddr() net.Addr {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "RemoteAddr")
@@ -153,13 +238,13 @@ func (m *MockAgent) RemoteAddr() net.Addr {
 	return ret0
 }
 
-// RemoteAddr indicates an expected call of RemoteAddr
+// RemoteAddr indicates an expected call of RemoteAddr.
 func (mr *MockAgentMockRecorder) RemoteAddr() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoteAddr", reflect.TypeOf((*MockAgent)(nil).RemoteAddr))
 }
 
-// ResponseMID mocks base method
+// ResponseMID mocks base method.
 func (m *MockAgent) ResponseMID(arg0 context.Context, arg1 uint, arg2 interface{}, arg3 ...bool) error {
 	m.ctrl.T.Helper()
 	varargs := []interface{}{arg0, arg1, arg2}
@@ -171,28 +256,42 @@ func (m *MockAgent) ResponseMID(arg0 context.Context, arg1 uint, arg2 interface{
 	return ret0
 }
 
-// ResponseMID indicates an expected call of ResponseMID
+// ResponseMID indicates an expected call of ResponseMID.
 func (mr *MockAgentMockRecorder) ResponseMID(arg0, arg1, arg2 interface{}, arg3 ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	varargs := append([]interface{}{arg0, arg1, arg2}, arg3...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResponseMID", reflect.TypeOf((*MockAgent)(nil).ResponseMID), varargs...)
 }
 
-// SendHandshakeResponse mocks base method
-func (m *MockAgent) SendHandshakeResponse() error {
+// ResumeSession mocks base method.
+func (m *MockAgent) ResumeSession(arg0 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendHandshakeResponse")
+	ret := m.ctrl.Call(m, "ResumeSession", arg0)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SendHandshakeResponse indicates an expected call of SendHandshakeResponse
-func (mr *MockAgentMockRecorder) SendHandshakeResponse() *gomock.Call {
+// ResumeSession indicates an expected call of ResumeSession.
+func (mr *MockAgentMockRecorder) ResumeSession(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHandshakeResponse", reflect.TypeOf((*MockAgent)(nil).SendHandshakeResponse))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeSession", reflect.TypeOf((*MockAgent)(nil).ResumeSession), arg0)
 }
 
-// SendRequest mocks base method
+// SendHandshakeResponse mocks base method.
+func (m *MockAgent) SendHandshakeResponse(arg0 *session.HandshakeData) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHandshakeResponse", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHandshakeResponse indicates an expected call of SendHandshakeResponse.
+func (mr *MockAgentMockRecorder) SendHandshakeResponse(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHandshakeResponse", reflect.TypeOf((*MockAgent)(nil).SendHandshakeResponse), arg0)
+}
+
+// SendRequest mocks base method.
 func (m *MockAgent) SendRequest(arg0 context.Context, arg1, arg2 string, arg3 interface{}) (*protos.Response, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SendRequest", arg0, arg1, arg2, arg3)
@@ -201,37 +300,37 @@ func (m *MockAgent) SendRequest(arg0 context.Context, arg1, arg2 string, arg3 in
 	return ret0, ret1
 }
 
-// SendRequest indicates an expected call of SendRequest
+// SendRequest indicates an expected call of SendRequest.
 func (mr *MockAgentMockRecorder) SendRequest(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendRequest", reflect.TypeOf((*MockAgent)(nil).SendRequest), arg0, arg1, arg2, arg3)
 }
 
-// SetLastAt mocks base method
+// SetLastAt mocks base method.
 func (m *MockAgent) SetLastAt() {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "SetLastAt")
 }
 
-// SetLastAt indicates an expected call of SetLastAt
+// SetLastAt indicates an expected call of SetLastAt.
 func (mr *MockAgentMockRecorder) SetLastAt() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLastAt", reflect.TypeOf((*MockAgent)(nil).SetLastAt))
 }
 
-// SetStatus mocks base method
+// SetStatus mocks base method.
 func (m *MockAgent) SetStatus(arg0 int32) {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "SetStatus", arg0)
 }
 
-// SetStatus indicates an expected call of SetStatus
+// SetStatus indicates an expected call of SetStatus.
 func (mr *MockAgentMockRecorder) SetStatus(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStatus", reflect.TypeOf((*MockAgent)(nil).SetStatus), arg0)
 }
 
-// String mocks base method
+// String mocks base method.
 func (m *MockAgent) String() string {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "String")
@@ -239,36 +338,36 @@ func (m *MockAgent) String() string {
 	return ret0
 }
 
-// String indicates an expected call of String
+// String indicates an expected call of String.
 func (mr *MockAgentMockRecorder) String() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "String", reflect.TypeOf((*MockAgent)(nil).String))
 }
 
-// MockAgentFactory is a mock of AgentFactory interface
+// MockAgentFactory is a mock of AgentFactory interface.
 type MockAgentFactory struct {
 	ctrl     *gomock.Controller
 	recorder *MockAgentFactoryMockRecorder
 }
 
-// MockAgentFactoryMockRecorder is the mock recorder for MockAgentFactory
+// MockAgentFactoryMockRecorder is the mock recorder for MockAgentFactory.
 type MockAgentFactoryMockRecorder struct {
 	mock *MockAgentFactory
 }
 
-// NewMockAgentFactory creates a new mock instance
+// NewMockAgentFactory creates a new mock instance.
 func NewMockAgentFactory(ctrl *gomock.Controller) *MockAgentFactory {
 	mock := &MockAgentFactory{ctrl: ctrl}
 	mock.recorder = &MockAgentFactoryMockRecorder{mock}
 	return mock
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use
+// EXPECT returns an object that allows the caller to indicate expected use.
 func (m *MockAgentFactory) EXPECT() *MockAgentFactoryMockRecorder {
 	return m.recorder
 }
 
-// CreateAgent mocks base method
+// CreateAgent mocks base method.
 func (m *MockAgentFactory) CreateAgent(arg0 net.Conn) agent.Agent {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateAgent", arg0)
@@ -276,8 +375,22 @@ func (m *MockAgentFactory) CreateAgent(arg0 net.Conn) agent.Agent {
 	return ret0
 }
 
-// CreateAgent indicates an expected call of CreateAgent
+// CreateAgent indicates an expected call of CreateAgent.
 func (mr *MockAgentFactoryMockRecorder) CreateAgent(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAgent", reflect.TypeOf((*MockAgentFactory)(nil).CreateAgent), arg0)
 }
+
+// Reconnect mocks base method.
+func (m *MockAgentFactory) Reconnect(arg0 net.Conn, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reconnect", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reconnect indicates an expected call of Reconnect.
+func (mr *MockAgentFactoryMockRecorder) Reconnect(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reconnect", reflect.TypeOf((*MockAgentFactory)(nil).Reconnect), arg0, arg1)
+}
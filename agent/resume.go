@@ -0,0 +1,350 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package agent
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/topfreegames/pitaya/v2/config"
+	"github.com/topfreegames/pitaya/v2/conn/message"
+	"github.com/topfreegames/pitaya/v2/constants"
+	"github.com/topfreegames/pitaya/v2/errors"
+	"github.com/topfreegames/pitaya/v2/logger"
+	"github.com/topfreegames/pitaya/v2/metrics"
+	"github.com/topfreegames/pitaya/v2/tracing"
+)
+
+// resumeRegistry keeps the resumable agents of a frontend server indexed
+// by the resume token sent to their clients on handshake
+type resumeRegistry struct {
+	mutex       sync.Mutex
+	gracePeriod time.Duration
+	maxPushes   int
+	agents      map[string]*agentImpl
+	timers      map[string]*time.Timer
+}
+
+func newResumeRegistry(config config.SessionResumeConfig) *resumeRegistry {
+	if !config.Enabled {
+		return nil
+	}
+	return &resumeRegistry{
+		gracePeriod: config.GracePeriod,
+		maxPushes:   config.MaxPushes,
+		agents:      make(map[string]*agentImpl),
+		timers:      make(map[string]*time.Timer),
+	}
+}
+
+func newResumeToken() string {
+	return uuid.New().String()
+}
+
+func (r *resumeRegistry) register(token string, a *agentImpl) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.agents[token] = a
+}
+
+func (r *resumeRegistry) get(token string) *agentImpl {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.agents[token]
+}
+
+// take removes the agent from the registry, only one caller can take it
+func (r *resumeRegistry) take(token string) *agentImpl {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	a, ok := r.agents[token]
+	if !ok {
+		return nil
+	}
+	delete(r.agents, token)
+	if t, ok := r.timers[token]; ok {
+		t.Stop()
+		delete(r.timers, token)
+	}
+	return a
+}
+
+func (r *resumeRegistry) remove(token string, a *agentImpl) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.agents[token] != a {
+		return
+	}
+	delete(r.agents, token)
+	if t, ok := r.timers[token]; ok {
+		t.Stop()
+		delete(r.timers, token)
+	}
+}
+
+// expireAfter closes the session of a suspended agent if it is not resumed
+// within the grace period, it returns false if the agent is not registered
+func (r *resumeRegistry) expireAfter(token string, a *agentImpl) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.agents[token] != a {
+		return false
+	}
+	r.timers[token] = time.AfterFunc(r.gracePeriod, func() {
+		if r.take(token) == a {
+			logger.Log.Debugf("Suspended session expired, ID=%d, UID=%s", a.Session.ID(), a.Session.UID())
+			a.Session.Close()
+		}
+	})
+	return true
+}
+
+// suspend stops the agent goroutines and closes its connection while
+// keeping the session alive, so that the client can resume it later
+func (a *agentImpl) suspend() bool {
+	a.closeMutex.Lock()
+	defer a.closeMutex.Unlock()
+
+	switch a.GetStatus() {
+	case constants.StatusSuspended:
+		return true
+	case constants.StatusWorking:
+	default:
+		return false
+	}
+
+	if a.resume == nil || !a.resume.expireAfter(a.resumeToken, a) {
+		return false
+	}
+	a.SetStatus(constants.StatusSuspended)
+
+	logger.Log.Debugf("Session suspended, ID=%d, UID=%s, IP=%s",
+		a.Session.ID(), a.Session.UID(), a.conn.RemoteAddr())
+
+	close(a.chStopWrite)
+	close(a.chStopOrder)
+	close(a.chStopHeartbeat)
+	a.conn.Close()
+	return true
+}
+
+// stop is called when the connection of the agent is broken, it suspends
+// the agent if its session can be resumed and closes it otherwise
+func (a *agentImpl) stop() {
+	if !a.suspend() {
+		a.Close()
+	}
+}
+
+// Disconnect is called when the client connection is lost
+func (a *agentImpl) Disconnect() {
+	a.suspendMutex.Lock()
+	resumed := a.resumedBy != nil
+	a.suspendMutex.Unlock()
+	if resumed || a.suspend() {
+		return
+	}
+	a.Session.Close()
+}
+
+// park keeps the pushes that were not sent to the client when the agent
+// was suspended, in the order they would have been written
+func (a *agentImpl) park() {
+	<-a.chWriteDone
+
+	pushes := make([]pendingWrite, 0)
+	keep := func(pWrite pendingWrite) {
+		if pWrite.msg == nil {
+			// heartbeat
+			return
+		}
+		if pWrite.msg.Type != message.Push {
+			err := errors.NewError(constants.ErrBrokenPipe, errors.ErrClientClosedRequest)
+			tracing.FinishSpan(pWrite.ctx, err)
+			metrics.ReportTimingFromCtx(pWrite.ctx, a.metricsReporters, handlerType, err)
+			return
+		}
+		pushes = append(pushes, pWrite)
+	}
+
+	if a.failedWrite != nil {
+		keep(*a.failedWrite)
+		a.failedWrite = nil
+	}
+
+	drain := func(ch chan pendingWrite) {
+		for {
+			select {
+			case pWrite := <-ch:
+				keep(pWrite)
+			default:
+				return
+			}
+		}
+	}
+
+	drain(a.chSend)
+	for _, pWrite := range a.unordered {
+		keep(pWrite)
+	}
+	a.unordered = nil
+
+	for _, pWrite := range a.takeAllFrozen(freezeReasonSuspended) {
+		keep(pWrite)
+	}
+	drain(a.chOrder)
+
+	logger.Log.Debugf("parked push msgs, ID=%d, UID=%s, len=%d", a.Session.ID(), a.Session.UID(), len(pushes))
+
+	a.suspendMutex.Lock()
+	a.suspended = append(pushes, a.suspended...)
+	a.suspendMutex.Unlock()
+	close(a.chParked)
+}
+
+// pushSuspended keeps a push sent to a suspended agent until its session is
+// resumed or the agent is closed
+func (a *agentImpl) pushSuspended(ctx context.Context, route string, v interface{}) error {
+	select {
+	case <-a.chParked:
+	case <-a.chDie:
+		return errors.NewError(constants.ErrBrokenPipe, errors.ErrClientClosedRequest)
+	}
+
+	m, err := a.getMessageFromPendingMessage(pendingMessage{ctx: ctx, typ: message.Push, route: route, payload: v})
+	if err != nil {
+		return err
+	}
+	p, err := a.packetEncodeMessage(ctx, m)
+	if err != nil {
+		return err
+	}
+
+	a.suspendMutex.Lock()
+	if next := a.resumedBy; next != nil {
+		a.suspendMutex.Unlock()
+		return next.Push(ctx, route, v)
+	}
+	if a.GetStatus() != constants.StatusSuspended {
+		a.suspendMutex.Unlock()
+		return errors.NewError(constants.ErrBrokenPipe, errors.ErrClientClosedRequest)
+	}
+	if len(a.suspended) >= a.resume.maxPushes {
+		a.suspendMutex.Unlock()
+		logger.Log.Warnf("suspended session push buffer is full, session will be closed, ID=%d, UID=%s",
+			a.Session.ID(), a.Session.UID())
+		if a.resume.take(a.resumeToken) == a {
+			go a.Session.Close()
+		}
+		return errors.NewError(constants.ErrResumeBufferExceed, errors.ErrClientClosedRequest)
+	}
+	a.suspended = append(a.suspended, pendingWrite{ctx: ctx, data: p, msg: m})
+	a.suspendMutex.Unlock()
+	return nil
+}
+
+// ResumeSession binds the session of the suspended agent identified by token
+// to this agent, the pushes kept meanwhile are sent after the handshake
+func (a *agentImpl) ResumeSession(token string) error {
+	if a.resume == nil {
+		return constants.ErrSessionResumeDisabled
+	}
+
+	old := a.resume.get(token)
+	if old == nil || old == a || !old.suspend() || a.resume.take(token) != old {
+		return constants.ErrInvalidResumeToken
+	}
+
+	select {
+	case <-old.chParked:
+	case <-old.chDie:
+		return constants.ErrInvalidResumeToken
+	}
+
+	fresh := a.Session
+	a.Session = old.Session
+	a.curMsgID = old.curMsgID
+	a.resuming = old
+	a.sessionPool.DiscardSession(fresh)
+	metrics.ReportNumberOfConnectedClients(a.metricsReporters, a.sessionPool.GetSessionCount())
+
+	logger.Log.Debugf("Session resumed, ID=%d, UID=%s, IP=%s",
+		a.Session.ID(), a.Session.UID(), a.conn.RemoteAddr())
+	return nil
+}
+
+// completeResume hands the session over from the suspended agent to this
+// agent and enqueues the pushes it kept
+func (a *agentImpl) completeResume() {
+	old := a.resuming
+	a.resuming = nil
+
+	// pushes that were being enqueued when the agent was suspended
+	var leftovers []pendingWrite
+	for drained := false; !drained; {
+		select {
+		case pWrite := <-old.chOrder:
+			if pWrite.msg != nil && pWrite.msg.Type == message.Push {
+				leftovers = append(leftovers, pWrite)
+			}
+		default:
+			drained = true
+		}
+	}
+
+	// the kept pushes are enqueued without holding suspendMutex, pushes
+	// kept meanwhile are taken in the next round until none is left and
+	// the following ones are forwarded to this agent
+	for {
+		old.suspendMutex.Lock()
+		pending := append(old.suspended, leftovers...)
+		old.suspended = nil
+		leftovers = nil
+		if len(pending) == 0 {
+			old.resumedBy = a
+			old.suspendMutex.Unlock()
+			break
+		}
+		old.suspendMutex.Unlock()
+
+		for _, pWrite := range pending {
+			// the relation ids refer to requests of the old connection
+			m := *pWrite.msg
+			m.ID = 0
+			pWrite.msg = &m
+			select {
+			case a.chOrder <- pWrite:
+			case <-a.chDie:
+			}
+		}
+	}
+
+	a.Session.SetEntity(a)
+
+	old.closeMutex.Lock()
+	old.SetStatus(constants.StatusClosed)
+	old.cancel()
+	close(old.chDie)
+	old.closeMutex.Unlock()
+}
@@ -0,0 +1,268 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/topfreegames/pitaya/v2/config"
+	"github.com/topfreegames/pitaya/v2/conn/codec"
+	"github.com/topfreegames/pitaya/v2/conn/message"
+	"github.com/topfreegames/pitaya/v2/conn/packet"
+	"github.com/topfreegames/pitaya/v2/constants"
+	"github.com/topfreegames/pitaya/v2/helpers"
+	serializejson "github.com/topfreegames/pitaya/v2/serialize/json"
+	"github.com/topfreegames/pitaya/v2/session"
+	"github.com/topfreegames/pitaya/v2/timer"
+)
+
+func newResumableAgentFactory(sessionPool session.SessionPool, gracePeriod time.Duration, maxPushes int) *agentFactoryImpl {
+	resumeConfig := config.SessionResumeConfig{
+		Enabled:     true,
+		GracePeriod: gracePeriod,
+		MaxPushes:   maxPushes,
+	}
+	return NewAgentFactory(nil, codec.NewPomeloPacketDecoder(), codec.NewPomeloPacketEncoder(), serializejson.NewSerializer(), nil,
+		time.Second, message.NewMessagesEncoder(false), 10, sessionPool, nil, resumeConfig, config.PushFreezeConfig{}, *config.NewDefaultHandshakeConfig(), timer.GlobalClock()).(*agentFactoryImpl)
+}
+
+// readPackets reads the packets written by the agent on the client side of the pipe
+func readPackets(conn net.Conn) chan *packet.Packet {
+	packets := make(chan *packet.Packet, 100)
+	decoder := codec.NewPomeloPacketDecoder()
+	go func() {
+		buf := make([]byte, 65536)
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				return
+			}
+			pkts, err := decoder.Decode(buf[:n])
+			if err != nil {
+				return
+			}
+			for _, p := range pkts {
+				if p.Type != packet.Heartbeat {
+					packets <- p
+				}
+			}
+		}
+	}()
+	return packets
+}
+
+func connectResumableAgent(t *testing.T, f *agentFactoryImpl) (*agentImpl, net.Conn, chan *packet.Packet) {
+	serverConn, clientConn := net.Pipe()
+	ag := f.CreateAgent(serverConn).(*agentImpl)
+	go ag.Handle()
+	packets := readPackets(clientConn)
+	return ag, clientConn, packets
+}
+
+func handshakeSys(t *testing.T, packets chan *packet.Packet) map[string]interface{} {
+	p := helpers.ShouldEventuallyReceive(t, packets).(*packet.Packet)
+	assert.EqualValues(t, packet.Handshake, p.Type)
+	res := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(p.Data, &res))
+	return res["sys"].(map[string]interface{})
+}
+
+func TestResumeRegistry(t *testing.T) {
+	assert.Nil(t, newResumeRegistry(config.SessionResumeConfig{Enabled: false}))
+
+	r := newResumeRegistry(config.SessionResumeConfig{Enabled: true, GracePeriod: time.Hour, MaxPushes: 1})
+	a := &agentImpl{}
+	b := &agentImpl{}
+
+	r.register("token", a)
+	assert.Equal(t, a, r.get("token"))
+
+	r.remove("token", b)
+	assert.Equal(t, a, r.get("token"))
+
+	assert.True(t, r.expireAfter("token", a))
+	assert.False(t, r.expireAfter("other", a))
+	assert.Len(t, r.timers, 1)
+
+	assert.Equal(t, a, r.take("token"))
+	assert.Nil(t, r.take("token"))
+	assert.Len(t, r.timers, 0)
+
+	r.register("token", a)
+	r.remove("token", a)
+	assert.Nil(t, r.get("token"))
+}
+
+func TestAgentSendHandshakeResponseWithResumeToken(t *testing.T) {
+	sessionPool := session.NewSessionPool()
+	f := newResumableAgentFactory(sessionPool, time.Minute, 10)
+
+	ag, clientConn, packets := connectResumableAgent(t, f)
+	defer clientConn.Close()
+
+	assert.NoError(t, ag.SendHandshakeResponse(&session.HandshakeData{}))
+	sys := handshakeSys(t, packets)
+	assert.Equal(t, ag.resumeToken, sys["resumeToken"])
+	assert.Equal(t, false, sys["resumed"])
+	assert.Equal(t, ag, f.resume.get(ag.resumeToken))
+
+	ag.Close()
+	assert.Nil(t, f.resume.get(ag.resumeToken))
+}
+
+func TestAgentDisconnectClosesSessionIfNotResumable(t *testing.T) {
+	sessionPool := session.NewSessionPool()
+	f := newResumableAgentFactory(sessionPool, time.Minute, 10)
+
+	ag, clientConn, _ := connectResumableAgent(t, f)
+	defer clientConn.Close()
+
+	// the handshake was not completed
+	ag.Disconnect()
+	assert.Equal(t, constants.StatusClosed, ag.GetStatus())
+	assert.Equal(t, int64(0), sessionPool.GetSessionCount())
+}
+
+func TestAgentResumeSession(t *testing.T) {
+	sessionPool := session.NewSessionPool()
+	f := newResumableAgentFactory(sessionPool, time.Minute, 10)
+
+	ag, clientConn, packets := connectResumableAgent(t, f)
+	assert.NoError(t, ag.SendHandshakeResponse(&session.HandshakeData{}))
+	handshakeSys(t, packets)
+	ag.SetStatus(constants.StatusWorking)
+	assert.NoError(t, ag.Session.Bind(context.Background(), "uid"))
+	assert.NoError(t, ag.Session.Set("key", "value"))
+	token := ag.resumeToken
+
+	var closed int32
+	assert.NoError(t, ag.Session.OnClose(func() { atomic.StoreInt32(&closed, 1) }))
+
+	// connection lost
+	clientConn.Close()
+	ag.Disconnect()
+	assert.Equal(t, constants.StatusSuspended, ag.GetStatus())
+
+	assert.NoError(t, ag.Session.Push(context.Background(), "route.a", []byte("a")))
+	assert.NoError(t, ag.Session.Push(context.Background(), "route.b", []byte("b")))
+
+	newAg, newClientConn, newPackets := connectResumableAgent(t, f)
+	defer newClientConn.Close()
+
+	assert.NoError(t, newAg.ResumeSession(token))
+	assert.Equal(t, ag.Session, newAg.Session)
+	assert.Equal(t, "uid", newAg.Session.UID())
+	assert.Equal(t, "value", newAg.Session.String("key"))
+	assert.Equal(t, newAg.Session, sessionPool.GetSessionByUID("uid"))
+	assert.Equal(t, int64(1), sessionPool.GetSessionCount())
+
+	assert.NoError(t, newAg.SendHandshakeResponse(&session.HandshakeData{}))
+	sys := handshakeSys(t, newPackets)
+	assert.Equal(t, true, sys["resumed"])
+	assert.NotEqual(t, token, sys["resumeToken"])
+
+	for _, route := range []string{"route.a", "route.b"} {
+		p := helpers.ShouldEventuallyReceive(t, newPackets).(*packet.Packet)
+		m, err := message.Decode(p.Data)
+		assert.NoError(t, err)
+		assert.Equal(t, route, m.Route)
+	}
+
+	assert.Equal(t, constants.StatusClosed, ag.GetStatus())
+	assert.Equal(t, int32(0), atomic.LoadInt32(&closed))
+
+	// the old connection goroutine must not close the resumed session
+	ag.Disconnect()
+	assert.Equal(t, int32(0), atomic.LoadInt32(&closed))
+
+	// pushes are now sent through the new agent
+	assert.NoError(t, newAg.Session.Push(context.Background(), "route.c", []byte("c")))
+	p := helpers.ShouldEventuallyReceive(t, newPackets).(*packet.Packet)
+	m, err := message.Decode(p.Data)
+	assert.NoError(t, err)
+	assert.Equal(t, "route.c", m.Route)
+
+	// an used token can't resume the session again
+	otherAg, otherClientConn, _ := connectResumableAgent(t, f)
+	defer otherClientConn.Close()
+	assert.Equal(t, constants.ErrInvalidResumeToken, otherAg.ResumeSession(token))
+}
+
+func TestAgentResumeSessionErrors(t *testing.T) {
+	sessionPool := session.NewSessionPool()
+	f := newResumableAgentFactory(sessionPool, time.Minute, 10)
+
+	ag, clientConn, _ := connectResumableAgent(t, f)
+	defer clientConn.Close()
+	assert.Equal(t, constants.ErrInvalidResumeToken, ag.ResumeSession("invalid"))
+
+	ag.resume = nil
+	assert.Equal(t, constants.ErrSessionResumeDisabled, ag.ResumeSession("invalid"))
+}
+
+func TestAgentSuspendedSessionExpires(t *testing.T) {
+	sessionPool := session.NewSessionPool()
+	f := newResumableAgentFactory(sessionPool, 10*time.Millisecond, 10)
+
+	ag, clientConn, packets := connectResumableAgent(t, f)
+	assert.NoError(t, ag.SendHandshakeResponse(&session.HandshakeData{}))
+	handshakeSys(t, packets)
+	ag.SetStatus(constants.StatusWorking)
+
+	var closed int32
+	assert.NoError(t, ag.Session.OnClose(func() { atomic.StoreInt32(&closed, 1) }))
+
+	clientConn.Close()
+	ag.Disconnect()
+	assert.Equal(t, constants.StatusSuspended, ag.GetStatus())
+
+	helpers.ShouldEventuallyReturn(t, func() int32 { return atomic.LoadInt32(&closed) }, int32(1))
+	assert.Equal(t, constants.StatusClosed, ag.GetStatus())
+	assert.Equal(t, int64(0), sessionPool.GetSessionCount())
+	assert.Nil(t, f.resume.get(ag.resumeToken))
+}
+
+func TestAgentSuspendedSessionClosesIfBufferExceeds(t *testing.T) {
+	sessionPool := session.NewSessionPool()
+	f := newResumableAgentFactory(sessionPool, time.Minute, 1)
+
+	ag, clientConn, packets := connectResumableAgent(t, f)
+	assert.NoError(t, ag.SendHandshakeResponse(&session.HandshakeData{}))
+	handshakeSys(t, packets)
+	ag.SetStatus(constants.StatusWorking)
+
+	clientConn.Close()
+	ag.Disconnect()
+
+	assert.NoError(t, ag.Push(context.Background(), "route", []byte("a")))
+	err := ag.Push(context.Background(), "route", []byte("b"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), constants.ErrResumeBufferExceed.Error())
+
+	helpers.ShouldEventuallyReturn(t, func() int32 { return ag.GetStatus() }, constants.StatusClosed)
+	assert.Nil(t, f.resume.get(ag.resumeToken))
+}
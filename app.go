@@ -26,6 +26,7 @@ import (
 	"os/signal"
 	"reflect"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -87,9 +88,12 @@ type Pitaya interface {
 	RegisterRPCJob(rpcJob worker.RPCJob) error
 	Documentation(getPtrNames bool) (map[string]interface{}, error)
 	IsRunning() bool
+	IsDraining() bool
 
 	RPC(ctx context.Context, routeStr string, reply proto.Message, arg proto.Message) error
 	RPCTo(ctx context.Context, serverID, routeStr string, reply proto.Message, arg proto.Message) error
+	RPCStream(ctx context.Context, routeStr string, arg proto.Message) (component.ClientStream, error)
+	RPCStreamTo(ctx context.Context, serverID, routeStr string, arg proto.Message) (component.ClientStream, error)
 	ReliableRPC(
 		routeStr string,
 		metadata map[string]interface{},
@@ -101,6 +105,18 @@ type Pitaya interface {
 		reply, arg proto.Message,
 		opts *config.EnqueueOpts,
 	) (jid string, err error)
+	ScheduleReliableRPC(
+		routeStr string,
+		metadata map[string]interface{},
+		reply, arg proto.Message,
+		opts *worker.ScheduleOpts,
+	) (jid string, err error)
+	CancelReliableRPC(jid string) error
+	ReliableRPCStatus(jid string) (*worker.RPCJobInfo, error)
+	FailedReliableRPCs() ([]*worker.RPCJobInfo, error)
+	RequeueReliableRPC(jid string) error
+	PurgeReliableRPC(jid string) error
+	PurgeReliableRPCs() (int, error)
 
 	SendPushToUsers(ctx context.Context, route string, v interface{}, uids []string, frontendType string) ([]string, error)
 	SendKickToUsers(ctx context.Context, uids []string, frontendType string) ([]string, error)
@@ -138,8 +154,11 @@ type App struct {
 	rpcClient        cluster.RPCClient
 	rpcServer        cluster.RPCServer
 	metricsReporters []metrics.Reporter
-	running          bool
+	running          int32
+	ticker           timer.Ticker
+	clock            timer.Clock
 	serializer       serialize.Serializer
+	serializers      serialize.Serializers // serializers the clients can negotiate
 	server           *cluster.Server
 	serverMode       ServerMode
 	serviceDiscovery cluster.ServiceDiscovery
@@ -153,6 +172,7 @@ type App struct {
 	modulesArr       []moduleWrapper
 	groups           groups.GroupService
 	sessionPool      session.SessionPool
+	draining         int32
 }
 
 // NewApp is the base constructor for a pitaya app instance
@@ -190,7 +210,6 @@ func NewApp(
 		acceptors:        acceptors,
 		metricsReporters: metricsReporters,
 		serverMode:       serverMode,
-		running:          false,
 		serializer:       serializer,
 		router:           router,
 		handlerComp:      make([]regComp, 0),
@@ -198,6 +217,7 @@ func NewApp(
 		modulesMap:       make(map[string]interfaces.Module),
 		modulesArr:       []moduleWrapper{},
 		sessionPool:      sessionPool,
+		clock:            timer.GlobalClock(),
 	}
 	if app.heartbeat == time.Duration(0) {
 		app.heartbeat = config.Heartbeat.Interval
@@ -256,7 +276,7 @@ func (app *App) GetServers() []*cluster.Server {
 // doesn't cover acceptors, only the pitaya internal registration and modules
 // initialization.
 func (app *App) IsRunning() bool {
-	return app.running
+	return atomic.LoadInt32(&app.running) == 1
 }
 
 // SetLogger logger setter
@@ -272,12 +292,19 @@ func (app *App) initSysRemotes() {
 	)
 }
 
+func (app *App) initWorkerRemotes() {
+	app.RegisterRemote(remote.NewWorkerAdmin(app.worker),
+		component.WithName("sysworker"),
+		component.WithNameFunc(strings.ToLower),
+	)
+}
+
 func (app *App) periodicMetrics() {
 	period := app.config.Metrics.Period
 	go metrics.ReportSysMetrics(app.metricsReporters, period)
 
 	if app.worker.Started() {
-		go worker.Report(app.metricsReporters, period)
+		go app.worker.Report(app.metricsReporters, period)
 	}
 }
 
@@ -295,6 +322,9 @@ func (app *App) Start() {
 		if reflect.TypeOf(app.rpcClient) == reflect.TypeOf(&cluster.GRPCClient{}) {
 			app.serviceDiscovery.AddListener(app.rpcClient.(*cluster.GRPCClient))
 		}
+		if app.router != nil {
+			app.serviceDiscovery.AddListener(app.router)
+		}
 
 		if err := app.RegisterModuleBefore(app.rpcServer, "rpcServer"); err != nil {
 			logger.Log.Fatal("failed to register rpc server module: %s", err.Error())
@@ -315,8 +345,9 @@ func (app *App) Start() {
 	app.listen()
 
 	defer func() {
-		timer.GlobalTicker.Stop()
-		app.running = false
+		app.handlerService.Stop()
+		app.ticker.Stop()
+		atomic.StoreInt32(&app.running, 0)
 	}()
 
 	sg := make(chan os.Signal)
@@ -333,25 +364,43 @@ func (app *App) Start() {
 
 	logger.Log.Warn("server is stopping...")
 
+	if app.config.Drain.Enabled {
+		app.drain()
+	}
+
 	app.sessionPool.CloseAll()
 	app.shutdownModules()
 	app.shutdownComponents()
+	if err := app.worker.Stop(); err != nil {
+		logger.Log.Warnf("error stopping worker: %s", err.Error())
+	}
+	app.shutdownMetricsReporters()
 }
 
 func (app *App) listen() {
 	app.startupComponents()
-	// create global ticker instance, timer precision could be customized
-	// by SetTimerPrecision
-	timer.GlobalTicker = time.NewTicker(timer.Precision)
+	// create the ticker running the timers, timer precision could be
+	// customized by SetTimerPrecision
+	app.ticker = app.clock.NewTicker(timer.Precision)
+	app.handlerService.SetTicker(app.clock, app.ticker)
 
 	logger.Log.Infof("starting server %s:%s", app.server.Type, app.server.ID)
-	for i := 0; i < app.config.Concurrency.Handler.Dispatch; i++ {
+	dispatch := app.config.Concurrency.Handler.Dispatch
+	if app.config.Concurrency.Handler.Model == config.ConcurrencyActor && dispatch > 1 {
+		// messages are processed by the mailboxes, a single loop runs the timers
+		dispatch = 1
+	}
+	for i := 0; i < dispatch; i++ {
 		go app.handlerService.Dispatch(i)
 	}
 	for _, acc := range app.acceptors {
 		a := acc
 		go func() {
 			for conn := range a.GetConnChan() {
+				if app.IsDraining() {
+					go app.handlerService.Refuse(conn, app.config.Drain.Reconnect, app.config.Drain.ReconnectAddr)
+					continue
+				}
 				go app.handlerService.Handle(conn)
 			}
 		}()
@@ -375,12 +424,12 @@ func (app *App) listen() {
 
 	logger.Log.Info("all modules started!")
 
-	app.running = true
+	atomic.StoreInt32(&app.running, 1)
 }
 
 // SetDictionary sets routes map
 func (app *App) SetDictionary(dict map[string]uint16) error {
-	if app.running {
+	if app.IsRunning() {
 		return constants.ErrChangeDictionaryWhileRunning
 	}
 	return message.SetDictionary(dict)
@@ -392,7 +441,7 @@ func (app *App) AddRoute(
 	routingFunction router.RoutingFunc,
 ) error {
 	if app.router != nil {
-		if app.running {
+		if app.IsRunning() {
 			return constants.ErrChangeRouteWhileRunning
 		}
 		app.router.AddRoute(serverType, routingFunction)
@@ -499,9 +548,14 @@ func Descriptor(protoName string) ([]byte, error) {
 	return docgenerator.ProtoDescriptors(protoName)
 }
 
-// StartWorker configures, starts and returns pitaya worker
+// StartWorker configures, starts and returns pitaya worker, it also
+// registers the sysworker remotes so it must be called before Start
 func (app *App) StartWorker() {
+	if app.worker.Started() {
+		return
+	}
 	app.worker.Start()
+	app.initWorkerRemotes()
 }
 
 // RegisterRPCJob registers rpc job to execute jobs with retries
@@ -44,6 +44,8 @@ import (
 	"github.com/topfreegames/pitaya/v2/helpers"
 	"github.com/topfreegames/pitaya/v2/logger"
 	"github.com/topfreegames/pitaya/v2/logger/logrus"
+	"github.com/topfreegames/pitaya/v2/metrics"
+	"github.com/topfreegames/pitaya/v2/remote"
 	"github.com/topfreegames/pitaya/v2/route"
 	"github.com/topfreegames/pitaya/v2/router"
 	"github.com/topfreegames/pitaya/v2/session/mocks"
@@ -130,6 +132,25 @@ func TestGetMetricsReporters(t *testing.T) {
 	app := NewDefaultApp(true, "testtype", Cluster, map[string]string{}, *builderConfig).(*App)
 	assert.Equal(t, app.metricsReporters, app.GetMetricsReporters())
 }
+
+type shutdownReporter struct {
+	metrics.Reporter
+	shutdown bool
+}
+
+func (r *shutdownReporter) Shutdown(ctx context.Context) error {
+	r.shutdown = true
+	return nil
+}
+
+func TestShutdownMetricsReporters(t *testing.T) {
+	builderConfig := config.NewDefaultBuilderConfig()
+	app := NewDefaultApp(true, "testtype", Cluster, map[string]string{}, *builderConfig).(*App)
+	reporter := &shutdownReporter{}
+	app.metricsReporters = append(app.metricsReporters, reporter)
+	app.shutdownMetricsReporters()
+	assert.True(t, reporter.shutdown)
+}
 func TestGetServerByID(t *testing.T) {
 	builderConfig := config.NewDefaultBuilderConfig()
 	app := NewDefaultApp(true, "testtype", Cluster, map[string]string{}, *builderConfig)
@@ -170,7 +191,7 @@ func TestSetDictionary(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, dict, message.GetDictionary())
 
-	app.running = true
+	app.running = 1
 	err = app.SetDictionary(dict)
 	assert.EqualError(t, constants.ErrChangeDictionaryWhileRunning, err.Error())
 }
@@ -190,7 +211,7 @@ func TestAddRoute(t *testing.T) {
 	})
 	assert.NoError(t, err)
 
-	app.running = true
+	app.running = 1
 	err = app.AddRoute("somesv", func(ctx context.Context, route *route.Route, payload []byte, servers map[string]*cluster.Server) (*cluster.Server, error) {
 		return nil, nil
 	})
@@ -266,6 +287,51 @@ func TestDefaultRPCClient(t *testing.T) {
 	assert.Equal(t, typeOfNatsRPCClient, reflect.TypeOf(app.rpcClient))
 }
 
+// tickedClock is a clock at a fixed time whose tickers have already ticked
+type tickedClock struct {
+	now time.Time
+}
+
+func (c *tickedClock) Now() time.Time {
+	return c.now
+}
+
+func (c *tickedClock) NewTicker(d time.Duration) timer.Ticker {
+	return &tickedTicker{now: c.now.Add(d)}
+}
+
+type tickedTicker struct {
+	now time.Time
+}
+
+func (t *tickedTicker) C() <-chan time.Time {
+	c := make(chan time.Time, 1)
+	c <- t.now
+	return c
+}
+
+func (t *tickedTicker) Stop() {}
+
+func TestDrainWaitsOnAppClock(t *testing.T) {
+	builderConfig := config.NewDefaultBuilderConfig()
+	builderConfig.Pitaya.Drain.Delay = time.Hour
+	builder := NewDefaultBuilder(true, "testtype", Standalone, map[string]string{}, *builderConfig)
+	builder.Clock = &tickedClock{now: time.Now()}
+	app := builder.Build().(*App)
+
+	done := make(chan struct{})
+	go func() {
+		app.drain()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("drain didn't wait for the delay on the clock of the app")
+	}
+	assert.True(t, app.IsDraining())
+}
+
 func TestStartAndListenStandalone(t *testing.T) {
 	builderConfig := config.NewDefaultBuilderConfig()
 
@@ -278,11 +344,11 @@ func TestStartAndListenStandalone(t *testing.T) {
 		app.Start()
 	}()
 	helpers.ShouldEventuallyReturn(t, func() bool {
-		return app.running
+		return app.IsRunning()
 	}, true)
 
 	assert.NotNil(t, app.handlerService)
-	assert.NotNil(t, timer.GlobalTicker)
+	assert.NotNil(t, app.ticker)
 	// should be listening
 	assert.NotEmpty(t, acc.GetAddr())
 	helpers.ShouldEventuallyReturn(t, func() error {
@@ -327,11 +393,11 @@ func TestStartAndListenCluster(t *testing.T) {
 		app.Start()
 	}()
 	helpers.ShouldEventuallyReturn(t, func() bool {
-		return app.running
+		return app.IsRunning()
 	}, true)
 
 	assert.NotNil(t, app.handlerService)
-	assert.NotNil(t, timer.GlobalTicker)
+	assert.NotNil(t, app.ticker)
 	// should be listening
 	assert.NotEmpty(t, acc.GetAddr())
 	helpers.ShouldEventuallyReturn(t, func() error {
@@ -434,9 +500,10 @@ func TestDocumentation(t *testing.T) {
 		"remotes": map[string]interface{}{
 			"testtype.sys.bindsession": map[string]interface{}{
 				"input": map[string]interface{}{
-					"uid":  "string",
-					"data": "[]byte",
-					"id":   "int64",
+					"uid":        "string",
+					"data":       "[]byte",
+					"id":         "int64",
+					"serializer": "string",
 				},
 				"output": []interface{}{
 					map[string]interface{}{
@@ -452,7 +519,8 @@ func TestDocumentation(t *testing.T) {
 			},
 			"testtype.sys.kick": map[string]interface{}{
 				"input": map[string]interface{}{
-					"userId": "string",
+					"relation_msg_id": "uint64",
+					"userId":          "string",
 				},
 				"output": []interface{}{
 					map[string]interface{}{
@@ -463,9 +531,10 @@ func TestDocumentation(t *testing.T) {
 			},
 			"testtype.sys.pushsession": map[string]interface{}{
 				"input": map[string]interface{}{
-					"data": "[]byte",
-					"id":   "int64",
-					"uid":  "string",
+					"data":       "[]byte",
+					"id":         "int64",
+					"serializer": "string",
+					"uid":        "string",
 				},
 				"output": []interface{}{
 					map[string]interface{}{
@@ -494,9 +563,10 @@ func TestDocumentationTrue(t *testing.T) {
 			"testtype.sys.bindsession": map[string]interface{}{
 				"input": map[string]interface{}{
 					"*protos.Session": map[string]interface{}{
-						"data": "[]byte",
-						"id":   "int64",
-						"uid":  "string",
+						"data":       "[]byte",
+						"id":         "int64",
+						"serializer": "string",
+						"uid":        "string",
 					},
 				},
 				"output": []interface{}{map[string]interface{}{
@@ -517,7 +587,8 @@ func TestDocumentationTrue(t *testing.T) {
 			"testtype.sys.kick": map[string]interface{}{
 				"input": map[string]interface{}{
 					"*protos.KickMsg": map[string]interface{}{
-						"userId": "string",
+						"relation_msg_id": "uint64",
+						"userId":          "string",
 					},
 				},
 				"output": []interface{}{map[string]interface{}{
@@ -531,9 +602,10 @@ func TestDocumentationTrue(t *testing.T) {
 			"testtype.sys.pushsession": map[string]interface{}{
 				"input": map[string]interface{}{
 					"*protos.Session": map[string]interface{}{
-						"data": "[]byte",
-						"id":   "int64",
-						"uid":  "string",
+						"data":       "[]byte",
+						"id":         "int64",
+						"serializer": "string",
+						"uid":        "string",
 					},
 				},
 				"output": []interface{}{map[string]interface{}{
@@ -583,8 +655,15 @@ func TestStartWorker(t *testing.T) {
 	builderConfig := config.NewDefaultBuilderConfig()
 	app := NewDefaultApp(true, "testtype", Cluster, map[string]string{}, *builderConfig).(*App)
 
+	assert.Len(t, app.remoteComp, 1)
+
 	app.StartWorker()
 	assert.True(t, app.worker.Started())
+	assert.Len(t, app.remoteComp, 2)
+	assert.IsType(t, &remote.WorkerAdmin{}, app.remoteComp[1].comp)
+
+	app.StartWorker()
+	assert.Len(t, app.remoteComp, 2)
 }
 
 func TestRegisterRPCJob(t *testing.T) {
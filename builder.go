@@ -1,6 +1,9 @@
 package pitaya
 
 import (
+	"fmt"
+	"io/ioutil"
+
 	"github.com/google/uuid"
 	"github.com/topfreegames/pitaya/v2/acceptor"
 	"github.com/topfreegames/pitaya/v2/agent"
@@ -8,6 +11,7 @@ import (
 	"github.com/topfreegames/pitaya/v2/config"
 	"github.com/topfreegames/pitaya/v2/conn/codec"
 	"github.com/topfreegames/pitaya/v2/conn/message"
+	"github.com/topfreegames/pitaya/v2/constants"
 	"github.com/topfreegames/pitaya/v2/defaultpipelines"
 	"github.com/topfreegames/pitaya/v2/groups"
 	"github.com/topfreegames/pitaya/v2/logger"
@@ -16,9 +20,13 @@ import (
 	"github.com/topfreegames/pitaya/v2/pipeline"
 	"github.com/topfreegames/pitaya/v2/router"
 	"github.com/topfreegames/pitaya/v2/serialize"
+	"github.com/topfreegames/pitaya/v2/serialize/cbor"
 	"github.com/topfreegames/pitaya/v2/serialize/json"
+	"github.com/topfreegames/pitaya/v2/serialize/msgpack"
+	"github.com/topfreegames/pitaya/v2/serialize/protobuf"
 	"github.com/topfreegames/pitaya/v2/service"
 	"github.com/topfreegames/pitaya/v2/session"
+	"github.com/topfreegames/pitaya/v2/timer"
 	"github.com/topfreegames/pitaya/v2/worker"
 )
 
@@ -42,6 +50,15 @@ type Builder struct {
 	SessionPool      session.SessionPool
 	Worker           *worker.Worker
 	HandlerHooks     *pipeline.HandlerHooks
+	// MailboxKey chooses the mailbox of each message when the actor
+	// concurrency model is used, each session has its own mailbox if nil
+	MailboxKey service.MailboxKeyFunc
+	// Serializers are the serializers the clients can negotiate at handshake
+	// besides Serializer, which is used by the clients that don't negotiate one
+	Serializers []serialize.Serializer
+	// Clock drives the timers added by the App and the heartbeats of its
+	// agents
+	Clock timer.Clock
 }
 
 // PitayaBuilder Builder interface
@@ -62,6 +79,7 @@ func NewBuilderWithConfigs(
 	customMetrics := config.NewCustomMetricsSpec(conf)
 	prometheusConfig := config.NewPrometheusConfig(conf)
 	statsdConfig := config.NewStatsdConfig(conf)
+	builderConfig.Metrics.OTLP.Reporter = *config.NewOTLPConfig(conf)
 	etcdSDConfig := config.NewEtcdServiceDiscoveryConfig(conf)
 	natsRPCServerConfig := config.NewNatsRPCServerConfig(conf)
 	natsRPCClientConfig := config.NewNatsRPCClientConfig(conf)
@@ -116,6 +134,33 @@ func NewDefaultBuilder(isFrontend bool, serverType string, serverMode ServerMode
 	)
 }
 
+// NewInProcessBuilder return a builder instance with default configs for an
+// App run inside the process of a test or a tool, with the metrics reporters
+// disabled and the worker jobs kept in memory
+func NewInProcessBuilder(isFrontend bool, serverType string, serverMode ServerMode, serverMetadata map[string]string, builderConfig config.BuilderConfig) *Builder {
+	builderConfig.Metrics.Prometheus.Enabled = false
+	builderConfig.Metrics.Statsd.Enabled = false
+	builderConfig.Metrics.OTLP.Enabled = false
+	workerConfig := config.NewDefaultWorkerConfig()
+	workerConfig.Backend = "memory"
+	return NewBuilder(
+		isFrontend,
+		serverType,
+		serverMode,
+		serverMetadata,
+		builderConfig,
+		*config.NewDefaultCustomMetricsSpec(),
+		*config.NewDefaultPrometheusConfig(),
+		*config.NewDefaultStatsdConfig(),
+		*config.NewDefaultEtcdServiceDiscoveryConfig(),
+		*config.NewDefaultNatsRPCServerConfig(),
+		*config.NewDefaultNatsRPCClientConfig(),
+		*workerConfig,
+		*config.NewDefaultEnqueueOpts(),
+		*config.NewDefaultMemoryGroupConfig(),
+	)
+}
+
 // NewBuilder return a builder instance with default dependency instances for a pitaya App,
 // with configs explicitly defined
 func NewBuilder(isFrontend bool,
@@ -145,6 +190,10 @@ func NewBuilder(isFrontend bool,
 		metricsReporters = addDefaultStatsd(statsdConfig, metricsReporters, serverType)
 	}
 
+	if config.Metrics.OTLP.Enabled {
+		metricsReporters = addDefaultOTLP(config.Metrics.OTLP.Reporter, metricsReporters, serverType)
+	}
+
 	handlerHooks := pipeline.NewHandlerHooks()
 	if config.DefaultPipelines.StructValidation.Enabled {
 		configureDefaultPipelines(handlerHooks)
@@ -157,25 +206,43 @@ func NewBuilder(isFrontend bool,
 	var rpcClient cluster.RPCClient
 	if serverMode == Cluster {
 		var err error
-		serviceDiscovery, err = cluster.NewEtcdServiceDiscovery(etcdSDConfig, server, dieChan)
+		serviceDiscovery, rpcServer, rpcClient, err = newClusterComponents(
+			config.Pitaya.Cluster,
+			server,
+			sessionPool,
+			metricsReporters,
+			dieChan,
+			etcdSDConfig,
+			natsRPCServerConfig,
+			natsRPCClientConfig,
+		)
 		if err != nil {
-			logger.Log.Fatalf("error creating default cluster service discovery component: %s", err.Error())
+			logger.Log.Fatalf("error creating default cluster components: %s", err.Error())
 		}
+	}
 
-		rpcServer, err = cluster.NewNatsRPCServer(natsRPCServerConfig, server, metricsReporters, dieChan, sessionPool)
-		if err != nil {
-			logger.Log.Fatalf("error setting default cluster rpc server component: %s", err.Error())
-		}
+	worker, err := worker.NewWorker(workerConfig, enqueueOpts)
+	if err != nil {
+		logger.Log.Fatalf("error creating default worker: %s", err.Error())
+	}
 
-		rpcClient, err = cluster.NewNatsRPCClient(natsRPCClientConfig, server, metricsReporters, dieChan)
-		if err != nil {
-			logger.Log.Fatalf("error setting default cluster rpc client component: %s", err.Error())
+	if path := config.Pitaya.Handshake.Dictionary; path != "" {
+		if err := registerZstdDictionary(path); err != nil {
+			logger.Log.Fatalf("error loading zstd dictionary: %s", err.Error())
 		}
 	}
 
-	worker, err := worker.NewWorker(workerConfig, enqueueOpts)
+	serializer, err := newSerializer(config.Pitaya.Serializer.Name)
 	if err != nil {
-		logger.Log.Fatalf("error creating default worker: %s", err.Error())
+		logger.Log.Fatalf("error creating default serializer: %s", err.Error())
+	}
+	serializers := make([]serialize.Serializer, 0, len(config.Pitaya.Serializer.Negotiable))
+	for _, name := range config.Pitaya.Serializer.Negotiable {
+		s, err := newSerializer(name)
+		if err != nil {
+			logger.Log.Fatalf("error creating negotiable serializer: %s", err.Error())
+		}
+		serializers = append(serializers, s)
 	}
 
 	gsi := groups.NewMemoryGroupService(groupServiceConfig)
@@ -190,7 +257,7 @@ func NewBuilder(isFrontend bool,
 		PacketDecoder:    codec.NewPomeloPacketDecoder(),
 		PacketEncoder:    codec.NewPomeloPacketEncoder(),
 		MessageEncoder:   message.NewMessagesEncoder(config.Pitaya.Handler.Messages.Compression),
-		Serializer:       json.NewSerializer(),
+		Serializer:       serializer,
 		Router:           router.New(),
 		RPCClient:        rpcClient,
 		RPCServer:        rpcServer,
@@ -202,6 +269,48 @@ func NewBuilder(isFrontend bool,
 		ServiceDiscovery: serviceDiscovery,
 		SessionPool:      sessionPool,
 		Worker:           worker,
+		Serializers:      serializers,
+		Clock:            timer.GlobalClock(),
+	}
+}
+
+// newClusterComponents returns the service discovery and rpc server and
+// client of the cluster backend of conf
+func newClusterComponents(
+	conf config.ClusterConfig,
+	server *cluster.Server,
+	sessionPool session.SessionPool,
+	metricsReporters []metrics.Reporter,
+	dieChan chan bool,
+	etcdSDConfig config.EtcdServiceDiscoveryConfig,
+	natsRPCServerConfig config.NatsRPCServerConfig,
+	natsRPCClientConfig config.NatsRPCClientConfig,
+) (cluster.ServiceDiscovery, cluster.RPCServer, cluster.RPCClient, error) {
+	switch conf.Backend {
+	case "", config.ClusterBackendNats:
+		serviceDiscovery, err := cluster.NewEtcdServiceDiscovery(etcdSDConfig, server, dieChan)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("service discovery: %w", err)
+		}
+
+		rpcServer, err := cluster.NewNatsRPCServer(natsRPCServerConfig, server, metricsReporters, dieChan, sessionPool)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("rpc server: %w", err)
+		}
+
+		rpcClient, err := cluster.NewNatsRPCClient(natsRPCClientConfig, server, metricsReporters, dieChan)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("rpc client: %w", err)
+		}
+		return serviceDiscovery, rpcServer, rpcClient, nil
+	case config.ClusterBackendMemory:
+		memoryCluster := cluster.DefaultMemoryCluster
+		return cluster.NewMemoryServiceDiscovery(memoryCluster, server),
+			cluster.NewMemoryRPCServer(memoryCluster, server, sessionPool),
+			cluster.NewMemoryRPCClient(conf.RPC.Client.Memory, memoryCluster, server, metricsReporters),
+			nil
+	default:
+		return nil, nil, nil, constants.ErrUnknownClusterBackend
 	}
 }
 
@@ -235,6 +344,7 @@ func (builder *Builder) Build() Pitaya {
 			builder.ServiceDiscovery,
 			builder.PacketEncoder,
 			builder.Serializer,
+			builder.Serializers,
 			builder.Router,
 			builder.MessageEncoder,
 			builder.Server,
@@ -250,13 +360,27 @@ func (builder *Builder) Build() Pitaya {
 		builder.PacketDecoder,
 		builder.PacketEncoder,
 		builder.Serializer,
+		builder.Serializers,
 		builder.Config.Pitaya.Heartbeat.Interval,
 		builder.MessageEncoder,
 		builder.Config.Pitaya.Buffer.Agent.Messages,
 		builder.SessionPool,
 		builder.MetricsReporters,
+		builder.Config.Pitaya.Session.Resume,
+		builder.Config.Pitaya.Buffer.Agent.Freeze,
+		builder.Config.Pitaya.Handshake,
+		builder.Clock,
 	)
 
+	var mailboxes *service.Mailboxes
+	if builder.Config.Pitaya.Concurrency.Handler.Model == config.ConcurrencyActor {
+		mailboxes = service.NewMailboxes(
+			builder.Config.Pitaya.Concurrency.Handler.Mailbox,
+			builder.MailboxKey,
+			builder.MetricsReporters,
+		)
+	}
+
 	handlerService := service.NewHandlerService(
 		builder.PacketDecoder,
 		builder.Serializer,
@@ -269,9 +393,10 @@ func (builder *Builder) Build() Pitaya {
 		builder.MetricsReporters,
 		builder.HandlerHooks,
 		handlerPool,
+		mailboxes,
 	)
 
-	return NewApp(
+	app := NewApp(
 		builder.ServerMode,
 		builder.Serializer,
 		builder.acceptors,
@@ -289,6 +414,9 @@ func (builder *Builder) Build() Pitaya {
 		builder.MetricsReporters,
 		builder.Config.Pitaya,
 	)
+	app.clock = builder.Clock
+	app.serializers = builder.Serializers
+	return app
 }
 
 // NewDefaultApp returns a default pitaya app instance
@@ -320,3 +448,43 @@ func addDefaultStatsd(config config.StatsdConfig, reporters []metrics.Reporter,
 	}
 	return reporters
 }
+
+func addDefaultOTLP(config config.OTLPConfig, reporters []metrics.Reporter, serverType string) []metrics.Reporter {
+	otlp, err := CreateOTLPReporter(serverType, config)
+	if err != nil {
+		logger.Log.Errorf("failed to start otlp metrics reporter, skipping %v", err)
+	} else {
+		reporters = append(reporters, otlp)
+	}
+	return reporters
+}
+
+// newSerializer returns the built-in serializer with the given name
+func newSerializer(name string) (serialize.Serializer, error) {
+	switch name {
+	case "json":
+		return json.NewSerializer(), nil
+	case "protobuf":
+		return protobuf.NewSerializer(), nil
+	case "msgpack":
+		return msgpack.NewSerializer(), nil
+	case "cbor":
+		return cbor.NewSerializer(), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", constants.ErrUnknownSerializer, name)
+	}
+}
+
+// registerZstdDictionary registers the zstd-dict compression codec with the
+// dictionary of the file
+func registerZstdDictionary(path string) error {
+	dictionary, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	codec, err := message.NewZstdDictionaryCodec(dictionary)
+	if err != nil {
+		return err
+	}
+	return message.RegisterCodec(codec)
+}
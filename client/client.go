@@ -22,6 +22,8 @@ package client
 
 import (
 	"bytes"
+	"context"
+	"crypto/ed25519"
 	"crypto/tls"
 	"encoding/json"
 	"errors"
@@ -33,39 +35,106 @@ import (
 	"time"
 
 	"github.com/topfreegames/pitaya/v2/acceptor"
+	"github.com/topfreegames/pitaya/v2/agent"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/gorilla/websocket"
 	"github.com/sirupsen/logrus"
 	"github.com/topfreegames/pitaya/v2"
 	"github.com/topfreegames/pitaya/v2/conn/codec"
 	"github.com/topfreegames/pitaya/v2/conn/message"
 	"github.com/topfreegames/pitaya/v2/conn/packet"
-	"github.com/topfreegames/pitaya/v2/logger"
+	"github.com/topfreegames/pitaya/v2/constants"
+	pitayaerrors "github.com/topfreegames/pitaya/v2/errors"
+	logging "github.com/topfreegames/pitaya/v2/logger/interfaces"
 	logruswrapper "github.com/topfreegames/pitaya/v2/logger/logrus"
+	"github.com/topfreegames/pitaya/v2/protos"
+	"github.com/topfreegames/pitaya/v2/serialize"
+	"github.com/topfreegames/pitaya/v2/serialize/cbor"
+	serializejson "github.com/topfreegames/pitaya/v2/serialize/json"
+	"github.com/topfreegames/pitaya/v2/serialize/msgpack"
+	"github.com/topfreegames/pitaya/v2/serialize/protobuf"
 	"github.com/topfreegames/pitaya/v2/session"
 	"github.com/topfreegames/pitaya/v2/util/compression"
 )
 
 // HandshakeSys struct
 type HandshakeSys struct {
-	Dict       map[string]uint16 `json:"dict"`
-	Heartbeat  int               `json:"heartbeat"`
-	Serializer string            `json:"serializer"`
+	Dict            map[string]uint16 `json:"dict"`
+	Heartbeat       int               `json:"heartbeat"`
+	Serializer      string            `json:"serializer"`
+	ResumeToken     string            `json:"resumeToken,omitempty"`
+	Resumed         bool              `json:"resumed,omitempty"`
+	ProtocolVersion int               `json:"protocolVersion,omitempty"`
+	Compression     string            `json:"compression,omitempty"`
+	DictionaryID    uint32            `json:"dictionaryId,omitempty"`
+	Dictionary      []byte            `json:"dictionary,omitempty"`
 }
 
-// HandshakeData struct
+// HandshakeData struct, Error is set when the server rejects the handshake
 type HandshakeData struct {
-	Code int          `json:"code"`
-	Sys  HandshakeSys `json:"sys"`
+	Code  int           `json:"code"`
+	Sys   HandshakeSys  `json:"sys"`
+	Error *protos.Error `json:"error,omitempty"`
 }
 
+// decodeHandshakeResponse decodes the handshake response of the server, which
+// is in JSON unless protobuf was negotiated
+func decodeHandshakeResponse(data []byte) (*HandshakeData, error) {
+	handshake := &HandshakeData{}
+	if len(data) > 0 && data[0] == '{' {
+		if err := json.Unmarshal(data, handshake); err != nil {
+			return nil, err
+		}
+		return handshake, nil
+	}
+
+	res := &protos.HandshakeResponse{}
+	if err := proto.Unmarshal(data, res); err != nil {
+		return nil, err
+	}
+	handshake.Code = int(res.Code)
+	if res.Error != nil {
+		handshake.Error = &protos.Error{Code: res.Error.Code, Msg: res.Error.Msg, Metadata: res.Error.Metadata}
+	}
+	if res.Sys != nil {
+		handshake.Sys = HandshakeSys{
+			Heartbeat:       int(res.Sys.Heartbeat),
+			Serializer:      res.Sys.Serializer,
+			ResumeToken:     res.Sys.ResumeToken,
+			Resumed:         res.Sys.Resumed,
+			ProtocolVersion: int(res.Sys.ProtocolVersion),
+			Compression:     res.Sys.Compression,
+			DictionaryID:    res.Sys.DictionaryId,
+			Dictionary:      res.Sys.Dictionary,
+		}
+		if len(res.Sys.Dict) > 0 {
+			handshake.Sys.Dict = make(map[string]uint16, len(res.Sys.Dict))
+			for route, code := range res.Sys.Dict {
+				handshake.Sys.Dict[route] = uint16(code)
+			}
+		}
+	}
+	return handshake, nil
+}
+
+// PushHandler handles the pushes of a route, the data is encoded with the
+// serializer negotiated at handshake and can be decoded with Client.Unmarshal
+type PushHandler func(data []byte)
+
+// pendingRequest is a request waiting for its response, the responses of the
+// requests made with Request are sent to the response channel instead of
+// IncomingMsgChan
 type pendingRequest struct {
-	msg    *message.Message
-	sentAt time.Time
+	msg      *message.Message
+	sentAt   time.Time
+	cancel   context.CancelFunc
+	response chan *message.Message
 }
 
 // Client struct
 type Client struct {
+	logger              logging.Logger
 	conn                net.Conn
 	Connected           bool
 	packetEncoder       codec.PacketEncoder
@@ -80,6 +149,21 @@ type Client struct {
 	nextID              uint32
 	messageEncoder      message.Encoder
 	clientHandshakeData *session.HandshakeData
+	resumeToken         string
+	resumed             bool
+	serializer          string
+	serverKey           ed25519.PublicKey
+	packetCipher        *codec.PacketCipher
+	sendMutex           sync.Mutex
+	serializers         serialize.Serializers
+	pushHandlers        map[string]PushHandler
+	pushHandlersMutex   sync.RWMutex
+	connMutex           sync.Mutex
+	dial                func(addr string) (net.Conn, error)
+	addr                string
+	disconnected        chan struct{}
+	reconnectPolicy     *ReconnectPolicy
+	eventHandler        func(ConnectionEvent)
 }
 
 // MsgChannel return the incoming message channel
@@ -89,23 +173,25 @@ func (c *Client) MsgChannel() chan *message.Message {
 
 // ConnectedStatus return the connection status
 func (c *Client) ConnectedStatus() bool {
+	c.connMutex.Lock()
+	defer c.connMutex.Unlock()
 	return c.Connected
 }
 
-// New returns a new client
+// New returns a new client logging with logLevel, the client has its own
+// logger so the global one isn't changed
 func New(logLevel logrus.Level, requestTimeout ...time.Duration) *Client {
 	l := logrus.New()
 	l.Formatter = &logrus.TextFormatter{}
 	l.SetLevel(logLevel)
 
-	logger.Log = logruswrapper.NewWithFieldLogger(l)
-
 	reqTimeout := 5 * time.Second
 	if len(requestTimeout) > 0 {
 		reqTimeout = requestTimeout[0]
 	}
 
 	return &Client{
+		logger:          logruswrapper.NewWithFieldLogger(l),
 		Connected:       false,
 		packetEncoder:   codec.NewPomeloPacketEncoder(),
 		packetDecoder:   codec.NewPomeloPacketDecoder(),
@@ -116,12 +202,20 @@ func New(logLevel logrus.Level, requestTimeout ...time.Duration) *Client {
 		// TODO this should probably be configurable
 		pendingChan:    make(chan bool, 30),
 		messageEncoder: message.NewMessagesEncoder(false),
+		serializers: serialize.Serializers{
+			serializejson.NewSerializer(),
+			protobuf.NewSerializer(),
+			msgpack.NewSerializer(),
+			cbor.NewSerializer(),
+		},
+		pushHandlers: make(map[string]PushHandler),
 		clientHandshakeData: &session.HandshakeData{
 			Sys: session.HandshakeClientData{
-				Platform:    "mac",
-				LibVersion:  "0.3.5-release",
-				BuildNumber: "20",
-				Version:     "2.1",
+				Platform:        "mac",
+				LibVersion:      "0.3.5-release",
+				BuildNumber:     "20",
+				Version:         "2.1",
+				ProtocolVersion: constants.HandshakeProtocolVersion,
 			},
 			User: map[string]interface{}{
 				"age": 30,
@@ -135,21 +229,103 @@ func (c *Client) SetClientHandshakeData(data *session.HandshakeData) {
 	c.clientHandshakeData = data
 }
 
+// ResumeToken returns the token sent by the server for resuming the session
+// after a reconnection, it is empty if the server doesn't support it
+func (c *Client) ResumeToken() string {
+	return c.resumeToken
+}
+
+// Resumed returns if the server resumed a previous session on the last handshake
+func (c *Client) Resumed() bool {
+	return c.resumed
+}
+
+// SetResumeToken sets the token sent on the next handshake for resuming a previous session
+func (c *Client) SetResumeToken(token string) {
+	c.clientHandshakeData.Sys.ResumeToken = token
+}
+
+// SetSerializers sets the serializers accepted by the client on the next
+// handshake, in order of preference
+func (c *Client) SetSerializers(names ...string) {
+	c.clientHandshakeData.Sys.Serializers = names
+}
+
+// SetCompressions sets the compressions accepted by the client on the next
+// handshake, in order of preference
+func (c *Client) SetCompressions(names ...string) {
+	c.clientHandshakeData.Sys.Compressions = names
+}
+
+// SetServerKey sets the ed25519 public key of the server, when set the client
+// exchanges keys with the server on the next connections, checking that they
+// are signed with it, and encrypts every packet afterwards, the server acceptor
+// must be wrapped with acceptorwrapper.NewEncryptionWrapper. A nil key disables
+// the encryption
+func (c *Client) SetServerKey(serverKey ed25519.PublicKey) {
+	c.serverKey = serverKey
+}
+
+// Serializer returns the name of the serializer chosen by the server on the
+// last handshake, the data of the requests and pushes must be encoded with it
+func (c *Client) Serializer() string {
+	return c.serializer
+}
+
 func (c *Client) sendHandshakeRequest() error {
 	enc, err := json.Marshal(c.clientHandshakeData)
 	if err != nil {
 		return err
 	}
 
-	p, err := c.packetEncoder.Encode(packet.Handshake, enc)
+	return c.writePacket(packet.Handshake, enc)
+}
+
+// writePacket writes a packet to the server, encrypting its data once the keys
+// were exchanged
+func (c *Client) writePacket(typ packet.Type, data []byte) error {
+	if c.packetCipher != nil {
+		// encrypted packets must be written in the order of their sequence numbers
+		c.sendMutex.Lock()
+		defer c.sendMutex.Unlock()
+		data = c.packetCipher.Seal(data)
+	}
+	p, err := c.packetEncoder.Encode(typ, data)
 	if err != nil {
 		return err
 	}
-
 	_, err = c.conn.Write(p)
 	return err
 }
 
+func (c *Client) exchangeKeys() error {
+	keyExchange, err := codec.NewKeyExchange()
+	if err != nil {
+		return err
+	}
+	p, err := c.packetEncoder.Encode(packet.KeyExchange, keyExchange.PublicKey())
+	if err != nil {
+		return err
+	}
+	if _, err := c.conn.Write(p); err != nil {
+		return err
+	}
+
+	packets, err := c.readPackets(bytes.NewBuffer(nil))
+	if err != nil {
+		return err
+	}
+	if len(packets) == 0 || packets[0].Type != packet.KeyExchange {
+		return fmt.Errorf("got first packet from server that is not a key exchange, aborting")
+	}
+	serverPublicKey, err := keyExchange.VerifySignedPublicKey(packets[0].Data, c.serverKey)
+	if err != nil {
+		return err
+	}
+	c.packetCipher, err = keyExchange.Cipher(serverPublicKey, false)
+	return err
+}
+
 func (c *Client) handleHandshakeResponse() error {
 	buf := bytes.NewBuffer(nil)
 	packets, err := c.readPackets(buf)
@@ -162,7 +338,6 @@ func (c *Client) handleHandshakeResponse() error {
 		return fmt.Errorf("got first packet from server that is not a handshake, aborting")
 	}
 
-	handshake := &HandshakeData{}
 	if compression.IsCompressed(handshakePacket.Data) {
 		handshakePacket.Data, err = compression.InflateData(handshakePacket.Data)
 		if err != nil {
@@ -170,100 +345,137 @@ func (c *Client) handleHandshakeResponse() error {
 		}
 	}
 
-	err = json.Unmarshal(handshakePacket.Data, handshake)
+	handshake, err := decodeHandshakeResponse(handshakePacket.Data)
 	if err != nil {
 		return err
 	}
 
-	logger.Log.Debug("got handshake from sv, data: %v", handshake)
+	c.logger.Debug("got handshake from sv, data: %v", handshake)
+
+	if handshake.Error != nil {
+		return pitayaerrors.NewError(errors.New(handshake.Error.Msg), handshake.Error.Code, handshake.Error.Metadata)
+	}
 
 	if handshake.Sys.Dict != nil {
 		message.SetDictionary(handshake.Sys.Dict)
 	}
-	p, err := c.packetEncoder.Encode(packet.HandshakeAck, []byte{})
-	if err != nil {
-		return err
+	c.resumeToken = handshake.Sys.ResumeToken
+	c.resumed = handshake.Sys.Resumed
+	c.serializer = handshake.Sys.Serializer
+	if handshake.Sys.Dictionary != nil {
+		codec, err := message.NewZstdDictionaryCodec(handshake.Sys.Dictionary)
+		if err != nil {
+			return err
+		}
+		if err := message.RegisterCodec(codec); err != nil {
+			return err
+		}
+		// the dictionary isn't sent again on the next handshakes
+		c.clientHandshakeData.Sys.DictionaryID = codec.DictionaryID()
 	}
-	_, err = c.conn.Write(p)
-	if err != nil {
+	if err := c.writePacket(packet.HandshakeAck, []byte{}); err != nil {
 		return err
 	}
 
+	c.connMutex.Lock()
 	c.Connected = true
+	done := c.closeChan
+	c.connMutex.Unlock()
 
-	go c.sendHeartbeats(handshake.Sys.Heartbeat)
-	go c.handleServerMessages()
-	go c.handlePackets()
-	go c.pendingRequestsReaper()
+	go c.sendHeartbeats(handshake.Sys.Heartbeat, done)
+	// a resumed session may send its pending pushes right after the handshake
+	go c.handleServerMessages(done, buf, packets[1:]...)
+	go c.handlePackets(done)
 
 	return nil
 }
 
-// pendingRequestsReaper delete timedout requests
-func (c *Client) pendingRequestsReaper() {
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
-	for {
-		select {
-		case <-ticker.C:
-			toDelete := make([]*pendingRequest, 0)
-			c.pendingReqMutex.Lock()
-			for _, v := range c.pendingRequests {
-				if time.Now().Sub(v.sentAt) > c.requestTimeout {
-					toDelete = append(toDelete, v)
-				}
-			}
-			for _, pendingReq := range toDelete {
-				err := pitaya.Error(errors.New("request timeout"), "PIT-504")
-				errMarshalled, _ := json.Marshal(err)
-				// send a timeout to incoming msg chan
-				m := &message.Message{
-					Type:  message.Response,
-					ID:    pendingReq.msg.ID,
-					Route: pendingReq.msg.Route,
-					Data:  errMarshalled,
-					Err:   true,
-				}
-				delete(c.pendingRequests, pendingReq.msg.ID)
-				<-c.pendingChan
-				c.IncomingMsgChan <- m
-			}
-			c.pendingReqMutex.Unlock()
-		case <-c.closeChan:
-			return
-		}
+// expireRequest removes the request from the pending ones when its context
+// is done before the response arrives, the requests get a timeout error on
+// their response channel or, when made with SendRequest, on IncomingMsgChan
+func (c *Client) expireRequest(ctx context.Context, pendingReq *pendingRequest) {
+	<-ctx.Done()
+
+	if !c.removePendingRequest(pendingReq) {
+		// the response arrived
+		return
+	}
+	err := pitaya.Error(errors.New("request timeout"), "PIT-504")
+	if pendingReq.response != nil {
+		c.failRequest(pendingReq, err)
+		return
+	}
+	errMarshalled, _ := json.Marshal(err)
+	// send a timeout to incoming msg chan
+	m := &message.Message{
+		Type:  message.Response,
+		ID:    pendingReq.msg.ID,
+		Route: pendingReq.msg.Route,
+		Data:  errMarshalled,
+		Err:   true,
+	}
+	select {
+	case c.IncomingMsgChan <- m:
+	case <-c.closeChan:
 	}
 }
 
-func (c *Client) handlePackets() {
+// removePendingRequest removes the request from the pending ones, it returns
+// false if it was already removed
+func (c *Client) removePendingRequest(pendingReq *pendingRequest) bool {
+	c.pendingReqMutex.Lock()
+	defer c.pendingReqMutex.Unlock()
+	if c.pendingRequests[pendingReq.msg.ID] != pendingReq {
+		return false
+	}
+	delete(c.pendingRequests, pendingReq.msg.ID)
+	<-c.pendingChan
+	return true
+}
+
+func (c *Client) handlePackets(done chan struct{}) {
 	for {
 		select {
 		case p := <-c.packetChan:
 			switch p.Type {
 			case packet.Data:
 				//handle data
-				logger.Log.Debug("got data: %s", string(p.Data))
+				c.logger.Debug("got data: %s", string(p.Data))
 				m, err := message.Decode(p.Data)
 				if err != nil {
-					logger.Log.Errorf("error decoding msg from sv: %s", string(m.Data))
+					c.logger.Errorf("error decoding msg from sv: %s", string(m.Data))
 				}
 				if m.Type == message.Response {
 					c.pendingReqMutex.Lock()
-					if _, ok := c.pendingRequests[m.ID]; ok {
-						delete(c.pendingRequests, m.ID)
-						<-c.pendingChan
-					} else {
-						c.pendingReqMutex.Unlock()
+					pendingReq, ok := c.pendingRequests[m.ID]
+					c.pendingReqMutex.Unlock()
+					if !ok || !c.removePendingRequest(pendingReq) {
 						continue // do not process msg for already timedout request
 					}
-					c.pendingReqMutex.Unlock()
+					pendingReq.cancel()
+					if pendingReq.response != nil {
+						pendingReq.response <- m
+						continue
+					}
+				}
+				if m.Type == message.Push {
+					if handler := c.pushHandler(m.Route); handler != nil {
+						handler(m.Data)
+						continue
+					}
 				}
 				c.IncomingMsgChan <- m
 			case packet.Kick:
-				logger.Log.Warn("got kick packet from the server! disconnecting...")
+				c.logger.Warn("got kick packet from the server! disconnecting...")
 				c.Disconnect()
+			case packet.Reconnect:
+				reconnect := &agent.ReconnectData{}
+				if err := json.Unmarshal(p.Data, reconnect); err != nil {
+					c.logger.Errorf("error decoding reconnect packet from sv: %s", err.Error())
+				}
+				c.connectionLost(done, reconnect.Addr, fmt.Errorf("server asked to reconnect: %s", string(p.Data)))
 			}
-		case <-c.closeChan:
+		case <-done:
 			return
 		}
 	}
@@ -284,7 +496,7 @@ func (c *Client) readPackets(buf *bytes.Buffer) ([]*packet.Packet, error) {
 	}
 	packets, err := c.packetDecoder.Decode(buf.Bytes())
 	if err != nil {
-		logger.Log.Errorf("error decoding packet from server: %s", err.Error())
+		c.logger.Errorf("error decoding packet from server: %s", err.Error())
 	}
 	totalProcessed := 0
 	for _, p := range packets {
@@ -292,17 +504,26 @@ func (c *Client) readPackets(buf *bytes.Buffer) ([]*packet.Packet, error) {
 	}
 	buf.Next(totalProcessed)
 
+	if c.packetCipher != nil {
+		for _, p := range packets {
+			if p.Data, err = c.packetCipher.Open(p.Data); err != nil {
+				return nil, fmt.Errorf("error decrypting packet from server: %w", err)
+			}
+		}
+	}
+
 	return packets, nil
 }
 
-func (c *Client) handleServerMessages() {
-	buf := bytes.NewBuffer(nil)
-	defer c.Disconnect()
-	for c.Connected {
+func (c *Client) handleServerMessages(done chan struct{}, buf *bytes.Buffer, pending ...*packet.Packet) {
+	for _, p := range pending {
+		c.packetChan <- p
+	}
+	for {
 		packets, err := c.readPackets(buf)
-		if err != nil && c.Connected {
-			logger.Log.Error(err)
-			break
+		if err != nil {
+			c.connectionLost(done, "", err)
+			return
 		}
 
 		for _, p := range packets {
@@ -311,93 +532,113 @@ func (c *Client) handleServerMessages() {
 	}
 }
 
-func (c *Client) sendHeartbeats(interval int) {
+func (c *Client) sendHeartbeats(interval int, done chan struct{}) {
 	t := time.NewTicker(time.Duration(interval) * time.Second)
-	defer func() {
-		t.Stop()
-		c.Disconnect()
-	}()
+	defer t.Stop()
 	for {
 		select {
 		case <-t.C:
-			p, _ := c.packetEncoder.Encode(packet.Heartbeat, []byte{})
-			_, err := c.conn.Write(p)
-			if err != nil {
-				logger.Log.Errorf("error sending heartbeat to server: %s", err.Error())
+			if err := c.writePacket(packet.Heartbeat, []byte{}); err != nil {
+				c.connectionLost(done, "", fmt.Errorf("error sending heartbeat to server: %w", err))
 				return
 			}
-		case <-c.closeChan:
+		case <-done:
 			return
 		}
 	}
 }
 
-// Disconnect disconnects the client
+// Disconnect disconnects the client, it doesn't reconnect afterwards
 func (c *Client) Disconnect() {
-	if c.Connected {
-		c.Connected = false
-		close(c.closeChan)
-		c.conn.Close()
+	c.connMutex.Lock()
+	defer c.connMutex.Unlock()
+	if c.disconnected != nil {
+		select {
+		case <-c.disconnected:
+		default:
+			close(c.disconnected)
+		}
+	}
+	c.closeConnection()
+}
+
+// closeConnection closes the current connection, it returns false if it was
+// already closed. It must be called with connMutex locked.
+func (c *Client) closeConnection() bool {
+	if !c.Connected {
+		return false
 	}
+	c.Connected = false
+	close(c.closeChan)
+	c.conn.Close()
+	return true
 }
 
 // ConnectTo connects to the server at addr, for now the only supported protocol is tcp
 // if tlsConfig is sent, it connects using TLS
 func (c *Client) ConnectTo(addr string, tlsConfig ...*tls.Config) error {
-	var conn net.Conn
-	var err error
-	if len(tlsConfig) > 0 {
-		conn, err = tls.Dial("tcp", addr, tlsConfig[0])
-	} else {
-		conn, err = net.Dial("tcp", addr)
-	}
-	if err != nil {
-		return err
-	}
-	c.conn = conn
-	c.IncomingMsgChan = make(chan *message.Message, 10)
-
-	if err = c.handleHandshake(); err != nil {
-		return err
-	}
-
-	c.closeChan = make(chan struct{})
-
-	return nil
+	return c.ConnectWith(addr, func(addr string) (net.Conn, error) {
+		if len(tlsConfig) > 0 {
+			return tls.Dial("tcp", addr, tlsConfig[0])
+		}
+		return net.Dial("tcp", addr)
+	})
 }
 
 // ConnectToWS connects using webshocket protocol
 func (c *Client) ConnectToWS(addr string, path string, tlsConfig ...*tls.Config) error {
-	u := url.URL{Scheme: "ws", Host: addr, Path: path}
-	dialer := websocket.DefaultDialer
+	return c.ConnectWith(addr, func(addr string) (net.Conn, error) {
+		u := url.URL{Scheme: "ws", Host: addr, Path: path}
+		dialer := websocket.DefaultDialer
 
-	if len(tlsConfig) > 0 {
-		dialer.TLSClientConfig = tlsConfig[0]
-		u.Scheme = "wss"
-	}
+		if len(tlsConfig) > 0 {
+			dialer.TLSClientConfig = tlsConfig[0]
+			u.Scheme = "wss"
+		}
 
-	conn, _, err := dialer.Dial(u.String(), nil)
-	if err != nil {
-		return err
-	}
+		conn, _, err := dialer.Dial(u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		return acceptor.NewWSConn(conn)
+	})
+}
+
+// ConnectWith connects to the server at addr using dial to create the
+// connection, which is also used to reconnect. It allows connecting through
+// other transports, such as an acceptor.PipeAcceptor running in the same process
+func (c *Client) ConnectWith(addr string, dial func(addr string) (net.Conn, error)) error {
+	c.dial = dial
+	c.IncomingMsgChan = make(chan *message.Message, 10)
+	c.disconnected = make(chan struct{})
+	return c.connect(addr)
+}
 
-	c.conn, err = acceptor.NewWSConn(conn)
+// connect dials addr and handshakes with the server
+func (c *Client) connect(addr string) error {
+	conn, err := c.dial(addr)
 	if err != nil {
 		return err
 	}
-
-	c.IncomingMsgChan = make(chan *message.Message, 10)
+	c.addr = addr
+	c.conn = conn
+	c.closeChan = make(chan struct{})
 
 	if err = c.handleHandshake(); err != nil {
+		conn.Close()
 		return err
 	}
-
-	c.closeChan = make(chan struct{})
-
 	return nil
 }
 
 func (c *Client) handleHandshake() error {
+	c.packetCipher = nil
+	if c.serverKey != nil {
+		if err := c.exchangeKeys(); err != nil {
+			return err
+		}
+	}
+
 	if err := c.sendHandshakeRequest(); err != nil {
 		return err
 	}
@@ -408,22 +649,124 @@ func (c *Client) handleHandshake() error {
 	return nil
 }
 
-// SendRequest sends a request to the server
+// SendRequest sends a request to the server, the response or a timeout error
+// arrives on IncomingMsgChan
 func (c *Client) SendRequest(route string, data []byte) (uint, error) {
-	return c.sendMsg(message.Request, route, data)
+	return c.sendMsg(context.Background(), message.Request, route, data, nil)
 }
 
 // SendNotify sends a notify to the server
 func (c *Client) SendNotify(route string, data []byte) error {
-	_, err := c.sendMsg(message.Notify, route, data)
+	_, err := c.sendMsg(context.Background(), message.Notify, route, data, nil)
 	return err
 }
 
+// Request sends a request to the server and waits for its response, in and
+// out are encoded with the serializer negotiated at handshake. It returns the
+// error sent by the server as an *errors.Error, or the error of ctx if it is
+// done before the response arrives. The request timeout of the client applies
+// when ctx has no earlier deadline, its expiration returns a PIT-504 error.
+func (c *Client) Request(ctx context.Context, route string, in, out interface{}) error {
+	serializer, err := c.getSerializer()
+	if err != nil {
+		return err
+	}
+	var data []byte
+	if in != nil {
+		if data, err = serializer.Marshal(in); err != nil {
+			return err
+		}
+	}
+
+	response := make(chan *message.Message, 1)
+	if _, err := c.sendMsg(ctx, message.Request, route, data, response); err != nil {
+		return err
+	}
+
+	select {
+	case m := <-response:
+		if ctx.Err() != nil {
+			// the timeout error of a request whose context was canceled
+			return ctx.Err()
+		}
+		if m.Err {
+			e := &protos.Error{}
+			if err := serializer.Unmarshal(m.Data, e); err != nil {
+				return err
+			}
+			return pitayaerrors.NewError(errors.New(e.Msg), e.Code, e.Metadata)
+		}
+		if out == nil {
+			return nil
+		}
+		return serializer.Unmarshal(m.Data, out)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// OnPush subscribes handler to the pushes of route, which aren't sent to
+// IncomingMsgChan anymore. The handlers are called in the order the pushes
+// arrive, from the goroutine reading the responses, so they can't wait for
+// the responses of requests. A nil handler removes the subscription.
+func (c *Client) OnPush(route string, handler PushHandler) {
+	c.pushHandlersMutex.Lock()
+	defer c.pushHandlersMutex.Unlock()
+	if handler == nil {
+		delete(c.pushHandlers, route)
+		return
+	}
+	c.pushHandlers[route] = handler
+}
+
+func (c *Client) pushHandler(route string) PushHandler {
+	c.pushHandlersMutex.RLock()
+	defer c.pushHandlersMutex.RUnlock()
+	return c.pushHandlers[route]
+}
+
+// Marshal encodes v with the serializer negotiated at handshake, e.g. to send
+// it with SendNotify
+func (c *Client) Marshal(v interface{}) ([]byte, error) {
+	serializer, err := c.getSerializer()
+	if err != nil {
+		return nil, err
+	}
+	return serializer.Marshal(v)
+}
+
+// Unmarshal decodes the data of a response or push with the serializer
+// negotiated at handshake
+func (c *Client) Unmarshal(data []byte, v interface{}) error {
+	serializer, err := c.getSerializer()
+	if err != nil {
+		return err
+	}
+	return serializer.Unmarshal(data, v)
+}
+
+// getSerializer returns the serializer negotiated at handshake, the servers
+// that don't negotiate it use json
+func (c *Client) getSerializer() (serialize.Serializer, error) {
+	name := c.serializer
+	if name == "" {
+		name = "json"
+	}
+	serializer := c.serializers.Get(name)
+	if serializer == nil {
+		return nil, fmt.Errorf("%w: %s", constants.ErrUnknownSerializer, name)
+	}
+	return serializer, nil
+}
+
 func (c *Client) buildPacket(msg message.Message) ([]byte, error) {
 	encMsg, err := c.messageEncoder.Encode(&msg)
 	if err != nil {
 		return nil, err
 	}
+	if c.packetCipher != nil {
+		encMsg = c.packetCipher.Seal(encMsg)
+	}
 	p, err := c.packetEncoder.Encode(packet.Data, encMsg)
 	if err != nil {
 		return nil, err
@@ -432,8 +775,9 @@ func (c *Client) buildPacket(msg message.Message) ([]byte, error) {
 	return p, nil
 }
 
-// sendMsg sends the request to the server
-func (c *Client) sendMsg(msgType message.Type, route string, data []byte) (uint, error) {
+// sendMsg sends the request to the server, requests are pending until their
+// response arrives or ctx is done, with the request timeout of the client
+func (c *Client) sendMsg(ctx context.Context, msgType message.Type, route string, data []byte, response chan *message.Message) (uint, error) {
 	// TODO mount msg and encode
 	m := message.Message{
 		Type:  msgType,
@@ -442,22 +786,36 @@ func (c *Client) sendMsg(msgType message.Type, route string, data []byte) (uint,
 		Data:  data,
 		Err:   false,
 	}
-	p, err := c.buildPacket(m)
-	if msgType == message.Request {
-		c.pendingChan <- true
-		c.pendingReqMutex.Lock()
-		if _, ok := c.pendingRequests[m.ID]; !ok {
-			c.pendingRequests[m.ID] = &pendingRequest{
-				msg:    &m,
-				sentAt: time.Now(),
-			}
-		}
-		c.pendingReqMutex.Unlock()
+	if c.packetCipher != nil {
+		// encrypted packets must be written in the order of their sequence numbers
+		c.sendMutex.Lock()
+		defer c.sendMutex.Unlock()
 	}
-
+	p, err := c.buildPacket(m)
 	if err != nil {
 		return m.ID, err
 	}
-	_, err = c.conn.Write(p)
+	if msgType != message.Request {
+		_, err = c.conn.Write(p)
+		return m.ID, err
+	}
+
+	c.pendingChan <- true
+	ctx, cancel := context.WithTimeout(ctx, c.requestTimeout)
+	pendingReq := &pendingRequest{
+		msg:      &m,
+		sentAt:   time.Now(),
+		cancel:   cancel,
+		response: response,
+	}
+	c.pendingReqMutex.Lock()
+	c.pendingRequests[m.ID] = pendingReq
+	c.pendingReqMutex.Unlock()
+	go c.expireRequest(ctx, pendingReq)
+
+	if _, err = c.conn.Write(p); err != nil {
+		c.removePendingRequest(pendingReq)
+		cancel()
+	}
 	return m.ID, err
 }
@@ -1,15 +1,26 @@
 package client
 
 import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/topfreegames/pitaya/v2/conn/codec"
 	"github.com/topfreegames/pitaya/v2/conn/message"
+	"github.com/topfreegames/pitaya/v2/conn/packet"
+	"github.com/topfreegames/pitaya/v2/constants"
+	"github.com/topfreegames/pitaya/v2/errors"
 	"github.com/topfreegames/pitaya/v2/helpers"
 	"github.com/topfreegames/pitaya/v2/mocks"
+	"github.com/topfreegames/pitaya/v2/protos"
 )
 
 func TestSendRequestShouldTimeout(t *testing.T) {
@@ -19,7 +30,6 @@ func TestSendRequestShouldTimeout(t *testing.T) {
 
 	mockConn := mocks.NewMockPlayerConn(ctrl)
 	c.conn = mockConn
-	go c.pendingRequestsReaper()
 
 	route := "com.sometest.route"
 	data := []byte{0x02, 0x03, 0x04}
@@ -46,3 +56,312 @@ func TestSendRequestShouldTimeout(t *testing.T) {
 
 	assert.Equal(t, true, msg.Err)
 }
+
+func TestHandleHandshakeResponseRejected(t *testing.T) {
+	c := New(logrus.InfoLevel)
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	c.conn = clientConn
+
+	data := []byte(`{"code":400,"error":{"code":"PIT-400","msg":"handshake protocol version not supported","metadata":{"minVersion":"2"}}}`)
+	p, err := codec.NewPomeloPacketEncoder().Encode(packet.Handshake, data)
+	assert.NoError(t, err)
+	go serverConn.Write(p)
+
+	err = c.handleHandshakeResponse()
+	assert.IsType(t, &errors.Error{}, err)
+	assert.Equal(t, "PIT-400", err.(*errors.Error).Code)
+	assert.Equal(t, "handshake protocol version not supported", err.Error())
+	assert.Equal(t, "2", err.(*errors.Error).Metadata["minVersion"])
+	assert.False(t, c.Connected)
+}
+
+func TestHandleHandshakeResponseSerializer(t *testing.T) {
+	c := New(logrus.InfoLevel)
+	c.SetSerializers("msgpack", "json")
+	assert.Equal(t, []string{"msgpack", "json"}, c.clientHandshakeData.Sys.Serializers)
+
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	c.conn = clientConn
+	c.closeChan = make(chan struct{})
+	defer c.Disconnect()
+
+	data := []byte(`{"code":200,"sys":{"heartbeat":3,"serializer":"msgpack"}}`)
+	p, err := codec.NewPomeloPacketEncoder().Encode(packet.Handshake, data)
+	assert.NoError(t, err)
+	go func() {
+		serverConn.Write(p)
+		// handshake ack
+		serverConn.Read(make([]byte, 1024))
+	}()
+
+	err = c.handleHandshakeResponse()
+	assert.NoError(t, err)
+	assert.Equal(t, "msgpack", c.Serializer())
+}
+
+func TestHandleHandshakeResponseRejectedProtobuf(t *testing.T) {
+	c := New(logrus.InfoLevel)
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	c.conn = clientConn
+
+	data, err := proto.Marshal(&protos.HandshakeResponse{
+		Code:  400,
+		Error: &protos.HandshakeError{Code: "PIT-400", Msg: "serializer not supported", Metadata: map[string]string{"serializers": "json"}},
+	})
+	assert.NoError(t, err)
+	p, err := codec.NewPomeloPacketEncoder().Encode(packet.Handshake, data)
+	assert.NoError(t, err)
+	go serverConn.Write(p)
+
+	err = c.handleHandshakeResponse()
+	assert.IsType(t, &errors.Error{}, err)
+	assert.Equal(t, "PIT-400", err.(*errors.Error).Code)
+	assert.Equal(t, "serializer not supported", err.Error())
+	assert.Equal(t, "json", err.(*errors.Error).Metadata["serializers"])
+	assert.False(t, c.Connected)
+}
+
+func TestDecodeHandshakeResponseProtobuf(t *testing.T) {
+	data, err := proto.Marshal(&protos.HandshakeResponse{
+		Code: 200,
+		Sys: &protos.HandshakeSys{
+			Heartbeat:       3,
+			Dict:            map[string]uint32{"room.room.join": 1},
+			Serializer:      "protobuf",
+			ProtocolVersion: 1,
+			Compression:     "none",
+			ResumeToken:     "token",
+		},
+	})
+	assert.NoError(t, err)
+
+	handshake, err := decodeHandshakeResponse(data)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, handshake.Code)
+	assert.Nil(t, handshake.Error)
+	assert.Equal(t, HandshakeSys{
+		Dict:            map[string]uint16{"room.room.join": 1},
+		Heartbeat:       3,
+		Serializer:      "protobuf",
+		ResumeToken:     "token",
+		ProtocolVersion: 1,
+		Compression:     "none",
+	}, handshake.Sys)
+}
+
+// newRequestClient returns a connected client whose packets written are
+// answered by respond
+func newRequestClient(t *testing.T, ctrl *gomock.Controller, respond func(m *message.Message) *message.Message) *Client {
+	c := New(logrus.InfoLevel, time.Second)
+	mockConn := mocks.NewMockPlayerConn(ctrl)
+	c.conn = mockConn
+	c.IncomingMsgChan = make(chan *message.Message, 10)
+	c.closeChan = make(chan struct{})
+	t.Cleanup(func() { close(c.closeChan) })
+	go c.handlePackets(c.closeChan)
+
+	mockConn.EXPECT().Write(gomock.Any()).DoAndReturn(func(b []byte) (int, error) {
+		packets, err := codec.NewPomeloPacketDecoder().Decode(b)
+		assert.NoError(t, err)
+		m, err := message.Decode(packets[0].Data)
+		assert.NoError(t, err)
+		if res := respond(m); res != nil {
+			sendPacket(t, c, res)
+		}
+		return len(b), nil
+	}).AnyTimes()
+	return c
+}
+
+func sendPacket(t *testing.T, c *Client, m *message.Message) {
+	data, err := message.NewMessagesEncoder(false).Encode(m)
+	assert.NoError(t, err)
+	c.packetChan <- &packet.Packet{Type: packet.Data, Length: len(data), Data: data}
+}
+
+func TestRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type player struct {
+		Name  string `json:"name"`
+		Level int    `json:"level"`
+	}
+	c := newRequestClient(t, ctrl, func(m *message.Message) *message.Message {
+		assert.Equal(t, "room.room.join", m.Route)
+		in := &player{}
+		assert.NoError(t, json.Unmarshal(m.Data, in))
+		in.Level++
+		data, _ := json.Marshal(in)
+		return &message.Message{Type: message.Response, ID: m.ID, Data: data}
+	})
+
+	out := &player{}
+	err := c.Request(context.Background(), "room.room.join", &player{Name: "alice", Level: 1}, out)
+	assert.NoError(t, err)
+	assert.Equal(t, &player{Name: "alice", Level: 2}, out)
+	assert.Empty(t, c.pendingRequests)
+	assert.Len(t, c.IncomingMsgChan, 0)
+}
+
+func TestRequestError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c := newRequestClient(t, ctrl, func(m *message.Message) *message.Message {
+		data, _ := json.Marshal(&protos.Error{Code: "PIT-404", Msg: "room not found"})
+		return &message.Message{Type: message.Response, ID: m.ID, Data: data, Err: true}
+	})
+
+	err := c.Request(context.Background(), "room.room.join", nil, nil)
+	assert.IsType(t, &errors.Error{}, err)
+	assert.Equal(t, "PIT-404", err.(*errors.Error).Code)
+	assert.Equal(t, "room not found", err.Error())
+}
+
+func TestRequestContextDone(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c := newRequestClient(t, ctrl, func(m *message.Message) *message.Message { return nil })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := c.Request(ctx, "room.room.join", nil, nil)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	helpers.ShouldEventuallyReturn(t, func() int {
+		c.pendingReqMutex.Lock()
+		defer c.pendingReqMutex.Unlock()
+		return len(c.pendingRequests)
+	}, 0)
+	// timeouts of requests made with Request aren't sent to the incoming channel
+	assert.Len(t, c.IncomingMsgChan, 0)
+}
+
+func TestRequestTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c := newRequestClient(t, ctrl, func(m *message.Message) *message.Message { return nil })
+	c.requestTimeout = 50 * time.Millisecond
+
+	err := c.Request(context.Background(), "room.room.join", nil, nil)
+	assert.IsType(t, &errors.Error{}, err)
+	assert.Equal(t, "PIT-504", err.(*errors.Error).Code)
+	assert.Empty(t, c.pendingRequests)
+	assert.Len(t, c.IncomingMsgChan, 0)
+}
+
+func TestRequestWriteError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c := New(logrus.InfoLevel, time.Second)
+	mockConn := mocks.NewMockPlayerConn(ctrl)
+	c.conn = mockConn
+	mockConn.EXPECT().Write(gomock.Any()).Return(0, constants.ErrConnectionClosed)
+
+	err := c.Request(context.Background(), "room.room.join", nil, nil)
+	assert.Equal(t, constants.ErrConnectionClosed, err)
+	assert.Empty(t, c.pendingRequests)
+	assert.Len(t, c.pendingChan, 0)
+}
+
+func TestOnPush(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c := newRequestClient(t, ctrl, func(m *message.Message) *message.Message { return nil })
+	pushes := make(chan []byte, 1)
+	c.OnPush("room.room.onMessage", func(data []byte) {
+		pushes <- data
+	})
+
+	sendPacket(t, c, &message.Message{Type: message.Push, Route: "room.room.onMessage", Data: []byte(`"hello"`)})
+	data := helpers.ShouldEventuallyReceive(t, pushes).([]byte)
+	var msg string
+	assert.NoError(t, c.Unmarshal(data, &msg))
+	assert.Equal(t, "hello", msg)
+
+	// pushes without handlers and after unsubscribing go to the incoming channel
+	c.OnPush("room.room.onMessage", nil)
+	sendPacket(t, c, &message.Message{Type: message.Push, Route: "room.room.onMessage", Data: []byte(`"bye"`)})
+	m := helpers.ShouldEventuallyReceive(t, c.IncomingMsgChan).(*message.Message)
+	assert.Equal(t, "room.room.onMessage", m.Route)
+	assert.Len(t, pushes, 0)
+}
+
+func TestServerKey(t *testing.T) {
+	serverPub, serverKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	tables := []struct {
+		name      string
+		pinnedKey ed25519.PublicKey
+		downgrade bool
+		err       error
+	}{
+		{"test_encrypted", serverPub, false, nil},
+		{"test_other_server_key", otherPub, false, codec.ErrInvalidSignature},
+		{"test_key_exchange_dropped", serverPub, true, nil},
+	}
+
+	for _, table := range tables {
+		t.Run(table.name, func(t *testing.T) {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			assert.NoError(t, err)
+			defer l.Close()
+
+			go func() {
+				conn, err := l.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+
+				p := readTestPacket(t, conn)
+				assert.Equal(t, packet.Type(packet.KeyExchange), p.Type)
+				if table.downgrade {
+					// a man in the middle answering in plain text
+					writeTestPacket(t, conn, packet.Handshake, []byte(`{"code":200,"sys":{"heartbeat":10}}`))
+					return
+				}
+				keys, err := codec.NewKeyExchange()
+				assert.NoError(t, err)
+				c, err := keys.Cipher(p.Data, true)
+				assert.NoError(t, err)
+				writeTestPacket(t, conn, packet.KeyExchange, keys.SignedPublicKey(p.Data, serverKey))
+
+				p = readTestPacket(t, conn)
+				if p == nil {
+					return
+				}
+				assert.Equal(t, packet.Type(packet.Handshake), p.Type)
+				_, err = c.Open(p.Data)
+				assert.NoError(t, err)
+				writeTestPacket(t, conn, packet.Handshake, c.Seal([]byte(`{"code":200,"sys":{"heartbeat":10}}`)))
+				p = readTestPacket(t, conn)
+				assert.Equal(t, packet.Type(packet.HandshakeAck), p.Type)
+				_, err = c.Open(p.Data)
+				assert.NoError(t, err)
+			}()
+
+			c := New(logrus.InfoLevel)
+			c.SetServerKey(table.pinnedKey)
+			err = c.ConnectTo(l.Addr().String())
+			if table.downgrade {
+				assert.Error(t, err)
+				return
+			}
+			assert.Equal(t, table.err, err)
+			if err == nil {
+				c.Disconnect()
+			}
+		})
+	}
+}
@@ -21,6 +21,7 @@
 package client
 
 import (
+	"context"
 	"crypto/tls"
 
 	"github.com/topfreegames/pitaya/v2/conn/message"
@@ -34,6 +35,8 @@ type PitayaClient interface {
 	ConnectedStatus() bool
 	Disconnect()
 	MsgChannel() chan *message.Message
+	OnPush(route string, handler PushHandler)
+	Request(ctx context.Context, route string, in, out interface{}) error
 	SendNotify(route string, data []byte) error
 	SendRequest(route string, data []byte) (uint, error)
 	SetClientHandshakeData(data *session.HandshakeData)
@@ -37,7 +37,6 @@ import (
 	"github.com/jhump/protoreflect/dynamic"
 	"github.com/sirupsen/logrus"
 	"github.com/topfreegames/pitaya/v2/conn/message"
-	"github.com/topfreegames/pitaya/v2/logger"
 	"github.com/topfreegames/pitaya/v2/protos"
 )
 
@@ -388,12 +387,12 @@ func (pc *ProtoClient) waitForData() {
 				errMsg := &protos.Error{}
 				err := proto.Unmarshal(response.Data, errMsg)
 				if err != nil {
-					logger.Log.Errorf("Erro decode error data: %s", string(response.Data))
+					pc.logger.Errorf("Erro decode error data: %s", string(response.Data))
 					continue
 				}
 				response.Data, err = json.Marshal(errMsg)
 				if err != nil {
-					logger.Log.Errorf("error encode error to json: %s", string(response.Data))
+					pc.logger.Errorf("error encode error to json: %s", string(response.Data))
 					continue
 				}
 				pc.IncomingMsgChan <- response
@@ -401,19 +400,19 @@ func (pc *ProtoClient) waitForData() {
 			}
 
 			if inputMsg == nil {
-				logger.Log.Errorf("not expected data: %s", string(response.Data))
+				pc.logger.Errorf("not expected data: %s", string(response.Data))
 				continue
 			}
 
 			err := inputMsg.Unmarshal(response.Data)
 			if err != nil {
-				logger.Log.Errorf("error decode data: %s", string(response.Data))
+				pc.logger.Errorf("error decode data: %s", string(response.Data))
 				continue
 			}
 
 			data, err2 := inputMsg.MarshalJSON()
 			if err2 != nil {
-				logger.Log.Errorf("error encode data to json: %s", string(response.Data))
+				pc.logger.Errorf("error encode data to json: %s", string(response.Data))
 				continue
 			}
 
@@ -0,0 +1,255 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/topfreegames/pitaya/v2"
+	"github.com/topfreegames/pitaya/v2/conn/message"
+	pitayaerrors "github.com/topfreegames/pitaya/v2/errors"
+	"github.com/topfreegames/pitaya/v2/protos"
+)
+
+// ReconnectPolicy makes the client reconnect when the connection drops or the
+// server asks it to. The client waits Backoff before the first attempt, the
+// wait is multiplied by Multiplier after each failed attempt up to MaxBackoff,
+// and it gives up after MaxAttempts, or never if it is 0. The handshake data
+// is the same of the first connection, with the resume token sent by the
+// server if any. The requests in flight whose routes are in IdempotentRoutes
+// are sent again after reconnecting, the others fail with a PIT-503 error.
+type ReconnectPolicy struct {
+	MaxAttempts      int
+	Backoff          time.Duration
+	MaxBackoff       time.Duration
+	Multiplier       float64
+	IdempotentRoutes []string
+}
+
+// NewDefaultReconnectPolicy returns the default reconnect policy
+func NewDefaultReconnectPolicy() *ReconnectPolicy {
+	return &ReconnectPolicy{
+		MaxAttempts: 10,
+		Backoff:     100 * time.Millisecond,
+		MaxBackoff:  10 * time.Second,
+		Multiplier:  2,
+	}
+}
+
+func (p *ReconnectPolicy) isIdempotent(route string) bool {
+	for _, r := range p.IdempotentRoutes {
+		if r == route {
+			return true
+		}
+	}
+	return false
+}
+
+// ConnectionEventType is the type of a connection lifecycle event
+type ConnectionEventType int
+
+// Connection lifecycle events
+const (
+	// ConnectionLost is emitted when the connection drops or the server asks
+	// the client to reconnect, Err tells why
+	ConnectionLost ConnectionEventType = iota
+	// Reconnecting is emitted before waiting for each reconnection attempt
+	Reconnecting
+	// Reconnected is emitted after handshaking with the server again
+	Reconnected
+	// ReconnectFailed is emitted when the client gives up reconnecting, Err
+	// is the error of the last attempt
+	ReconnectFailed
+)
+
+var connectionEventTypeNames = map[ConnectionEventType]string{
+	ConnectionLost:  "connection_lost",
+	Reconnecting:    "reconnecting",
+	Reconnected:     "reconnected",
+	ReconnectFailed: "reconnect_failed",
+}
+
+func (t ConnectionEventType) String() string {
+	return connectionEventTypeNames[t]
+}
+
+// ConnectionEvent is a connection lifecycle event, Addr is the address of the
+// server the client is connecting to and Attempt the number of the attempt
+type ConnectionEvent struct {
+	Type    ConnectionEventType
+	Addr    string
+	Attempt int
+	Err     error
+}
+
+// SetReconnectPolicy sets the policy for reconnecting when the connection
+// drops, the client doesn't reconnect when it is nil
+func (c *Client) SetReconnectPolicy(policy *ReconnectPolicy) {
+	c.reconnectPolicy = policy
+}
+
+// OnConnectionEvent sets the handler of the connection lifecycle events, it
+// is called from the goroutines of the connection so it must not block
+func (c *Client) OnConnectionEvent(handler func(ConnectionEvent)) {
+	c.eventHandler = handler
+}
+
+func (c *Client) emitConnectionEvent(event ConnectionEvent) {
+	if c.eventHandler != nil {
+		c.eventHandler(event)
+	}
+}
+
+// connectionLost closes the connection whose goroutines are stopped by done
+// and reconnects to addr, or to the last address if it is empty, when there
+// is a reconnect policy. It does nothing if the connection was already closed.
+func (c *Client) connectionLost(done chan struct{}, addr string, err error) {
+	c.connMutex.Lock()
+	if c.closeChan != done || !c.closeConnection() {
+		c.connMutex.Unlock()
+		return
+	}
+	if addr == "" {
+		addr = c.addr
+	}
+	policy := c.reconnectPolicy
+	c.connMutex.Unlock()
+
+	c.logger.Errorf("connection to %s lost: %s", c.addr, err.Error())
+	c.emitConnectionEvent(ConnectionEvent{Type: ConnectionLost, Addr: addr, Err: err})
+	if policy == nil {
+		return
+	}
+	c.failPendingRequests(func(pendingReq *pendingRequest) bool {
+		return !policy.isIdempotent(pendingReq.msg.Route)
+	})
+	go c.reconnect(policy, addr)
+}
+
+func (c *Client) reconnect(policy *ReconnectPolicy, addr string) {
+	if c.resumeToken != "" {
+		c.SetResumeToken(c.resumeToken)
+	}
+
+	backoff := policy.Backoff
+	var err error
+	for attempt := 1; policy.MaxAttempts == 0 || attempt <= policy.MaxAttempts; attempt++ {
+		c.emitConnectionEvent(ConnectionEvent{Type: Reconnecting, Addr: addr, Attempt: attempt})
+		select {
+		case <-time.After(backoff):
+		case <-c.disconnected:
+			return
+		}
+
+		if err = c.connect(addr); err == nil {
+			select {
+			case <-c.disconnected:
+				// disconnected while handshaking
+				c.Disconnect()
+				return
+			default:
+			}
+			c.emitConnectionEvent(ConnectionEvent{Type: Reconnected, Addr: addr, Attempt: attempt})
+			c.resendPendingRequests()
+			return
+		}
+		c.logger.Warnf("failed to reconnect to %s: %s", addr, err.Error())
+
+		backoff = time.Duration(float64(backoff) * policy.Multiplier)
+		if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+	}
+
+	c.emitConnectionEvent(ConnectionEvent{Type: ReconnectFailed, Addr: addr, Err: err})
+	c.failPendingRequests(func(*pendingRequest) bool { return true })
+}
+
+// resendPendingRequests sends again the requests in flight when the
+// connection was lost
+func (c *Client) resendPendingRequests() {
+	c.pendingReqMutex.Lock()
+	pendingReqs := make([]*pendingRequest, 0, len(c.pendingRequests))
+	for _, pendingReq := range c.pendingRequests {
+		pendingReqs = append(pendingReqs, pendingReq)
+	}
+	c.pendingReqMutex.Unlock()
+
+	for _, pendingReq := range pendingReqs {
+		if err := c.resendRequest(pendingReq); err != nil {
+			c.logger.Errorf("error sending request %d again: %s", pendingReq.msg.ID, err.Error())
+		}
+	}
+}
+
+func (c *Client) resendRequest(pendingReq *pendingRequest) error {
+	if c.packetCipher != nil {
+		c.sendMutex.Lock()
+		defer c.sendMutex.Unlock()
+	}
+	p, err := c.buildPacket(*pendingReq.msg)
+	if err != nil {
+		return err
+	}
+	_, err = c.conn.Write(p)
+	return err
+}
+
+// failPendingRequests fails the requests in flight that match with a PIT-503
+// error, the requests made with SendRequest get it on IncomingMsgChan
+func (c *Client) failPendingRequests(match func(*pendingRequest) bool) {
+	c.pendingReqMutex.Lock()
+	pendingReqs := make([]*pendingRequest, 0)
+	for _, pendingReq := range c.pendingRequests {
+		if match(pendingReq) {
+			pendingReqs = append(pendingReqs, pendingReq)
+		}
+	}
+	c.pendingReqMutex.Unlock()
+
+	for _, pendingReq := range pendingReqs {
+		if !c.removePendingRequest(pendingReq) {
+			continue
+		}
+		pendingReq.cancel()
+		go c.failRequest(pendingReq, pitaya.Error(errors.New("connection lost"), "PIT-503"))
+	}
+}
+
+func (c *Client) failRequest(pendingReq *pendingRequest, err *pitayaerrors.Error) {
+	m := &message.Message{
+		Type:  message.Response,
+		ID:    pendingReq.msg.ID,
+		Route: pendingReq.msg.Route,
+		Err:   true,
+	}
+	if pendingReq.response != nil {
+		if serializer, serr := c.getSerializer(); serr == nil {
+			m.Data, _ = serializer.Marshal(&protos.Error{Code: err.Code, Msg: err.Message})
+		}
+		pendingReq.response <- m
+		return
+	}
+	m.Data, _ = json.Marshal(err)
+	c.IncomingMsgChan <- m
+}
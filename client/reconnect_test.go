@@ -0,0 +1,157 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/topfreegames/pitaya/v2/conn/codec"
+	"github.com/topfreegames/pitaya/v2/conn/message"
+	"github.com/topfreegames/pitaya/v2/conn/packet"
+	"github.com/topfreegames/pitaya/v2/errors"
+	"github.com/topfreegames/pitaya/v2/helpers"
+	"github.com/topfreegames/pitaya/v2/session"
+)
+
+func readTestPacket(t *testing.T, conn net.Conn) *packet.Packet {
+	header := make([]byte, codec.HeadLength)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return nil
+	}
+	size, typ, err := codec.ParseHeader(header)
+	assert.NoError(t, err)
+	data := make([]byte, size)
+	_, err = io.ReadFull(conn, data)
+	assert.NoError(t, err)
+	return &packet.Packet{Type: typ, Length: size, Data: data}
+}
+
+func writeTestPacket(t *testing.T, conn net.Conn, typ packet.Type, data []byte) {
+	p, err := codec.NewPomeloPacketEncoder().Encode(typ, data)
+	assert.NoError(t, err)
+	_, err = conn.Write(p)
+	assert.NoError(t, err)
+}
+
+// acceptTestConn accepts a connection and handshakes with it, returning the
+// handshake data sent by the client
+func acceptTestConn(t *testing.T, l net.Listener) (net.Conn, *session.HandshakeData) {
+	conn, err := l.Accept()
+	assert.NoError(t, err)
+	handshake := &session.HandshakeData{}
+	assert.NoError(t, json.Unmarshal(readTestPacket(t, conn).Data, handshake))
+	writeTestPacket(t, conn, packet.Handshake, []byte(`{"code":200,"sys":{"heartbeat":10,"serializer":"json","resumeToken":"token"}}`))
+	assert.Equal(t, packet.Type(packet.HandshakeAck), readTestPacket(t, conn).Type)
+	return conn, handshake
+}
+
+func readTestRequests(t *testing.T, conn net.Conn, n int) map[string]*message.Message {
+	requests := map[string]*message.Message{}
+	for len(requests) < n {
+		m, err := message.Decode(readTestPacket(t, conn).Data)
+		assert.NoError(t, err)
+		requests[m.Route] = m
+	}
+	return requests
+}
+
+func TestReconnect(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer l.Close()
+
+	c := New(logrus.InfoLevel, 5*time.Second)
+	c.SetReconnectPolicy(&ReconnectPolicy{
+		MaxAttempts:      3,
+		Backoff:          10 * time.Millisecond,
+		Multiplier:       2,
+		IdempotentRoutes: []string{"room.room.get"},
+	})
+	var eventsMutex sync.Mutex
+	events := []ConnectionEventType{}
+	c.OnConnectionEvent(func(event ConnectionEvent) {
+		eventsMutex.Lock()
+		defer eventsMutex.Unlock()
+		events = append(events, event.Type)
+	})
+
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		conn, _ := acceptTestConn(t, l)
+		readTestRequests(t, conn, 2)
+		// the connection drops before answering
+		conn.Close()
+
+		conn, handshake := acceptTestConn(t, l)
+		defer conn.Close()
+		assert.Equal(t, "token", handshake.Sys.ResumeToken)
+		requests := readTestRequests(t, conn, 1)
+		get := requests["room.room.get"]
+		if assert.NotNil(t, get) {
+			res, err := message.NewMessagesEncoder(false).Encode(&message.Message{Type: message.Response, ID: get.ID, Data: []byte(`"room"`)})
+			assert.NoError(t, err)
+			writeTestPacket(t, conn, packet.Data, res)
+		}
+		readTestPacket(t, conn)
+	}()
+
+	assert.NoError(t, c.ConnectTo(l.Addr().String()))
+	defer c.Disconnect()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		var out string
+		assert.NoError(t, c.Request(context.Background(), "room.room.get", nil, &out))
+		assert.Equal(t, "room", out)
+	}()
+	go func() {
+		defer wg.Done()
+		err := c.Request(context.Background(), "room.room.join", nil, nil)
+		assert.IsType(t, &errors.Error{}, err)
+		assert.Equal(t, "PIT-503", err.(*errors.Error).Code)
+	}()
+	wg.Wait()
+
+	assert.True(t, c.ConnectedStatus())
+	eventsMutex.Lock()
+	assert.Equal(t, []ConnectionEventType{ConnectionLost, Reconnecting, Reconnected}, events)
+	eventsMutex.Unlock()
+	c.Disconnect()
+	<-served
+}
+
+func TestReconnectFailed(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	c := New(logrus.InfoLevel, 5*time.Second)
+	c.SetReconnectPolicy(&ReconnectPolicy{MaxAttempts: 2, Backoff: 10 * time.Millisecond, Multiplier: 2})
+	events := make(chan ConnectionEvent, 10)
+	c.OnConnectionEvent(func(event ConnectionEvent) {
+		events <- event
+	})
+
+	go func() {
+		conn, _ := acceptTestConn(t, l)
+		// the server goes away
+		l.Close()
+		conn.Close()
+	}()
+	assert.NoError(t, c.ConnectTo(l.Addr().String()))
+	defer c.Disconnect()
+
+	for _, typ := range []ConnectionEventType{ConnectionLost, Reconnecting, Reconnecting, ReconnectFailed} {
+		event := helpers.ShouldEventuallyReceive(t, events).(ConnectionEvent)
+		assert.Equal(t, typ, event.Type)
+	}
+	assert.False(t, c.ConnectedStatus())
+}
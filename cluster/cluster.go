@@ -47,9 +47,29 @@ type RPCClient interface {
 	SendKick(userID string, serverType string, kick *protos.KickMsg) error
 	BroadcastSessionBind(uid string) error
 	Call(ctx context.Context, rpcType protos.RPCType, route *route.Route, session session.Session, msg *message.Message, server *Server) (*protos.Response, error)
+	Stream(ctx context.Context, route *route.Route, msg *message.Message, server *Server) (ClientStream, error)
 	interfaces.Module
 }
 
+// Stream is a stream of raw messages between two servers
+type Stream interface {
+	Context() context.Context
+	Send(data []byte) error
+	Recv() ([]byte, error)
+}
+
+// ClientStream is the side of a stream opened by the caller
+type ClientStream interface {
+	Stream
+	CloseSend() error
+}
+
+// StreamServer processes the streams opened by other servers, the returned
+// response is only sent if it contains an error
+type StreamServer interface {
+	Stream(ctx context.Context, req *protos.Request, stream Stream) *protos.Response
+}
+
 // SDListener interface
 type SDListener interface {
 	AddServer(*Server)
@@ -102,6 +122,7 @@ func buildRequest(
 	if relationData != nil {
 		ctx = pcontext.AddToPropagateCtx(ctx, constants.MsgRelationKey, relationData)
 	}
+	ctx = pcontext.AddDeadlineToPropagateCtx(ctx)
 	req.Metadata, err = pcontext.Encode(ctx)
 	if err != nil {
 		return req, err
@@ -128,6 +149,9 @@ func buildRequest(
 			Uid:  session.UID(),
 			Data: session.GetDataEncoded(),
 		}
+		if serializer := session.GetSerializer(); serializer != nil {
+			req.Session.Serializer = serializer.GetName()
+		}
 	}
 
 	return req, nil
@@ -0,0 +1,83 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/topfreegames/pitaya/v2/conn/message"
+	"github.com/topfreegames/pitaya/v2/constants"
+	pcontext "github.com/topfreegames/pitaya/v2/context"
+	"github.com/topfreegames/pitaya/v2/protos"
+	"github.com/topfreegames/pitaya/v2/relation"
+	"github.com/topfreegames/pitaya/v2/route"
+	"github.com/topfreegames/pitaya/v2/session"
+	"github.com/topfreegames/pitaya/v2/util"
+)
+
+func TestBuildRequestRelationAcrossHops(t *testing.T) {
+	frontend := &Server{ID: "connector", Type: "connector", Frontend: true}
+	backendA := &Server{ID: "room", Type: "room"}
+	backendB := &Server{ID: "game", Type: "game"}
+	rt := route.NewRoute("room", "handler", "method")
+
+	sessionPool := session.NewSessionPool()
+	frontendSession := sessionPool.NewSession(nil, true, "uid")
+
+	// frontend -> room handler
+	req, err := buildRequest(context.Background(), protos.RPCType_Sys, rt, frontendSession,
+		&message.Message{Type: message.Request, ID: 7, Route: rt.String()}, frontend)
+	assert.NoError(t, err)
+
+	ctx, err := util.GetContextFromRequest(&req, backendA.ID)
+	assert.NoError(t, err)
+	backendSession := sessionPool.NewSession(nil, false, req.GetSession().GetUid())
+	assert.NoError(t, backendSession.Set(constants.FrontendSessionID, req.GetSession().GetId()))
+	ctx = util.CtxWithRelation(ctx, req.GetMsg().GetId(), backendSession)
+	ctx = pcontext.CtxWithRelationData(ctx, "other", relation.Data{MsgID: 3, SessID: 42})
+
+	// room -> game -> game, each hop only sees the propagated context
+	for _, sv := range []*Server{backendA, backendB} {
+		req, err = buildRequest(ctx, protos.RPCType_User, route.NewRoute("game", "remote", "method"), nil,
+			&message.Message{Type: message.Request}, sv)
+		assert.NoError(t, err)
+		ctx, err = util.GetContextFromRequest(&req, backendB.ID)
+		assert.NoError(t, err)
+	}
+
+	expected := map[string]relation.Data{
+		"uid":   {MsgID: 7, SessID: frontendSession.ID()},
+		"other": {MsgID: 3, SessID: 42},
+	}
+	assert.Equal(t, expected, pcontext.GetRelationDataFromContext(ctx))
+
+	// game -> frontend push, the relation travels in the push message
+	push := &protos.Push{
+		Uid:           "uid",
+		RelationMsgId: uint64(pcontext.GetRelationMsgIdFromContext(ctx, "uid")),
+		SessionId:     pcontext.GetSessionIdFromContext(ctx, "uid"),
+	}
+	pushCtx := pcontext.CtxWithRelationData(context.Background(), push.Uid,
+		relation.Data{MsgID: push.RelationMsgId, SessID: push.SessionId})
+	assert.Equal(t, expected["uid"], pcontext.GetRelationDataFromContextByUID(pushCtx, "uid"))
+}
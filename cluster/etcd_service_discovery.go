@@ -24,6 +24,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"strings"
 	"sync"
 	"time"
@@ -52,7 +53,8 @@ type etcdServiceDiscovery struct {
 	etcdPrefix             string
 	etcdDialTimeout        time.Duration
 	running                bool
-	server                 *Server
+	serverLock             sync.RWMutex
+	server                 *Server // replaced by Drain, read with getServer
 	stopChan               chan bool
 	stopLeaseChan          chan bool
 	lastSyncTime           time.Time
@@ -174,7 +176,7 @@ func (sd *etcdServiceDiscovery) renewLease() error {
 			c <- err
 			return
 		}
-		err = sd.bootstrapServer(sd.server)
+		err = sd.bootstrapServer(sd.getServer())
 		c <- err
 	}()
 	select {
@@ -324,7 +326,7 @@ func (sd *etcdServiceDiscovery) bootstrap() error {
 		return err
 	}
 
-	if err := sd.bootstrapServer(sd.server); err != nil {
+	if err := sd.bootstrapServer(sd.getServer()); err != nil {
 		return err
 	}
 
@@ -558,7 +560,7 @@ func (sd *etcdServiceDiscovery) SyncServers(firstSync bool) error {
 		}
 
 		// Check whether the server type is blacklisted or not
-		if sd.isServerTypeBlacklisted(svType) && svID != sd.server.ID {
+		if sd.isServerTypeBlacklisted(svType) && svID != sd.getServer().ID {
 			logger.Log.Debug("ignoring blacklisted server type '%s'", svType)
 			continue
 		}
@@ -597,6 +599,34 @@ func (sd *etcdServiceDiscovery) SyncServers(firstSync bool) error {
 	return nil
 }
 
+// Drain publishes the draining state in the metadata of the server, so that
+// the other servers stop sending new work to it before it shuts down
+func (sd *etcdServiceDiscovery) Drain() error {
+	server := sd.getServer()
+	metadata := make(map[string]string, len(server.Metadata)+1)
+	for k, v := range server.Metadata {
+		metadata[k] = v
+	}
+	metadata[ServerStateKey] = ServerStateDraining
+
+	sv := *server
+	sv.Metadata = metadata
+	if err := sd.addServerIntoEtcd(&sv); err != nil {
+		return err
+	}
+	sd.serverLock.Lock()
+	sd.server = &sv
+	sd.serverLock.Unlock()
+	return nil
+}
+
+// getServer returns the server registered by the service discovery
+func (sd *etcdServiceDiscovery) getServer() *Server {
+	sd.serverLock.RLock()
+	defer sd.serverLock.RUnlock()
+	return sd.server
+}
+
 // BeforeShutdown executes before shutting down and will remove the server from the list
 func (sd *etcdServiceDiscovery) BeforeShutdown() {
 	sd.revoke()
@@ -631,7 +661,7 @@ func (sd *etcdServiceDiscovery) revoke() error {
 }
 
 func (sd *etcdServiceDiscovery) addServer(sv *Server) {
-	if _, loaded := sd.serverMapByID.LoadOrStore(sv.ID, sv); !loaded {
+	if actual, loaded := sd.serverMapByID.LoadOrStore(sv.ID, sv); !loaded {
 		sd.writeLockScope(func() {
 			mapSvByType, ok := sd.serverMapByType[sv.Type]
 			if !ok {
@@ -640,12 +670,26 @@ func (sd *etcdServiceDiscovery) addServer(sv *Server) {
 			}
 			mapSvByType[sv.ID] = sv
 		})
-		if sv.ID != sd.server.ID {
+		if sv.ID != sd.getServer().ID {
 			sd.notifyListeners(ADD, sv)
 		}
+	} else if !reflect.DeepEqual(actual.(*Server).Metadata, sv.Metadata) {
+		sd.updateServer(sv)
 	}
 }
 
+// updateServer replaces a known server whose metadata changed, e.g. when it
+// starts draining, the listeners are not notified as the server is the same
+func (sd *etcdServiceDiscovery) updateServer(sv *Server) {
+	sd.serverMapByID.Store(sv.ID, sv)
+	sd.writeLockScope(func() {
+		if svMap, ok := sd.serverMapByType[sv.Type]; ok {
+			svMap[sv.ID] = sv
+		}
+	})
+	logger.Log.Debugf("server %s updated, metadata: %v", sv.ID, sv.Metadata)
+}
+
 func (sd *etcdServiceDiscovery) watchEtcdChanges() {
 	w := sd.cli.Watch(context.Background(), "servers/", clientv3.WithPrefix())
 	failedWatchAttempts := 0
@@ -684,7 +728,7 @@ func (sd *etcdServiceDiscovery) watchEtcdChanges() {
 						continue
 					}
 
-					if sd.isServerTypeBlacklisted(svType) && sd.server.ID != svID {
+					if sd.isServerTypeBlacklisted(svType) && sd.getServer().ID != svID {
 						continue
 					}
 
@@ -310,6 +310,36 @@ func TestEtcdBeforeShutdown(t *testing.T) {
 	}
 }
 
+func TestEtcdDrain(t *testing.T) {
+	t.Parallel()
+	config := config.NewDefaultEtcdServiceDiscoveryConfig()
+	c, cli := helpers.GetTestEtcd(t)
+	defer c.Terminate(t)
+	server := NewServer("frontend-1", "type1", true, map[string]string{"k1": "v1"})
+	e := getEtcdSD(t, *config, server, cli)
+	e.Init()
+
+	err := e.Drain()
+	assert.NoError(t, err)
+	assert.True(t, e.getServer().IsDraining())
+	assert.Equal(t, "v1", e.getServer().Metadata["k1"])
+	// the server given to the service discovery isn't modified
+	assert.False(t, server.IsDraining())
+
+	ss, err := getServerFromEtcd(e.cli, server.Type, server.ID)
+	assert.NoError(t, err)
+	assert.True(t, ss.IsDraining())
+
+	// the watcher replaces the server known by the service discovery
+	helpers.ShouldEventuallyReturn(t, func() bool {
+		sv, err := e.GetServer(server.ID)
+		return err == nil && sv.IsDraining()
+	}, true)
+	servers, err := e.GetServersByType(server.Type)
+	assert.NoError(t, err)
+	assert.True(t, servers[server.ID].IsDraining())
+}
+
 func TestEtcdShutdown(t *testing.T) {
 	t.Parallel()
 	for _, table := range etcdSDTables {
@@ -121,6 +121,8 @@ func (gs *GRPCClient) Call(
 		return nil, err
 	}
 
+	// grpc sends the deadline of ctx, shortened by the request timeout, to the
+	// server, which applies it to the context of the call
 	ctxT, done := context.WithTimeout(ctx, gs.reqTimeout)
 	defer done()
 
@@ -149,6 +151,34 @@ func (gs *GRPCClient) Call(
 	return res, nil
 }
 
+// Stream opens a stream with a stream remote of the server
+func (gs *GRPCClient) Stream(ctx context.Context, route *route.Route, msg *message.Message, server *Server) (ClientStream, error) {
+	c, ok := gs.clientMap.Load(server.ID)
+	if !ok {
+		return nil, constants.ErrNoConnectionToServer
+	}
+
+	ctx = startStreamSpan(ctx, "GRPC RPC Stream", gs.server, server)
+	req, err := buildRequest(ctx, protos.RPCType_User, route, nil, msg, gs.server)
+	if err != nil {
+		tracing.FinishSpan(ctx, err)
+		return nil, err
+	}
+
+	// the stream is released when it ends or ctx is canceled
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := c.(*grpcClient).stream(ctx)
+	if err == nil {
+		err = stream.SendMsg(&req)
+	}
+	if err != nil {
+		cancel()
+		tracing.FinishSpan(ctx, err)
+		return nil, err
+	}
+	return newGRPCClientStream(ctx, cancel, stream), nil
+}
+
 // Send not implemented in grpc client
 func (gs *GRPCClient) Send(uid string, d []byte) error {
 	return constants.ErrNotImplemented
@@ -348,6 +378,15 @@ func (gc *grpcClient) call(ctx context.Context, req *protos.Request) (*protos.Re
 	return gc.cli.Call(ctx, req)
 }
 
+func (gc *grpcClient) stream(ctx context.Context) (grpc.ClientStream, error) {
+	if !gc.connected {
+		if err := gc.connect(); err != nil {
+			return nil, err
+		}
+	}
+	return gc.conn.NewStream(ctx, &grpcStreamServiceDesc.Streams[0], grpcStreamMethod)
+}
+
 func (gc *grpcClient) sessionBindRemote(ctx context.Context, req *protos.BindMsg) error {
 	if !gc.connected {
 		if err := gc.connect(); err != nil {
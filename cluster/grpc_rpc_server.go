@@ -21,8 +21,10 @@
 package cluster
 
 import (
+	"context"
 	"fmt"
 	"net"
+	"sync"
 
 	"google.golang.org/grpc"
 
@@ -35,15 +37,92 @@ import (
 type GRPCServer struct {
 	server           *Server
 	port             int
+	sessionOrdered   bool
+	services         int
 	metricsReporters []metrics.Reporter
 	grpcSv           *grpc.Server
 	pitayaServer     protos.PitayaServer
+	dispatcher       *sessionDispatcher
+}
+
+type sessionCallResult struct {
+	res *protos.Response
+	err error
+}
+
+// sessionCall is a call waiting to be processed by the goroutine of its session
+type sessionCall struct {
+	ctx  context.Context
+	req  *protos.Request
+	done chan sessionCallResult
+}
+
+// sessionDispatcher processes the calls of a session one at a time on the
+// same goroutine, as the nats rpc server does. A handler must not make a
+// synchronous rpc that is processed by the same server for the same session,
+// it waits for the goroutine that is running the handler itself and only
+// returns when its context is done
+type sessionDispatcher struct {
+	protos.PitayaServer
+	calls    []chan *sessionCall
+	stopOnce sync.Once
+}
+
+func newSessionDispatcher(pitayaServer protos.PitayaServer, services int) *sessionDispatcher {
+	d := &sessionDispatcher{
+		PitayaServer: pitayaServer,
+		calls:        make([]chan *sessionCall, services),
+	}
+	for i := range d.calls {
+		d.calls[i] = make(chan *sessionCall)
+		go d.process(d.calls[i])
+	}
+	return d
+}
+
+func (d *sessionDispatcher) process(calls chan *sessionCall) {
+	for call := range calls {
+		res, err := d.PitayaServer.Call(call.ctx, call.req)
+		call.done <- sessionCallResult{res: res, err: err}
+	}
+}
+
+// Call dispatches the call to the goroutine of its session, calls without a
+// session are processed concurrently
+func (d *sessionDispatcher) Call(ctx context.Context, req *protos.Request) (*protos.Response, error) {
+	sessionID := req.GetSession().GetId()
+	if sessionID <= 0 || len(d.calls) == 0 {
+		return d.PitayaServer.Call(ctx, req)
+	}
+
+	call := &sessionCall{ctx: ctx, req: req, done: make(chan sessionCallResult, 1)}
+	select {
+	case d.calls[sessionID%int64(len(d.calls))] <- call:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	select {
+	case result := <-call.done:
+		return result.res, result.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (d *sessionDispatcher) stop() {
+	d.stopOnce.Do(func() {
+		for _, calls := range d.calls {
+			close(calls)
+		}
+	})
 }
 
 // NewGRPCServer constructor
 func NewGRPCServer(config config.GRPCServerConfig, server *Server, metricsReporters []metrics.Reporter) (*GRPCServer, error) {
 	gs := &GRPCServer{
 		port:             config.Port,
+		sessionOrdered:   config.SessionOrdered,
+		services:         config.Services,
 		server:           server,
 		metricsReporters: metricsReporters,
 	}
@@ -58,7 +137,16 @@ func (gs *GRPCServer) Init() error {
 		return err
 	}
 	gs.grpcSv = grpc.NewServer()
-	protos.RegisterPitayaServer(gs.grpcSv, gs.pitayaServer)
+	if gs.sessionOrdered {
+		gs.dispatcher = newSessionDispatcher(gs.pitayaServer, gs.services)
+		protos.RegisterPitayaServer(gs.grpcSv, gs.dispatcher)
+	} else {
+		protos.RegisterPitayaServer(gs.grpcSv, gs.pitayaServer)
+	}
+	// streams are long lived, they are not serialized per session
+	if streamServer, ok := gs.pitayaServer.(StreamServer); ok {
+		gs.grpcSv.RegisterService(&grpcStreamServiceDesc, streamServer)
+	}
 	go gs.grpcSv.Serve(lis)
 	return nil
 }
@@ -80,5 +168,8 @@ func (gs *GRPCServer) Shutdown() error {
 	// blocks until all the pending RPCs are finished.
 	// source: https://godoc.org/google.golang.org/grpc#Server.GracefulStop
 	gs.grpcSv.GracefulStop()
+	if gs.dispatcher != nil {
+		gs.dispatcher.stop()
+	}
 	return nil
 }
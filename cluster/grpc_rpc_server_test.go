@@ -1,9 +1,12 @@
 package cluster
 
 import (
+	"context"
 	"fmt"
 	"net"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 
@@ -11,6 +14,7 @@ import (
 	"github.com/topfreegames/pitaya/v2/config"
 	"github.com/topfreegames/pitaya/v2/helpers"
 	"github.com/topfreegames/pitaya/v2/metrics"
+	"github.com/topfreegames/pitaya/v2/protos"
 	protosmocks "github.com/topfreegames/pitaya/v2/protos/mocks"
 )
 
@@ -32,7 +36,7 @@ func TestGRPCServerInit(t *testing.T) {
 
 	sv := getServer()
 	gs, err := NewGRPCServer(*c, sv, []metrics.Reporter{})
-	gs.SetPitayaServer(mockPitayaServer)
+	gs.SetPitayaServer(newPitayaServerMock(mockPitayaServer))
 	err = gs.Init()
 	assert.NoError(t, err)
 	assert.NotNil(t, gs)
@@ -41,4 +45,126 @@ func TestGRPCServerInit(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, conn)
 	assert.NotNil(t, gs.grpcSv)
+	// calls of the same session are processed in order by default
+	assert.NotNil(t, gs.dispatcher)
+}
+
+func TestGRPCServerInitUnordered(t *testing.T) {
+	t.Parallel()
+	c := config.NewDefaultGRPCServerConfig()
+	c.Port = helpers.GetFreePort(t)
+	c.SessionOrdered = false
+
+	gs, err := NewGRPCServer(*c, getServer(), []metrics.Reporter{})
+	assert.NoError(t, err)
+	gs.SetPitayaServer(&concurrencyPitayaServer{running: map[int64]int{}})
+	err = gs.Init()
+	assert.NoError(t, err)
+	assert.Nil(t, gs.dispatcher)
+	assert.NoError(t, gs.Shutdown())
+}
+
+func TestGRPCServerInitSessionOrdered(t *testing.T) {
+	t.Parallel()
+	c := config.NewDefaultGRPCServerConfig()
+	c.Port = helpers.GetFreePort(t)
+	c.SessionOrdered = true
+	c.Services = 3
+
+	gs, err := NewGRPCServer(*c, getServer(), []metrics.Reporter{})
+	assert.NoError(t, err)
+	gs.SetPitayaServer(&concurrencyPitayaServer{running: map[int64]int{}})
+	err = gs.Init()
+	assert.NoError(t, err)
+	assert.NotNil(t, gs.dispatcher)
+	assert.Len(t, gs.dispatcher.calls, 3)
+
+	assert.NoError(t, gs.Shutdown())
+	// stopping again must not close the channels twice
+	gs.dispatcher.stop()
+}
+
+// concurrencyPitayaServer records if calls of the same session overlapped
+type concurrencyPitayaServer struct {
+	protos.UnimplementedPitayaServer
+	mutex   sync.Mutex
+	running map[int64]int
+	overlap bool
+}
+
+func (s *concurrencyPitayaServer) Call(ctx context.Context, req *protos.Request) (*protos.Response, error) {
+	id := req.GetSession().GetId()
+	s.mutex.Lock()
+	s.running[id]++
+	if id > 0 && s.running[id] > 1 {
+		s.overlap = true
+	}
+	s.mutex.Unlock()
+
+	time.Sleep(time.Millisecond)
+
+	s.mutex.Lock()
+	s.running[id]--
+	s.mutex.Unlock()
+	return &protos.Response{Data: []byte(req.GetMsg().GetRoute())}, nil
+}
+
+func TestGRPCServerSessionDispatcher(t *testing.T) {
+	t.Parallel()
+	pitayaServer := &concurrencyPitayaServer{running: map[int64]int{}}
+	d := newSessionDispatcher(pitayaServer, 2)
+	defer d.stop()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 30; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			route := fmt.Sprintf("sv.svc.mth%d", i)
+			req := &protos.Request{Msg: &protos.Msg{Route: route}}
+			if sessionID := int64(i % 3); sessionID > 0 {
+				req.Session = &protos.Session{Id: sessionID}
+			}
+			res, err := d.Call(context.Background(), req)
+			assert.NoError(t, err)
+			assert.Equal(t, route, string(res.Data))
+		}(i)
+	}
+	wg.Wait()
+	assert.False(t, pitayaServer.overlap)
+}
+
+func TestGRPCServerSessionDispatcherCanceledCall(t *testing.T) {
+	t.Parallel()
+	d := newSessionDispatcher(&concurrencyPitayaServer{running: map[int64]int{}}, 0)
+	d.calls = []chan *sessionCall{make(chan *sessionCall)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := d.Call(ctx, &protos.Request{Session: &protos.Session{Id: 1}})
+	assert.Equal(t, context.Canceled, err)
+}
+
+// blockingPitayaServer blocks the calls until release is closed
+type blockingPitayaServer struct {
+	protos.UnimplementedPitayaServer
+	release chan struct{}
+}
+
+func (s *blockingPitayaServer) Call(ctx context.Context, req *protos.Request) (*protos.Response, error) {
+	<-s.release
+	return &protos.Response{}, nil
+}
+
+func TestGRPCServerSessionDispatcherCallTimeout(t *testing.T) {
+	t.Parallel()
+	pitayaServer := &blockingPitayaServer{release: make(chan struct{})}
+	d := newSessionDispatcher(pitayaServer, 1)
+	defer d.stop()
+	defer close(pitayaServer.release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := d.Call(ctx, &protos.Request{Session: &protos.Session{Id: 1}})
+	assert.Equal(t, context.DeadlineExceeded, err)
 }
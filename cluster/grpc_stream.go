@@ -0,0 +1,122 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"github.com/topfreegames/pitaya/v2/protos"
+)
+
+// grpcStreamMethod is the full name of the bidirectional stream method, the
+// stream starts with a request followed by responses in both directions
+const grpcStreamMethod = "/protos.PitayaStream/Stream"
+
+var grpcStreamServiceDesc = grpc.ServiceDesc{
+	ServiceName: "protos.PitayaStream",
+	HandlerType: (*StreamServer)(nil),
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Stream",
+			Handler:       grpcStreamHandler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "pitaya.proto",
+}
+
+func grpcStreamHandler(srv interface{}, stream grpc.ServerStream) error {
+	req := &protos.Request{}
+	if err := stream.RecvMsg(req); err != nil {
+		return err
+	}
+	res := srv.(StreamServer).Stream(stream.Context(), req, &grpcStream{stream: stream})
+	if res != nil && res.Error != nil {
+		return stream.SendMsg(res)
+	}
+	return nil
+}
+
+// grpcMsgStream is implemented by both grpc.ServerStream and grpc.ClientStream
+type grpcMsgStream interface {
+	Context() context.Context
+	SendMsg(m interface{}) error
+	RecvMsg(m interface{}) error
+}
+
+type grpcStream struct {
+	stream grpcMsgStream
+}
+
+func (s *grpcStream) Context() context.Context {
+	return s.stream.Context()
+}
+
+func (s *grpcStream) Send(data []byte) error {
+	return s.stream.SendMsg(&protos.Response{Data: data})
+}
+
+func (s *grpcStream) Recv() ([]byte, error) {
+	res := &protos.Response{}
+	if err := s.stream.RecvMsg(res); err != nil {
+		return nil, err
+	}
+	if res.Error != nil {
+		return nil, errorFromProto(res.Error)
+	}
+	return res.Data, nil
+}
+
+type grpcClientStream struct {
+	grpcStream
+	ctx    context.Context
+	client grpc.ClientStream
+	end    *streamEnd
+}
+
+func newGRPCClientStream(ctx context.Context, cancel context.CancelFunc, client grpc.ClientStream) *grpcClientStream {
+	return &grpcClientStream{
+		grpcStream: grpcStream{stream: client},
+		ctx:        ctx,
+		client:     client,
+		end:        newStreamEnd(ctx, func(error) { cancel() }),
+	}
+}
+
+// Context returns the context of the stream, with the span of the call
+func (s *grpcClientStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *grpcClientStream) Recv() ([]byte, error) {
+	data, err := s.grpcStream.Recv()
+	if err != nil {
+		s.end.finish(err)
+	}
+	return data, err
+}
+
+func (s *grpcClientStream) CloseSend() error {
+	return s.client.CloseSend()
+}
@@ -0,0 +1,193 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"sync"
+)
+
+// MemoryCluster connects servers running in the same process, it is shared
+// by their MemoryServiceDiscovery, MemoryRPCClient and MemoryRPCServer in
+// place of etcd and nats
+type MemoryCluster struct {
+	mutex       sync.RWMutex
+	discoveries map[string]*MemoryServiceDiscovery
+	rpcServers  map[string]*MemoryRPCServer
+	// users maps the frontend type and the uid of the bound sessions to the
+	// frontend server handling them
+	users map[string]map[string]*userBinding
+}
+
+// DefaultMemoryCluster is the cluster joined by the servers built by the
+// Builder with the memory cluster backend
+var DefaultMemoryCluster = NewMemoryCluster()
+
+type userBinding struct {
+	rpcServer *MemoryRPCServer
+	sessionID int64
+}
+
+// NewMemoryCluster returns an empty cluster
+func NewMemoryCluster() *MemoryCluster {
+	return &MemoryCluster{
+		discoveries: map[string]*MemoryServiceDiscovery{},
+		rpcServers:  map[string]*MemoryRPCServer{},
+		users:       map[string]map[string]*userBinding{},
+	}
+}
+
+// join adds the server of sd to the cluster, it returns the other service
+// discoveries and the servers in the cluster, including its own
+func (mc *MemoryCluster) join(sd *MemoryServiceDiscovery) ([]*MemoryServiceDiscovery, []*Server) {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+	mc.discoveries[sd.server.ID] = sd
+	return mc.otherDiscoveries(sd), mc.servers()
+}
+
+// leave removes the server of sd from the cluster, it returns the service
+// discoveries remaining
+func (mc *MemoryCluster) leave(sd *MemoryServiceDiscovery) []*MemoryServiceDiscovery {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+	delete(mc.discoveries, sd.server.ID)
+	return mc.otherDiscoveries(sd)
+}
+
+// setMetadata replaces the metadata of the server of sd, it returns the other
+// service discoveries
+func (mc *MemoryCluster) setMetadata(sd *MemoryServiceDiscovery, metadata map[string]string) []*MemoryServiceDiscovery {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+	sd.server.Metadata = metadata
+	return mc.otherDiscoveries(sd)
+}
+
+// otherDiscoveries must be called with the mutex locked
+func (mc *MemoryCluster) otherDiscoveries(sd *MemoryServiceDiscovery) []*MemoryServiceDiscovery {
+	others := make([]*MemoryServiceDiscovery, 0, len(mc.discoveries))
+	for _, other := range mc.discoveries {
+		if other != sd {
+			others = append(others, other)
+		}
+	}
+	return others
+}
+
+// servers must be called with the mutex locked
+func (mc *MemoryCluster) servers() []*Server {
+	servers := make([]*Server, 0, len(mc.discoveries))
+	for _, sd := range mc.discoveries {
+		servers = append(servers, sd.server)
+	}
+	return servers
+}
+
+func (mc *MemoryCluster) getServers() []*Server {
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+	return mc.servers()
+}
+
+func (mc *MemoryCluster) getServer(id string) (*Server, bool) {
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+	sd, ok := mc.discoveries[id]
+	if !ok {
+		return nil, false
+	}
+	return sd.server, true
+}
+
+func (mc *MemoryCluster) addRPCServer(rs *MemoryRPCServer) {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+	mc.rpcServers[rs.server.ID] = rs
+}
+
+func (mc *MemoryCluster) removeRPCServer(rs *MemoryRPCServer) {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+	delete(mc.rpcServers, rs.server.ID)
+	for _, users := range mc.users {
+		for uid, b := range users {
+			if b.rpcServer == rs {
+				delete(users, uid)
+			}
+		}
+	}
+}
+
+func (mc *MemoryCluster) getRPCServer(id string) (*MemoryRPCServer, bool) {
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+	rs, ok := mc.rpcServers[id]
+	return rs, ok
+}
+
+// getRPCServersByType returns the rpc servers of the servers of svType
+func (mc *MemoryCluster) getRPCServersByType(svType string) []*MemoryRPCServer {
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+	servers := []*MemoryRPCServer{}
+	for _, rs := range mc.rpcServers {
+		if rs.server.Type == svType {
+			servers = append(servers, rs)
+		}
+	}
+	return servers
+}
+
+// bindUser routes the pushes and kicks to uid in frontends of the type of rs
+// to rs
+func (mc *MemoryCluster) bindUser(rs *MemoryRPCServer, uid string, sessionID int64) {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+	users, ok := mc.users[rs.server.Type]
+	if !ok {
+		users = map[string]*userBinding{}
+		mc.users[rs.server.Type] = users
+	}
+	users[uid] = &userBinding{rpcServer: rs, sessionID: sessionID}
+}
+
+// unbindUser removes the binding of uid made by the session, bindings of
+// newer sessions are kept
+func (mc *MemoryCluster) unbindUser(rs *MemoryRPCServer, uid string, sessionID int64) {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+	users := mc.users[rs.server.Type]
+	if b, ok := users[uid]; ok && b.rpcServer == rs && b.sessionID == sessionID {
+		delete(users, uid)
+	}
+}
+
+// getUserRPCServer returns the rpc server of the frontend of svType uid is
+// bound to
+func (mc *MemoryCluster) getUserRPCServer(uid, svType string) (*MemoryRPCServer, bool) {
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+	b, ok := mc.users[svType][uid]
+	if !ok {
+		return nil, false
+	}
+	return b.rpcServer, true
+}
@@ -0,0 +1,246 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	opentracing "github.com/opentracing/opentracing-go"
+
+	"github.com/topfreegames/pitaya/v2/config"
+	"github.com/topfreegames/pitaya/v2/conn/message"
+	"github.com/topfreegames/pitaya/v2/constants"
+	pcontext "github.com/topfreegames/pitaya/v2/context"
+	"github.com/topfreegames/pitaya/v2/errors"
+	"github.com/topfreegames/pitaya/v2/logger"
+	"github.com/topfreegames/pitaya/v2/metrics"
+	"github.com/topfreegames/pitaya/v2/protos"
+	"github.com/topfreegames/pitaya/v2/route"
+	"github.com/topfreegames/pitaya/v2/session"
+	"github.com/topfreegames/pitaya/v2/tracing"
+)
+
+// MemoryRPCClient sends rpcs, pushes and kicks to the MemoryRPCServer of
+// servers running in the same process, the messages are copied as if they
+// were sent through the network
+type MemoryRPCClient struct {
+	cluster          *MemoryCluster
+	server           *Server
+	reqTimeout       time.Duration
+	metricsReporters []metrics.Reporter
+	running          int32
+}
+
+// NewMemoryRPCClient returns a rpc client sending messages to the servers
+// in cluster
+func NewMemoryRPCClient(
+	config config.MemoryRPCClientConfig,
+	cluster *MemoryCluster,
+	server *Server,
+	metricsReporters []metrics.Reporter,
+) *MemoryRPCClient {
+	return &MemoryRPCClient{
+		cluster:          cluster,
+		server:           server,
+		reqTimeout:       config.RequestTimeout,
+		metricsReporters: metricsReporters,
+	}
+}
+
+// Init inits the rpc client
+func (mc *MemoryRPCClient) Init() error {
+	atomic.StoreInt32(&mc.running, 1)
+	return nil
+}
+
+func (mc *MemoryRPCClient) isRunning() bool {
+	return atomic.LoadInt32(&mc.running) == 1
+}
+
+// AfterInit runs after initialization
+func (mc *MemoryRPCClient) AfterInit() {}
+
+// BeforeShutdown runs before shutdown
+func (mc *MemoryRPCClient) BeforeShutdown() {}
+
+// Shutdown stops the rpc client
+func (mc *MemoryRPCClient) Shutdown() error {
+	atomic.StoreInt32(&mc.running, 0)
+	return nil
+}
+
+// Call calls a method remotely
+func (mc *MemoryRPCClient) Call(
+	ctx context.Context,
+	rpcType protos.RPCType,
+	route *route.Route,
+	session session.Session,
+	msg *message.Message,
+	server *Server,
+) (*protos.Response, error) {
+	parent, err := tracing.ExtractSpan(ctx)
+	if err != nil {
+		logger.Log.Warnf("failed to retrieve parent span: %s", err.Error())
+	}
+	tags := opentracing.Tags{
+		"span.kind":       "client",
+		"local.id":        mc.server.ID,
+		"peer.serverType": server.Type,
+		"peer.id":         server.ID,
+	}
+	ctx = tracing.StartSpan(ctx, "Memory RPC Call", tags, parent)
+	defer tracing.FinishSpan(ctx, err)
+
+	if !mc.isRunning() {
+		err = constants.ErrRPCClientNotInitialized
+		return nil, err
+	}
+	target, ok := mc.cluster.getRPCServer(server.ID)
+	if !ok {
+		err = constants.ErrNoConnectionToServer
+		return nil, err
+	}
+
+	if mc.metricsReporters != nil {
+		startTime := time.Now()
+		ctx = pcontext.AddToPropagateCtx(ctx, constants.StartTimeKey, startTime.UnixNano())
+		ctx = pcontext.AddToPropagateCtx(ctx, constants.RouteKey, route.String())
+		defer func() {
+			metrics.ReportTimingFromCtx(ctx, mc.metricsReporters, "rpc", err)
+		}()
+	}
+	// the request timeout is shortened by the deadline of ctx, if any
+	ctxT, done := context.WithTimeout(ctx, mc.reqTimeout)
+	defer done()
+	req, err := buildRequest(ctxT, rpcType, route, session, msg, mc.server)
+	if err != nil {
+		return nil, err
+	}
+
+	resChan := make(chan *protos.Response, 1)
+	go func() {
+		resChan <- target.call(ctxT, proto.Clone(&req).(*protos.Request))
+	}()
+	var res *protos.Response
+	select {
+	case res = <-resChan:
+	case <-ctxT.Done():
+		err = ctxT.Err()
+		if err == context.DeadlineExceeded && ctx.Err() == nil {
+			err = constants.ErrRPCTimeout
+		}
+		return nil, err
+	}
+
+	if res.Error != nil {
+		if res.Error.Code == "" {
+			res.Error.Code = errors.ErrUnknownCode
+		}
+		err = &errors.Error{
+			Code:     res.Error.Code,
+			Message:  res.Error.Msg,
+			Metadata: res.Error.Metadata,
+		}
+		return nil, err
+	}
+	return res, nil
+}
+
+// Stream opens a stream with a stream remote of the server
+func (mc *MemoryRPCClient) Stream(ctx context.Context, route *route.Route, msg *message.Message, server *Server) (ClientStream, error) {
+	if !mc.isRunning() {
+		return nil, constants.ErrRPCClientNotInitialized
+	}
+	target, ok := mc.cluster.getRPCServer(server.ID)
+	if !ok {
+		return nil, constants.ErrNoConnectionToServer
+	}
+
+	ctx = startStreamSpan(ctx, "Memory RPC Stream", mc.server, server)
+	req, err := buildRequest(ctx, protos.RPCType_User, route, nil, msg, mc.server)
+	if err != nil {
+		tracing.FinishSpan(ctx, err)
+		return nil, err
+	}
+
+	// the stream is released when it ends or ctx is canceled
+	caller, remote := newMemoryStreamPair(ctx)
+	go target.stream(proto.Clone(&req).(*protos.Request), remote)
+	return newMemoryClientStream(caller), nil
+}
+
+// Send is not implemented in memory rpc client
+func (mc *MemoryRPCClient) Send(route string, data []byte) error {
+	return constants.ErrNotImplemented
+}
+
+// SendPush sends a message to a user, to the frontend server with the ID of
+// frontendSv or else to the one of its type the user is bound to
+func (mc *MemoryRPCClient) SendPush(userID string, frontendSv *Server, push *protos.Push) error {
+	target, err := mc.frontendRPCServer(userID, frontendSv)
+	if err != nil {
+		return err
+	}
+	return target.push(proto.Clone(push).(*protos.Push))
+}
+
+// SendKick kicks a user from the frontend server of serverType it is bound to
+func (mc *MemoryRPCClient) SendKick(userID string, serverType string, kick *protos.KickMsg) error {
+	target, err := mc.frontendRPCServer(userID, &Server{Type: serverType})
+	if err != nil {
+		return err
+	}
+	return target.kick(proto.Clone(kick).(*protos.KickMsg))
+}
+
+// BroadcastSessionBind sends the binding information to the servers of the
+// same type
+func (mc *MemoryRPCClient) BroadcastSessionBind(uid string) error {
+	msg := &protos.BindMsg{
+		Uid: uid,
+		Fid: mc.server.ID,
+	}
+	for _, target := range mc.cluster.getRPCServersByType(mc.server.Type) {
+		if _, err := target.pitayaServer.SessionBindRemote(context.Background(), proto.Clone(msg).(*protos.BindMsg)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (mc *MemoryRPCClient) frontendRPCServer(userID string, frontendSv *Server) (*MemoryRPCServer, error) {
+	if !mc.isRunning() {
+		return nil, constants.ErrRPCClientNotInitialized
+	}
+	if frontendSv.ID != "" {
+		if target, ok := mc.cluster.getRPCServer(frontendSv.ID); ok {
+			return target, nil
+		}
+		return nil, constants.ErrNoConnectionToServer
+	}
+	if target, ok := mc.cluster.getUserRPCServer(userID, frontendSv.Type); ok {
+		return target, nil
+	}
+	return nil, constants.ErrSessionNotFound
+}
@@ -0,0 +1,190 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"context"
+
+	"github.com/topfreegames/pitaya/v2/constants"
+	pcontext "github.com/topfreegames/pitaya/v2/context"
+	e "github.com/topfreegames/pitaya/v2/errors"
+	"github.com/topfreegames/pitaya/v2/logger"
+	"github.com/topfreegames/pitaya/v2/protos"
+	"github.com/topfreegames/pitaya/v2/relation"
+	"github.com/topfreegames/pitaya/v2/session"
+)
+
+// memoryRPCServerBufferSize is the number of pushes and kicks queued before
+// the senders block
+const memoryRPCServerBufferSize = 100
+
+// MemoryRPCServer receives the rpcs, pushes and kicks sent by the
+// MemoryRPCClient of servers running in the same process
+type MemoryRPCServer struct {
+	cluster      *MemoryCluster
+	server       *Server
+	pitayaServer protos.PitayaServer
+	sessionPool  session.SessionPool
+	userPushCh   chan *protos.Push
+	userKickCh   chan *protos.KickMsg
+	dieChan      chan struct{}
+}
+
+// NewMemoryRPCServer returns a rpc server joining cluster when initialized
+func NewMemoryRPCServer(cluster *MemoryCluster, server *Server, sessionPool session.SessionPool) *MemoryRPCServer {
+	return &MemoryRPCServer{
+		cluster:     cluster,
+		server:      server,
+		sessionPool: sessionPool,
+		userPushCh:  make(chan *protos.Push, memoryRPCServerBufferSize),
+		userKickCh:  make(chan *protos.KickMsg, memoryRPCServerBufferSize),
+		dieChan:     make(chan struct{}),
+	}
+}
+
+// SetPitayaServer sets the pitaya server
+func (ms *MemoryRPCServer) SetPitayaServer(ps protos.PitayaServer) {
+	ms.pitayaServer = ps
+}
+
+// Init adds the rpc server to the cluster
+func (ms *MemoryRPCServer) Init() error {
+	ms.cluster.addRPCServer(ms)
+	ms.sessionPool.OnSessionBind(ms.onSessionBind)
+	go ms.processPushesAndKicks()
+	return nil
+}
+
+// onSessionBind routes the pushes and kicks to the uid of the sessions bound
+// in frontend servers to this server until the session is closed
+func (ms *MemoryRPCServer) onSessionBind(ctx context.Context, s session.Session) error {
+	if !ms.server.Frontend {
+		return nil
+	}
+	uid, id := s.UID(), s.ID()
+	ms.cluster.bindUser(ms, uid, id)
+	return s.OnClose(func() {
+		ms.cluster.unbindUser(ms, uid, id)
+	})
+}
+
+// call processes a request received from another server, ctx carries the
+// deadline and cancelation of the caller
+func (ms *MemoryRPCServer) call(ctx context.Context, req *protos.Request) *protos.Response {
+	res, err := ms.pitayaServer.Call(ctx, req)
+	if res == nil {
+		if err == nil {
+			err = constants.ErrNoResponse
+		}
+		res = &protos.Response{
+			Error: &protos.Error{
+				Code: e.ErrInternalCode,
+				Msg:  err.Error(),
+			},
+		}
+	}
+	return res
+}
+
+// stream runs the stream remote requested by req, s is the side of the
+// stream of this server
+func (ms *MemoryRPCServer) stream(req *protos.Request, s *memoryStream) {
+	var res *protos.Response
+	if streamServer, ok := ms.pitayaServer.(StreamServer); ok {
+		res = streamServer.Stream(s.ctx, req, s)
+	} else {
+		res = &protos.Response{
+			Error: &protos.Error{
+				Code: e.ErrInternalCode,
+				Msg:  constants.ErrNotImplemented.Error(),
+			},
+		}
+	}
+
+	var err error
+	if res != nil && res.Error != nil {
+		err = s.closeWithError(res.Error)
+	} else {
+		err = s.closeSend()
+	}
+	if err != nil {
+		logger.Log.Errorf("error ending stream: %s", err.Error())
+	}
+}
+
+// push queues a push to be sent to a user of this server. Like in the nats
+// rpc server pushes are processed in order, after the sender goes on.
+func (ms *MemoryRPCServer) push(push *protos.Push) error {
+	select {
+	case ms.userPushCh <- push:
+		return nil
+	case <-ms.dieChan:
+		return constants.ErrRPCServerNotInitialized
+	}
+}
+
+// kick queues a kick of a user of this server
+func (ms *MemoryRPCServer) kick(kick *protos.KickMsg) error {
+	select {
+	case ms.userKickCh <- kick:
+		return nil
+	case <-ms.dieChan:
+		return constants.ErrRPCServerNotInitialized
+	}
+}
+
+func (ms *MemoryRPCServer) processPushesAndKicks() {
+	for {
+		select {
+		case push := <-ms.userPushCh:
+			ctx := context.Background()
+			if push.RelationMsgId != 0 {
+				ctx = pcontext.CtxWithRelationData(ctx, push.Uid, relation.Data{MsgID: push.RelationMsgId, SessID: push.SessionId})
+			}
+			if _, err := ms.pitayaServer.PushToUser(ctx, push); err != nil {
+				logger.Log.Errorf("error sending push to user: %v", err)
+			}
+		case kick := <-ms.userKickCh:
+			ctx := context.Background()
+			if kick.RelationMsgId != 0 {
+				ctx = pcontext.CtxWithRelationData(ctx, kick.GetUserId(), relation.Data{MsgID: kick.RelationMsgId})
+			}
+			if _, err := ms.pitayaServer.KickUser(ctx, kick); err != nil {
+				logger.Log.Errorf("error sending kick to user: %v", err)
+			}
+		case <-ms.dieChan:
+			return
+		}
+	}
+}
+
+// AfterInit runs after initialization
+func (ms *MemoryRPCServer) AfterInit() {}
+
+// BeforeShutdown runs before shutdown
+func (ms *MemoryRPCServer) BeforeShutdown() {}
+
+// Shutdown removes the rpc server from the cluster
+func (ms *MemoryRPCServer) Shutdown() error {
+	ms.cluster.removeRPCServer(ms)
+	close(ms.dieChan)
+	return nil
+}
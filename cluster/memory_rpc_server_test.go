@@ -0,0 +1,29 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/topfreegames/pitaya/v2/constants"
+	e "github.com/topfreegames/pitaya/v2/errors"
+	"github.com/topfreegames/pitaya/v2/protos"
+	protosmocks "github.com/topfreegames/pitaya/v2/protos/mocks"
+	"github.com/topfreegames/pitaya/v2/session"
+)
+
+func TestMemoryRPCServerCallWithoutResponse(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPitayaServer := protosmocks.NewMockPitayaServer(ctrl)
+	mockPitayaServer.EXPECT().Call(gomock.Any(), gomock.Any()).Return(nil, nil)
+	ms := NewMemoryRPCServer(NewMemoryCluster(), getServer(), session.NewSessionPool())
+	ms.SetPitayaServer(newPitayaServerMock(mockPitayaServer))
+
+	res := ms.call(context.Background(), &protos.Request{})
+	assert.Equal(t, e.ErrInternalCode, res.Error.Code)
+	assert.Equal(t, constants.ErrNoResponse.Error(), res.Error.Msg)
+}
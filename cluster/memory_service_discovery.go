@@ -0,0 +1,140 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"sync"
+
+	"github.com/topfreegames/pitaya/v2/constants"
+)
+
+// MemoryServiceDiscovery is a service discovery for servers running in the
+// same process and sharing a MemoryCluster
+type MemoryServiceDiscovery struct {
+	cluster   *MemoryCluster
+	server    *Server
+	mutex     sync.Mutex
+	listeners []SDListener
+}
+
+// NewMemoryServiceDiscovery returns a service discovery adding server to
+// cluster when initialized
+func NewMemoryServiceDiscovery(cluster *MemoryCluster, server *Server) *MemoryServiceDiscovery {
+	return &MemoryServiceDiscovery{
+		cluster:   cluster,
+		server:    server,
+		listeners: make([]SDListener, 0),
+	}
+}
+
+// Init adds the server to the cluster and notifies the listeners of the
+// servers already in it
+func (sd *MemoryServiceDiscovery) Init() error {
+	others, servers := sd.cluster.join(sd)
+	for _, sv := range servers {
+		sd.notifyListeners(ADD, sv)
+	}
+	for _, other := range others {
+		other.notifyListeners(ADD, sd.server)
+	}
+	return nil
+}
+
+// AfterInit executes after Init
+func (sd *MemoryServiceDiscovery) AfterInit() {}
+
+// BeforeShutdown removes the server from the cluster
+func (sd *MemoryServiceDiscovery) BeforeShutdown() {
+	for _, other := range sd.cluster.leave(sd) {
+		other.notifyListeners(DEL, sd.server)
+	}
+}
+
+// Shutdown executes on shutdown
+func (sd *MemoryServiceDiscovery) Shutdown() error {
+	return nil
+}
+
+// AddListener adds a listener to the service discovery
+func (sd *MemoryServiceDiscovery) AddListener(listener SDListener) {
+	sd.mutex.Lock()
+	defer sd.mutex.Unlock()
+	sd.listeners = append(sd.listeners, listener)
+}
+
+func (sd *MemoryServiceDiscovery) notifyListeners(act Action, sv *Server) {
+	sd.mutex.Lock()
+	listeners := append([]SDListener{}, sd.listeners...)
+	sd.mutex.Unlock()
+	for _, l := range listeners {
+		if act == DEL {
+			l.RemoveServer(sv)
+		} else if act == ADD {
+			l.AddServer(sv)
+		}
+	}
+}
+
+// GetServersByType returns the servers of a type
+func (sd *MemoryServiceDiscovery) GetServersByType(serverType string) (map[string]*Server, error) {
+	ret := map[string]*Server{}
+	for _, sv := range sd.cluster.getServers() {
+		if sv.Type == serverType {
+			ret[sv.ID] = sv
+		}
+	}
+	if len(ret) == 0 {
+		return nil, constants.ErrNoServersAvailableOfType
+	}
+	return ret, nil
+}
+
+// GetServer returns a server given its id
+func (sd *MemoryServiceDiscovery) GetServer(id string) (*Server, error) {
+	if sv, ok := sd.cluster.getServer(id); ok {
+		return sv, nil
+	}
+	return nil, constants.ErrNoServerWithID
+}
+
+// GetServers returns a slice with all the servers
+func (sd *MemoryServiceDiscovery) GetServers() []*Server {
+	return sd.cluster.getServers()
+}
+
+// SyncServers does nothing, the servers are always in sync
+func (sd *MemoryServiceDiscovery) SyncServers(firstSync bool) error {
+	return nil
+}
+
+// Drain marks the server as draining to the other servers
+func (sd *MemoryServiceDiscovery) Drain() error {
+	metadata := make(map[string]string, len(sd.server.Metadata)+1)
+	for k, v := range sd.server.Metadata {
+		metadata[k] = v
+	}
+	metadata[ServerStateKey] = ServerStateDraining
+
+	for _, other := range sd.cluster.setMetadata(sd, metadata) {
+		other.notifyListeners(ADD, sd.server)
+	}
+	return nil
+}
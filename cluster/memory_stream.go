@@ -0,0 +1,150 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"context"
+	"io"
+
+	"github.com/topfreegames/pitaya/v2/constants"
+	"github.com/topfreegames/pitaya/v2/protos"
+)
+
+// memoryStreamWindow is the number of messages each side of a memory stream
+// can send before the other side receives them
+const memoryStreamWindow = 64
+
+// memoryFrame is a message of a memory stream, or the error ending it
+type memoryFrame struct {
+	data []byte
+	err  *protos.Error
+}
+
+// memoryStream is one side of a stream between servers of the same process,
+// each side sends its frames to a channel received by the other side which
+// is closed when it won't send anymore
+type memoryStream struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	send    chan memoryFrame
+	recv    chan memoryFrame
+	recvErr error
+	closed  bool
+}
+
+// newMemoryStreamPair returns the sides of the caller and of the remote of a
+// stream, canceling ctx ends both
+func newMemoryStreamPair(ctx context.Context) (*memoryStream, *memoryStream) {
+	ctx, cancel := context.WithCancel(ctx)
+	toRemote := make(chan memoryFrame, memoryStreamWindow)
+	toCaller := make(chan memoryFrame, memoryStreamWindow)
+	caller := &memoryStream{ctx: ctx, cancel: cancel, send: toRemote, recv: toCaller}
+	remote := &memoryStream{ctx: ctx, cancel: cancel, send: toCaller, recv: toRemote}
+	return caller, remote
+}
+
+func (s *memoryStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *memoryStream) Send(data []byte) error {
+	if s.closed {
+		return constants.ErrStreamSendClosed
+	}
+	// the other side may keep the data after this side reuses the slice
+	return s.sendFrame(memoryFrame{data: append([]byte{}, data...)})
+}
+
+func (s *memoryStream) sendFrame(frame memoryFrame) error {
+	select {
+	case s.send <- frame:
+		return nil
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	}
+}
+
+func (s *memoryStream) Recv() ([]byte, error) {
+	if s.recvErr != nil {
+		return nil, s.recvErr
+	}
+
+	select {
+	case frame, ok := <-s.recv:
+		if !ok {
+			s.recvErr = io.EOF
+			return nil, s.recvErr
+		}
+		if frame.err != nil {
+			s.recvErr = errorFromProto(frame.err)
+			return nil, s.recvErr
+		}
+		return frame.data, nil
+	case <-s.ctx.Done():
+		return nil, s.ctx.Err()
+	}
+}
+
+// closeSend tells the other side that no more messages will be sent
+func (s *memoryStream) closeSend() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	close(s.send)
+	return nil
+}
+
+// closeWithError ends the stream with err, which is returned by the Recv of
+// the other side
+func (s *memoryStream) closeWithError(err *protos.Error) error {
+	if s.closed {
+		return nil
+	}
+	if e := s.sendFrame(memoryFrame{err: err}); e != nil {
+		return e
+	}
+	return s.closeSend()
+}
+
+type memoryClientStream struct {
+	*memoryStream
+	end *streamEnd
+}
+
+func newMemoryClientStream(s *memoryStream) *memoryClientStream {
+	return &memoryClientStream{
+		memoryStream: s,
+		end:          newStreamEnd(s.ctx, func(error) { s.cancel() }),
+	}
+}
+
+func (s *memoryClientStream) Recv() ([]byte, error) {
+	data, err := s.memoryStream.Recv()
+	if err != nil {
+		s.end.finish(err)
+	}
+	return data, err
+}
+
+func (s *memoryClientStream) CloseSend() error {
+	return s.closeSend()
+}
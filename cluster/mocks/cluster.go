@@ -6,292 +6,562 @@ package mocks
 
 import (
 	context "context"
+	reflect "reflect"
+
 	gomock "github.com/golang/mock/gomock"
 	cluster "github.com/topfreegames/pitaya/v2/cluster"
 	message "github.com/topfreegames/pitaya/v2/conn/message"
 	protos "github.com/topfreegames/pitaya/v2/protos"
 	route "github.com/topfreegames/pitaya/v2/route"
 	session "github.com/topfreegames/pitaya/v2/session"
-	reflect "reflect"
 )
 
-// MockRPCServer is a mock of RPCServer interface
+// MockRPCServer is a mock of RPCServer interface.
 type MockRPCServer struct {
 	ctrl     *gomock.Controller
 	recorder *MockRPCServerMockRecorder
 }
 
-// MockRPCServerMockRecorder is the mock recorder for MockRPCServer
+// MockRPCServerMockRecorder is the mock recorder for MockRPCServer.
 type MockRPCServerMockRecorder struct {
 	mock *MockRPCServer
 }
 
-// NewMockRPCServer creates a new mock instance
+// NewMockRPCServer creates a new mock instance.
 func NewMockRPCServer(ctrl *gomock.Controller) *MockRPCServer {
 	mock := &MockRPCServer{ctrl: ctrl}
 	mock.recorder = &MockRPCServerMockRecorder{mock}
 	return mock
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use
+// EXPECT returns an object that allows the caller to indicate expected use.
 func (m *MockRPCServer) EXPECT() *MockRPCServerMockRecorder {
 	return m.recorder
 }
 
-// SetPitayaServer mocks base method
-func (m *MockRPCServer) SetPitayaServer(arg0 protos.PitayaServer) {
-	m.ctrl.Call(m, "SetPitayaServer", arg0)
+// AfterInit mocks base method.
+func (m *MockRPCServer) AfterInit() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AfterInit")
 }
 
-// SetPitayaServer indicates an expected call of SetPitayaServer
-func (mr *MockRPCServerMockRecorder) SetPitayaServer(arg0 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPitayaServer", reflect.TypeOf((*MockRPCServer)(nil).SetPitayaServer), arg0)
+// AfterInit indicates an expected call of AfterInit.
+func (mr *MockRPCServerMockRecorder) AfterInit() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AfterInit", reflect.TypeOf((*MockRPCServer)(nil).AfterInit))
 }
 
-// Init mocks base method
+// BeforeShutdown mocks base method.
+func (m *MockRPCServer) BeforeShutdown() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "BeforeShutdown")
+}
+
+// BeforeShutdown indicates an expected call of BeforeShutdown.
+func (mr *MockRPCServerMockRecorder) BeforeShutdown() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeforeShutdown", reflect.TypeOf((*MockRPCServer)(nil).BeforeShutdown))
+}
+
+// Init mocks base method.
 func (m *MockRPCServer) Init() error {
+	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Init")
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Init indicates an expected call of Init
+// Init indicates an expected call of Init.
 func (mr *MockRPCServerMockRecorder) Init() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Init", reflect.TypeOf((*MockRPCServer)(nil).Init))
 }
 
-// AfterInit mocks base method
-func (m *MockRPCServer) AfterInit() {
-	m.ctrl.Call(m, "AfterInit")
-}
-
-// AfterInit indicates an expected call of AfterInit
-func (mr *MockRPCServerMockRecorder) AfterInit() *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AfterInit", reflect.TypeOf((*MockRPCServer)(nil).AfterInit))
-}
-
-// BeforeShutdown mocks base method
-func (m *MockRPCServer) BeforeShutdown() {
-	m.ctrl.Call(m, "BeforeShutdown")
+// SetPitayaServer mocks base method.
+func (m *MockRPCServer) SetPitayaServer(arg0 protos.PitayaServer) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetPitayaServer", arg0)
 }
 
-// BeforeShutdown indicates an expected call of BeforeShutdown
-func (mr *MockRPCServerMockRecorder) BeforeShutdown() *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeforeShutdown", reflect.TypeOf((*MockRPCServer)(nil).BeforeShutdown))
+// SetPitayaServer indicates an expected call of SetPitayaServer.
+func (mr *MockRPCServerMockRecorder) SetPitayaServer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPitayaServer", reflect.TypeOf((*MockRPCServer)(nil).SetPitayaServer), arg0)
 }
 
-// Shutdown mocks base method
+// Shutdown mocks base method.
 func (m *MockRPCServer) Shutdown() error {
+	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Shutdown")
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Shutdown indicates an expected call of Shutdown
+// Shutdown indicates an expected call of Shutdown.
 func (mr *MockRPCServerMockRecorder) Shutdown() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shutdown", reflect.TypeOf((*MockRPCServer)(nil).Shutdown))
 }
 
-// MockRPCClient is a mock of RPCClient interface
+// MockRPCClient is a mock of RPCClient interface.
 type MockRPCClient struct {
 	ctrl     *gomock.Controller
 	recorder *MockRPCClientMockRecorder
 }
 
-// MockRPCClientMockRecorder is the mock recorder for MockRPCClient
+// MockRPCClientMockRecorder is the mock recorder for MockRPCClient.
 type MockRPCClientMockRecorder struct {
 	mock *MockRPCClient
 }
 
-// NewMockRPCClient creates a new mock instance
+// NewMockRPCClient creates a new mock instance.
 func NewMockRPCClient(ctrl *gomock.Controller) *MockRPCClient {
 	mock := &MockRPCClient{ctrl: ctrl}
 	mock.recorder = &MockRPCClientMockRecorder{mock}
 	return mock
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use
+// EXPECT returns an object that allows the caller to indicate expected use.
 func (m *MockRPCClient) EXPECT() *MockRPCClientMockRecorder {
 	return m.recorder
 }
 
-// Send mocks base method
-func (m *MockRPCClient) Send(route string, data []byte) error {
-	ret := m.ctrl.Call(m, "Send", route, data)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// Send indicates an expected call of Send
-func (mr *MockRPCClientMockRecorder) Send(route, data interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockRPCClient)(nil).Send), route, data)
-}
-
-// SendPush mocks base method
-func (m *MockRPCClient) SendPush(userID string, frontendSv *cluster.Server, push *protos.Push) error {
-	ret := m.ctrl.Call(m, "SendPush", userID, frontendSv, push)
-	ret0, _ := ret[0].(error)
-	return ret0
+// AfterInit mocks base method.
+func (m *MockRPCClient) AfterInit() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AfterInit")
 }
 
-// SendPush indicates an expected call of SendPush
-func (mr *MockRPCClientMockRecorder) SendPush(userID, frontendSv, push interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendPush", reflect.TypeOf((*MockRPCClient)(nil).SendPush), userID, frontendSv, push)
+// AfterInit indicates an expected call of AfterInit.
+func (mr *MockRPCClientMockRecorder) AfterInit() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AfterInit", reflect.TypeOf((*MockRPCClient)(nil).AfterInit))
 }
 
-// SendKick mocks base method
-func (m *MockRPCClient) SendKick(userID, serverType string, kick *protos.KickMsg) error {
-	ret := m.ctrl.Call(m, "SendKick", userID, serverType, kick)
-	ret0, _ := ret[0].(error)
-	return ret0
+// BeforeShutdown mocks base method.
+func (m *MockRPCClient) BeforeShutdown() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "BeforeShutdown")
 }
 
-// SendKick indicates an expected call of SendKick
-func (mr *MockRPCClientMockRecorder) SendKick(userID, serverType, kick interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendKick", reflect.TypeOf((*MockRPCClient)(nil).SendKick), userID, serverType, kick)
+// BeforeShutdown indicates an expected call of BeforeShutdown.
+func (mr *MockRPCClientMockRecorder) BeforeShutdown() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeforeShutdown", reflect.TypeOf((*MockRPCClient)(nil).BeforeShutdown))
 }
 
-// BroadcastSessionBind mocks base method
+// BroadcastSessionBind mocks base method.
 func (m *MockRPCClient) BroadcastSessionBind(uid string) error {
+	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "BroadcastSessionBind", uid)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// BroadcastSessionBind indicates an expected call of BroadcastSessionBind
+// BroadcastSessionBind indicates an expected call of BroadcastSessionBind.
 func (mr *MockRPCClientMockRecorder) BroadcastSessionBind(uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BroadcastSessionBind", reflect.TypeOf((*MockRPCClient)(nil).BroadcastSessionBind), uid)
 }
 
-// Call mocks base method
+// Call mocks base method.
 func (m *MockRPCClient) Call(ctx context.Context, rpcType protos.RPCType, route *route.Route, session session.Session, msg *message.Message, server *cluster.Server) (*protos.Response, error) {
+	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Call", ctx, rpcType, route, session, msg, server)
 	ret0, _ := ret[0].(*protos.Response)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Call indicates an expected call of Call
+// Call indicates an expected call of Call.
 func (mr *MockRPCClientMockRecorder) Call(ctx, rpcType, route, session, msg, server interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Call", reflect.TypeOf((*MockRPCClient)(nil).Call), ctx, rpcType, route, session, msg, server)
 }
 
-// Init mocks base method
+// Init mocks base method.
 func (m *MockRPCClient) Init() error {
+	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Init")
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Init indicates an expected call of Init
+// Init indicates an expected call of Init.
 func (mr *MockRPCClientMockRecorder) Init() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Init", reflect.TypeOf((*MockRPCClient)(nil).Init))
 }
 
-// AfterInit mocks base method
-func (m *MockRPCClient) AfterInit() {
-	m.ctrl.Call(m, "AfterInit")
+// Send mocks base method.
+func (m *MockRPCClient) Send(route string, data []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", route, data)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// AfterInit indicates an expected call of AfterInit
-func (mr *MockRPCClientMockRecorder) AfterInit() *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AfterInit", reflect.TypeOf((*MockRPCClient)(nil).AfterInit))
+// Send indicates an expected call of Send.
+func (mr *MockRPCClientMockRecorder) Send(route, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockRPCClient)(nil).Send), route, data)
 }
 
-// BeforeShutdown mocks base method
-func (m *MockRPCClient) BeforeShutdown() {
-	m.ctrl.Call(m, "BeforeShutdown")
+// SendKick mocks base method.
+func (m *MockRPCClient) SendKick(userID, serverType string, kick *protos.KickMsg) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendKick", userID, serverType, kick)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// BeforeShutdown indicates an expected call of BeforeShutdown
-func (mr *MockRPCClientMockRecorder) BeforeShutdown() *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeforeShutdown", reflect.TypeOf((*MockRPCClient)(nil).BeforeShutdown))
+// SendKick indicates an expected call of SendKick.
+func (mr *MockRPCClientMockRecorder) SendKick(userID, serverType, kick interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendKick", reflect.TypeOf((*MockRPCClient)(nil).SendKick), userID, serverType, kick)
+}
+
+// SendPush mocks base method.
+func (m *MockRPCClient) SendPush(userID string, frontendSv *cluster.Server, push *protos.Push) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendPush", userID, frontendSv, push)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendPush indicates an expected call of SendPush.
+func (mr *MockRPCClientMockRecorder) SendPush(userID, frontendSv, push interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendPush", reflect.TypeOf((*MockRPCClient)(nil).SendPush), userID, frontendSv, push)
 }
 
-// Shutdown mocks base method
+// Shutdown mocks base method.
 func (m *MockRPCClient) Shutdown() error {
+	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Shutdown")
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Shutdown indicates an expected call of Shutdown
+// Shutdown indicates an expected call of Shutdown.
 func (mr *MockRPCClientMockRecorder) Shutdown() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shutdown", reflect.TypeOf((*MockRPCClient)(nil).Shutdown))
 }
 
-// MockSDListener is a mock of SDListener interface
+// Stream mocks base method.
+func (m *MockRPCClient) Stream(ctx context.Context, route *route.Route, msg *message.Message, server *cluster.Server) (cluster.ClientStream, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stream", ctx, route, msg, server)
+	ret0, _ := ret[0].(cluster.ClientStream)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stream indicates an expected call of Stream.
+func (mr *MockRPCClientMockRecorder) Stream(ctx, route, msg, server interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stream", reflect.TypeOf((*MockRPCClient)(nil).Stream), ctx, route, msg, server)
+}
+
+// MockStream is a mock of Stream interface.
+type MockStream struct {
+	ctrl     *gomock.Controller
+	recorder *MockStreamMockRecorder
+}
+
+// MockStreamMockRecorder is the mock recorder for MockStream.
+type MockStreamMockRecorder struct {
+	mock *MockStream
+}
+
+// NewMockStream creates a new mock instance.
+func NewMockStream(ctrl *gomock.Controller) *MockStream {
+	mock := &MockStream{ctrl: ctrl}
+	mock.recorder = &MockStreamMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStream) EXPECT() *MockStreamMockRecorder {
+	return m.recorder
+}
+
+// Context mocks base method.
+func (m *MockStream) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockStreamMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockStream)(nil).Context))
+}
+
+// Recv mocks base method.
+func (m *MockStream) Recv() ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv.
+func (mr *MockStreamMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockStream)(nil).Recv))
+}
+
+// Send mocks base method.
+func (m *MockStream) Send(data []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockStreamMockRecorder) Send(data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockStream)(nil).Send), data)
+}
+
+// MockClientStream is a mock of ClientStream interface.
+type MockClientStream struct {
+	ctrl     *gomock.Controller
+	recorder *MockClientStreamMockRecorder
+}
+
+// MockClientStreamMockRecorder is the mock recorder for MockClientStream.
+type MockClientStreamMockRecorder struct {
+	mock *MockClientStream
+}
+
+// NewMockClientStream creates a new mock instance.
+func NewMockClientStream(ctrl *gomock.Controller) *MockClientStream {
+	mock := &MockClientStream{ctrl: ctrl}
+	mock.recorder = &MockClientStreamMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClientStream) EXPECT() *MockClientStreamMockRecorder {
+	return m.recorder
+}
+
+// CloseSend mocks base method.
+func (m *MockClientStream) CloseSend() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseSend")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseSend indicates an expected call of CloseSend.
+func (mr *MockClientStreamMockRecorder) CloseSend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockClientStream)(nil).CloseSend))
+}
+
+// Context mocks base method.
+func (m *MockClientStream) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockClientStreamMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockClientStream)(nil).Context))
+}
+
+// Recv mocks base method.
+func (m *MockClientStream) Recv() ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv.
+func (mr *MockClientStreamMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockClientStream)(nil).Recv))
+}
+
+// Send mocks base method.
+func (m *MockClientStream) Send(data []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockClientStreamMockRecorder) Send(data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockClientStream)(nil).Send), data)
+}
+
+// MockStreamServer is a mock of StreamServer interface.
+type MockStreamServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockStreamServerMockRecorder
+}
+
+// MockStreamServerMockRecorder is the mock recorder for MockStreamServer.
+type MockStreamServerMockRecorder struct {
+	mock *MockStreamServer
+}
+
+// NewMockStreamServer creates a new mock instance.
+func NewMockStreamServer(ctrl *gomock.Controller) *MockStreamServer {
+	mock := &MockStreamServer{ctrl: ctrl}
+	mock.recorder = &MockStreamServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStreamServer) EXPECT() *MockStreamServerMockRecorder {
+	return m.recorder
+}
+
+// Stream mocks base method.
+func (m *MockStreamServer) Stream(ctx context.Context, req *protos.Request, stream cluster.Stream) *protos.Response {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stream", ctx, req, stream)
+	ret0, _ := ret[0].(*protos.Response)
+	return ret0
+}
+
+// Stream indicates an expected call of Stream.
+func (mr *MockStreamServerMockRecorder) Stream(ctx, req, stream interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stream", reflect.TypeOf((*MockStreamServer)(nil).Stream), ctx, req, stream)
+}
+
+// MockSDListener is a mock of SDListener interface.
 type MockSDListener struct {
 	ctrl     *gomock.Controller
 	recorder *MockSDListenerMockRecorder
 }
 
-// MockSDListenerMockRecorder is the mock recorder for MockSDListener
+// MockSDListenerMockRecorder is the mock recorder for MockSDListener.
 type MockSDListenerMockRecorder struct {
 	mock *MockSDListener
 }
 
-// NewMockSDListener creates a new mock instance
+// NewMockSDListener creates a new mock instance.
 func NewMockSDListener(ctrl *gomock.Controller) *MockSDListener {
 	mock := &MockSDListener{ctrl: ctrl}
 	mock.recorder = &MockSDListenerMockRecorder{mock}
 	return mock
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use
+// EXPECT returns an object that allows the caller to indicate expected use.
 func (m *MockSDListener) EXPECT() *MockSDListenerMockRecorder {
 	return m.recorder
 }
 
-// AddServer mocks base method
+// AddServer mocks base method.
 func (m *MockSDListener) AddServer(arg0 *cluster.Server) {
+	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "AddServer", arg0)
 }
 
-// AddServer indicates an expected call of AddServer
+// AddServer indicates an expected call of AddServer.
 func (mr *MockSDListenerMockRecorder) AddServer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddServer", reflect.TypeOf((*MockSDListener)(nil).AddServer), arg0)
 }
 
-// RemoveServer mocks base method
+// RemoveServer mocks base method.
 func (m *MockSDListener) RemoveServer(arg0 *cluster.Server) {
+	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "RemoveServer", arg0)
 }
 
-// RemoveServer indicates an expected call of RemoveServer
+// RemoveServer indicates an expected call of RemoveServer.
 func (mr *MockSDListenerMockRecorder) RemoveServer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveServer", reflect.TypeOf((*MockSDListener)(nil).RemoveServer), arg0)
 }
 
-// MockRemoteBindingListener is a mock of RemoteBindingListener interface
+// MockRemoteBindingListener is a mock of RemoteBindingListener interface.
 type MockRemoteBindingListener struct {
 	ctrl     *gomock.Controller
 	recorder *MockRemoteBindingListenerMockRecorder
 }
 
-// MockRemoteBindingListenerMockRecorder is the mock recorder for MockRemoteBindingListener
+// MockRemoteBindingListenerMockRecorder is the mock recorder for MockRemoteBindingListener.
 type MockRemoteBindingListenerMockRecorder struct {
 	mock *MockRemoteBindingListener
 }
 
-// NewMockRemoteBindingListener creates a new mock instance
+// NewMockRemoteBindingListener creates a new mock instance.
 func NewMockRemoteBindingListener(ctrl *gomock.Controller) *MockRemoteBindingListener {
 	mock := &MockRemoteBindingListener{ctrl: ctrl}
 	mock.recorder = &MockRemoteBindingListenerMockRecorder{mock}
 	return mock
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use
+// EXPECT returns an object that allows the caller to indicate expected use.
 func (m *MockRemoteBindingListener) EXPECT() *MockRemoteBindingListenerMockRecorder {
 	return m.recorder
 }
 
-// OnUserBind mocks base method
+// OnUserBind mocks base method.
 func (m *MockRemoteBindingListener) OnUserBind(uid, fid string) {
+	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "OnUserBind", uid, fid)
 }
 
-// OnUserBind indicates an expected call of OnUserBind
+// OnUserBind indicates an expected call of OnUserBind.
 func (mr *MockRemoteBindingListenerMockRecorder) OnUserBind(uid, fid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnUserBind", reflect.TypeOf((*MockRemoteBindingListener)(nil).OnUserBind), uid, fid)
 }
+
+// MockInfoRetriever is a mock of InfoRetriever interface.
+type MockInfoRetriever struct {
+	ctrl     *gomock.Controller
+	recorder *MockInfoRetrieverMockRecorder
+}
+
+// MockInfoRetrieverMockRecorder is the mock recorder for MockInfoRetriever.
+type MockInfoRetrieverMockRecorder struct {
+	mock *MockInfoRetriever
+}
+
+// NewMockInfoRetriever creates a new mock instance.
+func NewMockInfoRetriever(ctrl *gomock.Controller) *MockInfoRetriever {
+	mock := &MockInfoRetriever{ctrl: ctrl}
+	mock.recorder = &MockInfoRetrieverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInfoRetriever) EXPECT() *MockInfoRetrieverMockRecorder {
+	return m.recorder
+}
+
+// Region mocks base method.
+func (m *MockInfoRetriever) Region() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Region")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Region indicates an expected call of Region.
+func (mr *MockInfoRetrieverMockRecorder) Region() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Region", reflect.TypeOf((*MockInfoRetriever)(nil).Region))
+}
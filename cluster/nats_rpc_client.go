@@ -176,7 +176,13 @@ func (ns *NatsRPCClient) Call(
 			metrics.ReportTimingFromCtx(ctx, ns.metricsReporters, typ, err)
 		}()
 	}
-	m, err = ns.conn.Request(getChannel(server.Type, server.ID), marshalledData, ns.reqTimeout)
+	// the request timeout is shortened by the deadline of ctx, if any
+	ctxT, done := context.WithTimeout(ctx, ns.reqTimeout)
+	defer done()
+	m, err = ns.conn.RequestWithContext(ctxT, getChannel(server.Type, server.ID), marshalledData)
+	if err == context.DeadlineExceeded && ctx.Err() == nil {
+		err = nats.ErrTimeout
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -201,6 +207,63 @@ func (ns *NatsRPCClient) Call(
 	return res, nil
 }
 
+// Stream opens a stream with a stream remote of the server
+func (ns *NatsRPCClient) Stream(ctx context.Context, route *route.Route, msg *message.Message, server *Server) (ClientStream, error) {
+	if !ns.running {
+		return nil, constants.ErrRPCClientNotInitialized
+	}
+
+	ctx = startStreamSpan(ctx, "NATS RPC Stream", ns.server, server)
+	s, err := ns.openStream(ctx, route, msg, server)
+	if err != nil {
+		tracing.FinishSpan(ctx, err)
+		return nil, err
+	}
+	return newNatsClientStream(s), nil
+}
+
+func (ns *NatsRPCClient) openStream(ctx context.Context, route *route.Route, msg *message.Message, server *Server) (*natsStream, error) {
+	req, err := buildRequest(ctx, protos.RPCType_User, route, nil, msg, ns.server)
+	if err != nil {
+		return nil, err
+	}
+	data, err := proto.Marshal(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := newNatsStream(ctx, ns.conn, true)
+	if err != nil {
+		return nil, err
+	}
+	open := append([]byte{natsStreamOpen}, data...)
+	if err := ns.conn.PublishRequest(getStreamChannel(server.Type, server.ID), s.inbox, open); err != nil {
+		s.close()
+		return nil, err
+	}
+
+	timer := time.NewTimer(ns.reqTimeout)
+	defer timer.Stop()
+	select {
+	case frame := <-s.frames:
+		switch frame[0] {
+		case natsStreamAccept:
+			s.peer = string(frame[1:])
+			return s, nil
+		case natsStreamError:
+			err = decodeStreamError(frame)
+		default:
+			err = constants.ErrInvalidStreamFrame
+		}
+	case <-timer.C:
+		err = nats.ErrTimeout
+	case <-s.ctx.Done():
+		err = s.ctx.Err()
+	}
+	s.close()
+	return nil, err
+}
+
 // Init inits nats rpc client
 func (ns *NatsRPCClient) Init() error {
 	ns.running = true
@@ -377,9 +377,13 @@ func TestNatsRPCClientBuildRequest(t *testing.T) {
 			ctrl := gomock.NewController(t)
 
 			ss := sessionmocks.NewMockSession(ctrl)
-			ss.EXPECT().ID().Return(sessionID).Times(1)
-			ss.EXPECT().UID().Return(uid).Times(1)
-			ss.EXPECT().GetDataEncoded().Return(data2).Times(1)
+			if table.rpcType == protos.RPCType_Sys {
+				// the session is only sent on sys rpcs
+				ss.EXPECT().ID().Return(sessionID).Times(1)
+				ss.EXPECT().UID().Return(uid).Times(1)
+				ss.EXPECT().GetDataEncoded().Return(data2).Times(1)
+				ss.EXPECT().GetSerializer().Return(nil).Times(1)
+			}
 
 			rpcClient.server.Frontend = table.frontendServer
 			req, err := buildRequest(context.Background(), table.rpcType, table.route, ss, table.msg, rpcClient.server)
@@ -465,9 +469,14 @@ func TestNatsRPCClientCall(t *testing.T) {
 			ss.EXPECT().ID().Return(sessionID).Times(1)
 			ss.EXPECT().UID().Return(uid).Times(1)
 			ss.EXPECT().GetDataEncoded().Return(data2).Times(1)
+			ss.EXPECT().GetSerializer().Return(nil).Times(1)
 
 			res, err := rpcClient.Call(context.Background(), protos.RPCType_Sys, rt, ss, msg, sv2)
-			assert.Equal(t, table.expected, res)
+			if table.expected != nil {
+				assert.True(t, proto.Equal(table.expected, res))
+			} else {
+				assert.Nil(t, res)
+			}
 			if table.err != nil {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), table.err.Error())
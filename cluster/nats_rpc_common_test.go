@@ -28,6 +28,8 @@ import (
 	nats "github.com/nats-io/nats.go"
 	"github.com/stretchr/testify/assert"
 	"github.com/topfreegames/pitaya/v2/helpers"
+	"github.com/topfreegames/pitaya/v2/protos"
+	protosmocks "github.com/topfreegames/pitaya/v2/protos/mocks"
 )
 
 func getServer() *Server {
@@ -38,6 +40,23 @@ func getServer() *Server {
 	}
 }
 
+// unimplementedPitayaServer is embedded one level deeper than the mock so
+// the mocked methods take precedence over the unimplemented ones
+type unimplementedPitayaServer struct {
+	protos.UnimplementedPitayaServer
+}
+
+type pitayaServerMock struct {
+	*protosmocks.MockPitayaServer
+	unimplementedPitayaServer
+}
+
+// newPitayaServerMock adapts the generated mock to protos.PitayaServer,
+// which requires embedding protos.UnimplementedPitayaServer
+func newPitayaServerMock(m *protosmocks.MockPitayaServer) protos.PitayaServer {
+	return &pitayaServerMock{MockPitayaServer: m}
+}
+
 func TestNatsRPCCommonGetChannel(t *testing.T) {
 	t.Parallel()
 	assert.Equal(t, "pitaya/servers/type1/sv1", getChannel("type1", "sv1"))
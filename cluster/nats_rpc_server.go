@@ -32,6 +32,7 @@ import (
 
 	"github.com/topfreegames/pitaya/v2/config"
 	"github.com/topfreegames/pitaya/v2/constants"
+	pcontext "github.com/topfreegames/pitaya/v2/context"
 	e "github.com/topfreegames/pitaya/v2/errors"
 	"github.com/topfreegames/pitaya/v2/logger"
 	"github.com/topfreegames/pitaya/v2/metrics"
@@ -63,6 +64,7 @@ type NatsRPCServer struct {
 	userPushCh             chan *protos.Push
 	userKickCh             chan *protos.KickMsg
 	sub                    *nats.Subscription
+	streamSub              *nats.Subscription
 	dropped                int
 	pitayaServer           protos.PitayaServer
 	metricsReporters       []metrics.Reporter
@@ -317,7 +319,10 @@ func (ns *NatsRPCServer) processRandMessages(threadID int) {
 				},
 			}
 		} else {
+			// apply the deadline propagated by the caller, nats doesn't carry it
+			ctx, cancel := pcontext.WithPropagatedDeadline(ctx)
 			ns.randResponses[threadID], _ = ns.pitayaServer.Call(ctx, ns.randRequests[threadID])
+			cancel()
 		}
 		p, err := ns.marshalResponse(ns.randResponses[threadID])
 		err = ns.conn.Publish(ns.randRequests[threadID].GetMsg().GetReply(), p)
@@ -339,7 +344,10 @@ func (ns *NatsRPCServer) processMessages(threadID int) {
 				},
 			}
 		} else {
+			// apply the deadline propagated by the caller, nats doesn't carry it
+			ctx, cancel := pcontext.WithPropagatedDeadline(ctx)
 			ns.responses[threadID], _ = ns.pitayaServer.Call(ctx, ns.requests[threadID])
+			cancel()
 		}
 		p, err := ns.marshalResponse(ns.responses[threadID])
 		err = ns.conn.Publish(ns.requests[threadID].GetMsg().GetReply(), p)
@@ -349,6 +357,61 @@ func (ns *NatsRPCServer) processMessages(threadID int) {
 	}
 }
 
+// processStream opens the stream requested by msg and runs its remote
+func (ns *NatsRPCServer) processStream(msg *nats.Msg) {
+	fail := func(code string, err error) {
+		frame := encodeStreamError(&protos.Error{Code: code, Msg: err.Error()})
+		if err := ns.conn.Publish(msg.Reply, frame); err != nil {
+			logger.Log.Errorf("error sending stream error: %s", err.Error())
+		}
+	}
+
+	if len(msg.Data) == 0 || msg.Data[0] != natsStreamOpen {
+		fail(e.ErrBadRequestCode, constants.ErrInvalidStreamFrame)
+		return
+	}
+	req := &protos.Request{}
+	if err := proto.Unmarshal(msg.Data[1:], req); err != nil {
+		fail(e.ErrBadRequestCode, err)
+		return
+	}
+	streamServer, ok := ns.pitayaServer.(StreamServer)
+	if !ok {
+		fail(e.ErrInternalCode, constants.ErrNotImplemented)
+		return
+	}
+	ctx, err := util.GetContextFromRequest(req, ns.server.ID)
+	if err != nil {
+		fail(e.ErrInternalCode, err)
+		return
+	}
+	// apply the deadline propagated by the caller, nats doesn't carry it
+	ctx, cancel := pcontext.WithPropagatedDeadline(ctx)
+	defer cancel()
+
+	s, err := newNatsStream(ctx, ns.conn, false)
+	if err != nil {
+		fail(e.ErrInternalCode, err)
+		return
+	}
+	defer s.close()
+	s.peer = msg.Reply
+	if err := s.publish(natsStreamAccept, []byte(s.inbox)); err != nil {
+		logger.Log.Errorf("error accepting stream: %s", err.Error())
+		return
+	}
+
+	res := streamServer.Stream(s.ctx, req, s)
+	if res != nil && res.Error != nil {
+		err = ns.conn.Publish(s.peer, encodeStreamError(res.Error))
+	} else {
+		err = s.closeSend()
+	}
+	if err != nil {
+		logger.Log.Errorf("error ending stream: %s", err.Error())
+	}
+}
+
 func (ns *NatsRPCServer) processSessionBindings() {
 	for bind := range ns.bindingsChan {
 		b := &protos.BindMsg{}
@@ -366,11 +429,7 @@ func (ns *NatsRPCServer) processPushes() {
 		// logger.Log.Debugf("sending push to user %s", push.GetUid())
 		ctx := context.Background()
 		if push.RelationMsgId != 0 {
-			ctx = context.WithValue(
-				ctx,
-				constants.MsgRelationKey,
-				map[string]relation.Data{push.Uid: {MsgID: push.RelationMsgId, SessID: push.SessionId}},
-			)
+			ctx = pcontext.CtxWithRelationData(ctx, push.Uid, relation.Data{MsgID: push.RelationMsgId, SessID: push.SessionId})
 		}
 
 		_, err := ns.pitayaServer.PushToUser(ctx, push)
@@ -386,11 +445,7 @@ func (ns *NatsRPCServer) processKick() {
 
 		ctx := context.Background()
 		if kick.RelationMsgId != 0 {
-			ctx = context.WithValue(
-				ctx,
-				constants.MsgRelationKey,
-				map[string]relation.Data{kick.GetUserId(): {MsgID: kick.RelationMsgId, SessID: 0}},
-			)
+			ctx = pcontext.CtxWithRelationData(ctx, kick.GetUserId(), relation.Data{MsgID: kick.RelationMsgId})
 		}
 
 		_, err := ns.pitayaServer.KickUser(ctx, kick)
@@ -424,6 +479,13 @@ func (ns *NatsRPCServer) Init() error {
 	if err != nil {
 		return err
 	}
+	// streams are long lived, each one is processed by its own goroutine
+	ns.streamSub, err = ns.conn.Subscribe(getStreamChannel(ns.server.Type, ns.server.ID), func(msg *nats.Msg) {
+		go ns.processStream(msg)
+	})
+	if err != nil {
+		return err
+	}
 	// this handles remote messages
 	for i := 0; i < ns.service; i++ {
 		go ns.processMessages(i)
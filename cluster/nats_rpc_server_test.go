@@ -132,8 +132,8 @@ func TestNatsRPCServerGetUnhandledRequestsChannel(t *testing.T) {
 	cfg := config.NewDefaultNatsRPCServerConfig()
 	sv := getServer()
 	n, _ := NewNatsRPCServer(*cfg, sv, nil, nil, nil)
-	assert.NotNil(t, n.GetUnhandledRequestsChannel())
-	assert.IsType(t, make(chan *protos.Request), n.GetUnhandledRequestsChannel())
+	assert.NotNil(t, n.GetUnhandledRequestsChannel(0))
+	assert.IsType(t, make(chan *protos.Request), n.GetUnhandledRequestsChannel(0))
 }
 
 func TestNatsRPCServerGetBindingsChannel(t *testing.T) {
@@ -319,7 +319,8 @@ func TestNatsRPCServerHandleMessages(t *testing.T) {
 			mockMetricsReporter.EXPECT().ReportGauge(metrics.ChannelCapacity, gomock.Any(), gomock.Any()).Times(3)
 
 			conn.Publish(table.topic, b)
-			r := helpers.ShouldEventuallyReceive(t, rpcServer.unhandledReqCh).(*protos.Request)
+			// requests without a session go to any of the workers
+			r := helpers.ShouldEventuallyReceive(t, rpcServer.unhandledRandReqCh).(*protos.Request)
 			assert.Equal(t, table.req.FrontendID, r.FrontendID)
 			assert.Equal(t, table.req.Msg.Id, r.Msg.Id)
 		})
@@ -335,7 +336,8 @@ func TestNatsRPCServerInitShouldFailIfConnFails(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	mockSessionPool := sessionmocks.NewMockSessionPool(ctrl)
 	rpcServer, _ := NewNatsRPCServer(*cfg, sv, nil, nil, mockSessionPool)
-	mockSessionPool.EXPECT().OnSessionBind(rpcServer.onSessionBind)
+	// the session bindings are only listened to once connected
+	mockSessionPool.EXPECT().OnSessionBind(gomock.Any()).Times(0)
 	err := rpcServer.Init()
 	assert.Error(t, err)
 }
@@ -371,7 +373,7 @@ func TestNatsRPCServerInit(t *testing.T) {
 		t.Run(table.name, func(t *testing.T) {
 			c := make(chan *nats.Msg)
 			rpcServer.conn.ChanSubscribe(table.req.Msg.Reply, c)
-			rpcServer.unhandledReqCh <- table.req
+			rpcServer.unhandledReqCh[0] <- table.req
 			r := helpers.ShouldEventuallyReceive(t, c).(*nats.Msg)
 			assert.NotNil(t, r.Data)
 		})
@@ -395,7 +397,7 @@ func TestNatsRPCServerProcessBindings(t *testing.T) {
 	pitayaSvMock := protosmocks.NewMockPitayaServer(ctrl)
 	defer ctrl.Finish()
 
-	rpcServer.SetPitayaServer(pitayaSvMock)
+	rpcServer.SetPitayaServer(newPitayaServerMock(pitayaSvMock))
 
 	bindMsg := &protos.BindMsg{
 		Uid: "testuid",
@@ -438,7 +440,7 @@ func TestNatsRPCServerProcessPushes(t *testing.T) {
 	pitayaSvMock := protosmocks.NewMockPitayaServer(ctrl)
 	defer ctrl.Finish()
 
-	rpcServer.SetPitayaServer(pitayaSvMock)
+	rpcServer.SetPitayaServer(newPitayaServerMock(pitayaSvMock))
 
 	push := &protos.Push{
 		Route: "someroute",
@@ -473,7 +475,7 @@ func TestNatsRPCServerProcessKick(t *testing.T) {
 	pitayaSvMock := protosmocks.NewMockPitayaServer(ctrl)
 	defer ctrl.Finish()
 
-	rpcServer.SetPitayaServer(pitayaSvMock)
+	rpcServer.SetPitayaServer(newPitayaServerMock(pitayaSvMock))
 
 	kick := &protos.KickMsg{
 		UserId: "someuid",
@@ -0,0 +1,329 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/golang/protobuf/proto"
+	nats "github.com/nats-io/nats.go"
+
+	"github.com/topfreegames/pitaya/v2/constants"
+	"github.com/topfreegames/pitaya/v2/logger"
+	"github.com/topfreegames/pitaya/v2/protos"
+)
+
+// frame types of the nats streams, every message published in a stream
+// starts with one of them
+const (
+	natsStreamOpen   byte = iota // request opening the stream, sent by the caller
+	natsStreamAccept             // inbox of the remote, sent after the open
+	natsStreamData               // a message or its last chunk
+	natsStreamChunk              // a chunk of a message, more chunks follow
+	natsStreamAck                // number of chunks consumed by the receiver
+	natsStreamEnd                // the sender won't send anymore
+	natsStreamError              // the remote failed
+	natsStreamCancel             // the caller canceled the stream
+)
+
+// natsStreamWindow is the number of chunks each side of a stream can send
+// before the other side acknowledges them
+const natsStreamWindow = 64
+
+// natsStreamDefaultChunkSize is the chunk size used when the max payload of
+// the connection is unknown, the default max payload of nats minus the frame type
+const natsStreamDefaultChunkSize = 1024*1024 - 1
+
+func getStreamChannel(serverType, serverID string) string {
+	return getChannel(serverType, serverID) + "/stream"
+}
+
+// natsStream is one side of a stream, messages bigger than the max payload of
+// the nats connection are split in chunks and the number of chunks in flight
+// is limited by the stream window
+type natsStream struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	conn      *nats.Conn
+	sub       *nats.Subscription
+	inbox     string
+	peer      string
+	caller    bool
+	chunkSize int
+	frames    chan []byte
+	done      chan struct{} // closed when the remote ends, only on the caller side
+	doneOnce  sync.Once
+	mutex     sync.Mutex
+	credits   int
+	granted   chan struct{}
+	consumed  int
+	recvErr   error
+	closed    int32 // set by closeSend, read by Send on any goroutine
+	overflow  int32 // set when the other side sent more frames than the window
+}
+
+func newNatsStream(ctx context.Context, conn *nats.Conn, caller bool) (*natsStream, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &natsStream{
+		ctx:       ctx,
+		cancel:    cancel,
+		conn:      conn,
+		inbox:     nats.NewInbox(),
+		caller:    caller,
+		chunkSize: int(conn.MaxPayload()) - 1,
+		frames:    make(chan []byte, natsStreamWindow+2),
+		done:      make(chan struct{}),
+		credits:   natsStreamWindow,
+		granted:   make(chan struct{}),
+	}
+	if s.chunkSize <= 0 {
+		// the max payload is unknown while disconnected
+		s.chunkSize = natsStreamDefaultChunkSize
+	}
+	sub, err := conn.Subscribe(s.inbox, s.handle)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	s.sub = sub
+	return s, nil
+}
+
+// handle is called by nats with the frames sent by the other side
+func (s *natsStream) handle(msg *nats.Msg) {
+	if len(msg.Data) == 0 {
+		return
+	}
+	switch msg.Data[0] {
+	case natsStreamAck:
+		n, _ := binary.Uvarint(msg.Data[1:])
+		s.grant(int(n))
+		return
+	case natsStreamCancel:
+		s.cancel()
+		return
+	case natsStreamEnd, natsStreamError:
+		if s.caller {
+			s.doneOnce.Do(func() { close(s.done) })
+		}
+	}
+	// frames over the window aren't waited for since that would block the
+	// other subscriptions of the connection, the stream is rejected instead
+	select {
+	case s.frames <- msg.Data:
+	default:
+		if atomic.CompareAndSwapInt32(&s.overflow, 0, 1) {
+			logger.Log.Warnf("stream %s received more frames than its window, closing it", s.inbox)
+			s.cancel()
+		}
+	}
+}
+
+func (s *natsStream) publish(typ byte, payload []byte) error {
+	data := make([]byte, 1+len(payload))
+	data[0] = typ
+	copy(data[1:], payload)
+	return s.conn.Publish(s.peer, data)
+}
+
+func (s *natsStream) grant(n int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.credits += n
+	close(s.granted)
+	s.granted = make(chan struct{})
+}
+
+// acquire blocks until the other side has room for another chunk
+func (s *natsStream) acquire() error {
+	for {
+		if s.remoteDone() {
+			return io.EOF
+		}
+		s.mutex.Lock()
+		if s.credits > 0 {
+			s.credits--
+			s.mutex.Unlock()
+			return nil
+		}
+		granted := s.granted
+		s.mutex.Unlock()
+
+		select {
+		case <-granted:
+		case <-s.done:
+			return io.EOF
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		}
+	}
+}
+
+// consume acknowledges the chunks received once half the window is consumed
+func (s *natsStream) consume() {
+	s.consumed++
+	if s.consumed < natsStreamWindow/2 {
+		return
+	}
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(buf, uint64(s.consumed))
+	s.consumed = 0
+	if err := s.publish(natsStreamAck, buf[:n]); err != nil {
+		logger.Log.Warnf("failed to acknowledge stream chunks: %s", err.Error())
+	}
+}
+
+func (s *natsStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *natsStream) Send(data []byte) error {
+	if atomic.LoadInt32(&s.closed) == 1 {
+		return constants.ErrStreamSendClosed
+	}
+	for {
+		n, typ := len(data), natsStreamData
+		if n > s.chunkSize {
+			n, typ = s.chunkSize, natsStreamChunk
+		}
+		if err := s.acquire(); err != nil {
+			return err
+		}
+		if err := s.publish(typ, data[:n]); err != nil {
+			return err
+		}
+		if typ == natsStreamData {
+			return nil
+		}
+		data = data[n:]
+	}
+}
+
+func (s *natsStream) Recv() ([]byte, error) {
+	if s.recvErr != nil {
+		return nil, s.recvErr
+	}
+
+	var data []byte
+	for {
+		var frame []byte
+		select {
+		case frame = <-s.frames:
+		case <-s.ctx.Done():
+			if atomic.LoadInt32(&s.overflow) == 1 {
+				s.recvErr = constants.ErrStreamWindowExceeded
+				return nil, s.recvErr
+			}
+			return nil, s.ctx.Err()
+		}
+
+		switch frame[0] {
+		case natsStreamChunk:
+			data = append(data, frame[1:]...)
+			s.consume()
+		case natsStreamData:
+			s.consume()
+			return append(data, frame[1:]...), nil
+		case natsStreamEnd:
+			s.recvErr = io.EOF
+			return nil, s.recvErr
+		case natsStreamError:
+			s.recvErr = decodeStreamError(frame)
+			return nil, s.recvErr
+		default:
+			s.recvErr = constants.ErrInvalidStreamFrame
+			return nil, s.recvErr
+		}
+	}
+}
+
+// closeSend tells the other side that no more messages will be sent
+func (s *natsStream) closeSend() error {
+	if !atomic.CompareAndSwapInt32(&s.closed, 0, 1) {
+		return nil
+	}
+	return s.publish(natsStreamEnd, nil)
+}
+
+func (s *natsStream) close() {
+	if err := s.sub.Unsubscribe(); err != nil {
+		logger.Log.Warnf("failed to unsubscribe from stream inbox: %s", err.Error())
+	}
+	s.cancel()
+}
+
+// remoteDone returns whether the remote already ended the stream
+func (s *natsStream) remoteDone() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func encodeStreamError(err *protos.Error) []byte {
+	payload, _ := proto.Marshal(err)
+	return append([]byte{natsStreamError}, payload...)
+}
+
+func decodeStreamError(frame []byte) error {
+	err := &protos.Error{}
+	if e := proto.Unmarshal(frame[1:], err); e != nil {
+		return e
+	}
+	return errorFromProto(err)
+}
+
+type natsClientStream struct {
+	*natsStream
+	end *streamEnd
+}
+
+func newNatsClientStream(s *natsStream) *natsClientStream {
+	return &natsClientStream{
+		natsStream: s,
+		end: newStreamEnd(s.ctx, func(error) {
+			if !s.remoteDone() {
+				if err := s.publish(natsStreamCancel, nil); err != nil {
+					logger.Log.Warnf("failed to cancel stream: %s", err.Error())
+				}
+			}
+			s.close()
+		}),
+	}
+}
+
+func (s *natsClientStream) Recv() ([]byte, error) {
+	data, err := s.natsStream.Recv()
+	if err != nil {
+		s.end.finish(err)
+	}
+	return data, err
+}
+
+func (s *natsClientStream) CloseSend() error {
+	return s.closeSend()
+}
@@ -0,0 +1,187 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	nats "github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/topfreegames/pitaya/v2/constants"
+	"github.com/topfreegames/pitaya/v2/errors"
+	"github.com/topfreegames/pitaya/v2/helpers"
+	"github.com/topfreegames/pitaya/v2/protos"
+)
+
+func getTestNatsStreams(t *testing.T, conn *nats.Conn) (*natsStream, *natsStream) {
+	t.Helper()
+	caller, err := newNatsStream(context.Background(), conn, true)
+	assert.NoError(t, err)
+	remote, err := newNatsStream(context.Background(), conn, false)
+	assert.NoError(t, err)
+	caller.peer = remote.inbox
+	remote.peer = caller.inbox
+	return caller, remote
+}
+
+func TestNatsStreamGetStreamChannel(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "pitaya/servers/type1/sv1/stream", getStreamChannel("type1", "sv1"))
+}
+
+func TestNatsStreamSendRecv(t *testing.T) {
+	t.Parallel()
+	s := helpers.GetTestNatsServer(t)
+	defer s.Shutdown()
+	conn, err := setupNatsConn(fmt.Sprintf("nats://%s", s.Addr()), nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	caller, remote := getTestNatsStreams(t, conn)
+	defer caller.close()
+	defer remote.close()
+	remote.chunkSize = 3
+
+	// more chunks than the window, so the sender waits for the acks
+	big := bytes.Repeat([]byte("abc"), natsStreamWindow*2)
+	go func() {
+		assert.NoError(t, remote.Send([]byte("hello")))
+		assert.NoError(t, remote.Send(big))
+		assert.NoError(t, remote.closeSend())
+	}()
+
+	data, err := caller.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("hello"), data)
+	data, err = caller.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, big, data)
+	_, err = caller.Recv()
+	assert.Equal(t, io.EOF, err)
+	assert.True(t, caller.remoteDone())
+	assert.Equal(t, io.EOF, caller.Send([]byte("late")))
+}
+
+func TestNatsStreamSendAfterClose(t *testing.T) {
+	t.Parallel()
+	s := helpers.GetTestNatsServer(t)
+	defer s.Shutdown()
+	conn, err := setupNatsConn(fmt.Sprintf("nats://%s", s.Addr()), nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	caller, remote := getTestNatsStreams(t, conn)
+	defer caller.close()
+	defer remote.close()
+
+	assert.NoError(t, remote.closeSend())
+	assert.Error(t, remote.Send([]byte("data")))
+}
+
+func TestNatsStreamError(t *testing.T) {
+	t.Parallel()
+	s := helpers.GetTestNatsServer(t)
+	defer s.Shutdown()
+	conn, err := setupNatsConn(fmt.Sprintf("nats://%s", s.Addr()), nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	caller, remote := getTestNatsStreams(t, conn)
+	defer caller.close()
+	defer remote.close()
+
+	assert.NoError(t, conn.Publish(remote.peer, encodeStreamError(&protos.Error{Code: "PIT-400", Msg: "bad"})))
+	_, err = caller.Recv()
+	assert.Equal(t, &errors.Error{Code: "PIT-400", Message: "bad"}, err)
+	_, err = caller.Recv()
+	assert.Equal(t, &errors.Error{Code: "PIT-400", Message: "bad"}, err)
+}
+
+func TestNatsStreamCancel(t *testing.T) {
+	t.Parallel()
+	s := helpers.GetTestNatsServer(t)
+	defer s.Shutdown()
+	conn, err := setupNatsConn(fmt.Sprintf("nats://%s", s.Addr()), nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	caller, remote := getTestNatsStreams(t, conn)
+	defer remote.close()
+
+	client := newNatsClientStream(caller)
+	client.natsStream.cancel()
+
+	<-remote.Context().Done()
+	_, err = remote.Recv()
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestNatsStreamWindowExceeded(t *testing.T) {
+	t.Parallel()
+	s := helpers.GetTestNatsServer(t)
+	defer s.Shutdown()
+	conn, err := setupNatsConn(fmt.Sprintf("nats://%s", s.Addr()), nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	caller, remote := getTestNatsStreams(t, conn)
+	defer caller.close()
+	defer remote.close()
+
+	// frames published without waiting for the acks
+	for i := 0; i < cap(caller.frames)+1; i++ {
+		assert.NoError(t, remote.publish(natsStreamChunk, []byte("abc")))
+	}
+	<-caller.Context().Done()
+	for err == nil {
+		_, err = caller.Recv()
+	}
+	assert.Equal(t, constants.ErrStreamWindowExceeded, err)
+}
+
+func TestNatsStreamConcurrentCloseSend(t *testing.T) {
+	t.Parallel()
+	s := helpers.GetTestNatsServer(t)
+	defer s.Shutdown()
+	conn, err := setupNatsConn(fmt.Sprintf("nats://%s", s.Addr()), nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	caller, remote := getTestNatsStreams(t, conn)
+	defer caller.close()
+	defer remote.close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, remote.closeSend())
+	}()
+	err = remote.Send([]byte("data"))
+	if err != nil {
+		assert.Equal(t, constants.ErrStreamSendClosed, err)
+	}
+	<-done
+	assert.Equal(t, constants.ErrStreamSendClosed, remote.Send([]byte("data")))
+}
@@ -27,6 +27,14 @@ import (
 	"github.com/topfreegames/pitaya/v2/logger"
 )
 
+const (
+	// ServerStateKey is the metadata key holding the lifecycle state of a server
+	ServerStateKey = "pitaya.state"
+	// ServerStateDraining is the state of a server that is shutting down, the
+	// other servers stop sending new work to it
+	ServerStateDraining = "draining"
+)
+
 // Server struct
 type Server struct {
 	ID       string            `json:"id"`
@@ -55,6 +63,12 @@ func NewServer(id, serverType string, frontend bool, metadata ...map[string]stri
 	}
 }
 
+// IsDraining returns true if the server is shutting down and should not
+// receive new work
+func (s *Server) IsDraining() bool {
+	return s.Metadata[ServerStateKey] == ServerStateDraining
+}
+
 // AsJSONString returns the server as a json string
 func (s *Server) AsJSONString() string {
 	str, err := json.Marshal(s)
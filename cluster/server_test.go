@@ -63,3 +63,16 @@ func TestAsJSONString(t *testing.T) {
 		})
 	}
 }
+
+func TestIsDraining(t *testing.T) {
+	t.Parallel()
+	for _, table := range svTestTables {
+		t.Run(table.id, func(t *testing.T) {
+			s := NewServer(table.id, table.svType, table.frontend, table.metadata)
+			assert.False(t, s.IsDraining())
+		})
+	}
+
+	s := NewServer("someid", "somesvtype", false, map[string]string{ServerStateKey: ServerStateDraining})
+	assert.True(t, s.IsDraining())
+}
@@ -29,5 +29,6 @@ type ServiceDiscovery interface {
 	GetServers() []*Server
 	SyncServers(firstSync bool) error
 	AddListener(listener SDListener)
+	Drain() error
 	interfaces.Module
 }
@@ -0,0 +1,87 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/topfreegames/pitaya/v2/errors"
+	"github.com/topfreegames/pitaya/v2/logger"
+	"github.com/topfreegames/pitaya/v2/protos"
+	"github.com/topfreegames/pitaya/v2/tracing"
+)
+
+// streamEnd releases a client stream once, when it is received until the end
+// or when its context is canceled
+type streamEnd struct {
+	once  sync.Once
+	ctx   context.Context
+	close func(err error)
+}
+
+// newStreamEnd calls close and finishes the span of ctx when the stream ends,
+// close must cancel ctx
+func newStreamEnd(ctx context.Context, close func(err error)) *streamEnd {
+	e := &streamEnd{ctx: ctx, close: close}
+	go func() {
+		<-ctx.Done()
+		e.finish(ctx.Err())
+	}()
+	return e
+}
+
+func (e *streamEnd) finish(err error) {
+	e.once.Do(func() {
+		if err == io.EOF {
+			err = nil
+		}
+		e.close(err)
+		tracing.FinishSpan(e.ctx, err)
+	})
+}
+
+func startStreamSpan(ctx context.Context, operationName string, thisServer, server *Server) context.Context {
+	parent, err := tracing.ExtractSpan(ctx)
+	if err != nil {
+		logger.Log.Warnf("failed to retrieve parent span: %s", err.Error())
+	}
+	tags := opentracing.Tags{
+		"span.kind":       "client",
+		"local.id":        thisServer.ID,
+		"peer.serverType": server.Type,
+		"peer.id":         server.ID,
+	}
+	return tracing.StartSpan(ctx, operationName, tags, parent)
+}
+
+func errorFromProto(err *protos.Error) error {
+	if err.Code == "" {
+		err.Code = errors.ErrUnknownCode
+	}
+	return &errors.Error{
+		Code:     err.Code,
+		Message:  err.Msg,
+		Metadata: err.Metadata,
+	}
+}
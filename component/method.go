@@ -35,6 +35,7 @@ var (
 	typeOfBytes    = reflect.TypeOf(([]byte)(nil))
 	typeOfContext  = reflect.TypeOf(new(context.Context)).Elem()
 	typeOfProtoMsg = reflect.TypeOf(new(proto.Message)).Elem()
+	typeOfStream   = reflect.TypeOf(new(Stream)).Elem()
 )
 
 func isExported(name string) bool {
@@ -81,6 +82,37 @@ func isRemoteMethod(method reflect.Method) bool {
 	return true
 }
 
+// isStreamMethod decide a method is suitable stream remote method
+func isStreamMethod(method reflect.Method) bool {
+	mt := method.Type
+	// Method must be exported.
+	if method.PkgPath != "" {
+		return false
+	}
+
+	// Method needs three or four ins: receiver, context.Context, optional proto message and Stream
+	if mt.NumIn() != 3 && mt.NumIn() != 4 {
+		return false
+	}
+
+	if t1 := mt.In(1); !t1.Implements(typeOfContext) {
+		return false
+	}
+
+	if mt.NumIn() == 4 {
+		if t2 := mt.In(2); t2.Kind() != reflect.Ptr || !t2.Implements(typeOfProtoMsg) {
+			return false
+		}
+	}
+
+	if mt.In(mt.NumIn()-1) != typeOfStream {
+		return false
+	}
+
+	// Method needs one out: error
+	return mt.NumOut() == 1 && mt.Out(0) == typeOfError
+}
+
 // isHandlerMethod decide a method is suitable handler method
 func isHandlerMethod(method reflect.Method) bool {
 	mt := method.Type
@@ -137,6 +169,29 @@ func suitableRemoteMethods(typ reflect.Type, nameFunc func(string) string) map[s
 	return methods
 }
 
+func suitableStreamMethods(typ reflect.Type, nameFunc func(string) string) map[string]*Remote {
+	methods := make(map[string]*Remote)
+	for m := 0; m < typ.NumMethod(); m++ {
+		method := typ.Method(m)
+		mt := method.Type
+		mn := method.Name
+		if isStreamMethod(method) {
+			// rewrite remote name
+			if nameFunc != nil {
+				mn = nameFunc(mn)
+			}
+			methods[mn] = &Remote{
+				Method:  method,
+				HasArgs: mt.NumIn() == 4,
+			}
+			if mt.NumIn() == 4 {
+				methods[mn].Type = mt.In(2)
+			}
+		}
+	}
+	return methods
+}
+
 func suitableHandlerMethods(typ reflect.Type, nameFunc func(string) string) map[string]*Handler {
 	methods := make(map[string]*Handler)
 	for m := 0; m < typ.NumMethod(); m++ {
@@ -49,6 +49,15 @@ func (t *TestType) ExportedRemoteRawOut(ctx context.Context) (*test.SomeStruct,
 func (t *TestType) ExportedRemotePointerOut(ctx context.Context) (*test.SomeStruct, error) {
 	return nil, nil
 }
+func (t *TestType) ExportedStream(ctx context.Context, arg *test.SomeStruct, stream Stream) error {
+	return nil
+}
+func (t *TestType) ExportedStreamNoArgs(ctx context.Context, stream Stream) error {
+	return nil
+}
+func (t *TestType) ExportedStreamWithOut(ctx context.Context, stream Stream) (*test.SomeStruct, error) {
+	return nil, nil
+}
 
 func TestIsExported(t *testing.T) {
 	t.Parallel()
@@ -91,6 +100,30 @@ func TestIsRemoteMethod(t *testing.T) {
 	}
 }
 
+func TestIsStreamMethod(t *testing.T) {
+	t.Parallel()
+	tables := []struct {
+		methodName string
+		isStream   bool
+	}{
+		{"ExportedHandlerWithOnlySession", false},
+		{"ExportedRemotePointerOut", false},
+		{"ExportedStream", true},
+		{"ExportedStreamNoArgs", true},
+		{"ExportedStreamWithOut", false},
+	}
+
+	for _, table := range tables {
+		t.Run(table.methodName, func(t *testing.T) {
+			tObj := &TestType{}
+			m, ok := reflect.TypeOf(tObj).MethodByName(table.methodName)
+			assert.True(t, ok)
+			assert.NotNil(t, m)
+			assert.Equal(t, table.isStream, isStreamMethod(m))
+		})
+	}
+}
+
 func TestIsHandleMethod(t *testing.T) {
 	t.Parallel()
 	tables := []struct {
@@ -138,6 +171,16 @@ func TestSuitableRemoteMethods(t *testing.T) {
 	}
 }
 
+func TestSuitableStreamMethods(t *testing.T) {
+	t.Parallel()
+	out := suitableStreamMethods(reflect.TypeOf(&TestType{}), strings.ToLower)
+	assert.Len(t, out, 2)
+	assert.True(t, out["exportedstream"].HasArgs)
+	assert.Equal(t, reflect.TypeOf(&test.SomeStruct{}), out["exportedstream"].Type)
+	assert.False(t, out["exportedstreamnoargs"].HasArgs)
+	assert.Nil(t, out["exportedstreamnoargs"].Type)
+}
+
 func TestSuitableHandlerMethods(t *testing.T) {
 	t.Parallel()
 	tables := []struct {
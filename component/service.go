@@ -54,6 +54,7 @@ type (
 		Receiver reflect.Value       // receiver of methods for the service
 		Handlers map[string]*Handler // registered methods
 		Remotes  map[string]*Remote  // registered remote methods
+		Streams  map[string]*Remote  // registered stream remote methods
 		Options  options             // options
 	}
 )
@@ -136,12 +137,14 @@ func (s *Service) ExtractRemote() error {
 
 	// Install the methods
 	s.Remotes = suitableRemoteMethods(s.Type, s.Options.nameFunc)
+	s.Streams = suitableStreamMethods(s.Type, s.Options.nameFunc)
 
-	if len(s.Remotes) == 0 {
+	if len(s.Remotes) == 0 && len(s.Streams) == 0 {
 		str := ""
 		// To help the user, see if a pointer receiver would work.
 		method := suitableRemoteMethods(reflect.PtrTo(s.Type), s.Options.nameFunc)
-		if len(method) != 0 {
+		stream := suitableStreamMethods(reflect.PtrTo(s.Type), s.Options.nameFunc)
+		if len(method) != 0 || len(stream) != 0 {
 			str = "type " + s.Name + " has no exported methods of remote type (hint: pass a pointer to value of that type)"
 		} else {
 			str = "type " + s.Name + " has no exported methods of remote type"
@@ -152,6 +155,9 @@ func (s *Service) ExtractRemote() error {
 	for i := range s.Remotes {
 		s.Remotes[i].Receiver = s.Receiver
 	}
+	for i := range s.Streams {
+		s.Streams[i].Receiver = s.Receiver
+	}
 	return nil
 }
 
@@ -0,0 +1,49 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package component
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// Stream is the server side of a streaming rpc, it is received by stream
+// remotes, e.g. func(ctx context.Context, arg *protos.Arg, stream component.Stream) error
+type Stream interface {
+	// Context returns the context of the rpc, it is canceled when the caller
+	// cancels the stream
+	Context() context.Context
+	// Send sends a message to the caller, it blocks while the caller is not
+	// receiving fast enough
+	Send(msg proto.Message) error
+	// Recv receives a message sent by the caller, it returns io.EOF after
+	// the caller closes its side of the stream
+	Recv(msg proto.Message) error
+}
+
+// ClientStream is the caller side of a streaming rpc, it must be received
+// until Recv returns an error or have its context canceled
+type ClientStream interface {
+	Stream
+	// CloseSend tells the remote that no more messages will be sent
+	CloseSend() error
+}
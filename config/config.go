@@ -12,7 +12,9 @@ type PitayaConfig struct {
 	Heartbeat struct {
 		Interval time.Duration
 	}
-	Handler struct {
+	Handshake  HandshakeConfig
+	Serializer SerializerConfig
+	Handler    struct {
 		Messages struct {
 			Compression bool
 		}
@@ -20,6 +22,7 @@ type PitayaConfig struct {
 	Buffer struct {
 		Agent struct {
 			Messages int
+			Freeze   PushFreezeConfig
 		}
 		Handler struct {
 			LocalProcess  int
@@ -29,22 +32,180 @@ type PitayaConfig struct {
 	Concurrency struct {
 		Handler struct {
 			Dispatch int
+			Model    string
+			Mailbox  MailboxConfig
 		}
 	}
 	Session struct {
 		Unique bool
+		Resume SessionResumeConfig
 	}
+	Drain   DrainConfig
+	Cluster ClusterConfig
 	Metrics struct {
 		Period time.Duration
 	}
 }
 
+const (
+	// ClusterBackendNats builds the etcd service discovery and the nats rpc
+	// client and server in cluster mode
+	ClusterBackendNats = "nats"
+	// ClusterBackendMemory builds in cluster mode the in-memory service
+	// discovery and rpc client and server, connecting the servers built in
+	// the same process
+	ClusterBackendMemory = "memory"
+)
+
+// ClusterConfig provides configuration for the cluster components built by
+// the Builder in cluster mode
+type ClusterConfig struct {
+	Backend string
+	RPC     struct {
+		Client struct {
+			Memory MemoryRPCClientConfig
+		}
+	}
+}
+
+// NewDefaultClusterConfig returns the default cluster configuration
+func NewDefaultClusterConfig() *ClusterConfig {
+	conf := &ClusterConfig{
+		Backend: ClusterBackendNats,
+	}
+	conf.RPC.Client.Memory = *NewDefaultMemoryRPCClientConfig()
+	return conf
+}
+
+// DrainConfig provides configuration for draining the server on shutdown,
+// other servers stop sending new work to it and frontends stop accepting
+// connections while the work in flight finishes
+type DrainConfig struct {
+	Enabled       bool
+	Delay         time.Duration
+	Timeout       time.Duration
+	Reconnect     bool
+	ReconnectAddr string
+}
+
+// NewDefaultDrainConfig returns the default drain configuration
+func NewDefaultDrainConfig() *DrainConfig {
+	return &DrainConfig{
+		Enabled:       false,
+		Delay:         time.Duration(1 * time.Second),
+		Timeout:       time.Duration(30 * time.Second),
+		Reconnect:     false,
+		ReconnectAddr: "",
+	}
+}
+
+// HandshakeConfig provides configuration for the settings negotiated with the
+// clients at handshake, Dictionary is the path of a zstd dictionary used by
+// the zstd-dict compression and sent to the clients that negotiate it
+type HandshakeConfig struct {
+	MinVersion   int
+	Compressions []string
+	Dictionary   string
+}
+
+// NewDefaultHandshakeConfig returns the default handshake configuration
+func NewDefaultHandshakeConfig() *HandshakeConfig {
+	return &HandshakeConfig{
+		MinVersion:   0,
+		Compressions: []string{"deflate", "none"},
+		Dictionary:   "",
+	}
+}
+
+// SerializerConfig provides configuration for the serializers of the client
+// messages, Name is used by the clients that don't negotiate one at handshake
+type SerializerConfig struct {
+	Name       string
+	Negotiable []string
+}
+
+// NewDefaultSerializerConfig returns the default serializer configuration
+func NewDefaultSerializerConfig() *SerializerConfig {
+	return &SerializerConfig{
+		Name:       "json",
+		Negotiable: []string{},
+	}
+}
+
+// SessionResumeConfig provides configuration for resuming sessions of clients
+// that reconnect after losing their connection
+type SessionResumeConfig struct {
+	Enabled     bool
+	GracePeriod time.Duration
+	MaxPushes   int
+}
+
+const (
+	// ConcurrencySharded processes the messages of a session on one of the
+	// handler dispatch goroutines, chosen by the session id
+	ConcurrencySharded = "sharded"
+	// ConcurrencyActor processes the messages of each session, or of each entity
+	// chosen by the mailbox key, on its own goroutine
+	ConcurrencyActor = "actor"
+)
+
+// MailboxConfig provides configuration for the mailboxes of the actor
+// concurrency model
+type MailboxConfig struct {
+	Size        int
+	IdleTimeout time.Duration
+}
+
+// NewDefaultMailboxConfig returns the default mailbox configuration
+func NewDefaultMailboxConfig() *MailboxConfig {
+	return &MailboxConfig{
+		Size:        20,
+		IdleTimeout: time.Duration(time.Minute),
+	}
+}
+
+const (
+	// PushFreezeRelease sends the frozen pushes to the client when a limit is reached
+	PushFreezeRelease = "release"
+	// PushFreezeDrop discards the frozen pushes when a limit is reached
+	PushFreezeDrop = "drop"
+)
+
+// PushFreezeConfig provides configuration for the pushes an agent holds
+// until the response of the request they are related to is sent, a zero
+// limit disables it
+type PushFreezeConfig struct {
+	MaxAge   time.Duration
+	MaxCount int
+	Policy   string
+}
+
+// NewDefaultPushFreezeConfig returns the default push freeze configuration
+func NewDefaultPushFreezeConfig() *PushFreezeConfig {
+	return &PushFreezeConfig{
+		MaxAge:   time.Duration(30 * time.Second),
+		MaxCount: 500,
+		Policy:   PushFreezeRelease,
+	}
+}
+
+// NewDefaultSessionResumeConfig returns the default session resume configuration
+func NewDefaultSessionResumeConfig() *SessionResumeConfig {
+	return &SessionResumeConfig{
+		Enabled:     false,
+		GracePeriod: time.Duration(30 * time.Second),
+		MaxPushes:   100,
+	}
+}
+
 // NewDefaultPitayaConfig provides default configuration for Pitaya App
 func NewDefaultPitayaConfig() *PitayaConfig {
 	return &PitayaConfig{
 		Heartbeat: struct{ Interval time.Duration }{
 			Interval: time.Duration(30 * time.Second),
 		},
+		Handshake:  *NewDefaultHandshakeConfig(),
+		Serializer: *NewDefaultSerializerConfig(),
 		Handler: struct {
 			Messages struct {
 				Compression bool
@@ -59,6 +220,7 @@ func NewDefaultPitayaConfig() *PitayaConfig {
 		Buffer: struct {
 			Agent struct {
 				Messages int
+				Freeze   PushFreezeConfig
 			}
 			Handler struct {
 				LocalProcess  int
@@ -67,8 +229,10 @@ func NewDefaultPitayaConfig() *PitayaConfig {
 		}{
 			Agent: struct {
 				Messages int
+				Freeze   PushFreezeConfig
 			}{
 				Messages: 100,
+				Freeze:   *NewDefaultPushFreezeConfig(),
 			},
 			Handler: struct {
 				LocalProcess  int
@@ -81,19 +245,29 @@ func NewDefaultPitayaConfig() *PitayaConfig {
 		Concurrency: struct {
 			Handler struct {
 				Dispatch int
+				Model    string
+				Mailbox  MailboxConfig
 			}
 		}{
 			Handler: struct {
 				Dispatch int
+				Model    string
+				Mailbox  MailboxConfig
 			}{
 				Dispatch: 25,
+				Model:    ConcurrencySharded,
+				Mailbox:  *NewDefaultMailboxConfig(),
 			},
 		},
 		Session: struct {
 			Unique bool
+			Resume SessionResumeConfig
 		}{
 			Unique: true,
+			Resume: *NewDefaultSessionResumeConfig(),
 		},
+		Drain:   *NewDefaultDrainConfig(),
+		Cluster: *NewDefaultClusterConfig(),
 		Metrics: struct {
 			Period time.Duration
 		}{
@@ -121,6 +295,11 @@ type BuilderConfig struct {
 		Statsd struct {
 			Enabled bool
 		}
+		OTLP struct {
+			Enabled bool
+			// Reporter is read from pitaya.metrics by NewBuilderWithConfigs
+			Reporter OTLPConfig
+		}
 	}
 	DefaultPipelines struct {
 		StructValidation struct {
@@ -140,6 +319,10 @@ func NewDefaultBuilderConfig() *BuilderConfig {
 			Statsd struct {
 				Enabled bool
 			}
+			OTLP struct {
+				Enabled  bool
+				Reporter OTLPConfig
+			}
 		}{
 			Prometheus: struct {
 				Enabled bool
@@ -151,6 +334,13 @@ func NewDefaultBuilderConfig() *BuilderConfig {
 			}{
 				Enabled: false,
 			},
+			OTLP: struct {
+				Enabled  bool
+				Reporter OTLPConfig
+			}{
+				Enabled:  false,
+				Reporter: *NewDefaultOTLPConfig(),
+			},
 		},
 		DefaultPipelines: struct {
 			StructValidation struct {
@@ -203,20 +393,28 @@ func NewGRPCClientConfig(config *Config) *GRPCClientConfig {
 
 // GRPCServerConfig provides configuration for GRPCServer
 type GRPCServerConfig struct {
-	Port int
+	Port           int
+	SessionOrdered bool
+	// Services is the number of goroutines processing the calls of sessions
+	// when SessionOrdered is set
+	Services int
 }
 
 // NewDefaultGRPCServerConfig returns a default GRPCServerConfig
 func NewDefaultGRPCServerConfig() *GRPCServerConfig {
 	return &GRPCServerConfig{
-		Port: 3434,
+		Port:           3434,
+		SessionOrdered: true,
+		Services:       30,
 	}
 }
 
 // NewGRPCServerConfig reads from config to build GRPCServerConfig
 func NewGRPCServerConfig(config *Config) *GRPCServerConfig {
 	return &GRPCServerConfig{
-		Port: config.GetInt("pitaya.cluster.rpc.server.grpc.port"),
+		Port:           config.GetInt("pitaya.cluster.rpc.server.grpc.port"),
+		SessionOrdered: config.GetBool("pitaya.cluster.rpc.server.grpc.sessionordered"),
+		Services:       config.GetInt("pitaya.cluster.rpc.server.grpc.services"),
 	}
 }
 
@@ -247,6 +445,28 @@ func NewNatsRPCClientConfig(config *Config) *NatsRPCClientConfig {
 	return conf
 }
 
+// MemoryRPCClientConfig provides the configuration of the rpc client of
+// servers running in the same process
+type MemoryRPCClientConfig struct {
+	RequestTimeout time.Duration
+}
+
+// NewDefaultMemoryRPCClientConfig provides default memory rpc client configuration
+func NewDefaultMemoryRPCClientConfig() *MemoryRPCClientConfig {
+	return &MemoryRPCClientConfig{
+		RequestTimeout: time.Duration(5 * time.Second),
+	}
+}
+
+// NewMemoryRPCClientConfig reads from config to build memory rpc client configuration
+func NewMemoryRPCClientConfig(config *Config) *MemoryRPCClientConfig {
+	conf := NewDefaultMemoryRPCClientConfig()
+	if err := config.UnmarshalKey("pitaya.cluster.rpc.client.memory", &conf); err != nil {
+		panic(err)
+	}
+	return conf
+}
+
 // NatsRPCServerConfig provides nats server configuration
 type NatsRPCServerConfig struct {
 	Connect                string
@@ -392,9 +612,10 @@ func NewEtcdServiceDiscoveryConfig(config *Config) *EtcdServiceDiscoveryConfig {
 // NewDefaultCustomMetricsSpec returns an empty *CustomMetricsSpec
 func NewDefaultCustomMetricsSpec() *models.CustomMetricsSpec {
 	return &models.CustomMetricsSpec{
-		Summaries: []*models.Summary{},
-		Gauges:    []*models.Gauge{},
-		Counters:  []*models.Counter{},
+		Summaries:  []*models.Summary{},
+		Histograms: []*models.Histogram{},
+		Gauges:     []*models.Gauge{},
+		Counters:   []*models.Counter{},
 	}
 }
 
@@ -409,6 +630,23 @@ func NewCustomMetricsSpec(config *Config) *models.CustomMetricsSpec {
 	return spec
 }
 
+// HistogramsConfig provides configuration for the histogram metrics, when
+// Latency is set the built-in latency metrics are reported as histograms
+// instead of summaries, so that they can be aggregated among servers
+type HistogramsConfig struct {
+	Latency bool
+	Buckets []float64
+}
+
+// NewDefaultHistogramsConfig provides default configuration for the histogram
+// metrics, the buckets range from 1ms to 10s in nanoseconds
+func NewDefaultHistogramsConfig() *HistogramsConfig {
+	return &HistogramsConfig{
+		Latency: false,
+		Buckets: []float64{1e6, 2.5e6, 5e6, 1e7, 2.5e7, 5e7, 1e8, 2.5e8, 5e8, 1e9, 2.5e9, 5e9, 1e10},
+	}
+}
+
 // PrometheusConfig provides configuration for PrometheusReporter
 type PrometheusConfig struct {
 	Prometheus struct {
@@ -417,6 +655,7 @@ type PrometheusConfig struct {
 	}
 	Game        string
 	ConstLabels map[string]string
+	Histograms  HistogramsConfig
 }
 
 // NewDefaultPrometheusConfig provides default configuration for PrometheusReporter
@@ -430,6 +669,7 @@ func NewDefaultPrometheusConfig() *PrometheusConfig {
 			AdditionalLabels: map[string]string{},
 		},
 		ConstLabels: map[string]string{},
+		Histograms:  *NewDefaultHistogramsConfig(),
 	}
 }
 
@@ -450,6 +690,7 @@ type StatsdConfig struct {
 		Rate   float64
 	}
 	ConstLabels map[string]string
+	Histograms  HistogramsConfig
 }
 
 // NewDefaultStatsdConfig provides default configuration for statsd
@@ -465,6 +706,7 @@ func NewDefaultStatsdConfig() *StatsdConfig {
 			Rate:   1,
 		},
 		ConstLabels: map[string]string{},
+		Histograms:  *NewDefaultHistogramsConfig(),
 	}
 }
 
@@ -477,6 +719,43 @@ func NewStatsdConfig(config *Config) *StatsdConfig {
 	return conf
 }
 
+// OTLPConfig provides configuration for the OTLP metrics reporter
+type OTLPConfig struct {
+	OTLP struct {
+		Endpoint string
+		Insecure bool
+		Period   time.Duration
+	}
+	ConstLabels map[string]string
+	Histograms  HistogramsConfig
+}
+
+// NewDefaultOTLPConfig provides default configuration for the OTLP metrics reporter
+func NewDefaultOTLPConfig() *OTLPConfig {
+	return &OTLPConfig{
+		OTLP: struct {
+			Endpoint string
+			Insecure bool
+			Period   time.Duration
+		}{
+			Endpoint: "localhost:4317",
+			Insecure: false,
+			Period:   15 * time.Second,
+		},
+		ConstLabels: map[string]string{},
+		Histograms:  *NewDefaultHistogramsConfig(),
+	}
+}
+
+// NewOTLPConfig reads from config to build configuration for the OTLP metrics reporter
+func NewOTLPConfig(config *Config) *OTLPConfig {
+	conf := NewDefaultOTLPConfig()
+	if err := config.UnmarshalKey("pitaya.metrics", &conf); err != nil {
+		panic(err)
+	}
+	return conf
+}
+
 // WorkerConfig provides worker configuration
 type WorkerConfig struct {
 	Redis struct {
@@ -486,6 +765,11 @@ type WorkerConfig struct {
 	}
 	Namespace   string
 	Concurrency int
+	// Backend is where the jobs are stored: redis, file or memory
+	Backend string
+	File    struct {
+		Path string
+	}
 }
 
 // NewDefaultWorkerConfig provides worker default configuration
@@ -500,6 +784,12 @@ func NewDefaultWorkerConfig() *WorkerConfig {
 			Pool:      "10",
 		},
 		Concurrency: 1,
+		Backend:     "redis",
+		File: struct {
+			Path string
+		}{
+			Path: "pitaya-worker.log",
+		},
 	}
 }
 
@@ -640,3 +930,28 @@ func NewRateLimitingConfig(config *Config) *RateLimitingConfig {
 	}
 	return conf
 }
+
+// EncryptionConfig encrypted connections config, when Required is false the
+// clients that don't exchange keys use plain connections, KeyFile is the PEM
+// encoded ed25519 key signing the key exchanges
+type EncryptionConfig struct {
+	Required bool
+	KeyFile  string
+}
+
+// NewDefaultEncryptionConfig encrypted connections default config
+func NewDefaultEncryptionConfig() *EncryptionConfig {
+	return &EncryptionConfig{
+		Required: false,
+		KeyFile:  "",
+	}
+}
+
+// NewEncryptionConfig reads from config to build encrypted connections configuration
+func NewEncryptionConfig(config *Config) *EncryptionConfig {
+	conf := NewDefaultEncryptionConfig()
+	if err := config.UnmarshalKey("pitaya.conn.encryption", &conf); err != nil {
+		panic(err)
+	}
+	return conf
+}
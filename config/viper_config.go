@@ -54,9 +54,11 @@ func (c *Config) fillDefaultValues() {
 	pitayaConfig := NewDefaultPitayaConfig()
 	prometheusConfig := NewDefaultPrometheusConfig()
 	statsdConfig := NewDefaultStatsdConfig()
+	otlpConfig := NewDefaultOTLPConfig()
 	etcdSDConfig := NewDefaultEtcdServiceDiscoveryConfig()
 	natsRPCServerConfig := NewDefaultNatsRPCServerConfig()
 	natsRPCClientConfig := NewDefaultNatsRPCClientConfig()
+	memoryRPCClientConfig := NewDefaultMemoryRPCClientConfig()
 	grpcRPCClientConfig := NewDefaultGRPCClientConfig()
 	grpcRPCServerConfig := NewDefaultGRPCServerConfig()
 	workerConfig := NewDefaultWorkerConfig()
@@ -64,23 +66,31 @@ func (c *Config) fillDefaultValues() {
 	groupServiceConfig := NewDefaultMemoryGroupConfig()
 	etcdGroupServiceConfig := NewDefaultEtcdGroupServiceConfig()
 	rateLimitingConfig := NewDefaultRateLimitingConfig()
+	encryptionConfig := NewDefaultEncryptionConfig()
 	infoRetrieverConfig := NewDefaultInfoRetrieverConfig()
 	etcdBindingConfig := NewDefaultETCDBindingConfig()
 
 	defaultsMap := map[string]interface{}{
-		"pitaya.buffer.agent.messages": pitayaConfig.Buffer.Agent.Messages,
+		"pitaya.buffer.agent.messages":        pitayaConfig.Buffer.Agent.Messages,
+		"pitaya.buffer.agent.freeze.maxage":   pitayaConfig.Buffer.Agent.Freeze.MaxAge,
+		"pitaya.buffer.agent.freeze.maxcount": pitayaConfig.Buffer.Agent.Freeze.MaxCount,
+		"pitaya.buffer.agent.freeze.policy":   pitayaConfig.Buffer.Agent.Freeze.Policy,
 		// the max buffer size that nats will accept, if this buffer overflows, messages will begin to be dropped
 		"pitaya.buffer.handler.localprocess":                    pitayaConfig.Buffer.Handler.LocalProcess,
 		"pitaya.buffer.handler.remoteprocess":                   pitayaConfig.Buffer.Handler.RemoteProcess,
+		"pitaya.cluster.backend":                                pitayaConfig.Cluster.Backend,
 		"pitaya.cluster.info.region":                            infoRetrieverConfig.Region,
 		"pitaya.cluster.rpc.client.grpc.dialtimeout":            grpcRPCClientConfig.DialTimeout,
 		"pitaya.cluster.rpc.client.grpc.requesttimeout":         grpcRPCClientConfig.RequestTimeout,
 		"pitaya.cluster.rpc.client.grpc.lazyconnection":         grpcRPCClientConfig.LazyConnection,
+		"pitaya.cluster.rpc.client.memory.requesttimeout":       memoryRPCClientConfig.RequestTimeout,
 		"pitaya.cluster.rpc.client.nats.connect":                natsRPCClientConfig.Connect,
 		"pitaya.cluster.rpc.client.nats.connectiontimeout":      natsRPCClientConfig.ConnectionTimeout,
 		"pitaya.cluster.rpc.client.nats.maxreconnectionretries": natsRPCClientConfig.MaxReconnectionRetries,
 		"pitaya.cluster.rpc.client.nats.requesttimeout":         natsRPCClientConfig.RequestTimeout,
 		"pitaya.cluster.rpc.server.grpc.port":                   grpcRPCServerConfig.Port,
+		"pitaya.cluster.rpc.server.grpc.services":               grpcRPCServerConfig.Services,
+		"pitaya.cluster.rpc.server.grpc.sessionordered":         grpcRPCServerConfig.SessionOrdered,
 		"pitaya.cluster.rpc.server.nats.connect":                natsRPCServerConfig.Connect,
 		"pitaya.cluster.rpc.server.nats.connectiontimeout":      natsRPCServerConfig.ConnectionTimeout,
 		"pitaya.cluster.rpc.server.nats.maxreconnectionretries": natsRPCServerConfig.MaxReconnectionRetries,
@@ -105,6 +115,9 @@ func (c *Config) fillDefaultValues() {
 		// a single backend server should have the config pitaya.buffer.cluster.rpc.server.nats.messages bigger
 		// than the sum of the config pitaya.concurrency.handler.dispatch among all frontend servers
 		"pitaya.concurrency.handler.dispatch":              pitayaConfig.Concurrency.Handler.Dispatch,
+		"pitaya.concurrency.handler.model":                 pitayaConfig.Concurrency.Handler.Model,
+		"pitaya.concurrency.handler.mailbox.size":          pitayaConfig.Concurrency.Handler.Mailbox.Size,
+		"pitaya.concurrency.handler.mailbox.idletimeout":   pitayaConfig.Concurrency.Handler.Mailbox.IdleTimeout,
 		"pitaya.defaultpipelines.structvalidation.enabled": builderConfig.DefaultPipelines.StructValidation.Enabled,
 		"pitaya.groups.etcd.dialtimeout":                   etcdGroupServiceConfig.DialTimeout,
 		"pitaya.groups.etcd.endpoints":                     etcdGroupServiceConfig.Endpoints,
@@ -112,10 +125,15 @@ func (c *Config) fillDefaultValues() {
 		"pitaya.groups.etcd.transactiontimeout":            etcdGroupServiceConfig.TransactionTimeout,
 		"pitaya.groups.memory.tickduration":                groupServiceConfig.TickDuration,
 		"pitaya.handler.messages.compression":              pitayaConfig.Handler.Messages.Compression,
+		"pitaya.handshake.compressions":                    pitayaConfig.Handshake.Compressions,
+		"pitaya.handshake.dictionary":                      pitayaConfig.Handshake.Dictionary,
+		"pitaya.handshake.minversion":                      pitayaConfig.Handshake.MinVersion,
 		"pitaya.heartbeat.interval":                        pitayaConfig.Heartbeat.Interval,
 		"pitaya.metrics.prometheus.additionalTags":         prometheusConfig.Prometheus.AdditionalLabels,
 		"pitaya.metrics.constTags":                         prometheusConfig.ConstLabels,
 		"pitaya.metrics.custom":                            customMetricsSpec,
+		"pitaya.metrics.histograms.buckets":                prometheusConfig.Histograms.Buckets,
+		"pitaya.metrics.histograms.latency":                prometheusConfig.Histograms.Latency,
 		"pitaya.metrics.periodicMetrics.period":            pitayaConfig.Metrics.Period,
 		"pitaya.metrics.prometheus.enabled":                builderConfig.Metrics.Prometheus.Enabled,
 		"pitaya.metrics.prometheus.port":                   prometheusConfig.Prometheus.Port,
@@ -123,6 +141,10 @@ func (c *Config) fillDefaultValues() {
 		"pitaya.metrics.statsd.host":                       statsdConfig.Statsd.Host,
 		"pitaya.metrics.statsd.prefix":                     statsdConfig.Statsd.Prefix,
 		"pitaya.metrics.statsd.rate":                       statsdConfig.Statsd.Rate,
+		"pitaya.metrics.otlp.enabled":                      builderConfig.Metrics.OTLP.Enabled,
+		"pitaya.metrics.otlp.endpoint":                     otlpConfig.OTLP.Endpoint,
+		"pitaya.metrics.otlp.insecure":                     otlpConfig.OTLP.Insecure,
+		"pitaya.metrics.otlp.period":                       otlpConfig.OTLP.Period,
 		"pitaya.modules.bindingstorage.etcd.dialtimeout":   etcdBindingConfig.DialTimeout,
 		"pitaya.modules.bindingstorage.etcd.endpoints":     etcdBindingConfig.Endpoints,
 		"pitaya.modules.bindingstorage.etcd.leasettl":      etcdBindingConfig.LeaseTTL,
@@ -130,8 +152,22 @@ func (c *Config) fillDefaultValues() {
 		"pitaya.conn.ratelimiting.limit":                   rateLimitingConfig.Limit,
 		"pitaya.conn.ratelimiting.interval":                rateLimitingConfig.Interval,
 		"pitaya.conn.ratelimiting.forcedisable":            rateLimitingConfig.ForceDisable,
+		"pitaya.conn.encryption.required":                  encryptionConfig.Required,
+		"pitaya.conn.encryption.keyfile":                   encryptionConfig.KeyFile,
+		"pitaya.serializer.name":                           pitayaConfig.Serializer.Name,
+		"pitaya.serializer.negotiable":                     pitayaConfig.Serializer.Negotiable,
 		"pitaya.session.unique":                            pitayaConfig.Session.Unique,
+		"pitaya.session.resume.enabled":                    pitayaConfig.Session.Resume.Enabled,
+		"pitaya.session.resume.graceperiod":                pitayaConfig.Session.Resume.GracePeriod,
+		"pitaya.session.resume.maxpushes":                  pitayaConfig.Session.Resume.MaxPushes,
+		"pitaya.drain.enabled":                             pitayaConfig.Drain.Enabled,
+		"pitaya.drain.delay":                               pitayaConfig.Drain.Delay,
+		"pitaya.drain.timeout":                             pitayaConfig.Drain.Timeout,
+		"pitaya.drain.reconnect":                           pitayaConfig.Drain.Reconnect,
+		"pitaya.drain.reconnectaddr":                       pitayaConfig.Drain.ReconnectAddr,
+		"pitaya.worker.backend":                            workerConfig.Backend,
 		"pitaya.worker.concurrency":                        workerConfig.Concurrency,
+		"pitaya.worker.file.path":                          workerConfig.File.Path,
 		"pitaya.worker.redis.pool":                         workerConfig.Redis.Pool,
 		"pitaya.worker.redis.url":                          workerConfig.Redis.ServerURL,
 		"pitaya.worker.retry.enabled":                      enqueueOpts.Enabled,
@@ -0,0 +1,232 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package codec
+
+import (
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Errors of the encrypted packets
+var (
+	ErrInvalidPublicKey = errors.New("codec: invalid public key")
+	ErrInvalidServerKey = errors.New("codec: invalid ed25519 server key")
+	ErrInvalidSignature = errors.New("codec: key exchange not signed by the server key")
+	ErrPacketSequence   = errors.New("codec: packet out of sequence, replayed or dropped")
+	ErrPacketTampered   = errors.New("codec: packet failed authentication")
+)
+
+// sequenceLength is the size of the sequence number sent before the encrypted data
+const sequenceLength = 8
+
+// signatureContext is signed along with the public keys of a key exchange
+const signatureContext = "pitaya key exchange"
+
+// KeyExchange holds the X25519 key pair of one end of a connection, the ends
+// send their public keys to each other and derive the same packet keys
+type KeyExchange struct {
+	privateKey []byte
+	publicKey  []byte
+}
+
+// NewKeyExchange returns a key exchange with a new random key pair
+func NewKeyExchange() (*KeyExchange, error) {
+	privateKey := make([]byte, curve25519.ScalarSize)
+	if _, err := io.ReadFull(rand.Reader, privateKey); err != nil {
+		return nil, err
+	}
+	publicKey, err := curve25519.X25519(privateKey, curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyExchange{privateKey: privateKey, publicKey: publicKey}, nil
+}
+
+// PublicKey returns the public key sent to the other end
+func (k *KeyExchange) PublicKey() []byte {
+	return k.publicKey
+}
+
+// SignedPublicKey returns the public key followed by its signature with the
+// static key of the server, the signature binds it to the public key of the
+// client so that clients pinning the server key can't be sent another one
+func (k *KeyExchange) SignedPublicKey(clientPublicKey []byte, serverKey ed25519.PrivateKey) []byte {
+	signature := ed25519.Sign(serverKey, signedKeys(clientPublicKey, k.publicKey))
+	return append(append([]byte{}, k.publicKey...), signature...)
+}
+
+// VerifySignedPublicKey checks that the public key sent by the server was
+// signed with the static key of the server and returns it
+func (k *KeyExchange) VerifySignedPublicKey(signed []byte, serverKey ed25519.PublicKey) ([]byte, error) {
+	if len(signed) != curve25519.PointSize+ed25519.SignatureSize {
+		return nil, ErrInvalidSignature
+	}
+	publicKey := signed[:curve25519.PointSize]
+	if !ed25519.Verify(serverKey, signedKeys(k.publicKey, publicKey), signed[curve25519.PointSize:]) {
+		return nil, ErrInvalidSignature
+	}
+	return publicKey, nil
+}
+
+func signedKeys(clientPublicKey, serverPublicKey []byte) []byte {
+	signed := append([]byte(signatureContext), clientPublicKey...)
+	return append(signed, serverPublicKey...)
+}
+
+// ParseServerKey parses the static ed25519 key of the server from a PEM
+// encoded PKCS #8 private key, such as the ones generated by
+// openssl genpkey -algorithm ed25519
+func ParseServerKey(data []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrInvalidServerKey
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidServerKey, err.Error())
+	}
+	serverKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, ErrInvalidServerKey
+	}
+	return serverKey, nil
+}
+
+// ParseServerPublicKey parses the public ed25519 key of the server pinned by
+// the clients from a PEM encoded PKIX public key, such as the ones generated
+// by openssl pkey -pubout
+func ParseServerPublicKey(data []byte) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrInvalidServerKey
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidServerKey, err.Error())
+	}
+	serverKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, ErrInvalidServerKey
+	}
+	return serverKey, nil
+}
+
+// Cipher returns the cipher of the packets exchanged with the owner of
+// peerPublicKey, isServer tells which end of the connection k is
+func (k *KeyExchange) Cipher(peerPublicKey []byte, isServer bool) (*PacketCipher, error) {
+	if len(peerPublicKey) != curve25519.PointSize {
+		return nil, ErrInvalidPublicKey
+	}
+	secret, err := curve25519.X25519(k.privateKey, peerPublicKey)
+	if err != nil {
+		// low order points would give away the secret
+		return nil, ErrInvalidPublicKey
+	}
+
+	clientKey, serverKey := k.publicKey, peerPublicKey
+	if isServer {
+		clientKey, serverKey = peerPublicKey, k.publicKey
+	}
+	salt := append(append([]byte{}, clientKey...), serverKey...)
+	clientAEAD, err := deriveAEAD(secret, salt, "pitaya client packets")
+	if err != nil {
+		return nil, err
+	}
+	serverAEAD, err := deriveAEAD(secret, salt, "pitaya server packets")
+	if err != nil {
+		return nil, err
+	}
+
+	if isServer {
+		return &PacketCipher{send: serverAEAD, recv: clientAEAD}, nil
+	}
+	return &PacketCipher{send: clientAEAD, recv: serverAEAD}, nil
+}
+
+func deriveAEAD(secret, salt []byte, info string) (cipher.AEAD, error) {
+	key := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, []byte(info)), key); err != nil {
+		return nil, err
+	}
+	return chacha20poly1305.New(key)
+}
+
+// PacketCipher encrypts and authenticates the data of the packets of a
+// connection with ChaCha20-Poly1305. Each direction has its own key and
+// sequence numbers, a packet is only accepted with the sequence number
+// following the one of the last accepted packet, so that packets can't be
+// replayed, dropped or reordered.
+type PacketCipher struct {
+	sendMutex sync.Mutex
+	send      cipher.AEAD
+	sendSeq   uint64
+	recvMutex sync.Mutex
+	recv      cipher.AEAD
+	recvSeq   uint64
+}
+
+// Seal encrypts data, the result starts with the sequence number of the packet
+func (c *PacketCipher) Seal(data []byte) []byte {
+	c.sendMutex.Lock()
+	defer c.sendMutex.Unlock()
+	c.sendSeq++
+	out := make([]byte, sequenceLength, sequenceLength+len(data)+c.send.Overhead())
+	binary.BigEndian.PutUint64(out, c.sendSeq)
+	return c.send.Seal(out, nonce(c.sendSeq), data, out[:sequenceLength])
+}
+
+// Open decrypts data sealed by the other end of the connection
+func (c *PacketCipher) Open(data []byte) ([]byte, error) {
+	if len(data) < sequenceLength+c.recv.Overhead() {
+		return nil, ErrPacketTampered
+	}
+	c.recvMutex.Lock()
+	defer c.recvMutex.Unlock()
+	seq := binary.BigEndian.Uint64(data)
+	if seq != c.recvSeq+1 {
+		return nil, ErrPacketSequence
+	}
+	plain, err := c.recv.Open(nil, nonce(seq), data[sequenceLength:], data[:sequenceLength])
+	if err != nil {
+		return nil, ErrPacketTampered
+	}
+	c.recvSeq = seq
+	return plain, nil
+}
+
+func nonce(seq uint64) []byte {
+	n := make([]byte, chacha20poly1305.NonceSize)
+	binary.BigEndian.PutUint64(n[chacha20poly1305.NonceSize-sequenceLength:], seq)
+	return n
+}
@@ -0,0 +1,154 @@
+package codec
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newCipherPair(t *testing.T) (*PacketCipher, *PacketCipher) {
+	t.Helper()
+	clientKeys, err := NewKeyExchange()
+	assert.NoError(t, err)
+	serverKeys, err := NewKeyExchange()
+	assert.NoError(t, err)
+
+	client, err := clientKeys.Cipher(serverKeys.PublicKey(), false)
+	assert.NoError(t, err)
+	server, err := serverKeys.Cipher(clientKeys.PublicKey(), true)
+	assert.NoError(t, err)
+	return client, server
+}
+
+func TestPacketCipher(t *testing.T) {
+	t.Parallel()
+
+	client, server := newCipherPair(t)
+	for _, data := range []string{"hello", "", "world"} {
+		sealed := client.Seal([]byte(data))
+		opened, err := server.Open(sealed)
+		assert.NoError(t, err)
+		assert.Equal(t, data, string(opened))
+	}
+
+	// each direction has its own key
+	client, server = newCipherPair(t)
+	sealed := server.Seal([]byte("pong"))
+	_, err := server.Open(sealed)
+	assert.Equal(t, ErrPacketTampered, err)
+	opened, err := client.Open(sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("pong"), opened)
+}
+
+func TestPacketCipherReplay(t *testing.T) {
+	t.Parallel()
+
+	client, server := newCipherPair(t)
+	first := client.Seal([]byte("first"))
+	second := client.Seal([]byte("second"))
+
+	_, err := server.Open(second)
+	assert.Equal(t, ErrPacketSequence, err)
+	_, err = server.Open(first)
+	assert.NoError(t, err)
+	_, err = server.Open(first)
+	assert.Equal(t, ErrPacketSequence, err)
+	_, err = server.Open(second)
+	assert.NoError(t, err)
+}
+
+func TestPacketCipherTampered(t *testing.T) {
+	t.Parallel()
+
+	client, server := newCipherPair(t)
+	sealed := client.Seal([]byte("hello"))
+	sealed[len(sealed)-1] ^= 0x01
+	_, err := server.Open(sealed)
+	assert.Equal(t, ErrPacketTampered, err)
+
+	// the sequence number is authenticated too
+	sealed = client.Seal([]byte("hello"))
+	sealed[7] = 0x01
+	_, err = server.Open(sealed)
+	assert.Equal(t, ErrPacketTampered, err)
+
+	_, err = server.Open([]byte{0x01})
+	assert.Equal(t, ErrPacketTampered, err)
+}
+
+func TestKeyExchangeInvalidPublicKey(t *testing.T) {
+	t.Parallel()
+
+	keys, err := NewKeyExchange()
+	assert.NoError(t, err)
+	_, err = keys.Cipher([]byte{0x01, 0x02}, true)
+	assert.Equal(t, ErrInvalidPublicKey, err)
+	// low order point
+	_, err = keys.Cipher(make([]byte, 32), true)
+	assert.Equal(t, ErrInvalidPublicKey, err)
+}
+
+func TestKeyExchangeSignedPublicKey(t *testing.T) {
+	t.Parallel()
+
+	serverPub, serverKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	client, err := NewKeyExchange()
+	assert.NoError(t, err)
+	server, err := NewKeyExchange()
+	assert.NoError(t, err)
+
+	signed := server.SignedPublicKey(client.PublicKey(), serverKey)
+	pub, err := client.VerifySignedPublicKey(signed, serverPub)
+	assert.NoError(t, err)
+	assert.Equal(t, server.PublicKey(), pub)
+
+	// signed by another key
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	_, err = client.VerifySignedPublicKey(signed, otherPub)
+	assert.Equal(t, ErrInvalidSignature, err)
+
+	// signed for another client
+	other, err := NewKeyExchange()
+	assert.NoError(t, err)
+	_, err = other.VerifySignedPublicKey(signed, serverPub)
+	assert.Equal(t, ErrInvalidSignature, err)
+
+	// replaced public key
+	tampered := append(other.PublicKey(), signed[len(pub):]...)
+	_, err = client.VerifySignedPublicKey(tampered, serverPub)
+	assert.Equal(t, ErrInvalidSignature, err)
+
+	_, err = client.VerifySignedPublicKey(signed[:10], serverPub)
+	assert.Equal(t, ErrInvalidSignature, err)
+}
+
+func TestParseServerKey(t *testing.T) {
+	t.Parallel()
+
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+	parsed, err := ParseServerKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	assert.NoError(t, err)
+	assert.Equal(t, key, parsed)
+
+	der, err = x509.MarshalPKIXPublicKey(pub)
+	assert.NoError(t, err)
+	parsedPub, err := ParseServerPublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	assert.NoError(t, err)
+	assert.Equal(t, pub, parsedPub)
+
+	_, err = ParseServerKey([]byte("not a key"))
+	assert.Equal(t, ErrInvalidServerKey, err)
+	_, err = ParseServerPublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte{0x01}}))
+	assert.True(t, errors.Is(err, ErrInvalidServerKey))
+}
@@ -17,8 +17,10 @@ var forwardTables = map[string]struct {
 	"test_heartbeat_type":     {[]byte{packet.Heartbeat, 0x00, 0x00, 0x00}, nil},
 	"test_data_type":          {[]byte{packet.Data, 0x00, 0x00, 0x00}, nil},
 	"test_kick_type":          {[]byte{packet.Kick, 0x00, 0x00, 0x00}, nil},
+	"test_reconnect_type":     {[]byte{packet.Reconnect, 0x00, 0x00, 0x00}, nil},
+	"test_key_exchange_type":  {[]byte{packet.KeyExchange, 0x00, 0x00, 0x00}, nil},
 
-	"test_wrong_packet_type": {[]byte{0x06, 0x00, 0x00, 0x00}, packet.ErrWrongPomeloPacketType},
+	"test_wrong_packet_type": {[]byte{0x08, 0x00, 0x00, 0x00}, packet.ErrWrongPomeloPacketType},
 }
 
 var (
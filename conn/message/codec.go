@@ -0,0 +1,126 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package message
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/topfreegames/pitaya/v2/util/compression"
+)
+
+// Errors of the compression codecs
+var (
+	ErrInvalidCodecFlag = errors.New("invalid compression codec flag")
+	ErrUnknownCodec     = errors.New("message compressed with an unknown codec")
+	ErrUnexpectedCodec  = errors.New("message compressed with a codec other than the negotiated one")
+)
+
+// MaxDecompressedSize is the largest size the data of a message can be
+// decompressed to, the same as the largest packet
+const MaxDecompressedSize = 1 << 24
+
+// Flags of the message header telling the codec that compressed the data
+const (
+	DeflateFlag        byte = gzipMask
+	ZstdFlag           byte = zstdMask
+	ZstdDictionaryFlag byte = gzipMask | zstdMask
+)
+
+// Codec compresses the data of the messages, the flag of a codec is set in
+// the header of the messages it compresses so that they can be decompressed
+type Codec interface {
+	Name() string
+	Flag() byte
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+// DictionaryCodec is a codec that needs a dictionary shared with the clients,
+// the dictionary is sent to them at handshake
+type DictionaryCodec interface {
+	Codec
+	Dictionary() []byte
+	DictionaryID() uint32
+}
+
+var (
+	codecsMutex  = sync.RWMutex{}
+	codecs       = map[string]Codec{}
+	codecsByFlag = map[byte]Codec{}
+)
+
+func init() {
+	RegisterCodec(deflateCodec{})
+	RegisterCodec(newZstdCodec())
+}
+
+// RegisterCodec makes a codec available for the connections, replacing the
+// codec registered with the same name or flag
+func RegisterCodec(codec Codec) error {
+	flag := codec.Flag()
+	if flag == 0 || flag&^compressionMask != 0 {
+		return fmt.Errorf("%w: %#x", ErrInvalidCodecFlag, flag)
+	}
+	codecsMutex.Lock()
+	defer codecsMutex.Unlock()
+	if old, ok := codecsByFlag[flag]; ok {
+		delete(codecs, old.Name())
+	}
+	if old, ok := codecs[codec.Name()]; ok {
+		delete(codecsByFlag, old.Flag())
+	}
+	codecs[codec.Name()] = codec
+	codecsByFlag[flag] = codec
+	return nil
+}
+
+// GetCodec returns the codec registered with the name, nil if there is none
+func GetCodec(name string) Codec {
+	codecsMutex.RLock()
+	defer codecsMutex.RUnlock()
+	return codecs[name]
+}
+
+func getCodecByFlag(flag byte) Codec {
+	codecsMutex.RLock()
+	defer codecsMutex.RUnlock()
+	return codecsByFlag[flag]
+}
+
+type deflateCodec struct{}
+
+func (deflateCodec) Name() string {
+	return CompressionDeflate
+}
+
+func (deflateCodec) Flag() byte {
+	return DeflateFlag
+}
+
+func (deflateCodec) Compress(data []byte) ([]byte, error) {
+	return compression.DeflateData(data)
+}
+
+func (deflateCodec) Decompress(data []byte) ([]byte, error) {
+	return compression.InflateDataWithLimit(data, MaxDecompressedSize)
+}
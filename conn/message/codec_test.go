@@ -0,0 +1,160 @@
+package message
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var codecPayload = bytes.Repeat([]byte(`{"player":{"name":"alice","level":10},"message":"hello"}`), 4)
+
+func loadZstdDictionary(t *testing.T) []byte {
+	t.Helper()
+	dictionary, err := ioutil.ReadFile("fixtures/zstd.dict")
+	assert.NoError(t, err)
+	return dictionary
+}
+
+func TestCodecs(t *testing.T) {
+	codec, err := NewZstdDictionaryCodec(loadZstdDictionary(t))
+	assert.NoError(t, err)
+
+	tables := []struct {
+		name  string
+		codec Codec
+		flag  byte
+	}{
+		{"deflate", GetCodec(CompressionDeflate), DeflateFlag},
+		{"zstd", GetCodec(CompressionZstd), ZstdFlag},
+		{"zstd-dict", codec, ZstdDictionaryFlag},
+	}
+	for _, table := range tables {
+		t.Run(table.name, func(t *testing.T) {
+			assert.Equal(t, table.name, table.codec.Name())
+			assert.Equal(t, table.flag, table.codec.Flag())
+
+			compressed, err := table.codec.Compress(codecPayload)
+			assert.NoError(t, err)
+			assert.Less(t, len(compressed), len(codecPayload))
+
+			data, err := table.codec.Decompress(compressed)
+			assert.NoError(t, err)
+			assert.Equal(t, codecPayload, data)
+		})
+	}
+}
+
+func TestZstdDictionaryCodec(t *testing.T) {
+	dictionary := loadZstdDictionary(t)
+	codec, err := NewZstdDictionaryCodec(dictionary)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(7), codec.DictionaryID())
+	assert.Equal(t, dictionary, codec.Dictionary())
+
+	// small messages compress better with the dictionary
+	msg := []byte(`{"code":200,"player":{"id":"player-42","name":"alice","level":12},"message":"hello"}`)
+	withDictionary, err := codec.Compress(msg)
+	assert.NoError(t, err)
+	withoutDictionary, err := GetCodec(CompressionZstd).Compress(msg)
+	assert.NoError(t, err)
+	assert.Less(t, len(withDictionary), len(withoutDictionary))
+
+	_, err = NewZstdDictionaryCodec([]byte("not a dictionary"))
+	assert.Equal(t, ErrInvalidZstdDictionary, err)
+}
+
+func TestRegisterCodec(t *testing.T) {
+	codec, err := NewZstdDictionaryCodec(loadZstdDictionary(t))
+	assert.NoError(t, err)
+
+	assert.False(t, IsCompressionSupported(CompressionZstdDictionary))
+	assert.NoError(t, RegisterCodec(codec))
+	defer func() {
+		codecsMutex.Lock()
+		defer codecsMutex.Unlock()
+		delete(codecs, CompressionZstdDictionary)
+		delete(codecsByFlag, ZstdDictionaryFlag)
+	}()
+	assert.True(t, IsCompressionSupported(CompressionZstdDictionary))
+	assert.Equal(t, codec, GetCodec(CompressionZstdDictionary))
+
+	err = RegisterCodec(&zstdCodec{name: "invalid", flag: 0x01})
+	assert.ErrorIs(t, err, ErrInvalidCodecFlag)
+	assert.Nil(t, GetCodec("invalid"))
+}
+
+func TestEncodeDecodeWithCodec(t *testing.T) {
+	for _, name := range []string{CompressionDeflate, CompressionZstd} {
+		t.Run(name, func(t *testing.T) {
+			encoder := NewMessagesEncoderWithCodec(GetCodec(name))
+			assert.True(t, encoder.IsCompressionEnabled())
+			assert.Equal(t, name, encoder.Compression())
+
+			data, err := encoder.Encode(&Message{Type: Push, Route: "room.room.message", Data: codecPayload})
+			assert.NoError(t, err)
+			assert.Equal(t, GetCodec(name).Flag(), data[0]&compressionMask)
+
+			m, err := Decode(data)
+			assert.NoError(t, err)
+			assert.Equal(t, "room.room.message", m.Route)
+			assert.Equal(t, codecPayload, m.Data)
+		})
+	}
+
+	encoder := NewMessagesEncoderWithCodec(nil)
+	assert.False(t, encoder.IsCompressionEnabled())
+	assert.Equal(t, CompressionNone, encoder.Compression())
+}
+
+func TestDecodeUnknownCodec(t *testing.T) {
+	// flag of a push compressed with the zstd-dict codec, which isn't registered
+	data := []byte{byte(Push)<<1 | ZstdDictionaryFlag, 0x01, 'a', 0x00}
+	_, err := Decode(data)
+	assert.Equal(t, ErrUnknownCodec, err)
+}
+
+func TestDecodeWithCompression(t *testing.T) {
+	deflated, err := NewMessagesEncoderWithCodec(GetCodec(CompressionDeflate)).Encode(&Message{Type: Notify, Route: "room.room.message", Data: codecPayload})
+	assert.NoError(t, err)
+	plain, err := NewMessagesEncoderWithCodec(nil).Encode(&Message{Type: Notify, Route: "room.room.message", Data: codecPayload})
+	assert.NoError(t, err)
+
+	m, err := DecodeWithCompression(deflated, CompressionDeflate)
+	assert.NoError(t, err)
+	assert.Equal(t, codecPayload, m.Data)
+
+	// the data may always be sent without compression
+	for _, name := range []string{CompressionNone, CompressionDeflate, CompressionZstd} {
+		m, err = DecodeWithCompression(plain, name)
+		assert.NoError(t, err)
+		assert.Equal(t, codecPayload, m.Data)
+	}
+
+	_, err = DecodeWithCompression(deflated, CompressionZstd)
+	assert.Equal(t, ErrUnexpectedCodec, err)
+	_, err = DecodeWithCompression(deflated, CompressionNone)
+	assert.Equal(t, ErrUnexpectedCodec, err)
+	_, err = NewMessagesEncoderWithCodec(GetCodec(CompressionZstd)).Decode(deflated)
+	assert.Equal(t, ErrUnexpectedCodec, err)
+}
+
+func TestDecompressMaxSize(t *testing.T) {
+	large := make([]byte, MaxDecompressedSize+1)
+	for _, name := range []string{CompressionDeflate, CompressionZstd} {
+		t.Run(name, func(t *testing.T) {
+			codec := GetCodec(name)
+			compressed, err := codec.Compress(large)
+			assert.NoError(t, err)
+			_, err = codec.Decompress(compressed)
+			assert.Error(t, err)
+
+			compressed, err = codec.Compress(large[:MaxDecompressedSize])
+			assert.NoError(t, err)
+			data, err := codec.Decompress(compressed)
+			assert.NoError(t, err)
+			assert.Len(t, data, MaxDecompressedSize)
+		})
+	}
+}
@@ -41,6 +41,8 @@ const (
 const (
 	errorMask            = 0x20
 	gzipMask             = 0x10
+	zstdMask             = 0x40
+	compressionMask      = gzipMask | zstdMask
 	msgRouteCompressMask = 0x01
 	msgTypeMask          = 0x07
 	msgRouteLengthMask   = 0xFF
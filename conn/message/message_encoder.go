@@ -22,30 +22,69 @@ package message
 
 import (
 	"encoding/binary"
+)
 
-	"github.com/topfreegames/pitaya/v2/util/compression"
+// Compression algorithms negotiated with the clients at handshake
+const (
+	CompressionNone           = "none"
+	CompressionDeflate        = "deflate"
+	CompressionZstd           = "zstd"
+	CompressionZstdDictionary = "zstd-dict"
 )
 
+// IsCompressionSupported returns whether the messages can be encoded with the
+// compression algorithm
+func IsCompressionSupported(name string) bool {
+	return name == CompressionNone || GetCodec(name) != nil
+}
+
 // Encoder interface
 type Encoder interface {
 	IsCompressionEnabled() bool
+	Compression() string
 	Encode(message *Message) ([]byte, error)
 }
 
-// MessagesEncoder implements MessageEncoder interface
+// MessagesEncoder implements MessageEncoder interface, the data is compressed
+// with Codec or with deflate if DataCompression is set
 type MessagesEncoder struct {
 	DataCompression bool
+	Codec           Codec
 }
 
 // NewMessagesEncoder returns a new message encoder
 func NewMessagesEncoder(dataCompression bool) *MessagesEncoder {
-	me := &MessagesEncoder{dataCompression}
+	me := &MessagesEncoder{DataCompression: dataCompression}
 	return me
 }
 
+// NewMessagesEncoderWithCodec returns a new message encoder compressing with
+// the codec, the data isn't compressed if codec is nil
+func NewMessagesEncoderWithCodec(codec Codec) *MessagesEncoder {
+	return &MessagesEncoder{Codec: codec}
+}
+
 // IsCompressionEnabled returns wether the compression is enabled or not
 func (me *MessagesEncoder) IsCompressionEnabled() bool {
-	return me.DataCompression
+	return me.codec() != nil
+}
+
+// Compression returns the name of the compression algorithm of the encoder
+func (me *MessagesEncoder) Compression() string {
+	if codec := me.codec(); codec != nil {
+		return codec.Name()
+	}
+	return CompressionNone
+}
+
+func (me *MessagesEncoder) codec() Codec {
+	if me.Codec != nil {
+		return me.Codec
+	}
+	if me.DataCompression {
+		return GetCodec(CompressionDeflate)
+	}
+	return nil
 }
 
 // Encode marshals message to binary format. Different message types is corresponding to
@@ -60,6 +99,8 @@ func (me *MessagesEncoder) IsCompressionEnabled() bool {
 // | push     |----011-|<route>             |
 // ------------------------------------------
 // The figure above indicates that the bit does not affect the type of message.
+// The data is compressed with the codec of the encoder when it gets smaller, the
+// flag of the codec (bits 0x10 and 0x40) is then set in the flag field.
 // See ref: https://github.com/topfreegames/pitaya/v2/blob/master/docs/communication_protocol.md
 func (me *MessagesEncoder) Encode(message *Message) ([]byte, error) {
 	if invalidType(message.Type) {
@@ -107,15 +148,15 @@ func (me *MessagesEncoder) Encode(message *Message) ([]byte, error) {
 		}
 	}
 
-	if me.DataCompression {
-		d, err := compression.DeflateData(message.Data)
+	if codec := me.codec(); codec != nil {
+		d, err := codec.Compress(message.Data)
 		if err != nil {
 			return nil, err
 		}
 
 		if len(d) < len(message.Data) {
 			message.Data = d
-			buf[0] |= gzipMask
+			buf[0] |= codec.Flag()
 		}
 	}
 
@@ -123,14 +164,31 @@ func (me *MessagesEncoder) Encode(message *Message) ([]byte, error) {
 	return buf, nil
 }
 
-// Decode decodes the message
+// Decode decodes the message, the data must be compressed with the codec of
+// the encoder or not compressed at all
 func (me *MessagesEncoder) Decode(data []byte) (*Message, error) {
-	return Decode(data)
+	return DecodeWithCompression(data, me.Compression())
 }
 
 // Decode unmarshal the bytes slice to a message
 // See ref: https://github.com/topfreegames/pitaya/v2/blob/master/docs/communication_protocol.md
 func Decode(data []byte) (*Message, error) {
+	return decode(data, nil)
+}
+
+// DecodeWithCompression unmarshals the bytes slice to a message, rejecting
+// the data compressed with a compression other than the negotiated one
+func DecodeWithCompression(data []byte, compression string) (*Message, error) {
+	var flag byte
+	if codec := GetCodec(compression); codec != nil {
+		flag = codec.Flag()
+	}
+	return decode(data, &flag)
+}
+
+// decode unmarshals the message, the data may be compressed with any codec
+// if allowed is nil, or only with the codec of the allowed flag otherwise
+func decode(data []byte, allowed *byte) (*Message, error) {
 	if len(data) < msgHeadLength {
 		return nil, ErrInvalidMessage
 	}
@@ -183,9 +241,16 @@ func Decode(data []byte) (*Message, error) {
 	}
 
 	m.Data = data[offset:]
-	var err error
-	if flag&gzipMask == gzipMask {
-		m.Data, err = compression.InflateData(m.Data)
+	if compressed := flag & compressionMask; compressed != 0 {
+		if allowed != nil && compressed != *allowed {
+			return nil, ErrUnexpectedCodec
+		}
+		codec := getCodecByFlag(compressed)
+		if codec == nil {
+			return nil, ErrUnknownCodec
+		}
+		var err error
+		m.Data, err = codec.Decompress(m.Data)
 		if err != nil {
 			return nil, err
 		}
@@ -45,6 +45,18 @@ func (mr *MockEncoderMockRecorder) IsCompressionEnabled() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsCompressionEnabled", reflect.TypeOf((*MockEncoder)(nil).IsCompressionEnabled))
 }
 
+// Compression mocks base method
+func (m *MockEncoder) Compression() string {
+	ret := m.ctrl.Call(m, "Compression")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Compression indicates an expected call of Compression
+func (mr *MockEncoderMockRecorder) Compression() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Compression", reflect.TypeOf((*MockEncoder)(nil).Compression))
+}
+
 // Encode mocks base method
 func (m *MockEncoder) Encode(message *message.Message) ([]byte, error) {
 	ret := m.ctrl.Call(m, "Encode", message)
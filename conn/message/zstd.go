@@ -0,0 +1,123 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package message
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// zstdDictionaryMagic starts the dictionaries in the zstd format, such as the
+// ones trained with zstd --train
+const zstdDictionaryMagic = 0xEC30A437
+
+// ErrInvalidZstdDictionary is returned for dictionaries not in the zstd format
+var ErrInvalidZstdDictionary = errors.New("invalid zstd dictionary")
+
+// zstdCodec compresses with zstd, the encoder and decoder are safe for
+// concurrent use when compressing whole messages
+type zstdCodec struct {
+	name    string
+	flag    byte
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+func newZstdCodec() *zstdCodec {
+	c, err := newZstdCodecWithOptions(CompressionZstd, ZstdFlag, nil, nil)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func newZstdCodecWithOptions(name string, flag byte, eopts []zstd.EOption, dopts []zstd.DOption) (*zstdCodec, error) {
+	// the checksum is left out as messages are small and the transport is reliable
+	eopts = append([]zstd.EOption{zstd.WithEncoderCRC(false)}, eopts...)
+	encoder, err := zstd.NewWriter(nil, eopts...)
+	if err != nil {
+		return nil, err
+	}
+	dopts = append([]zstd.DOption{zstd.WithDecoderMaxMemory(MaxDecompressedSize)}, dopts...)
+	decoder, err := zstd.NewReader(nil, dopts...)
+	if err != nil {
+		return nil, err
+	}
+	return &zstdCodec{name: name, flag: flag, encoder: encoder, decoder: decoder}, nil
+}
+
+func (c *zstdCodec) Name() string {
+	return c.name
+}
+
+func (c *zstdCodec) Flag() byte {
+	return c.flag
+}
+
+func (c *zstdCodec) Compress(data []byte) ([]byte, error) {
+	return c.encoder.EncodeAll(data, nil), nil
+}
+
+func (c *zstdCodec) Decompress(data []byte) ([]byte, error) {
+	return c.decoder.DecodeAll(data, nil)
+}
+
+// ZstdDictionaryCodec compresses with zstd and a dictionary trained with
+// samples of the messages, which helps with small and similar messages
+type ZstdDictionaryCodec struct {
+	*zstdCodec
+	dictionary []byte
+	id         uint32
+}
+
+// NewZstdDictionaryCodec returns a codec using a dictionary in the zstd format
+func NewZstdDictionaryCodec(dictionary []byte) (*ZstdDictionaryCodec, error) {
+	if len(dictionary) < 8 || binary.LittleEndian.Uint32(dictionary) != zstdDictionaryMagic {
+		return nil, ErrInvalidZstdDictionary
+	}
+	c, err := newZstdCodecWithOptions(
+		CompressionZstdDictionary,
+		ZstdDictionaryFlag,
+		// the fastest level is the one making the most of the dictionary with small messages
+		[]zstd.EOption{zstd.WithEncoderDict(dictionary), zstd.WithEncoderLevel(zstd.SpeedFastest)},
+		[]zstd.DOption{zstd.WithDecoderDicts(dictionary)},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &ZstdDictionaryCodec{
+		zstdCodec:  c,
+		dictionary: dictionary,
+		id:         binary.LittleEndian.Uint32(dictionary[4:]),
+	}, nil
+}
+
+// Dictionary returns the dictionary of the codec
+func (c *ZstdDictionaryCodec) Dictionary() []byte {
+	return c.dictionary
+}
+
+// DictionaryID returns the id of the dictionary set when it was trained
+func (c *ZstdDictionaryCodec) DictionaryID() uint32 {
+	return c.id
+}
@@ -41,6 +41,13 @@ const (
 
 	// Kick represents a kick off packet
 	Kick = 0x05 // disconnect message from server
+
+	// Reconnect asks the client to reconnect, possibly to another server
+	Reconnect = 0x06 // sent by draining servers
+
+	// KeyExchange carries the public keys of the encrypted connections, it is
+	// sent by the client before the handshake and answered by the server
+	KeyExchange = 0x07
 )
 
 // ErrWrongPomeloPacketType represents a wrong packet type.
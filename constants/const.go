@@ -30,6 +30,9 @@ const (
 	StatusWorking
 	// StatusClosed status
 	StatusClosed
+	// StatusSuspended status, the connection was lost but the session
+	// is kept waiting for the client to resume it
+	StatusSuspended
 )
 
 const (
@@ -43,6 +46,10 @@ const (
 	KickRoute = "sys.kick"
 )
 
+// HandshakeProtocolVersion is the latest version of the handshake protocol,
+// clients that don't send a version in the handshake are on version 0
+const HandshakeProtocolVersion = 1
+
 // SessionCtxKey is the context key where the session will be set
 var SessionCtxKey = "session"
 
@@ -73,6 +80,9 @@ var PeerServiceKey = "peer.service"
 // StartTimeKey is the key holding the request start time (in ns) to be sent over the context
 var StartTimeKey = "req-start-time"
 
+// TimeoutKey is the key holding the time left until the request deadline (in ns) to be sent over the context
+var TimeoutKey = "req-timeout"
+
 // RequestIDKey is the key holding the request id to be sent over the context
 var RequestIDKey = "request.id"
 
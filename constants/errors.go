@@ -57,6 +57,7 @@ var (
 	ErrNoNatsConnectionString         = errors.New("you have to provide a nats url")
 	ErrNoServerTypeChosenForRPC       = errors.New("no server type chosen for sending RPC, send a full route in the format server.service.component")
 	ErrNoServerWithID                 = errors.New("can't find any server with the provided ID")
+	ErrNoResponse                     = errors.New("rpc server returned no response")
 	ErrNoServersAvailableOfType       = errors.New("no servers available of this type")
 	ErrNoUIDBind                      = errors.New("you have to bind an UID to the session to do that")
 	ErrNonsenseRPC                    = errors.New("you are making a rpc that may be processed locally, either specify a different server type or specify a server id")
@@ -85,4 +86,33 @@ var (
 	ErrReceivedMsgSmallerThanExpected = errors.New("received less data than expected, EOF?")
 	ErrReceivedMsgBiggerThanExpected  = errors.New("received more data than expected")
 	ErrConnectionClosed               = errors.New("client connection closed")
+	ErrSessionResumeDisabled          = errors.New("session resume is disabled")
+	ErrInvalidResumeToken             = errors.New("invalid or expired session resume token")
+	ErrResumeBufferExceed             = errors.New("suspended session push buffer exceed")
+	ErrFrozenPushDropped              = errors.New("frozen push dropped")
+	ErrNotAgentSession                = errors.New("session is not handled by a client agent")
+	ErrNoRoutingKey                   = errors.New("no routing key found for the call")
+	ErrDocsWithoutTypeNames           = errors.New("docs must be generated with the type names of the messages")
+	ErrStreamSendClosed               = errors.New("send on a stream closed for sending")
+	ErrInvalidStreamFrame             = errors.New("invalid stream frame")
+	ErrStreamWindowExceeded           = errors.New("stream received more frames than its window")
+	ErrWorkerQueueClosed              = errors.New("worker queue is closed")
+	ErrUnknownWorkerBackend           = errors.New("unknown worker backend")
+	ErrJobNotFound                    = errors.New("job not found")
+	ErrInvalidCronSpec                = errors.New("invalid cron spec")
+	ErrCronWithoutNext                = errors.New("cron spec matches no time in the next five years")
+	ErrProtocolVersionNotSupported    = errors.New("handshake protocol version not supported")
+	ErrSerializerNotSupported         = errors.New("none of the client serializers is supported")
+	ErrUnknownSerializer              = errors.New("unknown serializer")
+	ErrPushNotEncoded                 = errors.New("push not encoded with the serializer of the session")
+	ErrCompressionNotSupported        = errors.New("none of the client compression algorithms is supported")
+	ErrKeyExchangeRequired            = errors.New("connection must exchange keys before the handshake")
+	ErrAcceptorStopped                = errors.New("acceptor is stopped")
+	ErrEmptyScenario                  = errors.New("load test scenario has no steps")
+	ErrStepWithoutRoute               = errors.New("load test step has no route")
+	ErrPushWithoutRoute               = errors.New("load test expected push has no route")
+	ErrPushTimeout                    = errors.New("timed out waiting for push")
+	ErrUnknownProtocol                = errors.New("unknown load test protocol")
+	ErrRPCTimeout                     = errors.New("rpc request timed out")
+	ErrUnknownClusterBackend          = errors.New("unknown cluster backend")
 )
@@ -23,6 +23,7 @@ package context
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/topfreegames/pitaya/v2/constants"
 	"github.com/topfreegames/pitaya/v2/relation"
@@ -91,6 +92,72 @@ func GetRelationDataFromContext(ctx context.Context) map[string]relation.Data {
 	return ret
 }
 
+// CtxWithRelationData returns a copy of ctx where the pushes to the user with
+// the given uid are related to the request described by data
+func CtxWithRelationData(ctx context.Context, uid string, data relation.Data) context.Context {
+	relationData := GetRelationDataFromContext(ctx)
+	relationData[uid] = data
+	return context.WithValue(ctx, constants.MsgRelationKey, relationData)
+}
+
+// AddDeadlineToPropagateCtx adds the time left until the deadline of ctx, if
+// it has one, to the values that will be propagated through RPC calls. The
+// time left is sent instead of the deadline itself so that the deadline does
+// not depend on the clocks of the servers being in sync
+func AddDeadlineToPropagateCtx(ctx context.Context) context.Context {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return ctx
+	}
+	return AddToPropagateCtx(ctx, constants.TimeoutKey, int64(time.Until(deadline)))
+}
+
+// GetPropagatedDeadline returns the deadline propagated by the caller of an
+// RPC, rebuilt from the local clock and the time that was left when the RPC
+// was sent
+func GetPropagatedDeadline(ctx context.Context) (time.Time, bool) {
+	var nanos int64
+	switch v := GetFromPropagateCtx(ctx, constants.TimeoutKey).(type) {
+	case int64:
+		nanos = v
+	case float64:
+		nanos = int64(v)
+	default:
+		return time.Time{}, false
+	}
+	return time.Now().Add(time.Duration(nanos)), true
+}
+
+// WithPropagatedDeadline returns a copy of ctx with the deadline propagated by
+// the caller of an RPC, the cancel function must be called once the RPC is done
+func WithPropagatedDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if deadline, ok := GetPropagatedDeadline(ctx); ok {
+		return context.WithDeadline(ctx, deadline)
+	}
+	return context.WithCancel(ctx)
+}
+
+// WithDeadlineFrom returns a copy of ctx with the deadline of parent that is
+// also canceled when parent is done, the values of parent are not copied
+func WithDeadlineFrom(ctx, parent context.Context) (context.Context, context.CancelFunc) {
+	var cancel context.CancelFunc
+	if deadline, ok := parent.Deadline(); ok {
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	if parent.Done() != nil {
+		go func() {
+			select {
+			case <-parent.Done():
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+	}
+	return ctx, cancel
+}
+
 // ToMap returns the values that will be propagated through RPC calls in map[string]interface{} format
 func ToMap(ctx context.Context) map[string]interface{} {
 	if ctx == nil {
@@ -27,6 +27,7 @@ import (
 	"flag"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -211,3 +212,64 @@ func TestDecodeWithEmptyData(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Nil(t, decoded)
 }
+
+func TestPropagatedDeadline(t *testing.T) {
+	_, ok := GetPropagatedDeadline(context.Background())
+	assert.False(t, ok)
+	assert.Equal(t, context.Background(), AddDeadlineToPropagateCtx(context.Background()))
+
+	deadline := time.Now().Add(time.Minute)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	ctx = AddDeadlineToPropagateCtx(ctx)
+
+	// the time left is propagated, not the deadline of the caller clock
+	timeout := GetFromPropagateCtx(ctx, constants.TimeoutKey).(int64)
+	assert.True(t, timeout > 0 && timeout <= int64(time.Minute))
+
+	// the deadline is kept when the context is sent over the wire
+	encoded, err := Encode(ctx)
+	require.NoError(t, err)
+	decoded, err := Decode(encoded)
+	require.NoError(t, err)
+
+	for _, c := range []context.Context{ctx, decoded} {
+		got, ok := GetPropagatedDeadline(c)
+		assert.True(t, ok)
+		assert.WithinDuration(t, deadline, got, 100*time.Millisecond)
+	}
+
+	withDeadline, cancel := WithPropagatedDeadline(decoded)
+	defer cancel()
+	got, ok := withDeadline.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, deadline, got, 100*time.Millisecond)
+}
+
+func TestWithPropagatedDeadlineExpired(t *testing.T) {
+	ctx := AddToPropagateCtx(context.Background(), constants.TimeoutKey, int64(-time.Second))
+	ctx, cancel := WithPropagatedDeadline(ctx)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+
+	ctx, cancel = WithPropagatedDeadline(context.Background())
+	assert.NoError(t, ctx.Err())
+	cancel()
+	assert.Equal(t, context.Canceled, ctx.Err())
+}
+
+func TestWithDeadlineFrom(t *testing.T) {
+	ctx := AddToPropagateCtx(context.Background(), "key", "val")
+	deadline := time.Now().Add(time.Minute)
+	parent, cancelParent := context.WithDeadline(context.Background(), deadline)
+
+	c, cancel := WithDeadlineFrom(ctx, parent)
+	defer cancel()
+	got, ok := c.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, deadline, got)
+	assert.Equal(t, "val", GetFromPropagateCtx(c, "key"))
+
+	cancelParent()
+	helpers.ShouldEventuallyReturn(t, func() error { return c.Err() }, context.Canceled)
+}
@@ -0,0 +1,280 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package docgenerator
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/topfreegames/pitaya/v2/component"
+	"github.com/topfreegames/pitaya/v2/constants"
+	"github.com/topfreegames/pitaya/v2/route"
+)
+
+type clientFile struct {
+	Package  string
+	Imports  []clientImport
+	Services []*clientService
+}
+
+type clientImport struct {
+	Name string
+	Path string
+}
+
+type clientService struct {
+	Name    string
+	Type    string
+	Methods []clientMethod
+}
+
+type clientMethod struct {
+	Name   string
+	Route  string
+	Client string
+	Arg    string
+	Reply  string
+}
+
+var clientTemplate = template.Must(template.New("client").Parse(`// Code generated by pitaya docgenerator. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/proto"
+{{range .Imports}}
+	{{.Name}} "{{.Path}}"
+{{- end}}
+)
+
+// RPCSender sends rpcs to other servers, it is implemented by pitaya.Pitaya
+type RPCSender interface {
+	RPC(ctx context.Context, routeStr string, reply proto.Message, arg proto.Message) error
+	RPCTo(ctx context.Context, serverID, routeStr string, reply proto.Message, arg proto.Message) error
+}
+{{range .Services}}
+// {{.Type}} calls the remotes of the {{.Name}} service
+type {{.Type}} struct {
+	rpc RPCSender
+}
+
+// New{{.Type}} returns a client for the remotes of the {{.Name}} service
+func New{{.Type}}(rpc RPCSender) *{{.Type}} {
+	return &{{.Type}}{rpc: rpc}
+}
+{{range .Methods}}
+// {{.Name}} calls the {{.Route}} remote
+func (c *{{.Client}}) {{.Name}}(ctx context.Context{{if .Arg}}, arg *{{.Arg}}{{end}}) (*{{.Reply}}, error) {
+	reply := &{{.Reply}}{}
+	if err := c.rpc.RPC(ctx, "{{.Route}}", reply, {{if .Arg}}arg{{else}}nil{{end}}); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// {{.Name}}To calls the {{.Route}} remote of the server with the given id
+func (c *{{.Client}}) {{.Name}}To(ctx context.Context, serverID string{{if .Arg}}, arg *{{.Arg}}{{end}}) (*{{.Reply}}, error) {
+	reply := &{{.Reply}}{}
+	if err := c.rpc.RPCTo(ctx, serverID, "{{.Route}}", reply, {{if .Arg}}arg{{else}}nil{{end}}); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+{{end}}{{end}}`))
+
+// clientImports assigns a unique name to each package used by the client
+type clientImports struct {
+	names map[string]string
+	paths map[string]string
+}
+
+func newClientImports() *clientImports {
+	return &clientImports{
+		names: map[string]string{},
+		// names used by the imports of every client
+		paths: map[string]string{"context": "context", "proto": "github.com/golang/protobuf/proto"},
+	}
+}
+
+func (c *clientImports) add(name, path string) string {
+	if n, ok := c.names[path]; ok {
+		return n
+	}
+	n := name
+	for i := 2; c.paths[n] != ""; i++ {
+		n = fmt.Sprintf("%s%d", name, i)
+	}
+	c.names[path] = n
+	c.paths[n] = path
+	return n
+}
+
+func (c *clientImports) list() []clientImport {
+	imports := make([]clientImport, 0, len(c.names))
+	for path, name := range c.names {
+		imports = append(imports, clientImport{Name: name, Path: path})
+	}
+	sort.Slice(imports, func(i, j int) bool {
+		return imports[i].Path < imports[j].Path
+	})
+	return imports
+}
+
+// typeName returns the name of the struct a proto message pointer points to
+func (c *clientImports) typeName(typ reflect.Type) string {
+	elm := typ.Elem()
+	pkg := strings.Split(elm.String(), ".")[0]
+	return c.add(pkg, elm.PkgPath()) + "." + elm.Name()
+}
+
+func exportedName(name string) string {
+	if name == "" {
+		return name
+	}
+	r := []rune(name)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+func (f *clientFile) service(name string) *clientService {
+	for _, s := range f.Services {
+		if s.Name == name {
+			return s
+		}
+	}
+	s := &clientService{Name: name, Type: exportedName(name) + "Client"}
+	f.Services = append(f.Services, s)
+	return s
+}
+
+func (f *clientFile) format() ([]byte, error) {
+	sort.Slice(f.Services, func(i, j int) bool {
+		return f.Services[i].Name < f.Services[j].Name
+	})
+	for _, s := range f.Services {
+		sort.Slice(s.Methods, func(i, j int) bool {
+			return s.Methods[i].Name < s.Methods[j].Name
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := clientTemplate.Execute(&buf, f); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// RemotesClient returns the source of a go package named pkgName with typed
+// clients for the remotes of the given services
+func RemotesClient(pkgName, serverType string, services map[string]*component.Service) ([]byte, error) {
+	f := &clientFile{Package: pkgName}
+	imports := newClientImports()
+
+	for serviceName, service := range services {
+		s := f.service(serviceName)
+		for name, remote := range service.Remotes {
+			method := remote.Method
+			m := clientMethod{
+				Name:   method.Name,
+				Route:  route.NewRoute(serverType, serviceName, name).String(),
+				Client: s.Type,
+				Reply:  imports.typeName(method.Type.Out(0)),
+			}
+			if method.Type.NumIn() > 2 {
+				m.Arg = imports.typeName(method.Type.In(2))
+			}
+			s.Methods = append(s.Methods, m)
+		}
+	}
+
+	f.Imports = imports.list()
+	return f.format()
+}
+
+// RemotesClientFromDocs returns the source of a go package named pkgName with
+// typed clients for the remotes in docs, which must be generated with the type
+// names (getPtrNames), imports maps the packages of the types to their paths
+func RemotesClientFromDocs(pkgName string, docs map[string]interface{}, imports map[string]string) ([]byte, error) {
+	f := &clientFile{Package: pkgName}
+	used := newClientImports()
+
+	typeName := func(doc interface{}) (string, error) {
+		fields, ok := doc.(map[string]interface{})
+		if !ok || len(fields) != 1 {
+			return "", constants.ErrDocsWithoutTypeNames
+		}
+		for name := range fields {
+			name = strings.TrimPrefix(name, "*")
+			parts := strings.Split(name, ".")
+			if len(parts) != 2 {
+				return "", constants.ErrDocsWithoutTypeNames
+			}
+			path, ok := imports[parts[0]]
+			if !ok {
+				return "", fmt.Errorf("no import path for package %s", parts[0])
+			}
+			return used.add(parts[0], path) + "." + parts[1], nil
+		}
+		return "", nil
+	}
+
+	for routeStr, d := range docs {
+		r, err := route.Decode(routeStr)
+		if err != nil {
+			return nil, err
+		}
+		doc, ok := d.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid doc for route %s", routeStr)
+		}
+		output, ok := doc["output"].([]interface{})
+		if !ok || len(output) == 0 {
+			return nil, fmt.Errorf("invalid doc for route %s", routeStr)
+		}
+
+		s := f.service(r.Service)
+		m := clientMethod{
+			Name:   exportedName(r.Method),
+			Route:  routeStr,
+			Client: s.Type,
+		}
+		if m.Reply, err = typeName(output[0]); err != nil {
+			return nil, err
+		}
+		if doc["input"] != nil {
+			if m.Arg, err = typeName(doc["input"]); err != nil {
+				return nil, err
+			}
+		}
+		s.Methods = append(s.Methods, m)
+	}
+
+	f.Imports = used.list()
+	return f.format()
+}
@@ -0,0 +1,158 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package docgenerator
+
+import (
+	"context"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/topfreegames/pitaya/v2/component"
+	"github.com/topfreegames/pitaya/v2/constants"
+	"github.com/topfreegames/pitaya/v2/protos"
+	"github.com/topfreegames/pitaya/v2/protos/test"
+)
+
+type RoomComp struct {
+	component.Base
+}
+
+func (r *RoomComp) Join(ctx context.Context, ss *test.SomeStruct) (*protos.Response, error) {
+	return nil, nil
+}
+
+func (r *RoomComp) Count(ctx context.Context) (*test.SomeStruct, error) {
+	return nil, nil
+}
+
+func roomServices(t *testing.T) map[string]*component.Service {
+	s := component.NewService(&RoomComp{}, []component.Option{component.WithName("room")})
+	assert.NoError(t, s.ExtractRemote())
+	return map[string]*component.Service{s.Name: s}
+}
+
+func TestRemotesClient(t *testing.T) {
+	t.Parallel()
+
+	src, err := RemotesClient("roomclient", "room", roomServices(t))
+	assert.NoError(t, err)
+
+	typeCheckClient(t, src)
+
+	code := string(src)
+	assert.Contains(t, code, "package roomclient")
+	assert.Contains(t, code, `protos "github.com/topfreegames/pitaya/v2/protos"`)
+	assert.Contains(t, code, `test "github.com/topfreegames/pitaya/v2/protos/test"`)
+	assert.Contains(t, code, "func NewRoomClient(rpc RPCSender) *RoomClient {")
+	assert.Contains(t, code, "func (c *RoomClient) Join(ctx context.Context, arg *test.SomeStruct) (*protos.Response, error) {")
+	assert.Contains(t, code, `c.rpc.RPC(ctx, "room.room.Join", reply, arg)`)
+	assert.Contains(t, code, "func (c *RoomClient) JoinTo(ctx context.Context, serverID string, arg *test.SomeStruct) (*protos.Response, error) {")
+	assert.Contains(t, code, `c.rpc.RPCTo(ctx, serverID, "room.room.Join", reply, arg)`)
+	assert.Contains(t, code, "func (c *RoomClient) Count(ctx context.Context) (*test.SomeStruct, error) {")
+	assert.Contains(t, code, `c.rpc.RPC(ctx, "room.room.Count", reply, nil)`)
+}
+
+// typeCheckClient type-checks a generated client against the export data of
+// the packages it imports, built by go list
+func typeCheckClient(t *testing.T, src []byte) {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "client.go", src, parser.ImportsOnly)
+	if !assert.NoError(t, err) {
+		return
+	}
+	args := []string{"list", "-export", "-deps", "-f", "{{.ImportPath}}={{.Export}}"}
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		assert.NoError(t, err)
+		args = append(args, path)
+	}
+	out, err := exec.Command("go", args...).Output()
+	if !assert.NoError(t, err) {
+		return
+	}
+	exports := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if i := strings.Index(line, "="); i > 0 {
+			exports[line[:i]] = line[i+1:]
+		}
+	}
+	lookup := func(path string) (io.ReadCloser, error) {
+		return os.Open(exports[path])
+	}
+
+	file, err = parser.ParseFile(fset, "client.go", src, 0)
+	assert.NoError(t, err)
+	conf := types.Config{Importer: importer.ForCompiler(fset, "gc", lookup)}
+	_, err = conf.Check("roomclient", fset, []*ast.File{file}, nil)
+	assert.NoError(t, err)
+}
+
+func TestRemotesClientFromDocs(t *testing.T) {
+	t.Parallel()
+
+	services := roomServices(t)
+	expected, err := RemotesClient("roomclient", "room", services)
+	assert.NoError(t, err)
+
+	docs, err := RemotesDocs("room", services, true)
+	assert.NoError(t, err)
+	imports := map[string]string{
+		"protos": "github.com/topfreegames/pitaya/v2/protos",
+		"test":   "github.com/topfreegames/pitaya/v2/protos/test",
+	}
+	src, err := RemotesClientFromDocs("roomclient", docs, imports)
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), string(src))
+
+	_, err = RemotesClientFromDocs("roomclient", docs, map[string]string{})
+	assert.Error(t, err)
+
+	docs, err = RemotesDocs("room", services, false)
+	assert.NoError(t, err)
+	_, err = RemotesClientFromDocs("roomclient", docs, imports)
+	assert.Equal(t, constants.ErrDocsWithoutTypeNames, err)
+}
+
+func TestClientImports(t *testing.T) {
+	t.Parallel()
+
+	imports := newClientImports()
+	assert.Equal(t, "protos", imports.add("protos", "a/protos"))
+	assert.Equal(t, "protos", imports.add("protos", "a/protos"))
+	assert.Equal(t, "protos2", imports.add("protos", "b/protos"))
+	assert.Equal(t, "proto2", imports.add("proto", "c/proto"))
+	assert.Equal(t, []clientImport{
+		{Name: "protos", Path: "a/protos"},
+		{Name: "protos2", Path: "b/protos"},
+		{Name: "proto2", Path: "c/proto"},
+	}, imports.list())
+}
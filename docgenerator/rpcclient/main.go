@@ -0,0 +1,98 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// rpcclient generates typed rpc clients from the documentation of a server,
+// as returned by the docs route or pitaya.Documentation(true), e.g.
+//
+//	rpcclient -docs room.json -pkg roomclient -import protos=github.com/me/game/protos -o roomclient/client.go
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/topfreegames/pitaya/v2/docgenerator"
+)
+
+type importsFlag map[string]string
+
+func (i importsFlag) String() string {
+	return fmt.Sprint(map[string]string(i))
+}
+
+func (i importsFlag) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("import must be in the format name=path")
+	}
+	i[parts[0]] = parts[1]
+	return nil
+}
+
+func main() {
+	imports := importsFlag{}
+	docsPath := flag.String("docs", "-", "path of the json docs of the server, - reads from stdin")
+	pkgName := flag.String("pkg", "rpcclient", "name of the generated package")
+	out := flag.String("o", "-", "path of the generated file, - writes to stdout")
+	flag.Var(imports, "import", "package of the messages in the format name=path, can be repeated")
+	flag.Parse()
+
+	if err := generate(*docsPath, *pkgName, *out, imports); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func generate(docsPath, pkgName, out string, imports map[string]string) error {
+	var bts []byte
+	var err error
+	if docsPath == "-" {
+		bts, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		bts, err = ioutil.ReadFile(docsPath)
+	}
+	if err != nil {
+		return err
+	}
+
+	docs := map[string]interface{}{}
+	if err := json.Unmarshal(bts, &docs); err != nil {
+		return err
+	}
+	// the full documentation has the remotes under their own key
+	if remotes, ok := docs["remotes"].(map[string]interface{}); ok {
+		docs = remotes
+	}
+
+	src, err := docgenerator.RemotesClientFromDocs(pkgName, docs, imports)
+	if err != nil {
+		return err
+	}
+
+	if out == "-" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return ioutil.WriteFile(out, src, 0644)
+}
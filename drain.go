@@ -0,0 +1,111 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package pitaya
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/topfreegames/pitaya/v2/agent"
+	"github.com/topfreegames/pitaya/v2/logger"
+	"github.com/topfreegames/pitaya/v2/session"
+)
+
+// drainCheckInterval is how often the work in flight is checked while draining
+var drainCheckInterval = 100 * time.Millisecond
+
+// IsDraining returns true if the server is draining before shutting down
+func (app *App) IsDraining() bool {
+	return atomic.LoadInt32(&app.draining) == 1
+}
+
+// drain publishes the draining state of the server so that the other servers
+// stop sending new work to it, then waits for the work in flight to finish
+// until the drain timeout, new connections are refused meanwhile
+func (app *App) drain() {
+	if !atomic.CompareAndSwapInt32(&app.draining, 0, 1) {
+		return
+	}
+	logger.Log.Warn("server is draining...")
+
+	if app.serverMode == Cluster {
+		if err := app.serviceDiscovery.Drain(); err != nil {
+			logger.Log.Errorf("failed to publish draining state: %s", err.Error())
+		}
+	}
+
+	if app.server.Frontend && app.config.Drain.Reconnect {
+		app.sessionPool.ForEachSession(func(s session.Session) {
+			if a, ok := s.GetEntity().(agent.Agent); ok {
+				if err := a.Reconnect(app.config.Drain.ReconnectAddr); err != nil {
+					logger.Log.Warnf("failed to send reconnect to session %d: %s", s.ID(), err.Error())
+				}
+			}
+		})
+	}
+
+	app.sleep(app.config.Drain.Delay)
+
+	if count := app.waitInFlight(app.clock.Now().Add(app.config.Drain.Timeout)); count > 0 {
+		logger.Log.Warnf("drain timed out with %d messages in flight", count)
+		return
+	}
+	logger.Log.Info("server drained")
+}
+
+// inFlight returns the number of handlers, rpcs and pushes not finished yet
+func (app *App) inFlight() int64 {
+	count := app.handlerService.InFlight()
+	if app.remoteService != nil {
+		count += app.remoteService.InFlight()
+	}
+	app.sessionPool.ForEachSession(func(s session.Session) {
+		if a, ok := s.GetEntity().(agent.Agent); ok {
+			count += int64(a.PendingWrites())
+		}
+	})
+	return count
+}
+
+// sleep waits for d on the clock of the App
+func (app *App) sleep(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	ticker := app.clock.NewTicker(d)
+	defer ticker.Stop()
+	<-ticker.C()
+}
+
+// waitInFlight waits until there is no work in flight or the deadline of the
+// clock of the App is reached, it returns the work that is still in flight
+func (app *App) waitInFlight(deadline time.Time) int64 {
+	ticker := time.NewTicker(drainCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		count := app.inFlight()
+		if count == 0 || !app.clock.Now().Before(deadline) {
+			return count
+		}
+		<-ticker.C
+	}
+}
@@ -36,6 +36,9 @@ const ErrBadRequestCode = "PIT-400"
 // ErrClientClosedRequest is a string code representing the client closed request error
 const ErrClientClosedRequest = "PIT-499"
 
+// ErrDeadlineExceededCode is a string code representing a request whose deadline was exceeded
+const ErrDeadlineExceededCode = "PIT-504"
+
 // Error is an error with a code, message and metadata
 type Error struct {
 	Code     string
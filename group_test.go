@@ -393,8 +393,8 @@ func TestBroadcast(t *testing.T) {
 	assert.NoError(t, err)
 	route := "some.route.bla"
 	data := []byte("hellow")
-	s1.EXPECT().Push(route, data).Times(1)
-	s2.EXPECT().Push(route, data).Times(1)
+	s1.EXPECT().Push(ctx, route, data).Times(1)
+	s2.EXPECT().Push(ctx, route, data).Times(1)
 	err = app.GroupBroadcast(ctx, "testtype", "testBroadcast", route, data)
 	assert.NoError(t, err)
 }
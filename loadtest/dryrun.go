@@ -0,0 +1,81 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package loadtest
+
+import (
+	"net"
+	"strings"
+	"time"
+
+	"github.com/topfreegames/pitaya/v2"
+	"github.com/topfreegames/pitaya/v2/acceptor"
+	"github.com/topfreegames/pitaya/v2/component"
+	"github.com/topfreegames/pitaya/v2/config"
+)
+
+// DryRun is a standalone frontend App running in the same process as the
+// load test, the sessions connect to it through an in-memory acceptor so no
+// network or external service is needed
+type DryRun struct {
+	App      pitaya.Pitaya
+	Acceptor *acceptor.PipeAcceptor
+	done     chan struct{}
+}
+
+// StartDryRun builds an App with NewInProcessBuilder, registers the Echo
+// component with the name loadtest, calls register to add the application
+// components and starts the App.
+func StartDryRun(builderConfig config.BuilderConfig, register func(app pitaya.Pitaya)) *DryRun {
+	builder := pitaya.NewInProcessBuilder(true, "loadtest", pitaya.Standalone, map[string]string{}, builderConfig)
+	pipe := acceptor.NewPipeAcceptor()
+	builder.AddAcceptor(pipe)
+	app := builder.Build()
+
+	app.Register(NewEcho(app), component.WithName("loadtest"), component.WithNameFunc(strings.ToLower))
+	if register != nil {
+		register(app)
+	}
+
+	d := &DryRun{
+		App:      app,
+		Acceptor: pipe,
+		done:     make(chan struct{}),
+	}
+	go func() {
+		defer close(d.done)
+		app.Start()
+	}()
+	for !app.IsRunning() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	return d
+}
+
+// Dial creates a connection to the App, it can be used as Config.Dial
+func (d *DryRun) Dial(addr string) (net.Conn, error) {
+	return d.Acceptor.Dial(addr)
+}
+
+// Stop shuts the App down and waits for it to stop
+func (d *DryRun) Stop() {
+	d.App.Shutdown()
+	<-d.done
+}
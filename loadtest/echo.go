@@ -0,0 +1,62 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package loadtest
+
+import (
+	"context"
+
+	"github.com/topfreegames/pitaya/v2"
+	"github.com/topfreegames/pitaya/v2/component"
+)
+
+// EchoPushRoute is the route of the pushes sent by Echo.Push
+const EchoPushRoute = "loadtest.onPush"
+
+// Echo is a component answering requests with their payload, it lets
+// scenarios run against a dry run without application handlers. Registered
+// with the name loadtest its routes are loadtest.echo, loadtest.push and
+// loadtest.notify.
+type Echo struct {
+	component.Base
+	app pitaya.Pitaya
+}
+
+// NewEcho returns an Echo component
+func NewEcho(app pitaya.Pitaya) *Echo {
+	return &Echo{app: app}
+}
+
+// Echo answers with the payload received
+func (e *Echo) Echo(ctx context.Context, msg []byte) ([]byte, error) {
+	return msg, nil
+}
+
+// Push answers with the payload received and pushes it to EchoPushRoute
+func (e *Echo) Push(ctx context.Context, msg []byte) ([]byte, error) {
+	s := e.app.GetSessionFromCtx(ctx)
+	if err := s.Push(ctx, EchoPushRoute, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// Notify discards the payload received
+func (e *Echo) Notify(ctx context.Context, msg []byte) {}
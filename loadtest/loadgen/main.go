@@ -0,0 +1,81 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// loadgen runs a load test scenario with many simulated sessions and prints
+// the latencies of each route, e.g.
+//
+//	loadgen -scenario room.json -addr localhost:3250 -sessions 2000 -duration 1m -rampup 10s
+//
+// With -dry-run the sessions connect to an App running in the same process
+// that serves the loadtest.echo, loadtest.push and loadtest.notify routes.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/topfreegames/pitaya/v2/config"
+	"github.com/topfreegames/pitaya/v2/loadtest"
+)
+
+func main() {
+	conf := loadtest.NewDefaultConfig()
+	scenarioPath := flag.String("scenario", "", "path of the json scenario")
+	flag.StringVar(&conf.Addr, "addr", conf.Addr, "address of the frontend server")
+	flag.StringVar(&conf.Protocol, "protocol", conf.Protocol, "protocol used to connect, tcp or ws")
+	flag.StringVar(&conf.Path, "path", conf.Path, "path of the websocket endpoint")
+	flag.IntVar(&conf.Sessions, "sessions", conf.Sessions, "number of simulated sessions")
+	flag.IntVar(&conf.Iterations, "iterations", conf.Iterations, "times each session runs the scenario, 0 runs it until -duration elapses")
+	flag.DurationVar(&conf.Duration, "duration", conf.Duration, "maximum duration of the load test")
+	flag.DurationVar(&conf.RampUp, "rampup", conf.RampUp, "time taken to start all sessions")
+	flag.DurationVar(&conf.RequestTimeout, "timeout", conf.RequestTimeout, "timeout of requests and expected pushes")
+	dryRun := flag.Bool("dry-run", false, "run the scenario against an in-process app")
+	jsonOutput := flag.Bool("json", false, "print the report as json")
+	flag.Parse()
+
+	if err := run(*conf, *scenarioPath, *dryRun, *jsonOutput); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(conf loadtest.Config, scenarioPath string, dryRun, jsonOutput bool) error {
+	scenario, err := loadtest.LoadScenario(scenarioPath)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		d := loadtest.StartDryRun(*config.NewDefaultBuilderConfig(), nil)
+		defer d.Stop()
+		conf.Dial = d.Dial
+	}
+
+	report, err := loadtest.Run(context.Background(), conf, scenario)
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		return report.WriteJSON(os.Stdout)
+	}
+	return report.WriteText(os.Stdout)
+}
@@ -0,0 +1,147 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package loadtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+)
+
+const (
+	// latencies are recorded in microseconds from 1µs to 1 minute
+	minLatency        = 1
+	maxLatency        = int64(time.Minute / time.Microsecond)
+	significantDigits = 3
+)
+
+// Report holds the latency histograms of the routes called by a load test
+type Report struct {
+	mutex   sync.Mutex
+	routes  map[string]*routeHistogram
+	Elapsed time.Duration
+}
+
+type routeHistogram struct {
+	histogram *hdrhistogram.Histogram
+	errors    int64
+}
+
+// RouteStats are the statistics of a route, latencies of failed calls are
+// not recorded
+type RouteStats struct {
+	Route  string        `json:"route"`
+	Count  int64         `json:"count"`
+	Errors int64         `json:"errors"`
+	Min    time.Duration `json:"min"`
+	Mean   time.Duration `json:"mean"`
+	P50    time.Duration `json:"p50"`
+	P90    time.Duration `json:"p90"`
+	P99    time.Duration `json:"p99"`
+	Max    time.Duration `json:"max"`
+}
+
+// NewReport returns an empty report
+func NewReport() *Report {
+	return &Report{
+		routes: map[string]*routeHistogram{},
+	}
+}
+
+// Record records the latency of a call to route, or its error
+func (r *Report) Record(route string, latency time.Duration, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	h, ok := r.routes[route]
+	if !ok {
+		h = &routeHistogram{histogram: hdrhistogram.New(minLatency, maxLatency, significantDigits)}
+		r.routes[route] = h
+	}
+	if err != nil {
+		h.errors++
+		return
+	}
+	v := int64(latency / time.Microsecond)
+	if v < minLatency {
+		v = minLatency
+	} else if v > maxLatency {
+		v = maxLatency
+	}
+	h.histogram.RecordValue(v)
+}
+
+// Routes returns the statistics of each route sorted by route
+func (r *Report) Routes() []*RouteStats {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	stats := make([]*RouteStats, 0, len(r.routes))
+	for route, h := range r.routes {
+		hist := h.histogram
+		stats = append(stats, &RouteStats{
+			Route:  route,
+			Count:  hist.TotalCount() + h.errors,
+			Errors: h.errors,
+			Min:    microseconds(float64(hist.Min())),
+			Mean:   microseconds(hist.Mean()),
+			P50:    microseconds(float64(hist.ValueAtQuantile(50))),
+			P90:    microseconds(float64(hist.ValueAtQuantile(90))),
+			P99:    microseconds(float64(hist.ValueAtQuantile(99))),
+			Max:    microseconds(float64(hist.Max())),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Route < stats[j].Route
+	})
+	return stats
+}
+
+func microseconds(v float64) time.Duration {
+	return time.Duration(v * float64(time.Microsecond))
+}
+
+// WriteText writes the report as a table, one route per line
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ROUTE\tCOUNT\tERRORS\tRATE\tMIN\tMEAN\tP50\tP90\tP99\tMAX")
+	for _, s := range r.Routes() {
+		rate := 0.0
+		if r.Elapsed > 0 {
+			rate = float64(s.Count) / r.Elapsed.Seconds()
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f/s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			s.Route, s.Count, s.Errors, rate, s.Min, s.Mean, s.P50, s.P90, s.P99, s.Max)
+	}
+	return tw.Flush()
+}
+
+// WriteJSON writes the report as JSON, latencies are in nanoseconds
+func (r *Report) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(struct {
+		Elapsed time.Duration `json:"elapsed"`
+		Routes  []*RouteStats `json:"routes"`
+	}{r.Elapsed, r.Routes()})
+}
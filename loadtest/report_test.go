@@ -0,0 +1,63 @@
+package loadtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReport(t *testing.T) {
+	t.Parallel()
+	r := NewReport()
+	for i := 1; i <= 100; i++ {
+		r.Record("room.room.join", time.Duration(i)*time.Millisecond, nil)
+	}
+	r.Record("room.room.join", 0, errors.New("request timeout"))
+	r.Record("connect", time.Hour, nil)
+
+	stats := r.Routes()
+	assert.Len(t, stats, 2)
+	assert.Equal(t, "connect", stats[0].Route)
+	// latencies above the histogram range are recorded as its maximum
+	assert.InDelta(t, time.Minute, stats[0].Max, float64(100*time.Millisecond))
+
+	join := stats[1]
+	assert.Equal(t, "room.room.join", join.Route)
+	assert.Equal(t, int64(101), join.Count)
+	assert.Equal(t, int64(1), join.Errors)
+	assert.InDelta(t, time.Millisecond, join.Min, float64(10*time.Microsecond))
+	assert.InDelta(t, 50*time.Millisecond, join.P50, float64(100*time.Microsecond))
+	assert.InDelta(t, 99*time.Millisecond, join.P99, float64(100*time.Microsecond))
+	assert.InDelta(t, 100*time.Millisecond, join.Max, float64(100*time.Microsecond))
+	assert.InDelta(t, 50500*time.Microsecond, join.Mean, float64(100*time.Microsecond))
+}
+
+func TestReportWrite(t *testing.T) {
+	t.Parallel()
+	r := NewReport()
+	r.Record("room.room.join", 2*time.Millisecond, nil)
+	r.Elapsed = time.Second
+
+	text := &bytes.Buffer{}
+	assert.NoError(t, r.WriteText(text))
+	lines := strings.Split(strings.TrimSpace(text.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "ROUTE"))
+	assert.Contains(t, lines[1], "room.room.join")
+	assert.Contains(t, lines[1], "1.0/s")
+
+	out := &bytes.Buffer{}
+	assert.NoError(t, r.WriteJSON(out))
+	var res struct {
+		Elapsed time.Duration `json:"elapsed"`
+		Routes  []*RouteStats `json:"routes"`
+	}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &res))
+	assert.Equal(t, time.Second, res.Elapsed)
+	assert.Equal(t, r.Routes(), res.Routes)
+}
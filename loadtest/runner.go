@@ -0,0 +1,269 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package loadtest
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/topfreegames/pitaya/v2/client"
+	"github.com/topfreegames/pitaya/v2/constants"
+)
+
+// connectRoute is the name the connection latencies are recorded with
+const connectRoute = "connect"
+
+// Config configures a load test
+type Config struct {
+	// Addr is the address of the frontend server
+	Addr string
+	// Protocol is tcp or ws
+	Protocol string
+	// Path is the path of the websocket endpoint
+	Path string
+	// Sessions is the number of simulated sessions
+	Sessions int
+	// Iterations is how many times each session runs the scenario, with 0
+	// the sessions run it until Duration elapses
+	Iterations int
+	// Duration limits how long the load test runs
+	Duration time.Duration
+	// RampUp is the time taken to start all the sessions
+	RampUp time.Duration
+	// RequestTimeout is the timeout of requests and expected pushes without
+	// their own timeout
+	RequestTimeout time.Duration
+	// Dial creates the connections instead of Protocol, e.g. acceptor.PipeAcceptor.Dial
+	Dial func(addr string) (net.Conn, error)
+}
+
+// NewDefaultConfig returns the default load test config
+func NewDefaultConfig() *Config {
+	return &Config{
+		Addr:           "localhost:3250",
+		Protocol:       "tcp",
+		Sessions:       100,
+		Iterations:     1,
+		RequestTimeout: 5 * time.Second,
+	}
+}
+
+type session struct {
+	index    int
+	config   Config
+	scenario *Scenario
+	client   *client.Client
+	connect  func(c *client.Client) error
+	report   *Report
+	pushes   map[string]chan struct{}
+}
+
+// Run runs the scenario in the simulated sessions and returns the latencies
+// of the routes called. It stops when all sessions ran their iterations, when
+// Duration elapses or when ctx is done.
+func Run(ctx context.Context, config Config, scenario *Scenario) (*Report, error) {
+	connect, err := connectFunc(config)
+	if err != nil {
+		return nil, err
+	}
+	if config.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.Duration)
+		defer cancel()
+	}
+
+	report := NewReport()
+	sessions := make([]*session, config.Sessions)
+	for i := range sessions {
+		sessions[i] = &session{
+			index:    i,
+			config:   config,
+			scenario: scenario,
+			client:   client.New(logrus.ErrorLevel, config.RequestTimeout),
+			connect:  connect,
+			report:   report,
+			pushes:   map[string]chan struct{}{},
+		}
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for _, s := range sessions {
+		var delay time.Duration
+		if config.Sessions > 1 {
+			delay = config.RampUp * time.Duration(s.index) / time.Duration(config.Sessions)
+		}
+		wg.Add(1)
+		go func(s *session) {
+			defer wg.Done()
+			s.run(ctx, delay)
+		}(s)
+	}
+	wg.Wait()
+	report.Elapsed = time.Since(start)
+	return report, nil
+}
+
+func connectFunc(config Config) (func(c *client.Client) error, error) {
+	if config.Dial != nil {
+		return func(c *client.Client) error {
+			return c.ConnectWith(config.Addr, config.Dial)
+		}, nil
+	}
+	switch config.Protocol {
+	case "tcp":
+		return func(c *client.Client) error {
+			return c.ConnectTo(config.Addr)
+		}, nil
+	case "ws":
+		return func(c *client.Client) error {
+			return c.ConnectToWS(config.Addr, config.Path)
+		}, nil
+	}
+	return nil, constants.ErrUnknownProtocol
+}
+
+func (s *session) run(ctx context.Context, delay time.Duration) {
+	if !sleep(ctx, delay) {
+		return
+	}
+
+	start := time.Now()
+	err := s.connect(s.client)
+	s.report.Record(connectRoute, time.Since(start), err)
+	if err != nil {
+		return
+	}
+	defer s.client.Disconnect()
+
+	// messages that aren't expected are discarded so the client never blocks
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-s.client.MsgChannel():
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for _, route := range s.scenario.pushRoutes() {
+		if _, ok := s.pushes[route]; ok {
+			continue
+		}
+		pushes := make(chan struct{}, 16)
+		s.pushes[route] = pushes
+		s.client.OnPush(route, func(data []byte) {
+			select {
+			case pushes <- struct{}{}:
+			default:
+			}
+		})
+	}
+
+	for iteration := 0; s.config.Iterations == 0 || iteration < s.config.Iterations; iteration++ {
+		for i, step := range s.scenario.Steps {
+			if ctx.Err() != nil {
+				return
+			}
+			s.runStep(ctx, step, TemplateData{Session: s.index, Iteration: iteration, Step: i})
+			if !sleep(ctx, time.Duration(step.ThinkTime)) {
+				return
+			}
+		}
+	}
+}
+
+func (s *session) runStep(ctx context.Context, step *Step, data TemplateData) {
+	payload, err := step.render(data)
+	if err != nil {
+		s.report.Record(step.Route, 0, err)
+		return
+	}
+
+	var pushes chan struct{}
+	if step.ExpectPush != nil {
+		pushes = s.pushes[step.ExpectPush.Route]
+		// only the pushes arriving after the message is sent are expected
+		for len(pushes) > 0 {
+			<-pushes
+		}
+	}
+
+	start := time.Now()
+	if step.Notify {
+		err = s.notify(step.Route, payload)
+	} else {
+		err = s.client.Request(ctx, step.Route, payload, nil)
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	s.report.Record(step.Route, time.Since(start), err)
+	if err != nil || pushes == nil {
+		return
+	}
+
+	timeout := time.Duration(step.ExpectPush.Timeout)
+	if timeout == 0 {
+		timeout = s.config.RequestTimeout
+	}
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case <-pushes:
+		s.report.Record(step.ExpectPush.Route, time.Since(start), nil)
+	case <-t.C:
+		s.report.Record(step.ExpectPush.Route, timeout, constants.ErrPushTimeout)
+	case <-ctx.Done():
+	}
+}
+
+func (s *session) notify(route string, payload interface{}) error {
+	var data []byte
+	if payload != nil {
+		var err error
+		if data, err = s.client.Marshal(payload); err != nil {
+			return err
+		}
+	}
+	return s.client.SendNotify(route, data)
+}
+
+// sleep waits for d, it returns false if ctx is done before
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,58 @@
+package loadtest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/topfreegames/pitaya/v2/config"
+	"github.com/topfreegames/pitaya/v2/constants"
+)
+
+func TestRunDryRun(t *testing.T) {
+	d := StartDryRun(*config.NewDefaultBuilderConfig(), nil)
+	defer d.Stop()
+
+	scenario, err := ParseScenario([]byte(`{
+		"steps": [
+			{"route": "loadtest.echo", "payload": {"name": "player-{{.Session}}"}},
+			{"route": "loadtest.push", "payload": {"id": "{{uuid}}"}, "expectPush": {"route": "loadtest.onPush"}},
+			{"route": "loadtest.notify", "notify": true},
+			{"route": "loadtest.missing"}
+		]
+	}`))
+	assert.NoError(t, err)
+
+	conf := NewDefaultConfig()
+	conf.Sessions = 10
+	conf.Iterations = 3
+	conf.RampUp = 50 * time.Millisecond
+	conf.Dial = d.Dial
+	report, err := Run(context.Background(), *conf, scenario)
+	assert.NoError(t, err)
+	assert.True(t, report.Elapsed > 0)
+
+	counts := map[string][2]int64{}
+	for _, s := range report.Routes() {
+		counts[s.Route] = [2]int64{s.Count, s.Errors}
+	}
+	assert.Equal(t, map[string][2]int64{
+		"connect":          {10, 0},
+		"loadtest.echo":    {30, 0},
+		"loadtest.push":    {30, 0},
+		"loadtest.onPush":  {30, 0},
+		"loadtest.notify":  {30, 0},
+		"loadtest.missing": {30, 30},
+	}, counts)
+}
+
+func TestRunUnknownProtocol(t *testing.T) {
+	t.Parallel()
+	scenario, err := ParseScenario([]byte(`{"steps": [{"route": "loadtest.echo"}]}`))
+	assert.NoError(t, err)
+	conf := NewDefaultConfig()
+	conf.Protocol = "udp"
+	_, err = Run(context.Background(), *conf, scenario)
+	assert.Equal(t, constants.ErrUnknownProtocol, err)
+}
@@ -0,0 +1,184 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package loadtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/topfreegames/pitaya/v2/constants"
+)
+
+// Scenario is the sequence of steps each simulated session runs
+type Scenario struct {
+	Name  string  `json:"name"`
+	Steps []*Step `json:"steps"`
+}
+
+// Step sends a request or notify to a route and optionally waits for a push
+type Step struct {
+	Route string `json:"route"`
+	// Notify sends a notify instead of a request
+	Notify bool `json:"notify"`
+	// Payload is a text/template rendering the JSON sent to the route, it may
+	// be written as a JSON value or as a string holding the template
+	Payload Payload `json:"payload"`
+	// ThinkTime is how long the session waits after the step
+	ThinkTime Duration `json:"thinkTime"`
+	// ExpectPush makes the session wait for a push after sending the message
+	ExpectPush *ExpectedPush `json:"expectPush"`
+
+	template *template.Template
+}
+
+// ExpectedPush is a push a session waits for after a step
+type ExpectedPush struct {
+	Route   string   `json:"route"`
+	Timeout Duration `json:"timeout"`
+}
+
+// TemplateData is the data available to payload templates
+type TemplateData struct {
+	// Session is the index of the simulated session
+	Session int
+	// Iteration is the number of times the session ran the scenario
+	Iteration int
+	// Step is the index of the step in the scenario
+	Step int
+}
+
+// Payload is the template text of a step payload
+type Payload string
+
+// UnmarshalJSON accepts a JSON string holding the template or any other JSON
+// value, which is used verbatim as the template
+func (p *Payload) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*p = Payload(s)
+		return nil
+	}
+	*p = Payload(b)
+	return nil
+}
+
+// Duration is a time.Duration read from strings such as "100ms" or "2s"
+type Duration time.Duration
+
+// UnmarshalJSON parses the duration with time.ParseDuration
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+var templateFuncs = template.FuncMap{
+	// randInt returns a random int in [min, max)
+	"randInt": func(min, max int) int {
+		return min + rand.Intn(max-min)
+	},
+	"uuid": func() string {
+		return uuid.New().String()
+	},
+}
+
+// LoadScenario reads a scenario from a JSON file
+func LoadScenario(path string) (*Scenario, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseScenario(b)
+}
+
+// ParseScenario parses a JSON scenario and its payload templates
+func ParseScenario(b []byte) (*Scenario, error) {
+	scenario := &Scenario{}
+	if err := json.Unmarshal(b, scenario); err != nil {
+		return nil, err
+	}
+	if err := scenario.compile(); err != nil {
+		return nil, err
+	}
+	return scenario, nil
+}
+
+func (s *Scenario) compile() error {
+	if len(s.Steps) == 0 {
+		return constants.ErrEmptyScenario
+	}
+	for i, step := range s.Steps {
+		if step.Route == "" {
+			return fmt.Errorf("step %d: %w", i, constants.ErrStepWithoutRoute)
+		}
+		if step.ExpectPush != nil && step.ExpectPush.Route == "" {
+			return fmt.Errorf("step %d: %w", i, constants.ErrPushWithoutRoute)
+		}
+		t, err := template.New(step.Route).Funcs(templateFuncs).Parse(string(step.Payload))
+		if err != nil {
+			return fmt.Errorf("step %d: %w", i, err)
+		}
+		step.template = t
+	}
+	return nil
+}
+
+// pushRoutes returns the routes of the pushes the scenario waits for
+func (s *Scenario) pushRoutes() []string {
+	routes := []string{}
+	for _, step := range s.Steps {
+		if step.ExpectPush != nil {
+			routes = append(routes, step.ExpectPush.Route)
+		}
+	}
+	return routes
+}
+
+// render executes the payload template of the step and decodes the JSON
+// produced, it returns nil for steps without payload
+func (s *Step) render(data TemplateData) (interface{}, error) {
+	buf := &bytes.Buffer{}
+	if err := s.template.Execute(buf, data); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(buf.String()) == "" {
+		return nil, nil
+	}
+	var payload interface{}
+	if err := json.Unmarshal(buf.Bytes(), &payload); err != nil {
+		return nil, fmt.Errorf("invalid payload for route %s: %w", s.Route, err)
+	}
+	return payload, nil
+}
@@ -0,0 +1,74 @@
+package loadtest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/topfreegames/pitaya/v2/constants"
+)
+
+func TestParseScenario(t *testing.T) {
+	t.Parallel()
+	scenario, err := ParseScenario([]byte(`{
+		"name": "room",
+		"steps": [
+			{"route": "room.room.join", "payload": {"name": "player-{{.Session}}"}, "thinkTime": "100ms"},
+			{"route": "room.room.message", "notify": true, "payload": "{\"step\": {{.Step}}, \"iteration\": {{.Iteration}}}",
+				"expectPush": {"route": "onMessage", "timeout": "1s"}},
+			{"route": "room.room.leave"}
+		]
+	}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "room", scenario.Name)
+	assert.Len(t, scenario.Steps, 3)
+	assert.Equal(t, Duration(100*time.Millisecond), scenario.Steps[0].ThinkTime)
+	assert.True(t, scenario.Steps[1].Notify)
+	assert.Equal(t, &ExpectedPush{Route: "onMessage", Timeout: Duration(time.Second)}, scenario.Steps[1].ExpectPush)
+	assert.Equal(t, []string{"onMessage"}, scenario.pushRoutes())
+
+	payload, err := scenario.Steps[0].render(TemplateData{Session: 7})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "player-7"}, payload)
+
+	payload, err = scenario.Steps[1].render(TemplateData{Session: 7, Iteration: 2, Step: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"step": float64(1), "iteration": float64(2)}, payload)
+
+	payload, err = scenario.Steps[2].render(TemplateData{})
+	assert.NoError(t, err)
+	assert.Nil(t, payload)
+}
+
+func TestParseScenarioErrors(t *testing.T) {
+	t.Parallel()
+	tables := []struct {
+		name     string
+		scenario string
+		err      error
+	}{
+		{"no_steps", `{"name": "empty"}`, constants.ErrEmptyScenario},
+		{"no_route", `{"steps": [{"payload": "{}"}]}`, constants.ErrStepWithoutRoute},
+		{"no_push_route", `{"steps": [{"route": "a.b", "expectPush": {}}]}`, constants.ErrPushWithoutRoute},
+	}
+	for _, table := range tables {
+		t.Run(table.name, func(t *testing.T) {
+			_, err := ParseScenario([]byte(table.scenario))
+			assert.True(t, errors.Is(err, table.err))
+		})
+	}
+
+	_, err := ParseScenario([]byte(`{"steps": [{"route": "a.b", "thinkTime": "soon"}]}`))
+	assert.Error(t, err)
+	_, err = ParseScenario([]byte(`{"steps": [{"route": "a.b", "payload": "{{.Session"}]}`))
+	assert.Error(t, err)
+}
+
+func TestRenderInvalidPayload(t *testing.T) {
+	t.Parallel()
+	scenario, err := ParseScenario([]byte(`{"steps": [{"route": "a.b", "payload": "{\"id\": {{uuid}}}"}]}`))
+	assert.NoError(t, err)
+	_, err = scenario.Steps[0].render(TemplateData{})
+	assert.Error(t, err)
+}
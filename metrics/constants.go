@@ -23,9 +23,25 @@ var (
 	WorkerJobsTotal = "worker_jobs_total"
 	// WorkerJobsRetry reports the number of retried jobs
 	WorkerJobsRetry = "worker_jobs_retry_total"
+	// WorkerJobsDead reports the number of jobs that ran out of retries
+	WorkerJobsDead = "worker_jobs_dead_total"
 	// WorkerQueueSize reports the queue size on worker
 	WorkerQueueSize = "worker_queue_size"
 	// ExceededRateLimiting reports the number of requests made in a connection
 	// after the rate limit was exceeded
 	ExceededRateLimiting = "exceeded_rate_limiting"
+	// FrozenPushes reports the number of pushes frozen waiting for the response
+	// of the request they are related to
+	FrozenPushes = "frozen_pushes"
+	// PushFreezeTime reports how long pushes were frozen in nanoseconds
+	PushFreezeTime = "push_freeze_time_ns"
+	// DroppedPushes reports the number of frozen pushes that were dropped
+	DroppedPushes = "dropped_pushes"
+	// Mailboxes reports the number of mailboxes of the actor concurrency model
+	Mailboxes = "mailboxes"
+	// MailboxDepth reports the number of messages waiting in a mailbox
+	MailboxDepth = "mailbox_depth"
+	// CompressionRatio reports the size of the compressed data of the messages
+	// sent to the clients relative to their size before compression
+	CompressionRatio = "compression_ratio"
 )
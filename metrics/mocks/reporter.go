@@ -57,6 +57,18 @@ func (mr *MockReporterMockRecorder) ReportSummary(metric, tags, value interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportSummary", reflect.TypeOf((*MockReporter)(nil).ReportSummary), metric, tags, value)
 }
 
+// ReportHistogram mocks base method
+func (m *MockReporter) ReportHistogram(metric string, tags map[string]string, value float64) error {
+	ret := m.ctrl.Call(m, "ReportHistogram", metric, tags, value)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReportHistogram indicates an expected call of ReportHistogram
+func (mr *MockReporterMockRecorder) ReportHistogram(metric, tags, value interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportHistogram", reflect.TypeOf((*MockReporter)(nil).ReportHistogram), metric, tags, value)
+}
+
 // ReportGauge mocks base method
 func (m *MockReporter) ReportGauge(metric string, tags map[string]string, value float64) error {
 	ret := m.ctrl.Call(m, "ReportGauge", metric, tags, value)
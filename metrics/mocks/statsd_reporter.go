@@ -57,6 +57,18 @@ func (mr *MockClientMockRecorder) Gauge(name, value, tags, rate interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Gauge", reflect.TypeOf((*MockClient)(nil).Gauge), name, value, tags, rate)
 }
 
+// Distribution mocks base method
+func (m *MockClient) Distribution(name string, value float64, tags []string, rate float64) error {
+	ret := m.ctrl.Call(m, "Distribution", name, value, tags, rate)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Distribution indicates an expected call of Distribution
+func (mr *MockClientMockRecorder) Distribution(name, value, tags, rate interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Distribution", reflect.TypeOf((*MockClient)(nil).Distribution), name, value, tags, rate)
+}
+
 // TimeInMilliseconds mocks base method
 func (m *MockClient) TimeInMilliseconds(name string, value float64, tags []string, rate float64) error {
 	ret := m.ctrl.Call(m, "TimeInMilliseconds", name, value, tags, rate)
@@ -0,0 +1,214 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package metrics
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+
+	"github.com/topfreegames/pitaya/v2/config"
+)
+
+// meterName is the instrumentation name of the OTLP metrics
+const meterName = "github.com/topfreegames/pitaya/v2"
+
+// OTLPReporter sends application metrics to an OpenTelemetry collector,
+// summaries are reported as histograms as OpenTelemetry has no summaries
+type OTLPReporter struct {
+	provider     *sdkmetric.MeterProvider
+	meter        metric.Meter
+	defaultAttrs []attribute.KeyValue
+	mutex        sync.Mutex
+	counters     map[string]metric.Float64Counter
+	histograms   map[string]metric.Float64Histogram
+	gauges       map[string]*otlpGauge
+}
+
+// otlpGauge keeps the last value reported for each set of attributes, which
+// is observed when the metrics are collected
+type otlpGauge struct {
+	mutex  sync.Mutex
+	values map[attribute.Distinct]otlpGaugeValue
+}
+
+type otlpGaugeValue struct {
+	attrs attribute.Set
+	value float64
+}
+
+// NewOTLPReporter returns an instance of the OTLP reporter, the metrics are
+// exported periodically to the OTLP gRPC endpoint unless a reader is given
+func NewOTLPReporter(
+	config config.OTLPConfig,
+	serverType string,
+	readerOrNil ...sdkmetric.Reader,
+) (*OTLPReporter, error) {
+	var reader sdkmetric.Reader
+	if len(readerOrNil) > 0 {
+		reader = readerOrNil[0]
+	} else {
+		opts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithEndpoint(config.OTLP.Endpoint)}
+		if config.OTLP.Insecure {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		}
+		exporter, err := otlpmetricgrpc.New(context.Background(), opts...)
+		if err != nil {
+			return nil, err
+		}
+		reader = sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(config.OTLP.Period))
+	}
+
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(reader),
+		sdkmetric.WithView(sdkmetric.NewView(
+			sdkmetric.Instrument{Kind: sdkmetric.InstrumentKindHistogram},
+			sdkmetric.Stream{Aggregation: sdkmetric.AggregationExplicitBucketHistogram{
+				Boundaries: config.Histograms.Buckets,
+			}},
+		)),
+	)
+
+	defaultAttrs := []attribute.KeyValue{attribute.String("serverType", serverType)}
+	for k, v := range config.ConstLabels {
+		defaultAttrs = append(defaultAttrs, attribute.String(k, v))
+	}
+
+	return &OTLPReporter{
+		provider:     provider,
+		meter:        provider.Meter(meterName),
+		defaultAttrs: defaultAttrs,
+		counters:     make(map[string]metric.Float64Counter),
+		histograms:   make(map[string]metric.Float64Histogram),
+		gauges:       make(map[string]*otlpGauge),
+	}, nil
+}
+
+func (o *OTLPReporter) attributes(tags map[string]string) attribute.Set {
+	attrs := make([]attribute.KeyValue, 0, len(o.defaultAttrs)+len(tags))
+	attrs = append(attrs, o.defaultAttrs...)
+	for k, v := range tags {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+	return attribute.NewSet(attrs...)
+}
+
+func (o *OTLPReporter) counter(name string) (metric.Float64Counter, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if c, ok := o.counters[name]; ok {
+		return c, nil
+	}
+	c, err := o.meter.Float64Counter(name)
+	if err != nil {
+		return nil, err
+	}
+	o.counters[name] = c
+	return c, nil
+}
+
+func (o *OTLPReporter) histogram(name string) (metric.Float64Histogram, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if h, ok := o.histograms[name]; ok {
+		return h, nil
+	}
+	h, err := o.meter.Float64Histogram(name)
+	if err != nil {
+		return nil, err
+	}
+	o.histograms[name] = h
+	return h, nil
+}
+
+func (o *OTLPReporter) gauge(name string) (*otlpGauge, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if g, ok := o.gauges[name]; ok {
+		return g, nil
+	}
+	g := &otlpGauge{values: make(map[attribute.Distinct]otlpGaugeValue)}
+	_, err := o.meter.Float64ObservableGauge(name, metric.WithFloat64Callback(g.observe))
+	if err != nil {
+		return nil, err
+	}
+	o.gauges[name] = g
+	return g, nil
+}
+
+func (g *otlpGauge) set(attrs attribute.Set, value float64) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.values[attrs.Equivalent()] = otlpGaugeValue{attrs: attrs, value: value}
+}
+
+func (g *otlpGauge) observe(_ context.Context, o metric.Float64Observer) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	for _, v := range g.values {
+		o.Observe(v.value, metric.WithAttributeSet(v.attrs))
+	}
+	return nil
+}
+
+// ReportCount adds the count to the counter
+func (o *OTLPReporter) ReportCount(metricName string, tags map[string]string, count float64) error {
+	c, err := o.counter(metricName)
+	if err != nil {
+		return err
+	}
+	c.Add(context.Background(), count, metric.WithAttributeSet(o.attributes(tags)))
+	return nil
+}
+
+// ReportSummary records the value in a histogram
+func (o *OTLPReporter) ReportSummary(metricName string, tags map[string]string, value float64) error {
+	return o.ReportHistogram(metricName, tags, value)
+}
+
+// ReportHistogram records the value in the histogram
+func (o *OTLPReporter) ReportHistogram(metricName string, tags map[string]string, value float64) error {
+	h, err := o.histogram(metricName)
+	if err != nil {
+		return err
+	}
+	h.Record(context.Background(), value, metric.WithAttributeSet(o.attributes(tags)))
+	return nil
+}
+
+// ReportGauge sets the gauge value, which is exported on the next collection
+func (o *OTLPReporter) ReportGauge(metricName string, tags map[string]string, value float64) error {
+	g, err := o.gauge(metricName)
+	if err != nil {
+		return err
+	}
+	g.set(o.attributes(tags), value)
+	return nil
+}
+
+// Shutdown flushes the metrics not exported yet and stops the reporter
+func (o *OTLPReporter) Shutdown(ctx context.Context) error {
+	return o.provider.Shutdown(ctx)
+}
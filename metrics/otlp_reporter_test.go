@@ -0,0 +1,125 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package metrics
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/topfreegames/pitaya/v2/config"
+)
+
+// otlpDataPoint has the fields of the data points of every aggregation used,
+// as the aggregations are generic types
+type otlpDataPoint struct {
+	Attributes   attribute.Set
+	Value        float64
+	Count        uint64
+	Bounds       []float64
+	BucketCounts []uint64
+}
+
+func collectOTLPMetrics(t *testing.T, reader *sdkmetric.ManualReader) map[string][]otlpDataPoint {
+	t.Helper()
+	rm := metricdata.ResourceMetrics{}
+	assert.NoError(t, reader.Collect(context.Background(), &rm))
+	res := map[string][]otlpDataPoint{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			points := reflect.ValueOf(m.Data).FieldByName("DataPoints")
+			for i := 0; i < points.Len(); i++ {
+				p := points.Index(i)
+				dp := otlpDataPoint{Attributes: p.FieldByName("Attributes").Interface().(attribute.Set)}
+				if v := p.FieldByName("Value"); v.IsValid() {
+					dp.Value = v.Float()
+				}
+				if v := p.FieldByName("Count"); v.IsValid() {
+					dp.Count = v.Uint()
+					dp.Bounds = p.FieldByName("Bounds").Interface().([]float64)
+					dp.BucketCounts = p.FieldByName("BucketCounts").Interface().([]uint64)
+				}
+				res[m.Name] = append(res[m.Name], dp)
+			}
+		}
+	}
+	return res
+}
+
+func getOTLPReporter(t *testing.T) (*OTLPReporter, *sdkmetric.ManualReader) {
+	t.Helper()
+	cfg := config.NewDefaultOTLPConfig()
+	cfg.ConstLabels = map[string]string{"defaultTag": "value"}
+	cfg.Histograms.Buckets = []float64{10, 100}
+	reader := sdkmetric.NewManualReader()
+	o, err := NewOTLPReporter(*cfg, "svType", reader)
+	assert.NoError(t, err)
+	return o, reader
+}
+
+func TestOTLPReporterReportCount(t *testing.T) {
+	o, reader := getOTLPReporter(t)
+
+	assert.NoError(t, o.ReportCount("count", map[string]string{"tag": "a"}, 1))
+	assert.NoError(t, o.ReportCount("count", map[string]string{"tag": "a"}, 2))
+
+	data := collectOTLPMetrics(t, reader)["count"]
+	assert.Len(t, data, 1)
+	assert.Equal(t, float64(3), data[0].Value)
+	assert.Equal(t, attribute.NewSet(
+		attribute.String("serverType", "svType"),
+		attribute.String("defaultTag", "value"),
+		attribute.String("tag", "a"),
+	), data[0].Attributes)
+}
+
+func TestOTLPReporterReportHistogram(t *testing.T) {
+	o, reader := getOTLPReporter(t)
+
+	assert.NoError(t, o.ReportHistogram(ResponseTime, map[string]string{}, 5))
+	assert.NoError(t, o.ReportSummary(ResponseTime, map[string]string{}, 50))
+	assert.NoError(t, o.ReportHistogram(ResponseTime, map[string]string{}, 500))
+
+	data := collectOTLPMetrics(t, reader)[ResponseTime]
+	assert.Len(t, data, 1)
+	assert.Equal(t, []float64{10, 100}, data[0].Bounds)
+	assert.Equal(t, []uint64{1, 1, 1}, data[0].BucketCounts)
+	assert.Equal(t, uint64(3), data[0].Count)
+}
+
+func TestOTLPReporterReportGauge(t *testing.T) {
+	o, reader := getOTLPReporter(t)
+
+	assert.NoError(t, o.ReportGauge(ConnectedClients, map[string]string{}, 10))
+	assert.NoError(t, o.ReportGauge(ConnectedClients, map[string]string{}, 7))
+	assert.NoError(t, o.ReportGauge(CountServers, map[string]string{"type": "a"}, 1))
+	assert.NoError(t, o.ReportGauge(CountServers, map[string]string{"type": "b"}, 2))
+
+	metrics := collectOTLPMetrics(t, reader)
+	assert.Len(t, metrics[ConnectedClients], 1)
+	assert.Equal(t, float64(7), metrics[ConnectedClients][0].Value)
+	assert.Len(t, metrics[CountServers], 2)
+}
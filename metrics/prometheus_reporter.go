@@ -41,12 +41,14 @@ var (
 
 // PrometheusReporter reports metrics to prometheus
 type PrometheusReporter struct {
-	serverType          string
-	game                string
-	countReportersMap   map[string]*prometheus.CounterVec
-	summaryReportersMap map[string]*prometheus.SummaryVec
-	gaugeReportersMap   map[string]*prometheus.GaugeVec
-	additionalLabels    map[string]string
+	serverType            string
+	game                  string
+	countReportersMap     map[string]*prometheus.CounterVec
+	summaryReportersMap   map[string]*prometheus.SummaryVec
+	histogramReportersMap map[string]*prometheus.HistogramVec
+	gaugeReportersMap     map[string]*prometheus.GaugeVec
+	additionalLabels      map[string]string
+	histograms            config.HistogramsConfig
 }
 
 func (p *PrometheusReporter) registerCustomMetrics(
@@ -68,6 +70,24 @@ func (p *PrometheusReporter) registerCustomMetrics(
 		)
 	}
 
+	for _, histogram := range spec.Histograms {
+		buckets := histogram.Buckets
+		if len(buckets) == 0 {
+			buckets = p.histograms.Buckets
+		}
+		p.histogramReportersMap[histogram.Name] = prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace:   "pitaya",
+				Subsystem:   histogram.Subsystem,
+				Name:        histogram.Name,
+				Help:        histogram.Help,
+				Buckets:     buckets,
+				ConstLabels: constLabels,
+			},
+			append(additionalLabelsKeys, histogram.Labels...),
+		)
+	}
+
 	for _, gauge := range spec.Gauges {
 		p.gaugeReportersMap[gauge.Name] = prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
@@ -111,31 +131,59 @@ func (p *PrometheusReporter) registerMetrics(
 
 	p.registerCustomMetrics(constLabels, additionalLabelsKeys, spec)
 
-	// HandlerResponseTimeMs summary
-	p.summaryReportersMap[ResponseTime] = prometheus.NewSummaryVec(
-		prometheus.SummaryOpts{
-			Namespace:   "pitaya",
-			Subsystem:   "handler",
-			Name:        ResponseTime,
-			Help:        "the time to process a msg in nanoseconds",
-			Objectives:  map[float64]float64{0.7: 0.02, 0.95: 0.005, 0.99: 0.001},
-			ConstLabels: constLabels,
-		},
-		append([]string{"route", "status", "type", "code"}, additionalLabelsKeys...),
-	)
+	if p.histograms.Latency {
+		// HandlerResponseTimeMs histogram
+		p.histogramReportersMap[ResponseTime] = prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace:   "pitaya",
+				Subsystem:   "handler",
+				Name:        ResponseTime,
+				Help:        "the time to process a msg in nanoseconds",
+				Buckets:     p.histograms.Buckets,
+				ConstLabels: constLabels,
+			},
+			append([]string{"route", "status", "type", "code"}, additionalLabelsKeys...),
+		)
 
-	// ProcessDelay summary
-	p.summaryReportersMap[ProcessDelay] = prometheus.NewSummaryVec(
-		prometheus.SummaryOpts{
-			Namespace:   "pitaya",
-			Subsystem:   "handler",
-			Name:        ProcessDelay,
-			Help:        "the delay to start processing a msg in nanoseconds",
-			Objectives:  map[float64]float64{0.7: 0.02, 0.95: 0.005, 0.99: 0.001},
-			ConstLabels: constLabels,
-		},
-		append([]string{"route", "type"}, additionalLabelsKeys...),
-	)
+		// ProcessDelay histogram
+		p.histogramReportersMap[ProcessDelay] = prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace:   "pitaya",
+				Subsystem:   "handler",
+				Name:        ProcessDelay,
+				Help:        "the delay to start processing a msg in nanoseconds",
+				Buckets:     p.histograms.Buckets,
+				ConstLabels: constLabels,
+			},
+			append([]string{"route", "type"}, additionalLabelsKeys...),
+		)
+	} else {
+		// HandlerResponseTimeMs summary
+		p.summaryReportersMap[ResponseTime] = prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Namespace:   "pitaya",
+				Subsystem:   "handler",
+				Name:        ResponseTime,
+				Help:        "the time to process a msg in nanoseconds",
+				Objectives:  map[float64]float64{0.7: 0.02, 0.95: 0.005, 0.99: 0.001},
+				ConstLabels: constLabels,
+			},
+			append([]string{"route", "status", "type", "code"}, additionalLabelsKeys...),
+		)
+
+		// ProcessDelay summary
+		p.summaryReportersMap[ProcessDelay] = prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Namespace:   "pitaya",
+				Subsystem:   "handler",
+				Name:        ProcessDelay,
+				Help:        "the delay to start processing a msg in nanoseconds",
+				Objectives:  map[float64]float64{0.7: 0.02, 0.95: 0.005, 0.99: 0.001},
+				ConstLabels: constLabels,
+			},
+			append([]string{"route", "type"}, additionalLabelsKeys...),
+		)
+	}
 
 	// ConnectedClients gauge
 	p.gaugeReportersMap[ConnectedClients] = prometheus.NewGaugeVec(
@@ -226,6 +274,17 @@ func (p *PrometheusReporter) registerMetrics(
 		additionalLabelsKeys,
 	)
 
+	p.gaugeReportersMap[WorkerJobsDead] = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace:   "pitaya",
+			Subsystem:   "worker",
+			Name:        WorkerJobsDead,
+			Help:        "the current number of jobs that ran out of retries",
+			ConstLabels: constLabels,
+		},
+		additionalLabelsKeys,
+	)
+
 	p.gaugeReportersMap[WorkerQueueSize] = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace:   "pitaya",
@@ -259,6 +318,75 @@ func (p *PrometheusReporter) registerMetrics(
 		additionalLabelsKeys,
 	)
 
+	p.gaugeReportersMap[FrozenPushes] = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace:   "pitaya",
+			Subsystem:   "agent",
+			Name:        FrozenPushes,
+			Help:        "the number of pushes frozen waiting for the response of their request",
+			ConstLabels: constLabels,
+		},
+		additionalLabelsKeys,
+	)
+
+	p.summaryReportersMap[PushFreezeTime] = prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Namespace:   "pitaya",
+			Subsystem:   "agent",
+			Name:        PushFreezeTime,
+			Help:        "the time a push was frozen in nanoseconds",
+			Objectives:  map[float64]float64{0.7: 0.02, 0.95: 0.005, 0.99: 0.001},
+			ConstLabels: constLabels,
+		},
+		append([]string{"reason"}, additionalLabelsKeys...),
+	)
+
+	p.countReportersMap[DroppedPushes] = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace:   "pitaya",
+			Subsystem:   "agent",
+			Name:        DroppedPushes,
+			Help:        "the number of frozen pushes that were dropped",
+			ConstLabels: constLabels,
+		},
+		append([]string{"reason"}, additionalLabelsKeys...),
+	)
+
+	p.gaugeReportersMap[Mailboxes] = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace:   "pitaya",
+			Subsystem:   "handler",
+			Name:        Mailboxes,
+			Help:        "the number of mailboxes running right now",
+			ConstLabels: constLabels,
+		},
+		additionalLabelsKeys,
+	)
+
+	p.summaryReportersMap[MailboxDepth] = prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Namespace:   "pitaya",
+			Subsystem:   "handler",
+			Name:        MailboxDepth,
+			Help:        "the number of messages waiting in a mailbox when a new one arrives",
+			Objectives:  map[float64]float64{0.7: 0.02, 0.95: 0.005, 0.99: 0.001},
+			ConstLabels: constLabels,
+		},
+		additionalLabelsKeys,
+	)
+
+	p.summaryReportersMap[CompressionRatio] = prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Namespace:   "pitaya",
+			Subsystem:   "agent",
+			Name:        CompressionRatio,
+			Help:        "the size of the compressed data of the messages relative to their size before compression",
+			Objectives:  map[float64]float64{0.7: 0.02, 0.95: 0.005, 0.99: 0.001},
+			ConstLabels: constLabels,
+		},
+		append([]string{"route", "compression"}, additionalLabelsKeys...),
+	)
+
 	toRegister := make([]prometheus.Collector, 0)
 	for _, c := range p.countReportersMap {
 		toRegister = append(toRegister, c)
@@ -272,6 +400,10 @@ func (p *PrometheusReporter) registerMetrics(
 		toRegister = append(toRegister, c)
 	}
 
+	for _, c := range p.histogramReportersMap {
+		toRegister = append(toRegister, c)
+	}
+
 	prometheus.MustRegister(toRegister...)
 }
 
@@ -291,11 +423,13 @@ func getPrometheusReporter(
 ) (*PrometheusReporter, error) {
 	once.Do(func() {
 		prometheusReporter = &PrometheusReporter{
-			serverType:          serverType,
-			game:                config.Game,
-			countReportersMap:   make(map[string]*prometheus.CounterVec),
-			summaryReportersMap: make(map[string]*prometheus.SummaryVec),
-			gaugeReportersMap:   make(map[string]*prometheus.GaugeVec),
+			serverType:            serverType,
+			game:                  config.Game,
+			countReportersMap:     make(map[string]*prometheus.CounterVec),
+			summaryReportersMap:   make(map[string]*prometheus.SummaryVec),
+			histogramReportersMap: make(map[string]*prometheus.HistogramVec),
+			gaugeReportersMap:     make(map[string]*prometheus.GaugeVec),
+			histograms:            config.Histograms,
 		}
 		prometheusReporter.registerMetrics(config.ConstLabels, config.Prometheus.AdditionalLabels, metricsSpecs)
 		http.Handle("/metrics", promhttp.Handler())
@@ -321,6 +455,17 @@ func (p *PrometheusReporter) ReportSummary(metric string, labels map[string]stri
 	return constants.ErrMetricNotKnown
 }
 
+// ReportHistogram reports a histogram metric
+func (p *PrometheusReporter) ReportHistogram(metric string, labels map[string]string, value float64) error {
+	h := p.histogramReportersMap[metric]
+	if h != nil {
+		labels = p.ensureLabels(labels)
+		h.With(labels).Observe(value)
+		return nil
+	}
+	return constants.ErrMetricNotKnown
+}
+
 // ReportCount reports a summary metric
 func (p *PrometheusReporter) ReportCount(metric string, labels map[string]string, count float64) error {
 	cnt := p.countReportersMap[metric]
@@ -343,6 +488,10 @@ func (p *PrometheusReporter) ReportGauge(metric string, labels map[string]string
 	return constants.ErrMetricNotKnown
 }
 
+func (p *PrometheusReporter) latencyHistograms() bool {
+	return p.histograms.Latency
+}
+
 // ensureLabels checks if labels contains the additionalLabels values,
 // otherwise adds them with the default values
 func (p *PrometheusReporter) ensureLabels(labels map[string]string) map[string]string {
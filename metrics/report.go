@@ -54,7 +54,7 @@ func ReportTimingFromCtx(ctx context.Context, reporters []Reporter, typ string,
 			"code":   code,
 		})
 		for _, r := range reporters {
-			r.ReportSummary(ResponseTime, tags, float64(elapsed.Nanoseconds()))
+			reportLatency(r, ResponseTime, tags, float64(elapsed.Nanoseconds()))
 		}
 	}
 }
@@ -70,7 +70,7 @@ func ReportMessageProcessDelayFromCtx(ctx context.Context, reporters []Reporter,
 			"type":  typ,
 		})
 		for _, r := range reporters {
-			r.ReportSummary(ProcessDelay, tags, float64(elapsed.Nanoseconds()))
+			reportLatency(r, ProcessDelay, tags, float64(elapsed.Nanoseconds()))
 		}
 	}
 }
@@ -107,6 +107,22 @@ func ReportExceededRateLimiting(reporters []Reporter) {
 	}
 }
 
+// latencyReporter is implemented by the reporters that can be configured to
+// report the latency metrics as histograms
+type latencyReporter interface {
+	latencyHistograms() bool
+}
+
+// reportLatency reports a latency metric as a histogram if the reporter is
+// configured to do so, otherwise as a summary
+func reportLatency(r Reporter, metric string, tags map[string]string, value float64) {
+	if l, ok := r.(latencyReporter); ok && l.latencyHistograms() {
+		r.ReportHistogram(metric, tags, value)
+		return
+	}
+	r.ReportSummary(metric, tags, value)
+}
+
 func tagsFromContext(ctx context.Context) map[string]string {
 	val := pcontext.GetFromPropagateCtx(ctx, constants.MetricTagsKey)
 	if val == nil {
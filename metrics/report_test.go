@@ -29,6 +29,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/topfreegames/pitaya/v2/config"
 	"github.com/topfreegames/pitaya/v2/constants"
 	pcontext "github.com/topfreegames/pitaya/v2/context"
 	e "github.com/topfreegames/pitaya/v2/errors"
@@ -182,4 +183,22 @@ func TestReportMessageProcessDelayFromCtx(t *testing.T) {
 
 		ReportMessageProcessDelayFromCtx(ctx, []Reporter{mockMetricsReporter}, expectedType)
 	})
+
+	t.Run("test-histogram", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockClient := mocks.NewMockClient(ctrl)
+
+		cfg := config.NewDefaultStatsdConfig()
+		cfg.Histograms.Latency = true
+		sr, err := NewStatsdReporter(*cfg, "svType", mockClient)
+		assert.NoError(t, err)
+
+		ctx := pcontext.AddToPropagateCtx(context.Background(), constants.StartTimeKey, time.Now().UnixNano())
+		ctx = pcontext.AddToPropagateCtx(ctx, constants.RouteKey, uuid.New().String())
+
+		mockClient.EXPECT().Distribution(ProcessDelay, gomock.Any(), gomock.Any(), sr.rate)
+
+		ReportMessageProcessDelayFromCtx(ctx, []Reporter{sr}, "local")
+	})
 }
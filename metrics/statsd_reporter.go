@@ -33,6 +33,7 @@ type Client interface {
 	Count(name string, value int64, tags []string, rate float64) error
 	Gauge(name string, value float64, tags []string, rate float64) error
 	TimeInMilliseconds(name string, value float64, tags []string, rate float64) error
+	Distribution(name string, value float64, tags []string, rate float64) error
 }
 
 // StatsdReporter sends application metrics to statsd
@@ -41,6 +42,7 @@ type StatsdReporter struct {
 	rate        float64
 	serverType  string
 	defaultTags []string
+	latency     bool
 }
 
 // NewStatsdReporter returns an instance of statsd reportar and an
@@ -60,6 +62,7 @@ func newStatsdReporter(
 	sr := &StatsdReporter{
 		rate:       config.Statsd.Rate,
 		serverType: serverType,
+		latency:    config.Histograms.Latency,
 	}
 
 	sr.buildDefaultTags(config.ConstLabels)
@@ -138,3 +141,24 @@ func (s *StatsdReporter) ReportSummary(metric string, tagsMap map[string]string,
 
 	return err
 }
+
+// ReportHistogram observes the histogram value and reports it to statsd as a
+// distribution, which is aggregated by the agent
+func (s *StatsdReporter) ReportHistogram(metric string, tagsMap map[string]string, value float64) error {
+	fullTags := s.defaultTags
+
+	for k, v := range tagsMap {
+		fullTags = append(fullTags, fmt.Sprintf("%s:%s", k, v))
+	}
+
+	err := s.client.Distribution(metric, value, fullTags, s.rate)
+	if err != nil {
+		logger.Log.Errorf("failed to report histogram: %q", err)
+	}
+
+	return err
+}
+
+func (s *StatsdReporter) latencyHistograms() bool {
+	return s.latency
+}
@@ -191,3 +191,48 @@ func TestReportGaugeError(t *testing.T) {
 	err = sr.ReportGauge("123", map[string]string{}, float64(123.1))
 	assert.Equal(t, expectedError, err)
 }
+
+func TestReportHistogram(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockClient := metricsmocks.NewMockClient(ctrl)
+
+	cfg := config.NewDefaultStatsdConfig()
+	cfg.ConstLabels = map[string]string{
+		"defaultTag": "value",
+	}
+	sr, err := NewStatsdReporter(*cfg, "svType", mockClient)
+	assert.NoError(t, err)
+
+	expectedValue := 123.1
+	expectedMetric := uuid.New().String()
+	customTags := map[string]string{
+		"tag1:": uuid.New().String(),
+	}
+	mockClient.EXPECT().Distribution(expectedMetric, expectedValue, gomock.Any(), sr.rate).Do(func(n string, v float64, tags []string, r float64) {
+		for k, v := range customTags {
+			assert.Contains(t, tags, fmt.Sprintf("%s:%s", k, v))
+		}
+		assert.Contains(t, tags, fmt.Sprintf("serverType:%s", sr.serverType))
+		assert.Contains(t, tags, "defaultTag:value")
+	})
+
+	err = sr.ReportHistogram(expectedMetric, customTags, expectedValue)
+	assert.NoError(t, err)
+}
+
+func TestReportHistogramError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockClient := metricsmocks.NewMockClient(ctrl)
+
+	cfg := config.NewDefaultStatsdConfig()
+	sr, err := NewStatsdReporter(*cfg, "svType", mockClient)
+	assert.NoError(t, err)
+
+	expectedError := errors.New("some error")
+	mockClient.EXPECT().Distribution(gomock.Any(), gomock.Any(), gomock.Any(), sr.rate).Return(expectedError)
+
+	err = sr.ReportHistogram("123", map[string]string{}, float64(123.1))
+	assert.Equal(t, expectedError, err)
+}
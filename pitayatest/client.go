@@ -0,0 +1,105 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package pitayatest
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/topfreegames/pitaya/v2/client"
+	"github.com/topfreegames/pitaya/v2/conn/message"
+)
+
+// Client is a client.Client connected to a frontend server of the harness
+type Client struct {
+	*client.Client
+	harness *Harness
+	// pending are the messages received while waiting for others
+	pending []*message.Message
+}
+
+// Connect connects a client to the frontend server sv, it is disconnected at
+// the end of the test
+func (h *Harness) Connect(sv *Server) *Client {
+	h.t.Helper()
+	c := client.New(logrus.ErrorLevel)
+	if err := c.ConnectWith(sv.Acceptor.GetAddr(), sv.Acceptor.Dial); err != nil {
+		h.t.Fatalf("failed to connect to %s: %s", sv.App.GetServer().Type, err.Error())
+	}
+	h.t.Cleanup(c.Disconnect)
+	return &Client{Client: c, harness: h}
+}
+
+// ExpectPush waits for a push to route and decodes its data into v, unless
+// v is nil. The other messages received meanwhile are kept for the next
+// expectations.
+func (c *Client) ExpectPush(route string, v interface{}) {
+	c.harness.t.Helper()
+	m := c.nextMessage(func(m *message.Message) bool {
+		return m.Type == message.Push && m.Route == route
+	})
+	if m == nil {
+		c.harness.t.Fatalf("timed out waiting for push to %s", route)
+		return
+	}
+	if v == nil {
+		return
+	}
+	if err := c.Unmarshal(m.Data, v); err != nil {
+		c.harness.t.Fatalf("failed to decode push to %s: %s", route, err.Error())
+	}
+}
+
+// ExpectDisconnected waits for the client to be disconnected by the server,
+// after a kick or a heartbeat timeout
+func (c *Client) ExpectDisconnected() {
+	c.harness.t.Helper()
+	deadline := time.Now().Add(c.harness.Timeout)
+	for c.ConnectedStatus() {
+		if time.Now().After(deadline) {
+			c.harness.t.Fatal("timed out waiting for the client to be disconnected")
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func (c *Client) nextMessage(match func(m *message.Message) bool) *message.Message {
+	for i, m := range c.pending {
+		if match(m) {
+			c.pending = append(c.pending[:i], c.pending[i+1:]...)
+			return m
+		}
+	}
+	timeout := time.NewTimer(c.harness.Timeout)
+	defer timeout.Stop()
+	for {
+		select {
+		case m := <-c.MsgChannel():
+			if match(m) {
+				return m
+			}
+			c.pending = append(c.pending, m)
+		case <-timeout.C:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package pitayatest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClockAdvance(t *testing.T) {
+	start := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	c := NewFakeClock(start)
+	ticker := c.NewTicker(10 * time.Second)
+
+	c.Advance(5 * time.Second)
+	assert.Equal(t, start.Add(5*time.Second), c.Now())
+	assert.Len(t, ticker.C(), 0)
+
+	c.Advance(5 * time.Second)
+	assert.Equal(t, start.Add(10*time.Second), <-ticker.C())
+
+	// like time.Ticker, many elapsed intervals tick once and unread ticks are dropped
+	c.Advance(25 * time.Second)
+	c.Advance(10 * time.Second)
+	assert.Equal(t, start.Add(35*time.Second), <-ticker.C())
+	assert.Len(t, ticker.C(), 0)
+
+	c.Advance(4 * time.Second)
+	assert.Len(t, ticker.C(), 0)
+	c.Advance(time.Second)
+	assert.Equal(t, start.Add(50*time.Second), <-ticker.C())
+
+	ticker.Stop()
+	assert.Empty(t, c.tickers)
+	c.Advance(10 * time.Second)
+	assert.Len(t, ticker.C(), 0)
+}
@@ -0,0 +1,158 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package pitayatest runs pitaya Apps in the test process, connected by an
+// in-memory cluster, with clients connected through pipes and time stepped
+// by a fake clock, so full apps can be tested without sockets, etcd or nats
+package pitayatest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/topfreegames/pitaya/v2"
+	"github.com/topfreegames/pitaya/v2/acceptor"
+	"github.com/topfreegames/pitaya/v2/cluster"
+	"github.com/topfreegames/pitaya/v2/config"
+	"github.com/topfreegames/pitaya/v2/timer"
+)
+
+// Harness builds and runs the Apps of a test
+type Harness struct {
+	t       testing.TB
+	Clock   *FakeClock
+	Cluster *cluster.MemoryCluster
+	// Timeout is how long the expectations wait
+	Timeout time.Duration
+
+	servers      []*Server
+	trafficMutex sync.Mutex
+	traffic      []*Message
+}
+
+// Server is an App run by the harness
+type Server struct {
+	App pitaya.Pitaya
+	// Acceptor accepts the connections of the clients, it is nil on backend servers
+	Acceptor *acceptor.PipeAcceptor
+	started  bool
+	done     chan struct{}
+}
+
+// New returns a harness whose clock drives the timers and the heartbeats of
+// the Apps until the end of the test, when the Apps are stopped. Timers are
+// due by the clock of the App that adds them, so timers created while Apps of
+// other harnesses run in the process may follow their clocks.
+func New(t testing.TB) *Harness {
+	h := &Harness{
+		t:       t,
+		Clock:   NewFakeClock(time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)),
+		Cluster: cluster.NewMemoryCluster(),
+		Timeout: time.Second,
+	}
+	t.Cleanup(h.stop)
+	return h
+}
+
+// AddServer builds an App of serverType with NewInProcessBuilder, replacing
+// its service discovery and rpc client and server with in-memory ones joining
+// the cluster of the harness, and calls register to add its components.
+// Frontend servers get a PipeAcceptor.
+func (h *Harness) AddServer(
+	serverType string,
+	frontend bool,
+	register func(app pitaya.Pitaya),
+	builderConfig ...config.BuilderConfig,
+) *Server {
+	conf := *config.NewDefaultBuilderConfig()
+	if len(builderConfig) > 0 {
+		conf = builderConfig[0]
+	}
+	conf.Pitaya.Cluster.Backend = config.ClusterBackendMemory
+
+	builder := pitaya.NewInProcessBuilder(frontend, serverType, pitaya.Cluster, map[string]string{}, conf)
+	builder.Clock = h.Clock
+	builder.ServiceDiscovery = cluster.NewMemoryServiceDiscovery(h.Cluster, builder.Server)
+	builder.RPCServer = cluster.NewMemoryRPCServer(h.Cluster, builder.Server, builder.SessionPool)
+	builder.RPCClient = &recordingRPCClient{
+		RPCClient: cluster.NewMemoryRPCClient(*config.NewDefaultMemoryRPCClientConfig(), h.Cluster, builder.Server, nil),
+		harness:   h,
+		server:    builder.Server,
+	}
+
+	sv := &Server{done: make(chan struct{})}
+	if frontend {
+		sv.Acceptor = acceptor.NewPipeAcceptor()
+		builder.AddAcceptor(sv.Acceptor)
+	}
+	sv.App = builder.Build()
+	if register != nil {
+		register(sv.App)
+	}
+	h.servers = append(h.servers, sv)
+	return sv
+}
+
+// Start starts the Apps added and waits for them to run
+func (h *Harness) Start() {
+	for _, sv := range h.servers {
+		if sv.started {
+			continue
+		}
+		sv.started = true
+		go func(sv *Server) {
+			defer close(sv.done)
+			sv.App.Start()
+		}(sv)
+		for !sv.App.IsRunning() {
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+// Advance moves the clock forward by d, running the timers and heartbeat
+// checks that are due. The timers created before are added by the Apps first,
+// so that their intervals start before the clock moves.
+func (h *Harness) Advance(d time.Duration) {
+	deadline := time.Now().Add(h.Timeout)
+	for timer.Pending() > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	h.Clock.Advance(d)
+}
+
+func (h *Harness) stop() {
+	for i := len(h.servers) - 1; i >= 0; i-- {
+		sv := h.servers[i]
+		if !sv.started {
+			continue
+		}
+		sv.App.Shutdown()
+		if sv.Acceptor != nil {
+			sv.Acceptor.Stop()
+		}
+		select {
+		case <-sv.done:
+		case <-time.After(h.Timeout):
+			h.t.Errorf("timed out waiting for server %s to stop", sv.App.GetServer().Type)
+		}
+	}
+}
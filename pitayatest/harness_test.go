@@ -0,0 +1,147 @@
+package pitayatest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/topfreegames/pitaya/v2"
+	"github.com/topfreegames/pitaya/v2/component"
+	"github.com/topfreegames/pitaya/v2/config"
+)
+
+type joinRequest struct {
+	UID string `json:"uid"`
+}
+
+type chatMessage struct {
+	From    string `json:"from"`
+	Content string `json:"content"`
+}
+
+type Room struct {
+	component.Base
+	app pitaya.Pitaya
+}
+
+func (r *Room) Join(ctx context.Context, msg *joinRequest) (*joinRequest, error) {
+	s := r.app.GetSessionFromCtx(ctx)
+	if err := s.Bind(ctx, msg.UID); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func (r *Room) Message(ctx context.Context, msg *chatMessage) (*chatMessage, error) {
+	s := r.app.GetSessionFromCtx(ctx)
+	msg.From = s.UID()
+	if _, err := r.app.SendPushToUsers(ctx, "onMessage", msg, []string{s.UID()}, "connector"); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func (r *Room) Leave(ctx context.Context, msg *joinRequest) {
+	r.app.SendKickToUsers(ctx, []string{msg.UID}, "connector")
+}
+
+func newRoomHarness(t *testing.T) (*Harness, *Server) {
+	h := New(t)
+	connector := h.AddServer("connector", true, nil)
+	h.AddServer("room", false, func(app pitaya.Pitaya) {
+		app.Register(&Room{app: app}, component.WithName("room"), component.WithNameFunc(strings.ToLower))
+	})
+	h.Start()
+	return h, connector
+}
+
+func join(t *testing.T, c *Client, uid string) {
+	out := &joinRequest{}
+	err := c.Request(context.Background(), "room.room.join", &joinRequest{UID: uid}, out)
+	require.NoError(t, err)
+	assert.Equal(t, uid, out.UID)
+}
+
+func TestHarnessForwardsRequests(t *testing.T) {
+	h, connector := newRoomHarness(t)
+	c := h.Connect(connector)
+	join(t, c, "alice")
+
+	calls := h.Messages(KindCall)
+	require.NotEmpty(t, calls)
+	assert.Equal(t, "connector", calls[0].From)
+	assert.Equal(t, "room", calls[0].To)
+	assert.Equal(t, "room.room.join", calls[0].Route)
+	assert.NoError(t, calls[0].Err)
+}
+
+func TestHarnessPushesToUsers(t *testing.T) {
+	h, connector := newRoomHarness(t)
+	c := h.Connect(connector)
+	join(t, c, "alice")
+
+	err := c.Request(context.Background(), "room.room.message", &chatMessage{Content: "hello"}, nil)
+	require.NoError(t, err)
+
+	push := &chatMessage{}
+	c.ExpectPush("onMessage", push)
+	assert.Equal(t, &chatMessage{From: "alice", Content: "hello"}, push)
+
+	pushes := h.Messages(KindPush)
+	require.Len(t, pushes, 1)
+	assert.Equal(t, "room", pushes[0].From)
+	assert.Equal(t, "connector", pushes[0].To)
+	assert.Equal(t, "alice", pushes[0].UID)
+	assert.NoError(t, pushes[0].Err)
+}
+
+func TestHarnessKicksUsers(t *testing.T) {
+	h, connector := newRoomHarness(t)
+	c := h.Connect(connector)
+	join(t, c, "alice")
+
+	data, err := c.Marshal(&joinRequest{UID: "alice"})
+	require.NoError(t, err)
+	require.NoError(t, c.SendNotify("room.room.leave", data))
+	c.ExpectDisconnected()
+
+	kicks := h.Messages(KindKick)
+	require.Len(t, kicks, 1)
+	assert.Equal(t, "alice", kicks[0].UID)
+	assert.NoError(t, kicks[0].Err)
+}
+
+func TestHarnessAdvancesTimers(t *testing.T) {
+	h, _ := newRoomHarness(t)
+	fired := make(chan time.Time, 1)
+	pitaya.NewAfterTimer(time.Minute, func() {
+		fired <- h.Clock.Now()
+	})
+
+	h.Advance(30 * time.Second)
+	select {
+	case <-fired:
+		t.Fatal("timer fired before its interval elapsed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	h.Advance(30 * time.Second)
+	select {
+	case now := <-fired:
+		assert.Equal(t, time.Date(2020, time.January, 1, 0, 1, 0, 0, time.UTC), now)
+	case <-time.After(h.Timeout):
+		t.Fatal("timer didn't fire after its interval elapsed")
+	}
+}
+
+func TestHarnessHeartbeatTimeout(t *testing.T) {
+	h, connector := newRoomHarness(t)
+	c := h.Connect(connector)
+	join(t, c, "alice")
+
+	h.Advance(3 * config.NewDefaultBuilderConfig().Pitaya.Heartbeat.Interval)
+	c.ExpectDisconnected()
+}
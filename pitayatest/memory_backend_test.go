@@ -0,0 +1,126 @@
+package pitayatest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/topfreegames/pitaya/v2"
+	"github.com/topfreegames/pitaya/v2/acceptor"
+	"github.com/topfreegames/pitaya/v2/client"
+	"github.com/topfreegames/pitaya/v2/component"
+	"github.com/topfreegames/pitaya/v2/config"
+	pitayaerrors "github.com/topfreegames/pitaya/v2/errors"
+	"github.com/topfreegames/pitaya/v2/protos"
+)
+
+type Chat struct {
+	component.Base
+}
+
+// History streams the messages of the user in arg
+func (c *Chat) History(ctx context.Context, arg *protos.Response, stream component.Stream) error {
+	if len(arg.Data) == 0 {
+		return pitayaerrors.NewError(errors.New("no user"), "PIT-400")
+	}
+	for _, content := range []string{"hello", "bye"} {
+		if err := stream.Send(&protos.Response{Data: append(arg.Data, ": "+content...)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestMemoryClusterBackend(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set("pitaya.cluster.backend", config.ClusterBackendMemory)
+	cfg.Set("pitaya.worker.backend", "memory")
+	conf := config.NewConfig(cfg)
+
+	pipe := acceptor.NewPipeAcceptor()
+	var apps []pitaya.Pitaya
+	addServer := func(serverType string, frontend bool, register func(app pitaya.Pitaya)) pitaya.Pitaya {
+		builder := pitaya.NewBuilderWithConfigs(frontend, serverType, pitaya.Cluster, map[string]string{}, conf)
+		if frontend {
+			builder.AddAcceptor(pipe)
+		}
+		app := builder.Build()
+		if register != nil {
+			register(app)
+		}
+		apps = append(apps, app)
+		return app
+	}
+	addServer("connector", true, nil)
+	room := addServer("room", false, func(app pitaya.Pitaya) {
+		app.Register(&Room{app: app}, component.WithName("room"), component.WithNameFunc(strings.ToLower))
+	})
+	addServer("chat", false, func(app pitaya.Pitaya) {
+		app.RegisterRemote(&Chat{}, component.WithName("chat"), component.WithNameFunc(strings.ToLower))
+	})
+
+	done := make(chan struct{}, len(apps))
+	for _, app := range apps {
+		go func(app pitaya.Pitaya) {
+			app.Start()
+			done <- struct{}{}
+		}(app)
+		for !app.IsRunning() {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	defer func() {
+		for i := len(apps) - 1; i >= 0; i-- {
+			apps[i].Shutdown()
+			<-done
+		}
+		pipe.Stop()
+	}()
+
+	c := client.New(logrus.ErrorLevel)
+	require.NoError(t, c.ConnectWith(pipe.GetAddr(), pipe.Dial))
+	defer c.Disconnect()
+
+	// requests are forwarded and sessions bound through rpcs
+	out := &joinRequest{}
+	require.NoError(t, c.Request(context.Background(), "room.room.join", &joinRequest{UID: "alice"}, out))
+	assert.Equal(t, "alice", out.UID)
+
+	// pushes reach the frontend the user is bound to
+	require.NoError(t, c.Request(context.Background(), "room.room.message", &chatMessage{Content: "hi"}, nil))
+	m := <-c.MsgChannel()
+	assert.Equal(t, "onMessage", m.Route)
+
+	// streams are served by the remotes of other servers
+	stream, err := room.RPCStream(context.Background(), "chat.chat.history", &protos.Response{Data: []byte("alice")})
+	require.NoError(t, err)
+	var history []string
+	for {
+		msg := &protos.Response{}
+		if err := stream.Recv(msg); err != nil {
+			assert.Equal(t, io.EOF, err)
+			break
+		}
+		history = append(history, string(msg.Data))
+	}
+	assert.Equal(t, []string{"alice: hello", "alice: bye"}, history)
+
+	stream, err = room.RPCStream(context.Background(), "chat.chat.history", &protos.Response{})
+	require.NoError(t, err)
+	err = stream.Recv(&protos.Response{})
+	assert.IsType(t, &pitayaerrors.Error{}, err)
+	assert.Equal(t, "PIT-400", err.(*pitayaerrors.Error).Code)
+
+	// kicks reach the frontend the user is bound to
+	data, err := c.Marshal(&joinRequest{UID: "alice"})
+	require.NoError(t, err)
+	require.NoError(t, c.SendNotify("room.room.leave", data))
+	assert.Eventually(t, func() bool { return !c.ConnectedStatus() }, time.Second, time.Millisecond)
+}
@@ -0,0 +1,149 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package pitayatest
+
+import (
+	"context"
+
+	"github.com/topfreegames/pitaya/v2/cluster"
+	"github.com/topfreegames/pitaya/v2/conn/message"
+	"github.com/topfreegames/pitaya/v2/protos"
+	"github.com/topfreegames/pitaya/v2/route"
+	"github.com/topfreegames/pitaya/v2/session"
+)
+
+// MessageKind is the kind of a message sent between servers
+type MessageKind string
+
+// Kinds of the messages recorded by the harness
+const (
+	KindCall MessageKind = "call"
+	KindPush MessageKind = "push"
+	KindKick MessageKind = "kick"
+)
+
+// Message is a message sent by a server to another through its rpc client
+type Message struct {
+	Kind MessageKind
+	// From is the type of the server sending the message
+	From string
+	// To is the type of the server receiving the message
+	To string
+	// Route is the route of calls and pushes
+	Route string
+	// UID is the user of pushes and kicks
+	UID string
+	// Data is the data of calls and pushes
+	Data []byte
+	Err  error
+}
+
+// recordingRPCClient records the messages sent by a server
+type recordingRPCClient struct {
+	cluster.RPCClient
+	harness *Harness
+	server  *cluster.Server
+}
+
+func (r *recordingRPCClient) Call(
+	ctx context.Context,
+	rpcType protos.RPCType,
+	route *route.Route,
+	session session.Session,
+	msg *message.Message,
+	server *cluster.Server,
+) (*protos.Response, error) {
+	m := r.harness.record(&Message{
+		Kind:  KindCall,
+		From:  r.server.Type,
+		To:    server.Type,
+		Route: route.String(),
+		Data:  msg.Data,
+	})
+	res, err := r.RPCClient.Call(ctx, rpcType, route, session, msg, server)
+	r.harness.setErr(m, err)
+	return res, err
+}
+
+func (r *recordingRPCClient) SendPush(userID string, frontendSv *cluster.Server, push *protos.Push) error {
+	m := r.harness.record(&Message{
+		Kind:  KindPush,
+		From:  r.server.Type,
+		To:    frontendSv.Type,
+		Route: push.Route,
+		UID:   userID,
+		Data:  push.Data,
+	})
+	err := r.RPCClient.SendPush(userID, frontendSv, push)
+	r.harness.setErr(m, err)
+	return err
+}
+
+func (r *recordingRPCClient) SendKick(userID string, serverType string, kick *protos.KickMsg) error {
+	m := r.harness.record(&Message{
+		Kind: KindKick,
+		From: r.server.Type,
+		To:   serverType,
+		UID:  userID,
+	})
+	err := r.RPCClient.SendKick(userID, serverType, kick)
+	r.harness.setErr(m, err)
+	return err
+}
+
+// record records a message when it is sent, its error is set with setErr
+// once the rpc client returns
+func (h *Harness) record(m *Message) *Message {
+	h.trafficMutex.Lock()
+	defer h.trafficMutex.Unlock()
+	h.traffic = append(h.traffic, m)
+	return m
+}
+
+func (h *Harness) setErr(m *Message, err error) {
+	h.trafficMutex.Lock()
+	defer h.trafficMutex.Unlock()
+	m.Err = err
+}
+
+// Traffic returns copies of the messages sent between the servers, in the
+// order they were sent
+func (h *Harness) Traffic() []*Message {
+	h.trafficMutex.Lock()
+	defer h.trafficMutex.Unlock()
+	traffic := make([]*Message, 0, len(h.traffic))
+	for _, m := range h.traffic {
+		c := *m
+		traffic = append(traffic, &c)
+	}
+	return traffic
+}
+
+// Messages returns the messages of a kind sent between the servers
+func (h *Harness) Messages(kind MessageKind) []*Message {
+	messages := []*Message{}
+	for _, m := range h.Traffic() {
+		if m.Kind == kind {
+			messages = append(messages, m)
+		}
+	}
+	return messages
+}
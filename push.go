@@ -28,6 +28,8 @@ import (
 	pcontext "github.com/topfreegames/pitaya/v2/context"
 	"github.com/topfreegames/pitaya/v2/logger"
 	"github.com/topfreegames/pitaya/v2/protos"
+	"github.com/topfreegames/pitaya/v2/serialize"
+	"github.com/topfreegames/pitaya/v2/session"
 	"github.com/topfreegames/pitaya/v2/util"
 )
 
@@ -46,18 +48,43 @@ func (app *App) SendPushToUsers(ctx context.Context, route string, v interface{}
 
 	logger.Log.Debugf("Type=PushToUsers Route=%s, Data=%+v, SvType=%s, #Users=%d", route, v, frontendType, len(uids))
 
+	// raw pushes are the same for every client, the others are encoded once
+	// for each serializer of the local sessions and, for the rpcs, once for
+	// each serializer the clients can negotiate so that the frontend picks the
+	// encoding of the session
+	_, raw := v.([]byte)
+	serializerName := app.serializer.GetName()
+	if raw {
+		serializerName = ""
+	}
+	encoded := map[string][]byte{app.serializer.GetName(): data}
+	var remoteEncoded map[string][]byte
 	for _, uid := range uids {
 		if s := app.sessionPool.GetSessionByUID(uid); s != nil && app.server.Type == frontendType {
-			if err := s.Push(ctx, route, data); err != nil {
+			sessionData := data
+			if serializer := app.pushSerializer(s, raw); serializer != nil {
+				if sessionData, err = app.serializeFor(serializer, v, encoded); err != nil {
+					notPushedUids = append(notPushedUids, uid)
+					logger.Log.Errorf("Session push message error, ID=%d, UID=%s, Error=%s",
+						s.ID(), s.UID(), err.Error())
+					continue
+				}
+			}
+			if err := s.Push(ctx, route, sessionData); err != nil {
 				notPushedUids = append(notPushedUids, uid)
 				logger.Log.Errorf("Session push message error, ID=%d, UID=%s, Error=%s",
 					s.ID(), s.UID(), err.Error())
 			}
 		} else if app.rpcClient != nil {
+			if !raw && remoteEncoded == nil {
+				remoteEncoded = app.encodeNegotiable(v, encoded)
+			}
 			push := &protos.Push{
 				Route:         route,
 				Uid:           uid,
 				Data:          data,
+				Serializer:    serializerName,
+				Encoded:       remoteEncoded,
 				RelationMsgId: uint64(pcontext.GetRelationMsgIdFromContext(ctx, uid)),
 				SessionId:     pcontext.GetSessionIdFromContext(ctx, uid),
 			}
@@ -85,3 +112,45 @@ func (app *App) SendPushToUsers(ctx context.Context, route string, v interface{}
 
 	return nil, nil
 }
+
+// serializeFor serializes the push with the serializer of a session, once for
+// all the sessions using it
+func (app *App) serializeFor(serializer serialize.Serializer, v interface{}, encoded map[string][]byte) ([]byte, error) {
+	if data, ok := encoded[serializer.GetName()]; ok {
+		return data, nil
+	}
+	data, err := util.SerializeOrRaw(serializer, v)
+	if err != nil {
+		return nil, err
+	}
+	encoded[serializer.GetName()] = data
+	return data, nil
+}
+
+// encodeNegotiable returns the push encoded with each serializer the clients
+// can negotiate other than the default one, which is already in the data of
+// the push, serializers that can't encode it are left out
+func (app *App) encodeNegotiable(v interface{}, encoded map[string][]byte) map[string][]byte {
+	negotiable := make(map[string][]byte, len(app.serializers))
+	for _, serializer := range app.serializers {
+		if serializer.GetName() == app.serializer.GetName() {
+			continue
+		}
+		data, err := app.serializeFor(serializer, v, encoded)
+		if err != nil {
+			logger.Log.Debugf("push not encoded with serializer %s: %s", serializer.GetName(), err.Error())
+			continue
+		}
+		negotiable[serializer.GetName()] = data
+	}
+	return negotiable
+}
+
+// pushSerializer returns the serializer negotiated by the client of the
+// session, nil if the push is raw or the client didn't negotiate one
+func (app *App) pushSerializer(s session.Session, raw bool) serialize.Serializer {
+	if raw {
+		return nil
+	}
+	return s.GetSerializer()
+}
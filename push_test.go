@@ -21,6 +21,7 @@
 package pitaya
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -31,7 +32,9 @@ import (
 	"github.com/topfreegames/pitaya/v2/config"
 	"github.com/topfreegames/pitaya/v2/constants"
 	"github.com/topfreegames/pitaya/v2/protos"
+	"github.com/topfreegames/pitaya/v2/serialize/json"
 	serializemocks "github.com/topfreegames/pitaya/v2/serialize/mocks"
+	"github.com/topfreegames/pitaya/v2/serialize/msgpack"
 	sessionmocks "github.com/topfreegames/pitaya/v2/session/mocks"
 )
 
@@ -55,7 +58,7 @@ func TestSendPushToUsersFailsIfErrSerializing(t *testing.T) {
 	expectedErr := errors.New("serialize error")
 	mockSerializer.EXPECT().Marshal(data).Return(nil, expectedErr)
 
-	errArr, err := app.SendPushToUsers(route, data, []string{uid}, "test")
+	errArr, err := app.SendPushToUsers(context.Background(), route, data, []string{uid}, "test")
 	assert.Equal(t, expectedErr, err)
 	assert.Len(t, errArr, 1)
 	assert.Equal(t, errArr[0], uid)
@@ -87,8 +90,8 @@ func TestSendToUsersLocalSession(t *testing.T) {
 				s1.EXPECT().UID().Times(1).Return(uid1)
 				s2.EXPECT().UID().Times(1).Return(uid2)
 			}
-			s1.EXPECT().Push(route, data).Times(1).Return(table.err)
-			s2.EXPECT().Push(route, data).Times(1).Return(table.err)
+			s1.EXPECT().Push(gomock.Any(), route, data).Times(1).Return(table.err)
+			s2.EXPECT().Push(gomock.Any(), route, data).Times(1).Return(table.err)
 
 			mockSessionPool := sessionmocks.NewMockSessionPool(ctrl)
 			mockSessionPool.EXPECT().GetSessionByUID(uid1).Return(s1).Times(1)
@@ -98,7 +101,7 @@ func TestSendToUsersLocalSession(t *testing.T) {
 			builder := NewDefaultBuilder(true, "testtype", Standalone, map[string]string{}, *config)
 			builder.SessionPool = mockSessionPool
 			app := builder.Build().(*App)
-			errArr, err := app.SendPushToUsers(route, data, []string{uid1, uid2}, app.server.Type)
+			errArr, err := app.SendPushToUsers(context.Background(), route, data, []string{uid1, uid2}, app.server.Type)
 
 			if table.err != nil {
 				assert.Equal(t, err, table.err)
@@ -155,7 +158,7 @@ func TestSendToUsersRemoteSession(t *testing.T) {
 			builder.RPCClient = mockRPCClient
 			app := builder.Build()
 
-			errArr, err := app.SendPushToUsers(route, data, []string{uid1, uid2}, svType)
+			errArr, err := app.SendPushToUsers(context.Background(), route, data, []string{uid1, uid2}, svType)
 			if table.err != nil {
 				assert.EqualError(t, err, table.err.Error())
 				assert.Len(t, errArr, 2)
@@ -166,3 +169,52 @@ func TestSendToUsersRemoteSession(t *testing.T) {
 		})
 	}
 }
+
+func TestSendToUsersWithSerializer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	route := "some.route.bla"
+	v := map[string]int{"a": 1}
+	uid1 := uuid.New().String()
+	uid2 := uuid.New().String()
+	uid3 := uuid.New().String()
+	jsonData, err := json.NewSerializer().Marshal(v)
+	assert.NoError(t, err)
+	msgpackData, err := msgpack.NewSerializer().Marshal(v)
+	assert.NoError(t, err)
+
+	s1 := sessionmocks.NewMockSession(ctrl)
+	s1.EXPECT().GetSerializer().Return(nil)
+	s1.EXPECT().Push(gomock.Any(), route, jsonData)
+	s2 := sessionmocks.NewMockSession(ctrl)
+	s2.EXPECT().GetSerializer().Return(msgpack.NewSerializer())
+	s2.EXPECT().Push(gomock.Any(), route, msgpackData)
+
+	mockSessionPool := sessionmocks.NewMockSessionPool(ctrl)
+	mockSessionPool.EXPECT().GetSessionByUID(uid1).Return(s1)
+	mockSessionPool.EXPECT().GetSessionByUID(uid2).Return(s2)
+	mockSessionPool.EXPECT().GetSessionByUID(uid3).Return(nil)
+
+	// pushes to other frontends are sent in the default serializer along with
+	// its name and in every negotiable serializer that can encode them
+	mockRPCClient := clustermocks.NewMockRPCClient(ctrl)
+	mockRPCClient.EXPECT().SendPush(uid3, gomock.Any(), &protos.Push{
+		Route:      route,
+		Uid:        uid3,
+		Data:       jsonData,
+		Serializer: "json",
+		Encoded:    map[string][]byte{"msgpack": msgpackData},
+	})
+
+	config := config.NewDefaultBuilderConfig()
+	config.Pitaya.Serializer.Negotiable = []string{"json", "msgpack", "protobuf"}
+	builder := NewDefaultBuilder(true, "testtype", Cluster, map[string]string{}, *config)
+	builder.SessionPool = mockSessionPool
+	builder.RPCClient = mockRPCClient
+	app := builder.Build().(*App)
+
+	errArr, err := app.SendPushToUsers(context.Background(), route, v, []string{uid1, uid2, uid3}, app.server.Type)
+	assert.NoError(t, err)
+	assert.Nil(t, errArr)
+}
@@ -1,5 +1,7 @@
 package relation
 
+// Data relates the pushes to an user to the request that originated them, MsgID
+// is the id of the request and SessID the frontend session that received it
 type Data struct {
 	MsgID  uint64 `json:"msg_id,string"`
 	SessID int64  `json:"sess_id,string"`
@@ -0,0 +1,97 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/topfreegames/pitaya/v2/component"
+	"github.com/topfreegames/pitaya/v2/protos"
+	"github.com/topfreegames/pitaya/v2/worker"
+)
+
+// WorkerAdmin contains the admin remotes of the reliable rpcs worker, used
+// to inspect, requeue and purge the rpcs that ran out of retries
+type WorkerAdmin struct {
+	component.Base
+	worker *worker.Worker
+}
+
+// NewWorkerAdmin returns a new WorkerAdmin instance
+func NewWorkerAdmin(worker *worker.Worker) *WorkerAdmin {
+	return &WorkerAdmin{worker: worker}
+}
+
+// FailedRPCs returns the rpcs that ran out of retries encoded as json
+func (w *WorkerAdmin) FailedRPCs(ctx context.Context) (*protos.Response, error) {
+	jobs, err := w.worker.FailedRPCs()
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(jobs)
+	if err != nil {
+		return nil, err
+	}
+	return &protos.Response{Data: data}, nil
+}
+
+// Job returns the rpc job with the jid encoded as json
+func (w *WorkerAdmin) Job(ctx context.Context, jid *wrapperspb.StringValue) (*protos.Response, error) {
+	job, err := w.worker.RPCJob(jid.GetValue())
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(job)
+	if err != nil {
+		return nil, err
+	}
+	return &protos.Response{Data: data}, nil
+}
+
+// Requeue enqueues again a rpc that ran out of retries
+func (w *WorkerAdmin) Requeue(ctx context.Context, jid *wrapperspb.StringValue) (*protos.Response, error) {
+	if err := w.worker.RequeueFailedRPC(jid.GetValue()); err != nil {
+		return nil, err
+	}
+	return &protos.Response{Data: []byte("ack")}, nil
+}
+
+// Purge deletes a rpc that ran out of retries
+func (w *WorkerAdmin) Purge(ctx context.Context, jid *wrapperspb.StringValue) (*protos.Response, error) {
+	if err := w.worker.PurgeFailedRPC(jid.GetValue()); err != nil {
+		return nil, err
+	}
+	return &protos.Response{Data: []byte("ack")}, nil
+}
+
+// PurgeAll deletes all the rpcs that ran out of retries and returns how many
+// were deleted
+func (w *WorkerAdmin) PurgeAll(ctx context.Context) (*protos.Response, error) {
+	purged, err := w.worker.PurgeFailedRPCs()
+	if err != nil {
+		return nil, err
+	}
+	return &protos.Response{Data: []byte(strconv.Itoa(purged))}, nil
+}
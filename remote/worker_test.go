@@ -0,0 +1,93 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/topfreegames/pitaya/v2/config"
+	"github.com/topfreegames/pitaya/v2/constants"
+	"github.com/topfreegames/pitaya/v2/worker"
+	"github.com/topfreegames/pitaya/v2/worker/mocks"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestWorkerAdmin(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	queue := worker.NewMemoryQueue()
+	defer queue.Stop()
+	w := worker.NewWorkerWithQueue(queue, config.WorkerConfig{Concurrency: 1}, config.EnqueueOpts{})
+
+	route := "server.svc.method"
+	rpcJob := mocks.NewMockRPCJob(ctrl)
+	rpcJob.EXPECT().GetArgReply(route).Return(nil, nil, errors.New("failed")).AnyTimes()
+	assert.NoError(t, w.RegisterRPCJob(rpcJob))
+	w.Start()
+
+	jid, err := w.EnqueueRPC(route, nil, nil, nil)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	admin := NewWorkerAdmin(w)
+	var jobs []*worker.RPCJobInfo
+	assert.Eventually(t, func() bool {
+		res, err := admin.FailedRPCs(ctx)
+		assert.NoError(t, err)
+		assert.NoError(t, json.Unmarshal(res.Data, &jobs))
+		return len(jobs) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, jid, jobs[0].ID)
+	assert.Equal(t, route, jobs[0].Route)
+
+	res, err := admin.Job(ctx, wrapperspb.String(jid))
+	assert.NoError(t, err)
+	job := &worker.RPCJobInfo{}
+	assert.NoError(t, json.Unmarshal(res.Data, job))
+	assert.Equal(t, worker.JobDead, job.Status)
+	assert.Equal(t, "failed", job.Error)
+
+	res, err = admin.Requeue(ctx, wrapperspb.String(jid))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("ack"), res.Data)
+	assert.Eventually(t, func() bool {
+		job, err := w.RPCJob(jid)
+		return err == nil && job.Status == worker.JobDead
+	}, time.Second, 10*time.Millisecond)
+
+	res, err = admin.Purge(ctx, wrapperspb.String(jid))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("ack"), res.Data)
+	_, err = admin.Job(ctx, wrapperspb.String(jid))
+	assert.Equal(t, constants.ErrJobNotFound, err)
+
+	res, err = admin.PurgeAll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("0"), res.Data)
+}
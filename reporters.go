@@ -1,12 +1,18 @@
 package pitaya
 
 import (
+	"context"
+	"time"
+
 	"github.com/topfreegames/pitaya/v2/config"
 	"github.com/topfreegames/pitaya/v2/logger"
 	"github.com/topfreegames/pitaya/v2/metrics"
 	"github.com/topfreegames/pitaya/v2/metrics/models"
 )
 
+// metricsShutdownTimeout bounds the flush of the reporters on shutdown
+const metricsShutdownTimeout = 5 * time.Second
+
 // CreatePrometheusReporter create a Prometheus reporter instance
 func CreatePrometheusReporter(serverType string, config config.PrometheusConfig, customSpecs models.CustomMetricsSpec) (*metrics.PrometheusReporter, error) {
 	logger.Log.Infof("prometheus is enabled, configuring reporter on port %d", config.Prometheus.Port)
@@ -32,3 +38,35 @@ func CreateStatsdReporter(serverType string, config config.StatsdConfig) (*metri
 	}
 	return metricsReporter, err
 }
+
+// CreateOTLPReporter create an OTLP reporter instance
+func CreateOTLPReporter(serverType string, config config.OTLPConfig) (*metrics.OTLPReporter, error) {
+	logger.Log.Infof(
+		"otlp is enabled, configuring the metrics reporter with endpoint: %s",
+		config.OTLP.Endpoint,
+	)
+	metricsReporter, err := metrics.NewOTLPReporter(
+		config,
+		serverType,
+	)
+	if err != nil {
+		logger.Log.Errorf("failed to start otlp metrics reporter, skipping %v", err)
+	}
+	return metricsReporter, err
+}
+
+// shutdownMetricsReporters flushes and stops the reporters that need it,
+// such as the OTLP reporter
+func (app *App) shutdownMetricsReporters() {
+	ctx, cancel := context.WithTimeout(context.Background(), metricsShutdownTimeout)
+	defer cancel()
+	for _, reporter := range app.metricsReporters {
+		r, ok := reporter.(interface{ Shutdown(context.Context) error })
+		if !ok {
+			continue
+		}
+		if err := r.Shutdown(ctx); err != nil {
+			logger.Log.Warnf("error stopping metrics reporter: %s", err.Error())
+		}
+	}
+}
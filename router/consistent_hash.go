@@ -0,0 +1,366 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package router
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/topfreegames/pitaya/v2/cluster"
+	"github.com/topfreegames/pitaya/v2/constants"
+	pcontext "github.com/topfreegames/pitaya/v2/context"
+	"github.com/topfreegames/pitaya/v2/route"
+	"github.com/topfreegames/pitaya/v2/session"
+)
+
+const defaultReplicas = 100
+
+type (
+	// KeyFunc returns the key used to choose the server of a call, the calls
+	// with the same key are sent to the same server
+	KeyFunc func(ctx context.Context, route *route.Route, payload []byte) (string, error)
+
+	hashOptions struct {
+		replicas   int
+		weight     func(*cluster.Server) int
+		loadFactor float64
+		maxKeys    int
+	}
+
+	// assignment is the server a key sticks to with bounded loads
+	assignment struct {
+		key string
+		id  string
+	}
+
+	// HashOption customizes a consistent hash route
+	HashOption func(options *hashOptions)
+
+	// ConsistentHash routes the calls to the servers of a type using
+	// consistent hashing, so that only the keys of the added or removed
+	// servers are moved when the servers change
+	ConsistentHash struct {
+		mutex    sync.Mutex
+		key      KeyFunc
+		options  hashOptions
+		servers  map[string]*cluster.Server
+		ring     []uint32
+		owners   map[uint32]string
+		loads    map[string]int
+		assigned map[string]*list.Element
+		// recent orders the assigned keys from the most recently used
+		recent *list.List
+	}
+)
+
+// WithReplicas sets the number of points each server has in the hash ring,
+// more points spread the keys more evenly
+func WithReplicas(replicas int) HashOption {
+	return func(opt *hashOptions) {
+		opt.replicas = replicas
+	}
+}
+
+// WithWeight sets the weight of each server, a server with twice the weight
+// of another receives twice the keys
+func WithWeight(weight func(*cluster.Server) int) HashOption {
+	return func(opt *hashOptions) {
+		opt.weight = weight
+	}
+}
+
+// WithMetadataWeight reads the weight of each server from its metadata,
+// servers without a valid weight have weight 1
+func WithMetadataWeight(key string) HashOption {
+	return WithWeight(func(sv *cluster.Server) int {
+		weight, err := strconv.Atoi(sv.Metadata[key])
+		if err != nil {
+			return 1
+		}
+		return weight
+	})
+}
+
+// WithBoundedLoad limits the keys assigned to a server to factor times its
+// share of the keys, the keys that don't fit are sent to the next servers of
+// the ring. Keys stick to their server until they are released, so Release
+// must be called when the entity of a key is gone unless WithMaxKeys is used
+func WithBoundedLoad(factor float64) HashOption {
+	return func(opt *hashOptions) {
+		opt.loadFactor = factor
+	}
+}
+
+// WithMaxKeys limits the keys kept with bounded loads, when the limit is
+// reached the least recently used key is released
+func WithMaxKeys(max int) HashOption {
+	return func(opt *hashOptions) {
+		opt.maxKeys = max
+	}
+}
+
+// SessionKey keys the calls by a field of the session in the context, or by
+// the session uid if field is empty
+func SessionKey(field string) KeyFunc {
+	return func(ctx context.Context, route *route.Route, payload []byte) (string, error) {
+		s, ok := ctx.Value(constants.SessionCtxKey).(session.Session)
+		if !ok || s == nil {
+			return "", constants.ErrNoRoutingKey
+		}
+		if field == "" {
+			if s.UID() == "" {
+				return "", constants.ErrNoRoutingKey
+			}
+			return s.UID(), nil
+		}
+		val := s.Get(field)
+		if val == nil {
+			return "", constants.ErrNoRoutingKey
+		}
+		return fmt.Sprint(val), nil
+	}
+}
+
+// ContextKey keys the calls by a value propagated in the context, see
+// pitaya.AddToPropagateCtx
+func ContextKey(key string) KeyFunc {
+	return func(ctx context.Context, route *route.Route, payload []byte) (string, error) {
+		val := pcontext.GetFromPropagateCtx(ctx, key)
+		if val == nil {
+			return "", constants.ErrNoRoutingKey
+		}
+		return fmt.Sprint(val), nil
+	}
+}
+
+// RouteKey keys the calls by their route, all the calls to a route are sent
+// to the same server
+func RouteKey() KeyFunc {
+	return func(ctx context.Context, route *route.Route, payload []byte) (string, error) {
+		return route.String(), nil
+	}
+}
+
+// PayloadKey keys the calls by a key extracted from their payload
+func PayloadKey(extract func(payload []byte) (string, error)) KeyFunc {
+	return func(ctx context.Context, route *route.Route, payload []byte) (string, error) {
+		return extract(payload)
+	}
+}
+
+// NewConsistentHash creates a consistent hash route
+func NewConsistentHash(key KeyFunc, opts ...HashOption) *ConsistentHash {
+	options := hashOptions{
+		replicas: defaultReplicas,
+		weight:   func(*cluster.Server) int { return 1 },
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &ConsistentHash{
+		key:      key,
+		options:  options,
+		servers:  make(map[string]*cluster.Server),
+		owners:   make(map[uint32]string),
+		loads:    make(map[string]int),
+		assigned: make(map[string]*list.Element),
+		recent:   list.New(),
+	}
+}
+
+func (c *ConsistentHash) points(sv *cluster.Server) []uint32 {
+	weight := c.options.weight(sv)
+	if weight < 1 {
+		weight = 1
+	}
+	points := make([]uint32, 0, c.options.replicas*weight)
+	for i := 0; i < c.options.replicas*weight; i++ {
+		points = append(points, crc32.ChecksumIEEE([]byte(sv.ID+"#"+strconv.Itoa(i))))
+	}
+	return points
+}
+
+// add must be called holding the mutex
+func (c *ConsistentHash) add(sv *cluster.Server) {
+	if _, ok := c.servers[sv.ID]; ok {
+		return
+	}
+	c.servers[sv.ID] = sv
+	for _, point := range c.points(sv) {
+		if _, ok := c.owners[point]; ok {
+			continue
+		}
+		c.owners[point] = sv.ID
+		c.ring = append(c.ring, point)
+	}
+	sort.Slice(c.ring, func(i, j int) bool { return c.ring[i] < c.ring[j] })
+}
+
+// AddServer adds a server to the hash ring
+func (c *ConsistentHash) AddServer(sv *cluster.Server) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.add(sv)
+}
+
+// RemoveServer removes a server from the hash ring, only its keys are moved
+func (c *ConsistentHash) RemoveServer(sv *cluster.Server) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if _, ok := c.servers[sv.ID]; !ok {
+		return
+	}
+	delete(c.servers, sv.ID)
+	ring := c.ring[:0]
+	for _, point := range c.ring {
+		if c.owners[point] == sv.ID {
+			delete(c.owners, point)
+			continue
+		}
+		ring = append(ring, point)
+	}
+	c.ring = ring
+
+	for key, elem := range c.assigned {
+		if elem.Value.(*assignment).id == sv.ID {
+			c.release(key)
+		}
+	}
+	delete(c.loads, sv.ID)
+}
+
+// Release frees the key from the server it was assigned to when using bounded
+// loads, it must be called when the entity of the key no longer exists
+func (c *ConsistentHash) Release(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.release(key)
+}
+
+// release must be called holding the mutex
+func (c *ConsistentHash) release(key string) {
+	if elem, ok := c.assigned[key]; ok {
+		delete(c.assigned, key)
+		c.recent.Remove(elem)
+		c.loads[elem.Value.(*assignment).id]--
+	}
+}
+
+// assign must be called holding the mutex
+func (c *ConsistentHash) assign(key, id string) {
+	c.assigned[key] = c.recent.PushFront(&assignment{key: key, id: id})
+	c.loads[id]++
+	if max := c.options.maxKeys; max > 0 && len(c.assigned) > max {
+		c.release(c.recent.Back().Value.(*assignment).key)
+	}
+}
+
+// totalWeight returns the weight of the given servers that are in the ring,
+// must be called holding the mutex
+func (c *ConsistentHash) totalWeight(servers map[string]*cluster.Server) int {
+	total := 0
+	for _, sv := range servers {
+		if _, ok := c.servers[sv.ID]; ok {
+			total += c.options.weight(sv)
+		}
+	}
+	return total
+}
+
+// capacity returns how many keys the server can have with bounded loads,
+// must be called holding the mutex
+func (c *ConsistentHash) capacity(sv *cluster.Server, total int) int {
+	if total == 0 {
+		return 0
+	}
+	share := float64(len(c.assigned)+1) * float64(c.options.weight(sv)) / float64(total)
+	return int(math.Ceil(c.options.loadFactor * share))
+}
+
+// Get returns the server of the key among the given servers
+func (c *ConsistentHash) Get(key string, servers map[string]*cluster.Server) (*cluster.Server, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// servers discovered before the route was created
+	for _, sv := range servers {
+		c.add(sv)
+	}
+
+	bounded := c.options.loadFactor > 0
+	total := 0
+	if bounded {
+		if elem, ok := c.assigned[key]; ok {
+			if sv, ok := servers[elem.Value.(*assignment).id]; ok {
+				c.recent.MoveToFront(elem)
+				return sv, nil
+			}
+			c.release(key)
+		}
+		total = c.totalWeight(servers)
+	}
+
+	hash := crc32.ChecksumIEEE([]byte(key))
+	start := sort.Search(len(c.ring), func(i int) bool { return c.ring[i] >= hash })
+	var first *cluster.Server
+	for i := 0; i < len(c.ring); i++ {
+		id := c.owners[c.ring[(start+i)%len(c.ring)]]
+		sv, ok := servers[id]
+		if !ok {
+			continue
+		}
+		if !bounded {
+			return sv, nil
+		}
+		if first == nil {
+			first = sv
+		}
+		if c.loads[id] < c.capacity(sv, total) {
+			c.assign(key, id)
+			return sv, nil
+		}
+	}
+	if first == nil {
+		return nil, constants.ErrNoServersAvailableOfType
+	}
+	c.assign(key, first.ID)
+	return first, nil
+}
+
+// Route is a RoutingFunc that sends the calls with the same key to the same server
+func (c *ConsistentHash) Route(
+	ctx context.Context,
+	route *route.Route,
+	payload []byte,
+	servers map[string]*cluster.Server,
+) (*cluster.Server, error) {
+	key, err := c.key(ctx, route, payload)
+	if err != nil {
+		return nil, err
+	}
+	return c.Get(key, servers)
+}
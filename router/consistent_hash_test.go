@@ -0,0 +1,199 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/topfreegames/pitaya/v2/cluster"
+	"github.com/topfreegames/pitaya/v2/constants"
+	pcontext "github.com/topfreegames/pitaya/v2/context"
+	"github.com/topfreegames/pitaya/v2/route"
+	"github.com/topfreegames/pitaya/v2/session"
+)
+
+func hashServers(n int) map[string]*cluster.Server {
+	servers := make(map[string]*cluster.Server, n)
+	for i := 0; i < n; i++ {
+		sv := cluster.NewServer(fmt.Sprintf("room-%d", i), "room", false)
+		servers[sv.ID] = sv
+	}
+	return servers
+}
+
+func hashAssignments(t *testing.T, c *ConsistentHash, servers map[string]*cluster.Server, keys int) map[string]string {
+	assignments := make(map[string]string, keys)
+	for i := 0; i < keys; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		sv, err := c.Get(key, servers)
+		assert.NoError(t, err)
+		assignments[key] = sv.ID
+	}
+	return assignments
+}
+
+func TestConsistentHashGet(t *testing.T) {
+	t.Parallel()
+	c := NewConsistentHash(RouteKey())
+
+	_, err := c.Get("key", map[string]*cluster.Server{})
+	assert.Equal(t, constants.ErrNoServersAvailableOfType, err)
+
+	servers := hashServers(5)
+	first := hashAssignments(t, c, servers, 100)
+	assert.Equal(t, first, hashAssignments(t, c, servers, 100))
+
+	used := map[string]bool{}
+	for _, id := range first {
+		used[id] = true
+	}
+	assert.Len(t, used, 5)
+}
+
+func TestConsistentHashMinimalRebalance(t *testing.T) {
+	t.Parallel()
+	c := NewConsistentHash(RouteKey())
+	servers := hashServers(10)
+	before := hashAssignments(t, c, servers, 1000)
+
+	added := cluster.NewServer("room-new", "room", false)
+	servers[added.ID] = added
+	c.AddServer(added)
+	after := hashAssignments(t, c, servers, 1000)
+
+	moved := 0
+	for key, id := range after {
+		if id != before[key] {
+			assert.Equal(t, added.ID, id)
+			moved++
+		}
+	}
+	assert.True(t, moved > 0 && moved < 200, "moved %d keys", moved)
+
+	removed := servers["room-3"]
+	delete(servers, removed.ID)
+	c.RemoveServer(removed)
+	final := hashAssignments(t, c, servers, 1000)
+	for key, id := range final {
+		if after[key] != removed.ID {
+			assert.Equal(t, after[key], id)
+		}
+	}
+}
+
+func TestConsistentHashWeight(t *testing.T) {
+	t.Parallel()
+	servers := hashServers(2)
+	servers["room-0"].Metadata["weight"] = "3"
+	c := NewConsistentHash(RouteKey(), WithMetadataWeight("weight"))
+
+	count := 0
+	for _, id := range hashAssignments(t, c, servers, 1000) {
+		if id == "room-0" {
+			count++
+		}
+	}
+	assert.True(t, count > 600 && count < 900, "heavier server got %d keys", count)
+}
+
+func TestConsistentHashBoundedLoad(t *testing.T) {
+	t.Parallel()
+	servers := hashServers(4)
+	c := NewConsistentHash(RouteKey(), WithReplicas(1), WithBoundedLoad(1.25))
+
+	assignments := hashAssignments(t, c, servers, 400)
+	loads := map[string]int{}
+	for _, id := range assignments {
+		loads[id]++
+	}
+	for id, load := range loads {
+		assert.True(t, load <= 125, "server %s got %d keys", id, load)
+		assert.Equal(t, load, c.loads[id])
+	}
+
+	// keys stick to their servers
+	assert.Equal(t, assignments, hashAssignments(t, c, servers, 400))
+
+	id := assignments["key-0"]
+	c.Release("key-0")
+	assert.Equal(t, loads[id]-1, c.loads[id])
+	assert.Len(t, c.assigned, 399)
+}
+
+func TestConsistentHashMaxKeys(t *testing.T) {
+	t.Parallel()
+	servers := hashServers(2)
+	c := NewConsistentHash(RouteKey(), WithBoundedLoad(1.25), WithMaxKeys(10))
+
+	hashAssignments(t, c, servers, 100)
+	assert.Len(t, c.assigned, 10)
+	assert.Equal(t, 10, c.recent.Len())
+	total := 0
+	for _, load := range c.loads {
+		total += load
+	}
+	assert.Equal(t, 10, total)
+}
+
+func TestKeyFuncs(t *testing.T) {
+	t.Parallel()
+	rt := route.NewRoute("room", "handler", "join")
+	s := session.NewSessionPool().NewSession(nil, true, "uid")
+	assert.NoError(t, s.Set("roomId", 42))
+
+	ctx := context.WithValue(context.Background(), constants.SessionCtxKey, s)
+	ctx = pcontext.AddToPropagateCtx(ctx, "matchId", "match")
+	extractErr := errors.New("invalid payload")
+
+	tables := []struct {
+		name string
+		key  KeyFunc
+		ctx  context.Context
+		res  string
+		err  error
+	}{
+		{"session_uid", SessionKey(""), ctx, "uid", nil},
+		{"session_field", SessionKey("roomId"), ctx, "42", nil},
+		{"session_missing_field", SessionKey("other"), ctx, "", constants.ErrNoRoutingKey},
+		{"no_session", SessionKey(""), context.Background(), "", constants.ErrNoRoutingKey},
+		{"context", ContextKey("matchId"), ctx, "match", nil},
+		{"context_missing", ContextKey("other"), ctx, "", constants.ErrNoRoutingKey},
+		{"route", RouteKey(), ctx, rt.String(), nil},
+		{"payload", PayloadKey(func(payload []byte) (string, error) { return string(payload), nil }), ctx, "payload", nil},
+		{"payload_error", PayloadKey(func(payload []byte) (string, error) { return "", extractErr }), ctx, "", extractErr},
+	}
+
+	for _, table := range tables {
+		t.Run(table.name, func(t *testing.T) {
+			res, err := table.key(table.ctx, rt, []byte("payload"))
+			assert.Equal(t, table.err, err)
+			assert.Equal(t, table.res, res)
+		})
+	}
+}
+
+func TestRouterConsistentHashRoute(t *testing.T) {
+	t.Parallel()
+	router := New()
+	ch := router.AddConsistentHashRoute("room", RouteKey())
+	assert.NotNil(t, router.routesMap["room"])
+
+	sv := cluster.NewServer("room-1", "room", false)
+	router.AddServer(sv)
+	router.AddServer(cluster.NewServer("connector-1", "connector", true))
+	assert.Len(t, ch.servers, 1)
+
+	rt := route.NewRoute("room", "handler", "join")
+	res, err := router.routesMap["room"](context.Background(), rt, nil, map[string]*cluster.Server{sv.ID: sv})
+	assert.NoError(t, err)
+	assert.Equal(t, sv, res)
+
+	router.RemoveServer(sv)
+	assert.Len(t, ch.servers, 0)
+	assert.Len(t, ch.ring, 0)
+
+	router.AddRoute("room", routingFunction)
+	assert.Nil(t, router.hashRoutes["room"])
+}
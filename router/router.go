@@ -23,6 +23,7 @@ package router
 import (
 	"context"
 	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/topfreegames/pitaya/v2/cluster"
@@ -37,6 +38,10 @@ import (
 type Router struct {
 	serviceDiscovery cluster.ServiceDiscovery
 	routesMap        map[string]RoutingFunc
+	hashRoutes       map[string]*ConsistentHash
+	hashMutex        sync.RWMutex
+	randMutex        sync.Mutex
+	rand             *rand.Rand
 }
 
 // RoutingFunc defines a routing function
@@ -50,7 +55,9 @@ type RoutingFunc func(
 // New returns the router
 func New() *Router {
 	return &Router{
-		routesMap: make(map[string]RoutingFunc),
+		routesMap:  make(map[string]RoutingFunc),
+		hashRoutes: make(map[string]*ConsistentHash),
+		rand:       rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
 
@@ -63,15 +70,30 @@ func (r *Router) defaultRoute(
 	servers map[string]*cluster.Server,
 ) *cluster.Server {
 	srvList := make([]*cluster.Server, 0)
-	s := rand.NewSource(time.Now().Unix())
-	rnd := rand.New(s)
 	for _, v := range servers {
 		srvList = append(srvList, v)
 	}
-	server := srvList[rnd.Intn(len(srvList))]
+	r.randMutex.Lock()
+	server := srvList[r.rand.Intn(len(srvList))]
+	r.randMutex.Unlock()
 	return server
 }
 
+// activeServers removes the draining servers, unless every server is draining
+// in which case they keep receiving work until they are gone
+func activeServers(servers map[string]*cluster.Server) map[string]*cluster.Server {
+	active := make(map[string]*cluster.Server, len(servers))
+	for id, sv := range servers {
+		if !sv.IsDraining() {
+			active[id] = sv
+		}
+	}
+	if len(active) == 0 {
+		return servers
+	}
+	return active
+}
+
 // Route gets the right server to use in the call
 func (r *Router) Route(
 	ctx context.Context,
@@ -87,6 +109,7 @@ func (r *Router) Route(
 	if err != nil {
 		return nil, err
 	}
+	serversOfType = activeServers(serversOfType)
 	if rpcType == protos.RPCType_User {
 		server := r.defaultRoute(serversOfType)
 		return server, nil
@@ -109,4 +132,43 @@ func (r *Router) AddRoute(
 		logger.Log.Warnf("overriding the route to svType %s", serverType)
 	}
 	r.routesMap[serverType] = routingFunction
+	r.hashMutex.Lock()
+	delete(r.hashRoutes, serverType)
+	r.hashMutex.Unlock()
+}
+
+// AddConsistentHashRoute routes the calls to a server type by the consistent
+// hash of the key, the route is kept up to date with the discovered servers
+func (r *Router) AddConsistentHashRoute(
+	serverType string,
+	key KeyFunc,
+	opts ...HashOption,
+) *ConsistentHash {
+	ch := NewConsistentHash(key, opts...)
+	r.AddRoute(serverType, ch.Route)
+	r.hashMutex.Lock()
+	r.hashRoutes[serverType] = ch
+	r.hashMutex.Unlock()
+	return ch
+}
+
+func (r *Router) hashRoute(serverType string) (*ConsistentHash, bool) {
+	r.hashMutex.RLock()
+	defer r.hashMutex.RUnlock()
+	ch, ok := r.hashRoutes[serverType]
+	return ch, ok
+}
+
+// AddServer is called when a new server is discovered
+func (r *Router) AddServer(sv *cluster.Server) {
+	if ch, ok := r.hashRoute(sv.Type); ok {
+		ch.AddServer(sv)
+	}
+}
+
+// RemoveServer is called when a server is removed
+func (r *Router) RemoveServer(sv *cluster.Server) {
+	if ch, ok := r.hashRoute(sv.Type); ok {
+		ch.RemoveServer(sv)
+	}
 }
@@ -95,6 +95,40 @@ func TestRoute(t *testing.T) {
 	}
 }
 
+func TestRouteSkipsDrainingServers(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	route := route.NewRoute(serverType, "service", "method")
+	draining := cluster.NewServer("draining", serverType, frontend, map[string]string{
+		cluster.ServerStateKey: cluster.ServerStateDraining,
+	})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockServiceDiscovery := mocks.NewMockServiceDiscovery(ctrl)
+	mockServiceDiscovery.EXPECT().GetServersByType(serverType).Return(map[string]*cluster.Server{
+		serverID:   server,
+		"draining": draining,
+	}, nil).Times(20)
+
+	router := New()
+	router.SetServiceDiscovery(mockServiceDiscovery)
+	for i := 0; i < 20; i++ {
+		retServer, err := router.Route(ctx, protos.RPCType_User, serverType, route, &message.Message{})
+		assert.NoError(t, err)
+		assert.Equal(t, server, retServer)
+	}
+
+	// draining servers are used when there is no other server
+	mockServiceDiscovery.EXPECT().GetServersByType(serverType).Return(map[string]*cluster.Server{
+		"draining": draining,
+	}, nil)
+	retServer, err := router.Route(ctx, protos.RPCType_User, serverType, route, &message.Message{})
+	assert.NoError(t, err)
+	assert.Equal(t, draining, retServer)
+}
+
 func TestAddRoute(t *testing.T) {
 	t.Parallel()
 
@@ -25,9 +25,11 @@ import (
 	"reflect"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/topfreegames/pitaya/v2/component"
 	"github.com/topfreegames/pitaya/v2/config"
 	"github.com/topfreegames/pitaya/v2/constants"
 	"github.com/topfreegames/pitaya/v2/route"
+	"github.com/topfreegames/pitaya/v2/worker"
 )
 
 // RPC calls a method in a different server
@@ -61,6 +63,49 @@ func (app *App) ReliableRPCWithOptions(
 	return app.worker.EnqueueRPCWithOptions(routeStr, metadata, reply, arg, opts)
 }
 
+// ScheduleReliableRPC enqueues RPC to worker to be executed later or
+// periodically, according to opts, with the same retries as ReliableRPC.
+// Scheduling again a job with the key of a job not finished returns its jid
+func (app *App) ScheduleReliableRPC(
+	routeStr string,
+	metadata map[string]interface{},
+	reply, arg proto.Message,
+	opts *worker.ScheduleOpts,
+) (jid string, err error) {
+	return app.worker.ScheduleRPC(routeStr, metadata, reply, arg, opts)
+}
+
+// CancelReliableRPC cancels a reliable rpc waiting to be executed
+func (app *App) CancelReliableRPC(jid string) error {
+	return app.worker.CancelRPC(jid)
+}
+
+// ReliableRPCStatus returns the job of a reliable rpc by the jid returned when
+// it was enqueued, jobs executed successfully are not found
+func (app *App) ReliableRPCStatus(jid string) (*worker.RPCJobInfo, error) {
+	return app.worker.RPCJob(jid)
+}
+
+// FailedReliableRPCs returns the reliable rpcs that ran out of retries
+func (app *App) FailedReliableRPCs() ([]*worker.RPCJobInfo, error) {
+	return app.worker.FailedRPCs()
+}
+
+// RequeueReliableRPC enqueues again a reliable rpc that ran out of retries
+func (app *App) RequeueReliableRPC(jid string) error {
+	return app.worker.RequeueFailedRPC(jid)
+}
+
+// PurgeReliableRPC deletes a reliable rpc that ran out of retries
+func (app *App) PurgeReliableRPC(jid string) error {
+	return app.worker.PurgeFailedRPC(jid)
+}
+
+// PurgeReliableRPCs deletes all the reliable rpcs that ran out of retries
+func (app *App) PurgeReliableRPCs() (int, error) {
+	return app.worker.PurgeFailedRPCs()
+}
+
 func (app *App) doSendRPC(ctx context.Context, serverID, routeStr string, reply proto.Message, arg proto.Message) error {
 	if app.rpcServer == nil {
 		return constants.ErrRPCServerNotInitialized
@@ -70,18 +115,52 @@ func (app *App) doSendRPC(ctx context.Context, serverID, routeStr string, reply
 		return constants.ErrReplyShouldBePtr
 	}
 
-	r, err := route.Decode(routeStr)
+	r, err := app.rpcRoute(serverID, routeStr)
 	if err != nil {
 		return err
 	}
 
+	return app.remoteService.RPC(ctx, serverID, r, reply, arg)
+}
+
+// RPCStream opens a stream with a stream remote, the messages sent by the
+// remote are received from the returned stream
+func (app *App) RPCStream(ctx context.Context, routeStr string, arg proto.Message) (component.ClientStream, error) {
+	return app.doOpenStream(ctx, "", routeStr, arg)
+}
+
+// RPCStreamTo opens a stream with a stream remote of a specific server
+func (app *App) RPCStreamTo(ctx context.Context, serverID, routeStr string, arg proto.Message) (component.ClientStream, error) {
+	return app.doOpenStream(ctx, serverID, routeStr, arg)
+}
+
+func (app *App) doOpenStream(ctx context.Context, serverID, routeStr string, arg proto.Message) (component.ClientStream, error) {
+	if app.rpcServer == nil {
+		return nil, constants.ErrRPCServerNotInitialized
+	}
+
+	r, err := app.rpcRoute(serverID, routeStr)
+	if err != nil {
+		return nil, err
+	}
+
+	return app.remoteService.RPCStream(ctx, serverID, r, arg)
+}
+
+// rpcRoute decodes the route of a rpc to another server
+func (app *App) rpcRoute(serverID, routeStr string) (*route.Route, error) {
+	r, err := route.Decode(routeStr)
+	if err != nil {
+		return nil, err
+	}
+
 	if r.SvType == "" {
-		return constants.ErrNoServerTypeChosenForRPC
+		return nil, constants.ErrNoServerTypeChosenForRPC
 	}
 
 	if (r.SvType == app.server.Type && serverID == "") || serverID == app.server.ID {
-		return constants.ErrNonsenseRPC
+		return nil, constants.ErrNonsenseRPC
 	}
 
-	return app.remoteService.RPC(ctx, serverID, r, reply, arg)
+	return r, nil
 }
@@ -0,0 +1,106 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cbor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MyStruct struct {
+	Str    string            `json:"str"`
+	Number int64             `json:"number,omitempty"`
+	Data   []byte            `json:"data"`
+	Extra  map[string]string `json:"extra,omitempty"`
+	Hidden string            `json:"-"`
+}
+
+func TestNewSerializer(t *testing.T) {
+	t.Parallel()
+
+	serializer := NewSerializer()
+
+	assert.NotNil(t, serializer)
+	assert.Equal(t, "cbor", serializer.GetName())
+}
+
+func TestMarshalUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	var tables = map[string]struct {
+		raw interface{}
+	}{
+		"test_struct":       {&MyStruct{Str: "hello", Number: 42, Data: []byte{0x01}, Extra: map[string]string{"a": "b"}}},
+		"test_empty_struct": {&MyStruct{}},
+	}
+	serializer := NewSerializer()
+
+	for name, table := range tables {
+		t.Run(name, func(t *testing.T) {
+			data, err := serializer.Marshal(table.raw)
+			assert.NoError(t, err)
+
+			var result MyStruct
+			err = serializer.Unmarshal(data, &result)
+			assert.NoError(t, err)
+			assert.Equal(t, table.raw, &result)
+		})
+	}
+}
+
+func TestMarshalUsesJSONTags(t *testing.T) {
+	t.Parallel()
+
+	serializer := NewSerializer()
+	data, err := serializer.Marshal(&MyStruct{Str: "hello", Hidden: "secret"})
+	assert.NoError(t, err)
+
+	var result map[string]interface{}
+	err = serializer.Unmarshal(data, &result)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", result["str"])
+	assert.Contains(t, result, "data")
+	assert.NotContains(t, result, "number")
+	assert.NotContains(t, result, "Hidden")
+}
+
+func TestUnmarshalMap(t *testing.T) {
+	t.Parallel()
+
+	serializer := NewSerializer()
+	data, err := serializer.Marshal(map[string]interface{}{"a": map[string]interface{}{"b": "c"}})
+	assert.NoError(t, err)
+
+	var result interface{}
+	err = serializer.Unmarshal(data, &result)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"a": map[string]interface{}{"b": "c"}}, result)
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	t.Parallel()
+
+	serializer := NewSerializer()
+	var result MyStruct
+	err := serializer.Unmarshal([]byte{0xc1}, &result)
+	assert.Error(t, err)
+}
@@ -39,3 +39,25 @@ type (
 		GetName() string
 	}
 )
+
+// Serializers is a list of serializers that can be chosen by name
+type Serializers []Serializer
+
+// Get returns the serializer with the given name, nil if there is none
+func (s Serializers) Get(name string) Serializer {
+	for _, serializer := range s {
+		if serializer.GetName() == name {
+			return serializer
+		}
+	}
+	return nil
+}
+
+// Names returns the names of the serializers
+func (s Serializers) Names() []string {
+	names := make([]string, 0, len(s))
+	for _, serializer := range s {
+		names = append(names, serializer.GetName())
+	}
+	return names
+}
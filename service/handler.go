@@ -26,6 +26,8 @@ import (
 	"fmt"
 	"math/rand"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/nats-io/nuid"
@@ -74,6 +76,12 @@ type (
 		handlers         map[string]*component.Handler // all handler method
 		dispatchCount    int
 		rander           *rand.Rand
+		mailboxes        *Mailboxes   // process messages with the actor model when set
+		inFlight         int64        // messages received but not processed yet
+		clock            timer.Clock  // clock the timers added by Dispatch are due by
+		ticker           timer.Ticker // ticker running the timers in Dispatch
+		stopChan         chan struct{}
+		stopOnce         sync.Once
 	}
 
 	unhandledMessage struct {
@@ -97,6 +105,7 @@ func NewHandlerService(
 	metricsReporters []metrics.Reporter,
 	handlerHooks *pipeline.HandlerHooks,
 	handlerPool *HandlerPool,
+	mailboxes *Mailboxes,
 ) *HandlerService {
 	h := &HandlerService{
 		rander:           rand.New(rand.NewSource(time.Now().UnixNano())),
@@ -110,6 +119,9 @@ func NewHandlerService(
 		metricsReporters: metricsReporters,
 		handlerPool:      handlerPool,
 		handlers:         make(map[string]*component.Handler),
+		mailboxes:        mailboxes,
+		clock:            timer.GlobalClock(),
+		stopChan:         make(chan struct{}),
 	}
 
 	for i := 0; i < dispatchCount; i++ {
@@ -117,38 +129,80 @@ func NewHandlerService(
 		h.chRemoteProcess = append(h.chRemoteProcess, make(chan unhandledMessage, remoteProcessBufferSize))
 	}
 	h.handlerHooks = handlerHooks
+	if mailboxes != nil {
+		mailboxes.process = h.processMailboxMessage
+	}
 
 	return h
 }
 
+// SetTicker sets the ticker running the timers in Dispatch and the clock the
+// timers are due by, it must be called before Dispatch
+func (h *HandlerService) SetTicker(clock timer.Clock, ticker timer.Ticker) {
+	h.clock = clock
+	h.ticker = ticker
+}
+
 // Dispatch message to corresponding logic handler
 func (h *HandlerService) Dispatch(thread int) {
-	// TODO: This timer is being stopped multiple times, it probably doesn't need to be stopped here
-	defer timer.GlobalTicker.Stop()
-
+	var ticks <-chan time.Time
+	if h.ticker != nil {
+		ticks = h.ticker.C()
+	}
 	for {
 		// Calls to remote servers block calls to local server
 		select {
 		case lm := <-h.chLocalProcess[thread]:
 			metrics.ReportMessageProcessDelayFromCtx(lm.ctx, h.metricsReporters, "local")
 			h.localProcess(lm.ctx, lm.agent, lm.route, lm.msg)
+			atomic.AddInt64(&h.inFlight, -1)
 
 		case rm := <-h.chRemoteProcess[thread]:
 			metrics.ReportMessageProcessDelayFromCtx(rm.ctx, h.metricsReporters, "remote")
 			h.remoteService.remoteProcess(rm.ctx, nil, rm.agent, rm.route, rm.msg)
+			atomic.AddInt64(&h.inFlight, -1)
 
-		case <-timer.GlobalTicker.C: // execute cron task
+		case <-ticks: // execute cron task
 			timer.Cron()
 
 		case t := <-timer.Manager.ChCreatedTimer: // new Timers
-			timer.AddTimer(t)
+			timer.AddClockTimer(t, h.clock)
 
 		case id := <-timer.Manager.ChClosingTimer: // closing Timers
 			timer.RemoveTimer(id)
+
+		case <-h.stopChan:
+			return
 		}
 	}
 }
 
+// Stop makes the Dispatch loops return, so that they don't add the timers
+// created after the App stopped
+func (h *HandlerService) Stop() {
+	h.stopOnce.Do(func() {
+		close(h.stopChan)
+	})
+}
+
+// InFlight returns the number of client messages received and not processed yet
+func (h *HandlerService) InFlight() int64 {
+	return atomic.LoadInt64(&h.inFlight)
+}
+
+// Refuse closes a connection that won't be handled because the server is
+// draining, asking its client to reconnect when reconnect is true
+func (h *HandlerService) Refuse(conn acceptor.PlayerConn, reconnect bool, addr string) {
+	if reconnect {
+		if err := h.agentFactory.Reconnect(conn, addr); err != nil {
+			logger.Log.Warnf("Failed to send reconnect to refused connection: %s", err.Error())
+		}
+	}
+	if err := conn.Close(); err != nil {
+		logger.Log.Debugf("Failed to close refused connection: %s", err.Error())
+	}
+}
+
 // Register registers components
 func (h *HandlerService) Register(comp component.Component, opts []component.Option) error {
 	s := component.NewService(comp, opts)
@@ -181,7 +235,7 @@ func (h *HandlerService) Handle(conn acceptor.PlayerConn) {
 
 	// guarantee agent related resource is destroyed
 	defer func() {
-		a.GetSession().Close()
+		a.Disconnect()
 		logger.Log.Debugf("Session read goroutine exit, SessionID=%d, UID=%s", a.GetSession().ID(), a.GetSession().UID())
 	}()
 
@@ -221,15 +275,26 @@ func (h *HandlerService) processPacket(a agent.Agent, p *packet.Packet) error {
 	switch p.Type {
 	case packet.Handshake:
 		logger.Log.Debug("Received handshake packet")
-		if err := a.SendHandshakeResponse(); err != nil {
+
+		// Parse the json sent with the handshake by the client
+		handshakeData := &session.HandshakeData{}
+		err := json.Unmarshal(p.Data, handshakeData)
+		if err == nil && handshakeData.Sys.ResumeToken != "" {
+			if err := a.ResumeSession(handshakeData.Sys.ResumeToken); err != nil {
+				logger.Log.Warnf("Failed to resume session, a new one will be used: %s", err.Error())
+			}
+		}
+
+		if err := a.SendHandshakeResponse(handshakeData); err != nil {
+			if _, ok := err.(*e.Error); ok {
+				a.SetStatus(constants.StatusClosed)
+				return fmt.Errorf("Handshake rejected. Id=%d: %s", a.GetSession().ID(), err.Error())
+			}
 			logger.Log.Errorf("Error sending handshake response: %s", err.Error())
 			return err
 		}
 		logger.Log.Debugf("Session handshake Id=%d, Remote=%s", a.GetSession().ID(), a.RemoteAddr())
 
-		// Parse the json sent with the handshake by the client
-		handshakeData := &session.HandshakeData{}
-		err := json.Unmarshal(p.Data, handshakeData)
 		if err != nil {
 			a.SetStatus(constants.StatusClosed)
 			return fmt.Errorf("Invalid handshake data. Id=%d", a.GetSession().ID())
@@ -253,7 +318,7 @@ func (h *HandlerService) processPacket(a agent.Agent, p *packet.Packet) error {
 			return fmt.Errorf("receive data on socket which is not yet ACK, session will be closed immediately, remote=%s",
 				a.RemoteAddr().String())
 		}
-		msg, err := message.Decode(p.Data)
+		msg, err := a.DecodeMessage(p.Data)
 		if err != nil {
 			return err
 		}
@@ -269,7 +334,8 @@ func (h *HandlerService) processPacket(a agent.Agent, p *packet.Packet) error {
 
 func (h *HandlerService) processMessage(a agent.Agent, msg *message.Message) {
 	requestID := nuid.New()
-	ctx := pcontext.AddToPropagateCtx(context.Background(), constants.StartTimeKey, time.Now().UnixNano())
+	// canceled if the client disconnects before the message is processed
+	ctx := pcontext.AddToPropagateCtx(a.Context(), constants.StartTimeKey, time.Now().UnixNano())
 	ctx = pcontext.AddToPropagateCtx(ctx, constants.RouteKey, msg.Route)
 	ctx = pcontext.AddToPropagateCtx(ctx, constants.RequestIDKey, requestID)
 	tags := opentracing.Tags{
@@ -299,6 +365,16 @@ func (h *HandlerService) processMessage(a agent.Agent, msg *message.Message) {
 		route: r,
 		msg:   msg,
 	}
+	if h.mailboxes != nil {
+		if r.SvType != h.server.Type && h.remoteService == nil {
+			logger.Log.Warnf("request made to another server type but no remoteService running")
+			return
+		}
+		atomic.AddInt64(&h.inFlight, 1)
+		h.mailboxes.deliver(h.mailboxes.key(a.GetSession(), r), message)
+		return
+	}
+
 	var sessionId int64
 	if message.agent != nil {
 		if sess := message.agent.GetSession(); sess != nil {
@@ -316,9 +392,11 @@ func (h *HandlerService) processMessage(a agent.Agent, msg *message.Message) {
 		// 	message.agent.GetSession().ID(), threadId)
 	}
 	if r.SvType == h.server.Type {
+		atomic.AddInt64(&h.inFlight, 1)
 		h.chLocalProcess[threadId] <- message
 	} else {
 		if h.remoteService != nil {
+			atomic.AddInt64(&h.inFlight, 1)
 			h.chRemoteProcess[threadId] <- message
 		} else {
 			logger.Log.Warnf("request made to another server type but no remoteService running")
@@ -326,6 +404,18 @@ func (h *HandlerService) processMessage(a agent.Agent, msg *message.Message) {
 	}
 }
 
+// processMailboxMessage processes a message on the goroutine of its mailbox
+func (h *HandlerService) processMailboxMessage(m unhandledMessage) {
+	defer atomic.AddInt64(&h.inFlight, -1)
+	if m.route.SvType == h.server.Type {
+		metrics.ReportMessageProcessDelayFromCtx(m.ctx, h.metricsReporters, "local")
+		h.localProcess(m.ctx, m.agent, m.route, m.msg)
+		return
+	}
+	metrics.ReportMessageProcessDelayFromCtx(m.ctx, h.metricsReporters, "remote")
+	h.remoteService.remoteProcess(m.ctx, nil, m.agent, m.route, m.msg)
+}
+
 func (h *HandlerService) localProcess(ctx context.Context, a agent.Agent, route *route.Route, msg *message.Message) {
 	var mid uint
 	switch msg.Type {
@@ -335,7 +425,8 @@ func (h *HandlerService) localProcess(ctx context.Context, a agent.Agent, route
 		mid = 0
 	}
 
-	ret, err := h.handlerPool.ProcessHandlerMessage(ctx, route, h.serializer, h.handlerHooks, a.GetSession(), uint64(msg.ID), msg.Data, msg.Type, false)
+	serializer := sessionSerializer(a.GetSession(), h.serializer)
+	ret, err := h.handlerPool.ProcessHandlerMessage(ctx, route, serializer, h.handlerHooks, a.GetSession(), uint64(msg.ID), msg.Data, msg.Type, false)
 	if msg.Type != message.Notify {
 		if err != nil {
 			logger.Log.Errorf("Failed to process handler message: %s", err.Error())
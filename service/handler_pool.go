@@ -64,6 +64,12 @@ func (h *HandlerPool) ProcessHandlerMessage(
 		return nil, e.NewError(err, e.ErrNotFoundCode)
 	}
 
+	// the request may have waited for its turn until the deadline or until
+	// the client disconnected
+	if err := ctxError(ctx); err != nil {
+		return nil, err
+	}
+
 	msgType, err := getMsgType(msgTypeIface)
 	if err != nil {
 		return nil, e.NewError(err, e.ErrInternalCode)
@@ -146,3 +152,15 @@ func (h *HandlerPool) getHandler(rt *route.Route) (*component.Handler, error) {
 	return handler, nil
 
 }
+
+// ctxError returns the error of a request whose context is done, or nil
+func ctxError(ctx context.Context) *e.Error {
+	switch ctx.Err() {
+	case nil:
+		return nil
+	case context.DeadlineExceeded:
+		return e.NewError(ctx.Err(), e.ErrDeadlineExceededCode)
+	default:
+		return e.NewError(ctx.Err(), e.ErrClientClosedRequest)
+	}
+}
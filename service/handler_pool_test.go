@@ -116,6 +116,7 @@ func TestProcessHandlerMessage(t *testing.T) {
 			ss := session_mocks.NewMockSession(ctrl)
 			ss.EXPECT().UID().Return("uid").AnyTimes()
 			ss.EXPECT().ID().Return(int64(1)).AnyTimes()
+			ss.EXPECT().GetIsFrontend().Return(true).AnyTimes()
 			mockSerializer := mocks.NewMockSerializer(ctrl)
 			if table.outSerialize != nil {
 				mockSerializer.EXPECT().Unmarshal(gomock.Any(), gomock.Any()).Return(table.errSerialize).Do(
@@ -129,7 +130,7 @@ func TestProcessHandlerMessage(t *testing.T) {
 				}
 			}
 			handlerHooks := pipeline.NewHandlerHooks()
-			out, err := handlerPool.ProcessHandlerMessage(nil, table.route, mockSerializer, handlerHooks, ss, nil, table.msgType, table.remote)
+			out, err := handlerPool.ProcessHandlerMessage(nil, table.route, mockSerializer, handlerHooks, ss, 0, nil, table.msgType, table.remote)
 			assert.Equal(t, table.out, out)
 			assert.Equal(t, table.err, err)
 		})
@@ -153,7 +154,8 @@ func TestProcessHandlerMessageBrokenBeforePipeline(t *testing.T) {
 	ss := session_mocks.NewMockSession(ctrl)
 	ss.EXPECT().UID().Return("uid").AnyTimes()
 	ss.EXPECT().ID().Return(int64(1)).AnyTimes()
-	out, err := handlerPool.ProcessHandlerMessage(nil, rt, nil, handlerHooks, ss, nil, message.Request, false)
+	ss.EXPECT().GetIsFrontend().Return(true).AnyTimes()
+	out, err := handlerPool.ProcessHandlerMessage(nil, rt, nil, handlerHooks, ss, 0, nil, message.Request, false)
 	assert.Nil(t, out)
 	assert.Equal(t, expected, err)
 }
@@ -179,6 +181,7 @@ func TestProcessHandlerMessageBrokenAfterPipeline(t *testing.T) {
 	ss := session_mocks.NewMockSession(ctrl)
 	ss.EXPECT().UID().Return("uid").AnyTimes()
 	ss.EXPECT().ID().Return(int64(1)).AnyTimes()
+	ss.EXPECT().GetIsFrontend().Return(true).AnyTimes()
 
 	mockSerializer := mocks.NewMockSerializer(ctrl)
 	mockSerializer.EXPECT().Unmarshal(gomock.Any(), gomock.Any()).Return(nil).Do(
@@ -188,7 +191,7 @@ func TestProcessHandlerMessageBrokenAfterPipeline(t *testing.T) {
 
 	handlerHooks := pipeline.NewHandlerHooks()
 	handlerHooks.AfterHandler = afterHandler
-	out, err := handlerPool.ProcessHandlerMessage(nil, rt, mockSerializer, handlerHooks, ss, nil, message.Request, false)
+	out, err := handlerPool.ProcessHandlerMessage(nil, rt, mockSerializer, handlerHooks, ss, 0, nil, message.Request, false)
 	assert.Nil(t, out)
 	assert.Equal(t, errors.New("oh noes"), err)
 }
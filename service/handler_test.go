@@ -39,6 +39,7 @@ import (
 	"github.com/topfreegames/pitaya/v2/conn/packet"
 	"github.com/topfreegames/pitaya/v2/constants"
 	pcontext "github.com/topfreegames/pitaya/v2/context"
+	e "github.com/topfreegames/pitaya/v2/errors"
 	"github.com/topfreegames/pitaya/v2/helpers"
 	"github.com/topfreegames/pitaya/v2/metrics"
 	metricsmocks "github.com/topfreegames/pitaya/v2/metrics/mocks"
@@ -97,13 +98,14 @@ func TestNewHandlerService(t *testing.T) {
 	svc := NewHandlerService(
 		packetDecoder,
 		serializer,
-		9, 8,
+		9, 8, 7,
 		sv,
 		remoteSvc,
 		mockAgentFactory,
 		mockMetricsReporters,
 		handlerHooks,
 		handlerPool,
+		nil,
 	)
 
 	assert.NotNil(t, svc)
@@ -121,7 +123,7 @@ func TestNewHandlerService(t *testing.T) {
 
 func TestHandlerServiceRegister(t *testing.T) {
 	handlerPool := NewHandlerPool()
-	svc := NewHandlerService(nil, nil, 0, 0, nil, nil, nil, nil, nil, handlerPool)
+	svc := NewHandlerService(nil, nil, 0, 0, 0, nil, nil, nil, nil, nil, handlerPool, nil)
 	err := svc.Register(&MyComp{}, []component.Option{})
 	assert.NoError(t, err)
 	assert.Len(t, svc.services, 1)
@@ -141,7 +143,7 @@ func TestHandlerServiceRegister(t *testing.T) {
 
 func TestHandlerServiceRegisterFailsIfRegisterTwice(t *testing.T) {
 	handlerPool := NewHandlerPool()
-	svc := NewHandlerService(nil, nil, 0, 0, nil, nil, nil, nil, nil, handlerPool)
+	svc := NewHandlerService(nil, nil, 0, 0, 0, nil, nil, nil, nil, nil, handlerPool, nil)
 	err := svc.Register(&MyComp{}, []component.Option{})
 	assert.NoError(t, err)
 	err = svc.Register(&MyComp{}, []component.Option{})
@@ -150,7 +152,7 @@ func TestHandlerServiceRegisterFailsIfRegisterTwice(t *testing.T) {
 
 func TestHandlerServiceRegisterFailsIfNoHandlerMethods(t *testing.T) {
 	handlerPool := NewHandlerPool()
-	svc := NewHandlerService(nil, nil, 0, 0, nil, nil, nil, nil, nil, handlerPool)
+	svc := NewHandlerService(nil, nil, 0, 0, 0, nil, nil, nil, nil, nil, handlerPool, nil)
 	err := svc.Register(&NoHandlerRemoteComp{}, []component.Option{})
 	assert.Equal(t, errors.New("type NoHandlerRemoteComp has no exported methods of handler type"), err)
 }
@@ -174,12 +176,15 @@ func TestHandlerServiceProcessMessage(t *testing.T) {
 
 			sv := &cluster.Server{}
 			handlerPool := NewHandlerPool()
-			svc := NewHandlerService(nil, nil, 1, 1, sv, &RemoteService{}, nil, nil, nil, handlerPool)
+			svc := NewHandlerService(nil, nil, 1, 1, 1, sv, &RemoteService{}, nil, nil, nil, handlerPool, nil)
 
 			mockSession := mocks.NewMockSession(ctrl)
 			mockSession.EXPECT().UID().Return("uid").Times(1)
+			// the session id chooses the dispatch thread
+			mockSession.EXPECT().ID().Return(int64(1)).AnyTimes()
 			mockAgent := agentmocks.NewMockAgent(ctrl)
-			mockAgent.EXPECT().GetSession().Return(mockSession).Times(2)
+			mockAgent.EXPECT().GetSession().Return(mockSession).AnyTimes()
+			mockAgent.EXPECT().Context().Return(context.Background())
 
 			if table.err != nil {
 				mockAgent.EXPECT().AnswerWithError(gomock.Any(), table.msg.ID, gomock.Any()).Times(1)
@@ -190,9 +195,9 @@ func TestHandlerServiceProcessMessage(t *testing.T) {
 			if table.err == nil {
 				var recvMsg unhandledMessage
 				if table.err == nil && table.local {
-					recvMsg = helpers.ShouldEventuallyReceive(t, svc.chLocalProcess).(unhandledMessage)
+					recvMsg = helpers.ShouldEventuallyReceive(t, svc.chLocalProcess[0]).(unhandledMessage)
 				} else if table.err == nil {
-					recvMsg = helpers.ShouldEventuallyReceive(t, svc.chRemoteProcess).(unhandledMessage)
+					recvMsg = helpers.ShouldEventuallyReceive(t, svc.chRemoteProcess[0]).(unhandledMessage)
 				}
 				assert.Equal(t, table.msg, recvMsg.msg)
 				assert.NotNil(t, pcontext.GetFromPropagateCtx(recvMsg.ctx, constants.StartTimeKey))
@@ -226,12 +231,15 @@ func TestHandlerServiceLocalProcess(t *testing.T) {
 			defer ctrl.Finish()
 
 			mockSession := mocks.NewMockSession(ctrl)
-			mockSession.EXPECT().UID().Return("uid").Times(1)
+			mockSession.EXPECT().UID().Return("uid").AnyTimes()
+			mockSession.EXPECT().GetSerializer().Return(nil).Times(1)
+			mockSession.EXPECT().ID().Return(int64(1)).AnyTimes()
+			mockSession.EXPECT().GetIsFrontend().Return(true).AnyTimes()
 
 			mockAgent := agentmocks.NewMockAgent(ctrl)
 			mockAgent.EXPECT().GetSession().Return(mockSession).AnyTimes()
 
-			svc := NewHandlerService(nil, nil, 1, 1, nil, nil, nil, nil, pipeline.NewHandlerHooks(), handlerPool)
+			svc := NewHandlerService(nil, nil, 1, 1, 1, nil, nil, nil, nil, pipeline.NewHandlerHooks(), handlerPool, nil)
 
 			ctx := context.Background()
 
@@ -239,7 +247,6 @@ func TestHandlerServiceLocalProcess(t *testing.T) {
 				mockAgent.EXPECT().AnswerWithError(gomock.Any(), table.msg.ID, gomock.Any())
 			} else {
 				mockSession.EXPECT().ResponseMID(ctx, table.msg.ID, table.msg.Data, gomock.Any()).Return(nil).Times(1)
-				mockSession.EXPECT().ID().Return(int64(1)).Times(1)
 			}
 
 			svc.localProcess(ctx, mockAgent, table.rt, table.msg)
@@ -256,6 +263,7 @@ func TestHandlerServiceProcessPacketHandshake(t *testing.T) {
 	}{
 		{"invalid_handshake_data", &packet.Packet{Type: packet.Handshake, Data: []byte("asiodjasd")}, constants.StatusClosed, "Invalid handshake data"},
 		{"valid_handshake_data", &packet.Packet{Type: packet.Handshake, Data: []byte(`{"sys":{"platform":"mac"}}`)}, constants.StatusHandshake, ""},
+		{"resume_handshake_data", &packet.Packet{Type: packet.Handshake, Data: []byte(`{"sys":{"platform":"mac","resumeToken":"token"}}`)}, constants.StatusHandshake, ""},
 	}
 	for _, table := range tables {
 		t.Run(table.name, func(t *testing.T) {
@@ -269,11 +277,14 @@ func TestHandlerServiceProcessPacketHandshake(t *testing.T) {
 			mockAgent.EXPECT().GetSession().Return(mockSession).Times(1)
 			mockAgent.EXPECT().RemoteAddr().Return(&mockAddr{})
 			mockAgent.EXPECT().SetStatus(table.socketStatus).Times(1)
-			mockAgent.EXPECT().SendHandshakeResponse().Return(nil).Times(1)
+			mockAgent.EXPECT().SendHandshakeResponse(gomock.Any()).Return(nil).Times(1)
 
 			if table.errStr == "" {
 				handshakeData := &session.HandshakeData{}
 				_ = encjson.Unmarshal(table.packet.Data, handshakeData)
+				if handshakeData.Sys.ResumeToken != "" {
+					mockAgent.EXPECT().ResumeSession(handshakeData.Sys.ResumeToken).Return(nil).Times(1)
+				}
 				mockAgent.EXPECT().GetSession().Return(mockSession).Times(2)
 				mockAgent.EXPECT().IPVersion().Return(constants.IPv4).Times(1)
 				mockSession.EXPECT().SetHandshakeData(handshakeData).Times(1)
@@ -285,7 +296,7 @@ func TestHandlerServiceProcessPacketHandshake(t *testing.T) {
 			}
 
 			handlerPool := NewHandlerPool()
-			svc := NewHandlerService(nil, nil, 1, 1, nil, nil, nil, nil, pipeline.NewHandlerHooks(), handlerPool)
+			svc := NewHandlerService(nil, nil, 1, 1, 1, nil, nil, nil, nil, pipeline.NewHandlerHooks(), handlerPool, nil)
 			err := svc.processPacket(mockAgent, table.packet)
 			if table.errStr == "" {
 				assert.Nil(t, err)
@@ -297,6 +308,26 @@ func TestHandlerServiceProcessPacketHandshake(t *testing.T) {
 	}
 }
 
+func TestHandlerServiceProcessPacketHandshakeRejected(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSession := mocks.NewMockSession(ctrl)
+	mockSession.EXPECT().ID().Return(int64(1))
+
+	rejection := e.NewError(constants.ErrProtocolVersionNotSupported, e.ErrBadRequestCode)
+	mockAgent := agentmocks.NewMockAgent(ctrl)
+	mockAgent.EXPECT().SendHandshakeResponse(&session.HandshakeData{Sys: session.HandshakeClientData{Platform: "mac"}}).Return(rejection)
+	mockAgent.EXPECT().SetStatus(constants.StatusClosed)
+	mockAgent.EXPECT().GetSession().Return(mockSession)
+
+	handlerPool := NewHandlerPool()
+	svc := NewHandlerService(nil, nil, 1, 1, 1, nil, nil, nil, nil, pipeline.NewHandlerHooks(), handlerPool, nil)
+	err := svc.processPacket(mockAgent, &packet.Packet{Type: packet.Handshake, Data: []byte(`{"sys":{"platform":"mac"}}`)})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Handshake rejected")
+}
+
 func TestHandlerServiceProcessPacketHandshakeAck(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -305,7 +336,7 @@ func TestHandlerServiceProcessPacketHandshakeAck(t *testing.T) {
 	mockSession.EXPECT().ID().Return(int64(1)).Times(1)
 
 	handlerPool := NewHandlerPool()
-	svc := NewHandlerService(nil, nil, 1, 1, nil, nil, nil, nil, nil, handlerPool)
+	svc := NewHandlerService(nil, nil, 1, 1, 1, nil, nil, nil, nil, nil, handlerPool, nil)
 
 	mockAgent := agentmocks.NewMockAgent(ctrl)
 	mockAgent.EXPECT().GetSession().Return(mockSession).Times(1)
@@ -325,7 +356,7 @@ func TestHandlerServiceProcessPacketHeartbeat(t *testing.T) {
 	mockAgent.EXPECT().SetLastAt()
 
 	handlerPool := NewHandlerPool()
-	svc := NewHandlerService(nil, nil, 1, 1, nil, nil, nil, nil, nil, handlerPool)
+	svc := NewHandlerService(nil, nil, 1, 1, 1, nil, nil, nil, nil, nil, handlerPool, nil)
 
 	err := svc.processPacket(mockAgent, &packet.Packet{Type: packet.Heartbeat})
 	assert.NoError(t, err)
@@ -360,8 +391,10 @@ func TestHandlerServiceProcessPacketData(t *testing.T) {
 			if table.socketStatus < constants.StatusWorking {
 				mockAgent.EXPECT().RemoteAddr().Return(&mockAddr{})
 			} else {
+				mockAgent.EXPECT().DecodeMessage(table.packet.Data).DoAndReturn(messageEncoder.Decode)
 				if table.errStr == "" {
 					mockAgent.EXPECT().GetSession().Return(mockSession).Times(2)
+					mockAgent.EXPECT().Context().Return(context.Background())
 					mockSession.EXPECT().UID().Return("uid").Times(1)
 
 					mockAgent.EXPECT().AnswerWithError(gomock.Any(), msgID, gomock.Any()).Times(1)
@@ -370,7 +403,7 @@ func TestHandlerServiceProcessPacketData(t *testing.T) {
 			}
 
 			handlerPool := NewHandlerPool()
-			svc := NewHandlerService(nil, nil, 1, 1, &cluster.Server{}, nil, nil, nil, nil, handlerPool)
+			svc := NewHandlerService(nil, nil, 1, 1, 1, &cluster.Server{}, nil, nil, nil, nil, handlerPool, nil)
 			err := svc.processPacket(mockAgent, table.packet)
 			if table.errStr != "" {
 				assert.Contains(t, err.Error(), table.errStr)
@@ -405,18 +438,18 @@ func TestHandlerServiceHandle(t *testing.T) {
 		wg.Done()
 	})
 
-	mockAgent.EXPECT().SendHandshakeResponse().Return(nil)
+	mockAgent.EXPECT().SendHandshakeResponse(gomock.Any()).Return(nil)
 
 	mockSession := mocks.NewMockSession(ctrl)
 	mockSession.EXPECT().SetHandshakeData(gomock.Any()).Times(1)
 	mockSession.EXPECT().UID().Return("uid").Times(1)
 	mockSession.EXPECT().ID().Return(int64(1)).Times(2)
 	mockSession.EXPECT().Set(constants.IPVersionKey, constants.IPv4)
-	mockSession.EXPECT().Close()
 
+	mockAgent.EXPECT().Disconnect()
 	mockAgent.EXPECT().String().Return("")
 	mockAgent.EXPECT().SetStatus(constants.StatusHandshake)
-	mockAgent.EXPECT().GetSession().Return(mockSession).Times(6)
+	mockAgent.EXPECT().GetSession().Return(mockSession).Times(5)
 	mockAgent.EXPECT().IPVersion().Return(constants.IPv4)
 	mockAgent.EXPECT().RemoteAddr().Return(&mockAddr{}).AnyTimes()
 	mockAgent.EXPECT().SetLastAt().Do(func() {
@@ -434,6 +467,6 @@ func TestHandlerServiceHandle(t *testing.T) {
 	mockConn.EXPECT().Close().MaxTimes(1)
 
 	handlerPool := NewHandlerPool()
-	svc := NewHandlerService(packetDecoder, mockSerializer, 1, 1, nil, nil, mockAgentFactory, nil, pipeline.NewHandlerHooks(), handlerPool)
+	svc := NewHandlerService(packetDecoder, mockSerializer, 1, 1, 1, nil, nil, mockAgentFactory, nil, pipeline.NewHandlerHooks(), handlerPool, nil)
 	svc.Handle(mockConn)
 }
@@ -0,0 +1,151 @@
+// Copyright (c) nano Author and TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package service
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/topfreegames/pitaya/v2/config"
+	"github.com/topfreegames/pitaya/v2/logger"
+	"github.com/topfreegames/pitaya/v2/metrics"
+	"github.com/topfreegames/pitaya/v2/route"
+	"github.com/topfreegames/pitaya/v2/session"
+)
+
+// MailboxKeyFunc returns the key of the mailbox that processes a message, the
+// messages with the same key are processed in order by the same goroutine
+type MailboxKeyFunc func(s session.Session, r *route.Route) string
+
+// SessionMailboxKey gives each session its own mailbox
+func SessionMailboxKey(s session.Session, r *route.Route) string {
+	return strconv.FormatInt(s.ID(), 10)
+}
+
+type mailbox struct {
+	messages chan unhandledMessage
+	senders  int
+}
+
+// Mailboxes processes the messages of each session, or of each entity chosen
+// by the mailbox key, on its own goroutine and in the order they arrive
+type Mailboxes struct {
+	mutex            sync.Mutex
+	size             int
+	idleTimeout      time.Duration
+	key              MailboxKeyFunc
+	metricsReporters []metrics.Reporter
+	boxes            map[string]*mailbox
+	process          func(unhandledMessage)
+}
+
+// NewMailboxes creates the mailboxes of the actor concurrency model, if key
+// is nil each session gets its own mailbox
+func NewMailboxes(config config.MailboxConfig, key MailboxKeyFunc, metricsReporters []metrics.Reporter) *Mailboxes {
+	if key == nil {
+		key = SessionMailboxKey
+	}
+	return &Mailboxes{
+		size:             config.Size,
+		idleTimeout:      config.IdleTimeout,
+		key:              key,
+		metricsReporters: metricsReporters,
+		boxes:            make(map[string]*mailbox),
+	}
+}
+
+// Len returns the number of mailboxes running
+func (m *Mailboxes) Len() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return len(m.boxes)
+}
+
+// deliver enqueues the message in the mailbox with the given key, starting the
+// mailbox goroutine if needed, it blocks while the mailbox is full
+func (m *Mailboxes) deliver(key string, msg unhandledMessage) {
+	m.mutex.Lock()
+	box, ok := m.boxes[key]
+	if !ok {
+		box = &mailbox{messages: make(chan unhandledMessage, m.size)}
+		m.boxes[key] = box
+		go m.run(key, box)
+	}
+	box.senders++
+	count := len(m.boxes)
+	m.mutex.Unlock()
+
+	if !ok {
+		m.reportMailboxes(count)
+	}
+	for _, mr := range m.metricsReporters {
+		if err := mr.ReportSummary(metrics.MailboxDepth, map[string]string{}, float64(len(box.messages))); err != nil {
+			logger.Log.Warnf("failed to report mailbox depth: %s", err.Error())
+		}
+	}
+
+	box.messages <- msg
+
+	m.mutex.Lock()
+	box.senders--
+	m.mutex.Unlock()
+}
+
+// run processes the messages of a mailbox until it is idle for longer than
+// the idle timeout
+func (m *Mailboxes) run(key string, box *mailbox) {
+	idle := time.NewTimer(m.idleTimeout)
+	defer idle.Stop()
+
+	for {
+		select {
+		case msg := <-box.messages:
+			m.process(msg)
+			if !idle.Stop() {
+				<-idle.C
+			}
+			idle.Reset(m.idleTimeout)
+
+		case <-idle.C:
+			m.mutex.Lock()
+			if box.senders > 0 || len(box.messages) > 0 {
+				m.mutex.Unlock()
+				idle.Reset(m.idleTimeout)
+				continue
+			}
+			delete(m.boxes, key)
+			count := len(m.boxes)
+			m.mutex.Unlock()
+
+			m.reportMailboxes(count)
+			return
+		}
+	}
+}
+
+func (m *Mailboxes) reportMailboxes(count int) {
+	for _, mr := range m.metricsReporters {
+		if err := mr.ReportGauge(metrics.Mailboxes, map[string]string{}, float64(count)); err != nil {
+			logger.Log.Warnf("failed to report mailboxes: %s", err.Error())
+		}
+	}
+}
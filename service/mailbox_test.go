@@ -0,0 +1,121 @@
+// Copyright (c) nano Author and TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package service
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/topfreegames/pitaya/v2/config"
+	"github.com/topfreegames/pitaya/v2/conn/message"
+	"github.com/topfreegames/pitaya/v2/helpers"
+	"github.com/topfreegames/pitaya/v2/metrics"
+	metricsmocks "github.com/topfreegames/pitaya/v2/metrics/mocks"
+	"github.com/topfreegames/pitaya/v2/session"
+)
+
+func TestSessionMailboxKey(t *testing.T) {
+	s := session.NewSessionPool().NewSession(nil, true)
+	assert.NotEqual(t, "", SessionMailboxKey(s, nil))
+	assert.Equal(t, SessionMailboxKey(s, nil), SessionMailboxKey(s, nil))
+}
+
+func TestMailboxesProcessInOrder(t *testing.T) {
+	m := NewMailboxes(config.MailboxConfig{Size: 1, IdleTimeout: time.Minute}, nil, nil)
+
+	var mutex sync.Mutex
+	processed := map[string][]uint{}
+	m.process = func(msg unhandledMessage) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		processed[msg.msg.Route] = append(processed[msg.msg.Route], msg.msg.ID)
+	}
+
+	for i := uint(0); i < 10; i++ {
+		for _, key := range []string{"a", "b"} {
+			m.deliver(key, unhandledMessage{msg: &message.Message{Route: key, ID: i}})
+		}
+	}
+	assert.Equal(t, 2, m.Len())
+
+	// ShouldEventuallyReturn compares with ==, so the ids are compared as strings
+	expected := fmt.Sprint([]uint{0, 1, 2, 3, 4, 5, 6, 7, 8, 9})
+	helpers.ShouldEventuallyReturn(t, func() string {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return fmt.Sprint(processed["a"])
+	}, expected)
+	helpers.ShouldEventuallyReturn(t, func() string {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return fmt.Sprint(processed["b"])
+	}, expected)
+}
+
+func TestMailboxesSlowMailboxDoesNotBlockOthers(t *testing.T) {
+	m := NewMailboxes(config.MailboxConfig{Size: 1, IdleTimeout: time.Minute}, nil, nil)
+
+	release := make(chan struct{})
+	done := make(chan string, 1)
+	m.process = func(msg unhandledMessage) {
+		if msg.msg.Route == "slow" {
+			<-release
+		}
+		done <- msg.msg.Route
+	}
+	defer close(release)
+
+	m.deliver("slow", unhandledMessage{msg: &message.Message{Route: "slow"}})
+	m.deliver("fast", unhandledMessage{msg: &message.Message{Route: "fast"}})
+	assert.Equal(t, "fast", helpers.ShouldEventuallyReceive(t, done))
+}
+
+func TestMailboxesReclaimIdle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reclaimed := make(chan bool, 2)
+	mockMetricsReporter := metricsmocks.NewMockReporter(ctrl)
+	mockMetricsReporter.EXPECT().ReportSummary(metrics.MailboxDepth, map[string]string{}, gomock.Any()).Times(2)
+	mockMetricsReporter.EXPECT().ReportGauge(metrics.Mailboxes, map[string]string{}, float64(1)).Times(2)
+	mockMetricsReporter.EXPECT().ReportGauge(metrics.Mailboxes, map[string]string{}, float64(0)).Times(2).
+		Do(func(string, map[string]string, float64) { reclaimed <- true })
+
+	m := NewMailboxes(config.MailboxConfig{Size: 1, IdleTimeout: 10 * time.Millisecond}, nil, []metrics.Reporter{mockMetricsReporter})
+	processed := make(chan uint, 2)
+	m.process = func(msg unhandledMessage) {
+		processed <- msg.msg.ID
+	}
+
+	m.deliver("a", unhandledMessage{msg: &message.Message{ID: 1}})
+	assert.Equal(t, uint(1), helpers.ShouldEventuallyReceive(t, processed))
+	helpers.ShouldEventuallyReceive(t, reclaimed)
+	assert.Equal(t, 0, m.Len())
+
+	// a new goroutine is started for the reclaimed mailbox
+	m.deliver("a", unhandledMessage{msg: &message.Message{ID: 2}})
+	assert.Equal(t, uint(2), helpers.ShouldEventuallyReceive(t, processed))
+	helpers.ShouldEventuallyReceive(t, reclaimed)
+}
@@ -25,6 +25,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sync/atomic"
 
 	"github.com/golang/protobuf/proto"
 
@@ -40,6 +41,7 @@ import (
 	"github.com/topfreegames/pitaya/v2/logger"
 	"github.com/topfreegames/pitaya/v2/pipeline"
 	"github.com/topfreegames/pitaya/v2/protos"
+	"github.com/topfreegames/pitaya/v2/relation"
 	"github.com/topfreegames/pitaya/v2/route"
 	"github.com/topfreegames/pitaya/v2/router"
 	"github.com/topfreegames/pitaya/v2/serialize"
@@ -55,6 +57,7 @@ type RemoteService struct {
 	rpcServer              cluster.RPCServer
 	serviceDiscovery       cluster.ServiceDiscovery
 	serializer             serialize.Serializer
+	serializers            serialize.Serializers // serializers the clients can negotiate
 	encoder                codec.PacketEncoder
 	rpcClient              cluster.RPCClient
 	services               map[string]*component.Service // all registered service
@@ -65,6 +68,8 @@ type RemoteService struct {
 	sessionPool            session.SessionPool
 	handlerPool            *HandlerPool
 	remotes                map[string]*component.Remote // all remote method
+	streams                map[string]*component.Remote // all stream remote method
+	inFlight               int64                        // rpcs, pushes and kicks being processed
 }
 
 // NewRemoteService creates and return a new RemoteService
@@ -74,6 +79,7 @@ func NewRemoteService(
 	sd cluster.ServiceDiscovery,
 	encoder codec.PacketEncoder,
 	serializer serialize.Serializer,
+	serializers serialize.Serializers,
 	router *router.Router,
 	messageEncoder message.Encoder,
 	server *cluster.Server,
@@ -88,6 +94,7 @@ func NewRemoteService(
 		encoder:                encoder,
 		serviceDiscovery:       sd,
 		serializer:             serializer,
+		serializers:            serializers,
 		router:                 router,
 		messageEncoder:         messageEncoder,
 		server:                 server,
@@ -95,6 +102,7 @@ func NewRemoteService(
 		sessionPool:            sessionPool,
 		handlerPool:            handlerPool,
 		remotes:                make(map[string]*component.Remote),
+		streams:                make(map[string]*component.Remote),
 	}
 
 	remote.handlerHooks = handlerHooks
@@ -140,6 +148,9 @@ func (r *RemoteService) AddRemoteBindingListener(bindingListener cluster.RemoteB
 
 // Call processes a remote call
 func (r *RemoteService) Call(ctx context.Context, req *protos.Request) (*protos.Response, error) {
+	atomic.AddInt64(&r.inFlight, 1)
+	defer atomic.AddInt64(&r.inFlight, -1)
+
 	c, err := util.GetContextFromRequest(req, r.server.ID)
 	c = util.StartSpanFromRequest(c, r.server.ID, req.GetMsg().GetRoute())
 	var res *protos.Response
@@ -151,11 +162,25 @@ func (r *RemoteService) Call(ctx context.Context, req *protos.Request) (*protos.
 			},
 		}
 	} else {
+		// the deadline and cancelation applied by the rpc server
+		var cancel context.CancelFunc
+		c, cancel = pcontext.WithDeadlineFrom(c, ctx)
+		defer cancel()
+
 		relationData := pcontext.GetRelationDataFromContext(c)
 		if relationData != nil && len(relationData) > 0 {
 			c = context.WithValue(c, constants.MsgRelationKey, relationData)
 		}
-		res = processRemoteMessage(c, req, r)
+		if ctxErr := ctxError(c); ctxErr != nil {
+			res = &protos.Response{
+				Error: &protos.Error{
+					Code: ctxErr.Code,
+					Msg:  ctxErr.Message,
+				},
+			}
+		} else {
+			res = processRemoteMessage(c, req, r)
+		}
 	}
 
 	if res.Error != nil {
@@ -178,6 +203,9 @@ func (r *RemoteService) SessionBindRemote(ctx context.Context, msg *protos.BindM
 
 // PushToUser sends a push to user
 func (r *RemoteService) PushToUser(ctx context.Context, push *protos.Push) (*protos.Response, error) {
+	atomic.AddInt64(&r.inFlight, 1)
+	defer atomic.AddInt64(&r.inFlight, -1)
+
 	// logger.Log.Debugf("sending push to user %s", push.GetUid())
 	s := r.sessionPool.GetSessionByUID(push.GetUid())
 	if s != nil {
@@ -191,7 +219,15 @@ func (r *RemoteService) PushToUser(ctx context.Context, push *protos.Push) (*pro
 			)
 			return nil, constants.ErrSessionNotFound
 		}
-		err := s.Push(ctx, push.Route, push.Data)
+		// the relation is carried by the push itself, whichever rpc client sent it
+		if push.RelationMsgId != 0 {
+			ctx = pcontext.CtxWithRelationData(ctx, push.Uid, relation.Data{MsgID: push.RelationMsgId, SessID: push.SessionId})
+		}
+		data, err := r.sessionPushData(s, push)
+		if err != nil {
+			return nil, err
+		}
+		err = s.Push(ctx, push.Route, data)
 		if err != nil {
 			return nil, err
 		}
@@ -202,11 +238,30 @@ func (r *RemoteService) PushToUser(ctx context.Context, push *protos.Push) (*pro
 	return nil, constants.ErrSessionNotFound
 }
 
+// sessionPushData returns the data of the push in the serializer of the
+// session, the sending server encodes it once for each negotiable serializer
+func (r *RemoteService) sessionPushData(s session.Session, push *protos.Push) ([]byte, error) {
+	serializer := sessionSerializer(s, r.serializer)
+	if push.Serializer == "" || serializer == nil || push.Serializer == serializer.GetName() {
+		return push.Data, nil
+	}
+	if data, ok := push.Encoded[serializer.GetName()]; ok {
+		return data, nil
+	}
+	return nil, constants.ErrPushNotEncoded
+}
+
 // KickUser sends a kick to user
 func (r *RemoteService) KickUser(ctx context.Context, kick *protos.KickMsg) (*protos.KickAnswer, error) {
+	atomic.AddInt64(&r.inFlight, 1)
+	defer atomic.AddInt64(&r.inFlight, -1)
+
 	logger.Log.Debugf("sending kick to user %s", kick.GetUserId())
 	s := r.sessionPool.GetSessionByUID(kick.GetUserId())
 	if s != nil {
+		if kick.RelationMsgId != 0 {
+			ctx = pcontext.CtxWithRelationData(ctx, kick.GetUserId(), relation.Data{MsgID: kick.RelationMsgId})
+		}
 		err := s.Kick(ctx)
 		if err != nil {
 			return nil, err
@@ -218,6 +273,12 @@ func (r *RemoteService) KickUser(ctx context.Context, kick *protos.KickMsg) (*pr
 	return nil, constants.ErrSessionNotFound
 }
 
+// InFlight returns the number of rpcs, pushes and kicks received from other
+// servers that are being processed
+func (r *RemoteService) InFlight() int64 {
+	return atomic.LoadInt64(&r.inFlight)
+}
+
 // DoRPC do rpc and get answer
 func (r *RemoteService) DoRPC(ctx context.Context, serverID string, route *route.Route, protoData []byte) (*protos.Response, error) {
 	msg := &message.Message{
@@ -287,6 +348,9 @@ func (r *RemoteService) Register(comp component.Component, opts []component.Opti
 	for name, remote := range s.Remotes {
 		r.remotes[fmt.Sprintf("%s.%s", s.Name, name)] = remote
 	}
+	for name, remote := range s.Streams {
+		r.streams[fmt.Sprintf("%s.%s", s.Name, name)] = remote
+	}
 
 	return nil
 }
@@ -403,13 +467,18 @@ func (r *RemoteService) handleRPCUser(ctx context.Context, req *protos.Request,
 func (r *RemoteService) handleRPCSys(ctx context.Context, req *protos.Request, rt *route.Route) *protos.Response {
 	reply := req.GetMsg().GetReply()
 	response := &protos.Response{}
+	// the client may have negotiated a serializer other than the default one
+	serializer := r.serializer
+	if negotiated := r.serializers.Get(req.GetSession().GetSerializer()); negotiated != nil {
+		serializer = negotiated
+	}
 	// (warning) a new agent is created for every new request
 	a, err := agent.NewRemote(
 		req.GetSession(),
 		reply,
 		r.rpcClient,
 		r.encoder,
-		r.serializer,
+		serializer,
 		r.serviceDiscovery,
 		req.FrontendID,
 		r.messageEncoder,
@@ -426,7 +495,7 @@ func (r *RemoteService) handleRPCSys(ctx context.Context, req *protos.Request, r
 		return response
 	}
 
-	ret, err := r.handlerPool.ProcessHandlerMessage(ctx, rt, r.serializer, r.handlerHooks, a.Session, req.GetMsg().GetId(), req.GetMsg().GetData(), req.GetMsg().GetType(), true)
+	ret, err := r.handlerPool.ProcessHandlerMessage(ctx, rt, serializer, r.handlerHooks, a.Session, req.GetMsg().GetId(), req.GetMsg().GetData(), req.GetMsg().GetType(), true)
 	if err != nil {
 		logger.Log.Warnf(err.Error())
 		response = &protos.Response{
@@ -480,6 +549,9 @@ func (r *RemoteService) DumpServices() {
 	for name := range r.remotes {
 		logger.Log.Infof("registered remote %s", name)
 	}
+	for name := range r.streams {
+		logger.Log.Infof("registered stream remote %s", name)
+	}
 }
 
 // Docs returns documentation for remotes
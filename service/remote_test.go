@@ -39,13 +39,20 @@ import (
 	"github.com/topfreegames/pitaya/v2/conn/message"
 	messagemocks "github.com/topfreegames/pitaya/v2/conn/message/mocks"
 	"github.com/topfreegames/pitaya/v2/constants"
+	pcontext "github.com/topfreegames/pitaya/v2/context"
 	e "github.com/topfreegames/pitaya/v2/errors"
+	"github.com/topfreegames/pitaya/v2/networkentity"
 	"github.com/topfreegames/pitaya/v2/pipeline"
 	"github.com/topfreegames/pitaya/v2/protos"
 	"github.com/topfreegames/pitaya/v2/protos/test"
+	"github.com/topfreegames/pitaya/v2/relation"
 	"github.com/topfreegames/pitaya/v2/route"
 	"github.com/topfreegames/pitaya/v2/router"
+	"github.com/topfreegames/pitaya/v2/serialize"
+	"github.com/topfreegames/pitaya/v2/serialize/json"
 	serializemocks "github.com/topfreegames/pitaya/v2/serialize/mocks"
+	"github.com/topfreegames/pitaya/v2/serialize/msgpack"
+	"github.com/topfreegames/pitaya/v2/serialize/protobuf"
 	"github.com/topfreegames/pitaya/v2/session"
 	sessionmocks "github.com/topfreegames/pitaya/v2/session/mocks"
 )
@@ -68,6 +75,19 @@ func (m *MyComp) RemoteErr(ctx context.Context) (*test.SomeStruct, error) {
 
 type unregisteredStruct struct{}
 
+// pushRecorder is a network entity that keeps the context of the last push
+type pushRecorder struct {
+	networkentity.NetworkEntity
+	ctx  context.Context
+	data interface{}
+}
+
+func (p *pushRecorder) Push(ctx context.Context, route string, v interface{}) error {
+	p.ctx = ctx
+	p.data = v
+	return nil
+}
+
 func TestNewRemoteService(t *testing.T) {
 	packetEncoder := codec.NewPomeloPacketEncoder()
 	ctrl := gomock.NewController(t)
@@ -82,7 +102,7 @@ func TestNewRemoteService(t *testing.T) {
 	sessionPool := session.NewSessionPool()
 	handlerHooks := pipeline.NewHandlerHooks()
 	handlerPool := NewHandlerPool()
-	svc := NewRemoteService(mockRPCClient, mockRPCServer, mockSD, packetEncoder, mockSerializer, router, mockMessageEncoder, sv, sessionPool, handlerHooks, handlerPool)
+	svc := NewRemoteService(mockRPCClient, mockRPCServer, mockSD, packetEncoder, mockSerializer, nil, router, mockMessageEncoder, sv, sessionPool, handlerHooks, handlerPool)
 
 	assert.NotNil(t, svc)
 	assert.Empty(t, svc.services)
@@ -99,7 +119,7 @@ func TestNewRemoteService(t *testing.T) {
 }
 
 func TestRemoteServiceRegister(t *testing.T) {
-	svc := NewRemoteService(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	svc := NewRemoteService(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 	err := svc.Register(&MyComp{}, []component.Option{})
 	assert.NoError(t, err)
 	defer func() { svc.remotes = make(map[string]*component.Remote, 0) }()
@@ -122,7 +142,7 @@ func TestRemoteServiceRegister(t *testing.T) {
 }
 
 func TestRemoteServiceAddRemoteBindingListener(t *testing.T) {
-	svc := NewRemoteService(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	svc := NewRemoteService(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	mockBindingListener := clustermocks.NewMockRemoteBindingListener(ctrl)
@@ -132,7 +152,7 @@ func TestRemoteServiceAddRemoteBindingListener(t *testing.T) {
 }
 
 func TestRemoteServiceSessionBindRemote(t *testing.T) {
-	svc := NewRemoteService(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	svc := NewRemoteService(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	mockBindingListener := clustermocks.NewMockRemoteBindingListener(ctrl)
@@ -184,8 +204,9 @@ func TestRemoteServicePushToUser(t *testing.T) {
 		}, constants.ErrSessionNotFound},
 	}
 
-	mockSession.EXPECT().Push(tables[0].p.Route, tables[0].p.Data).Times(1)
-	svc := NewRemoteService(nil, nil, nil, nil, nil, nil, nil, nil, mockSessionPool, nil, nil)
+	mockSession.EXPECT().GetSerializer().Return(nil).Times(1)
+	mockSession.EXPECT().Push(gomock.Any(), tables[0].p.Route, tables[0].p.Data).Times(1)
+	svc := NewRemoteService(nil, nil, nil, nil, nil, nil, nil, nil, nil, mockSessionPool, nil, nil)
 
 	for _, table := range tables {
 		t.Run(table.name, func(t *testing.T) {
@@ -199,10 +220,74 @@ func TestRemoteServicePushToUser(t *testing.T) {
 	}
 }
 
+func TestRemoteServicePushToUserWithRelation(t *testing.T) {
+	sessionPool := session.NewSessionPool()
+	entity := &pushRecorder{}
+	s := sessionPool.NewSession(entity, true)
+	assert.NoError(t, s.Bind(context.Background(), "uid"))
+	svc := NewRemoteService(nil, nil, nil, nil, nil, nil, nil, nil, nil, sessionPool, nil, nil)
+
+	// gRPC pushes arrive without the relation in the context
+	_, err := svc.PushToUser(context.Background(), &protos.Push{
+		Route:         "sv.svc.mth",
+		Uid:           "uid",
+		Data:          []byte{0x01},
+		RelationMsgId: 7,
+		SessionId:     s.ID(),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, relation.Data{MsgID: 7, SessID: s.ID()}, pcontext.GetRelationDataFromContextByUID(entity.ctx, "uid"))
+
+	_, err = svc.PushToUser(context.Background(), &protos.Push{Route: "sv.svc.mth", Uid: "uid"})
+	assert.NoError(t, err)
+	assert.Equal(t, context.Background(), entity.ctx)
+}
+
+func TestRemoteServicePushToUserWithSerializer(t *testing.T) {
+	sessionPool := session.NewSessionPool()
+	entity := &pushRecorder{}
+	s := sessionPool.NewSession(entity, true)
+	assert.NoError(t, s.Bind(context.Background(), "uid"))
+	serializers := serialize.Serializers{msgpack.NewSerializer(), protobuf.NewSerializer()}
+	svc := NewRemoteService(nil, nil, nil, nil, json.NewSerializer(), serializers, nil, nil, nil, sessionPool, nil, nil)
+
+	msgpackData, err := msgpack.NewSerializer().Marshal(map[string]int{"a": 1})
+	assert.NoError(t, err)
+	push := &protos.Push{
+		Route:      "sv.svc.mth",
+		Uid:        "uid",
+		Data:       []byte(`{"a":1}`),
+		Serializer: "json",
+		Encoded:    map[string][]byte{"msgpack": msgpackData},
+	}
+	_, err = svc.PushToUser(context.Background(), push)
+	assert.NoError(t, err)
+	assert.Equal(t, push.Data, entity.data)
+
+	s.SetSerializer(msgpack.NewSerializer())
+	_, err = svc.PushToUser(context.Background(), push)
+	assert.NoError(t, err)
+	assert.Equal(t, msgpackData, entity.data)
+
+	// raw pushes are sent as they are
+	entity.data = nil
+	raw := &protos.Push{Route: "sv.svc.mth", Uid: "uid", Data: []byte("raw")}
+	_, err = svc.PushToUser(context.Background(), raw)
+	assert.NoError(t, err)
+	assert.Equal(t, raw.Data, entity.data)
+
+	// the sending server couldn't encode the push with protobuf
+	entity.data = nil
+	s.SetSerializer(protobuf.NewSerializer())
+	_, err = svc.PushToUser(context.Background(), push)
+	assert.Equal(t, constants.ErrPushNotEncoded, err)
+	assert.Nil(t, entity.data)
+}
+
 func TestRemoteServiceKickUser(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	mockSessionPool := sessionmocks.NewMockSessionPool(ctrl)
-	svc := NewRemoteService(nil, nil, nil, nil, nil, nil, nil, nil, mockSessionPool, nil, nil)
+	svc := NewRemoteService(nil, nil, nil, nil, nil, nil, nil, nil, nil, mockSessionPool, nil, nil)
 
 	existingUID := "uid1"
 	nonexistingUID := "uid2"
@@ -244,7 +329,7 @@ func TestRemoteServiceKickUser(t *testing.T) {
 }
 
 func TestRemoteServiceRegisterFailsIfRegisterTwice(t *testing.T) {
-	svc := NewRemoteService(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	svc := NewRemoteService(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 	err := svc.Register(&MyComp{}, []component.Option{})
 	assert.NoError(t, err)
 	err = svc.Register(&MyComp{}, []component.Option{})
@@ -252,7 +337,7 @@ func TestRemoteServiceRegisterFailsIfRegisterTwice(t *testing.T) {
 }
 
 func TestRemoteServiceRegisterFailsIfNoRemoteMethods(t *testing.T) {
-	svc := NewRemoteService(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	svc := NewRemoteService(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 	err := svc.Register(&NoHandlerRemoteComp{}, []component.Option{})
 	assert.Equal(t, errors.New("type NoHandlerRemoteComp has no exported methods of remote type"), err)
 }
@@ -279,7 +364,7 @@ func TestRemoteServiceRemoteCall(t *testing.T) {
 			mockRPCClient := clustermocks.NewMockRPCClient(ctrl)
 			sessionPool := sessionmocks.NewMockSessionPool(ctrl)
 			router := router.New()
-			svc := NewRemoteService(mockRPCClient, nil, nil, nil, nil, router, nil, nil, sessionPool, pipeline.NewHandlerHooks(), nil)
+			svc := NewRemoteService(mockRPCClient, nil, nil, nil, nil, nil, router, nil, nil, sessionPool, pipeline.NewHandlerHooks(), nil)
 			assert.NotNil(t, svc)
 
 			msg := &message.Message{}
@@ -349,7 +434,7 @@ func TestRemoteServiceHandleRPCUser(t *testing.T) {
 			messageEncoder := message.NewMessagesEncoder(false)
 			router := router.New()
 			sessionPool := session.NewSessionPool()
-			svc := NewRemoteService(mockRPCClient, mockRPCServer, mockSD, packetEncoder, mockSerializer, router, messageEncoder, &cluster.Server{}, sessionPool, pipeline.NewHandlerHooks(), handlerPool)
+			svc := NewRemoteService(mockRPCClient, mockRPCServer, mockSD, packetEncoder, mockSerializer, nil, router, messageEncoder, &cluster.Server{}, sessionPool, pipeline.NewHandlerHooks(), handlerPool)
 
 			svc.remotes[rt.Short()] = comp
 			svc.remotes[rtErr.Short()] = compErr
@@ -403,7 +488,7 @@ func TestRemoteServiceHandleRPCSys(t *testing.T) {
 			sessionPool := session.NewSessionPool()
 			handlerPool := NewHandlerPool()
 			handlerPool.handlers[rt.Short()] = &component.Handler{Receiver: reflect.ValueOf(tObj), Method: m, Type: m.Type.In(2)}
-			svc := NewRemoteService(mockRPCClient, mockRPCServer, mockSD, packetEncoder, mockSerializer, router, messageEncoder, &cluster.Server{}, sessionPool, pipeline.NewHandlerHooks(), handlerPool)
+			svc := NewRemoteService(mockRPCClient, mockRPCServer, mockSD, packetEncoder, mockSerializer, nil, router, messageEncoder, &cluster.Server{}, sessionPool, pipeline.NewHandlerHooks(), handlerPool)
 			assert.NotNil(t, svc)
 
 			if table.errSubstring == "" {
@@ -475,7 +560,7 @@ func TestRemoteServiceRemoteProcess(t *testing.T) {
 				mockAgent.EXPECT().AnswerWithError(ctx, expectedMsg.ID, table.responseMIDErr)
 			}
 
-			svc := NewRemoteService(mockRPCClient, mockRPCServer, mockSD, packetEncoder, mockSerializer, router, messageEncoder, &cluster.Server{}, sessionPool, pipeline.NewHandlerHooks(), nil)
+			svc := NewRemoteService(mockRPCClient, mockRPCServer, mockSD, packetEncoder, mockSerializer, nil, router, messageEncoder, &cluster.Server{}, sessionPool, pipeline.NewHandlerHooks(), nil)
 			svc.remoteProcess(ctx, sv, mockAgent, rt, expectedMsg)
 		})
 	}
@@ -509,7 +594,7 @@ func TestRemoteServiceRPC(t *testing.T) {
 			messageEncoder := message.NewMessagesEncoder(false)
 			router := router.New()
 			sessionPool := session.NewSessionPool()
-			svc := NewRemoteService(mockRPCClient, mockRPCServer, mockSD, packetEncoder, mockSerializer, router, messageEncoder, &cluster.Server{}, sessionPool, pipeline.NewHandlerHooks(), nil)
+			svc := NewRemoteService(mockRPCClient, mockRPCServer, mockSD, packetEncoder, mockSerializer, nil, router, messageEncoder, &cluster.Server{}, sessionPool, pipeline.NewHandlerHooks(), nil)
 			assert.NotNil(t, svc)
 
 			if table.serverID != "" {
@@ -0,0 +1,208 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/topfreegames/pitaya/v2/cluster"
+	"github.com/topfreegames/pitaya/v2/component"
+	"github.com/topfreegames/pitaya/v2/conn/message"
+	"github.com/topfreegames/pitaya/v2/constants"
+	pcontext "github.com/topfreegames/pitaya/v2/context"
+	e "github.com/topfreegames/pitaya/v2/errors"
+	"github.com/topfreegames/pitaya/v2/logger"
+	"github.com/topfreegames/pitaya/v2/protos"
+	"github.com/topfreegames/pitaya/v2/route"
+	"github.com/topfreegames/pitaya/v2/tracing"
+	"github.com/topfreegames/pitaya/v2/util"
+)
+
+// protoStream exchanges proto messages over a stream of raw messages
+type protoStream struct {
+	ctx    context.Context
+	stream cluster.Stream
+}
+
+func (s *protoStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *protoStream) Send(msg proto.Message) error {
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return s.stream.Send(data)
+}
+
+func (s *protoStream) Recv(msg proto.Message) error {
+	data, err := s.stream.Recv()
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(data, msg)
+}
+
+type protoClientStream struct {
+	protoStream
+	client cluster.ClientStream
+}
+
+func (s *protoClientStream) CloseSend() error {
+	return s.client.CloseSend()
+}
+
+// Stream processes a stream opened by another server
+func (r *RemoteService) Stream(ctx context.Context, req *protos.Request, stream cluster.Stream) *protos.Response {
+	atomic.AddInt64(&r.inFlight, 1)
+	defer atomic.AddInt64(&r.inFlight, -1)
+
+	c, err := util.GetContextFromRequest(req, r.server.ID)
+	c = util.StartSpanFromRequest(c, r.server.ID, req.GetMsg().GetRoute())
+	var res *protos.Response
+	if err != nil {
+		res = &protos.Response{
+			Error: &protos.Error{
+				Code: e.ErrInternalCode,
+				Msg:  err.Error(),
+			},
+		}
+	} else {
+		// the deadline and cancelation applied by the rpc server
+		var cancel context.CancelFunc
+		c, cancel = pcontext.WithDeadlineFrom(c, ctx)
+		defer cancel()
+		res = r.handleStream(c, req, stream)
+	}
+
+	if res.Error != nil {
+		err = errors.New(res.Error.Msg)
+	}
+	tracing.FinishSpan(c, err)
+	return res
+}
+
+func (r *RemoteService) handleStream(ctx context.Context, req *protos.Request, stream cluster.Stream) *protos.Response {
+	rt, err := route.Decode(req.GetMsg().GetRoute())
+	if err != nil {
+		return &protos.Response{
+			Error: &protos.Error{
+				Code: e.ErrBadRequestCode,
+				Msg:  "cannot decode route",
+				Metadata: map[string]string{
+					"route": req.GetMsg().GetRoute(),
+				},
+			},
+		}
+	}
+
+	remote, ok := r.streams[rt.Short()]
+	if !ok {
+		logger.Log.Warnf("pitaya/remote: stream %s not found", rt.Short())
+		return &protos.Response{
+			Error: &protos.Error{
+				Code: e.ErrNotFoundCode,
+				Msg:  "route not found",
+				Metadata: map[string]string{
+					"route": rt.Short(),
+				},
+			},
+		}
+	}
+
+	params := []reflect.Value{remote.Receiver, reflect.ValueOf(ctx)}
+	if remote.HasArgs {
+		arg, err := unmarshalRemoteArg(remote, req.GetMsg().GetData())
+		if err != nil {
+			return &protos.Response{
+				Error: &protos.Error{
+					Code: e.ErrBadRequestCode,
+					Msg:  err.Error(),
+				},
+			}
+		}
+		params = append(params, reflect.ValueOf(arg))
+	}
+	params = append(params, reflect.ValueOf(&protoStream{ctx: ctx, stream: stream}))
+
+	if _, err := util.Pcall(remote.Method, params); err != nil {
+		response := &protos.Response{
+			Error: &protos.Error{
+				Code: e.ErrUnknownCode,
+				Msg:  err.Error(),
+			},
+		}
+		if val, ok := err.(*e.Error); ok {
+			response.Error.Code = val.Code
+			if val.Metadata != nil {
+				response.Error.Metadata = val.Metadata
+			}
+		}
+		return response
+	}
+	return &protos.Response{}
+}
+
+// RPCStream opens a stream with a stream remote, if serverID is empty the
+// server is chosen by the router
+func (r *RemoteService) RPCStream(ctx context.Context, serverID string, route *route.Route, arg proto.Message) (component.ClientStream, error) {
+	var data []byte
+	var err error
+	if arg != nil {
+		data, err = proto.Marshal(arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+	msg := &message.Message{
+		Type:  message.Request,
+		Route: route.Short(),
+		Data:  data,
+	}
+
+	var target *cluster.Server
+	if serverID == "" {
+		target, err = r.router.Route(ctx, protos.RPCType_User, route.SvType, route, msg)
+		if err != nil {
+			return nil, e.NewError(err, e.ErrInternalCode)
+		}
+	} else {
+		target, _ = r.serviceDiscovery.GetServer(serverID)
+		if target == nil {
+			return nil, constants.ErrServerNotFound
+		}
+	}
+
+	stream, err := r.rpcClient.Stream(ctx, route, msg, target)
+	if err != nil {
+		logger.Log.Errorf("error opening stream with target with id %s, route %s and host %s: %s", target.ID, route.String(), target.Hostname, err.Error())
+		return nil, err
+	}
+	return &protoClientStream{
+		protoStream: protoStream{ctx: stream.Context(), stream: stream},
+		client:      stream,
+	}, nil
+}
@@ -42,6 +42,15 @@ import (
 
 var errInvalidMsg = errors.New("invalid message type provided")
 
+// sessionSerializer returns the serializer negotiated by the client of the
+// session, or the default serializer if it didn't negotiate one
+func sessionSerializer(s session.Session, serializer serialize.Serializer) serialize.Serializer {
+	if negotiated := s.GetSerializer(); negotiated != nil {
+		return negotiated
+	}
+	return serializer
+}
+
 func unmarshalHandlerArg(handler *component.Handler, serializer serialize.Serializer, payload []byte) (interface{}, error) {
 	if handler.IsRawArg {
 		return payload, nil
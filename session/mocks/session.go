@@ -6,38 +6,40 @@ package mocks
 
 import (
 	context "context"
+	net "net"
+	reflect "reflect"
+
 	gomock "github.com/golang/mock/gomock"
 	nats "github.com/nats-io/nats.go"
 	networkentity "github.com/topfreegames/pitaya/v2/networkentity"
+	serialize "github.com/topfreegames/pitaya/v2/serialize"
 	session "github.com/topfreegames/pitaya/v2/session"
-	net "net"
-	reflect "reflect"
 )
 
-// MockSession is a mock of Session interface
+// MockSession is a mock of Session interface.
 type MockSession struct {
 	ctrl     *gomock.Controller
 	recorder *MockSessionMockRecorder
 }
 
-// MockSessionMockRecorder is the mock recorder for MockSession
+// MockSessionMockRecorder is the mock recorder for MockSession.
 type MockSessionMockRecorder struct {
 	mock *MockSession
 }
 
-// NewMockSession creates a new mock instance
+// NewMockSession creates a new mock instance.
 func NewMockSession(ctrl *gomock.Controller) *MockSession {
 	mock := &MockSession{ctrl: ctrl}
 	mock.recorder = &MockSessionMockRecorder{mock}
 	return mock
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use
+// EXPECT returns an object that allows the caller to indicate expected use.
 func (m *MockSession) EXPECT() *MockSessionMockRecorder {
 	return m.recorder
 }
 
-// Bind mocks base method
+// Bind mocks base method.
 func (m *MockSession) Bind(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Bind", arg0, arg1)
@@ -45,37 +47,37 @@ func (m *MockSession) Bind(arg0 context.Context, arg1 string) error {
 	return ret0
 }
 
-// Bind indicates an expected call of Bind
+// Bind indicates an expected call of Bind.
 func (mr *MockSessionMockRecorder) Bind(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Bind", reflect.TypeOf((*MockSession)(nil).Bind), arg0, arg1)
 }
 
-// Clear mocks base method
+// Clear mocks base method.
 func (m *MockSession) Clear() {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "Clear")
 }
 
-// Clear indicates an expected call of Clear
+// Clear indicates an expected call of Clear.
 func (mr *MockSessionMockRecorder) Clear() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clear", reflect.TypeOf((*MockSession)(nil).Clear))
 }
 
-// Close mocks base method
+// Close mocks base method.
 func (m *MockSession) Close() {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "Close")
 }
 
-// Close indicates an expected call of Close
+// Close indicates an expected call of Close.
 func (mr *MockSessionMockRecorder) Close() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockSession)(nil).Close))
 }
 
-// Float32 mocks base method
+// Float32 mocks base method.
 func (m *MockSession) Float32(arg0 string) float32 {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Float32", arg0)
@@ -83,13 +85,13 @@ func (m *MockSession) Float32(arg0 string) float32 {
 	return ret0
 }
 
-// Float32 indicates an expected call of Float32
+// Float32 indicates an expected call of Float32.
 func (mr *MockSessionMockRecorder) Float32(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Float32", reflect.TypeOf((*MockSession)(nil).Float32), arg0)
 }
 
-// Float64 mocks base method
+// Float64 mocks base method.
 func (m *MockSession) Float64(arg0 string) float64 {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Float64", arg0)
@@ -97,13 +99,13 @@ func (m *MockSession) Float64(arg0 string) float64 {
 	return ret0
 }
 
-// Float64 indicates an expected call of Float64
+// Float64 indicates an expected call of Float64.
 func (mr *MockSessionMockRecorder) Float64(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Float64", reflect.TypeOf((*MockSession)(nil).Float64), arg0)
 }
 
-// Get mocks base method
+// Get mocks base method.
 func (m *MockSession) Get(arg0 string) interface{} {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Get", arg0)
@@ -111,13 +113,13 @@ func (m *MockSession) Get(arg0 string) interface{} {
 	return ret0
 }
 
-// Get indicates an expected call of Get
+// Get indicates an expected call of Get.
 func (mr *MockSessionMockRecorder) Get(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockSession)(nil).Get), arg0)
 }
 
-// GetData mocks base method
+// GetData mocks base method.
 func (m *MockSession) GetData() map[string]interface{} {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetData")
@@ -125,13 +127,13 @@ func (m *MockSession) GetData() map[string]interface{} {
 	return ret0
 }
 
-// GetData indicates an expected call of GetData
+// GetData indicates an expected call of GetData.
 func (mr *MockSessionMockRecorder) GetData() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetData", reflect.TypeOf((*MockSession)(nil).GetData))
 }
 
-// GetDataEncoded mocks base method
+// GetDataEncoded mocks base method.
 func (m *MockSession) GetDataEncoded() []byte {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetDataEncoded")
@@ -139,13 +141,27 @@ func (m *MockSession) GetDataEncoded() []byte {
 	return ret0
 }
 
-// GetDataEncoded indicates an expected call of GetDataEncoded
+// GetDataEncoded indicates an expected call of GetDataEncoded.
 func (mr *MockSessionMockRecorder) GetDataEncoded() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDataEncoded", reflect.TypeOf((*MockSession)(nil).GetDataEncoded))
 }
 
-// GetHandshakeData mocks base method
+// GetEntity mocks base method.
+func (m *MockSession) GetEntity() networkentity.NetworkEntity {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntity")
+	ret0, _ := ret[0].(networkentity.NetworkEntity)
+	return ret0
+}
+
+// GetEntity indicates an expected call of GetEntity.
+func (mr *MockSessionMockRecorder) GetEntity() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntity", reflect.TypeOf((*MockSession)(nil).GetEntity))
+}
+
+// GetHandshakeData mocks base method.
 func (m *MockSession) GetHandshakeData() *session.HandshakeData {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetHandshakeData")
@@ -153,13 +169,13 @@ func (m *MockSession) GetHandshakeData() *session.HandshakeData {
 	return ret0
 }
 
-// GetHandshakeData indicates an expected call of GetHandshakeData
+// GetHandshakeData indicates an expected call of GetHandshakeData.
 func (mr *MockSessionMockRecorder) GetHandshakeData() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHandshakeData", reflect.TypeOf((*MockSession)(nil).GetHandshakeData))
 }
 
-// GetIsFrontend mocks base method
+// GetIsFrontend mocks base method.
 func (m *MockSession) GetIsFrontend() bool {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetIsFrontend")
@@ -167,13 +183,13 @@ func (m *MockSession) GetIsFrontend() bool {
 	return ret0
 }
 
-// GetIsFrontend indicates an expected call of GetIsFrontend
+// GetIsFrontend indicates an expected call of GetIsFrontend.
 func (mr *MockSessionMockRecorder) GetIsFrontend() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIsFrontend", reflect.TypeOf((*MockSession)(nil).GetIsFrontend))
 }
 
-// GetOnCloseCallbacks mocks base method
+// GetOnCloseCallbacks mocks base method.
 func (m *MockSession) GetOnCloseCallbacks() []func() {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetOnCloseCallbacks")
@@ -181,13 +197,27 @@ func (m *MockSession) GetOnCloseCallbacks() []func() {
 	return ret0
 }
 
-// GetOnCloseCallbacks indicates an expected call of GetOnCloseCallbacks
+// GetOnCloseCallbacks indicates an expected call of GetOnCloseCallbacks.
 func (mr *MockSessionMockRecorder) GetOnCloseCallbacks() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOnCloseCallbacks", reflect.TypeOf((*MockSession)(nil).GetOnCloseCallbacks))
 }
 
-// GetSubscriptions mocks base method
+// GetSerializer mocks base method.
+func (m *MockSession) GetSerializer() serialize.Serializer {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSerializer")
+	ret0, _ := ret[0].(serialize.Serializer)
+	return ret0
+}
+
+// GetSerializer indicates an expected call of GetSerializer.
+func (mr *MockSessionMockRecorder) GetSerializer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSerializer", reflect.TypeOf((*MockSession)(nil).GetSerializer))
+}
+
+// GetSubscriptions mocks base method.
 func (m *MockSession) GetSubscriptions() []*nats.Subscription {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetSubscriptions")
@@ -195,13 +225,13 @@ func (m *MockSession) GetSubscriptions() []*nats.Subscription {
 	return ret0
 }
 
-// GetSubscriptions indicates an expected call of GetSubscriptions
+// GetSubscriptions indicates an expected call of GetSubscriptions.
 func (mr *MockSessionMockRecorder) GetSubscriptions() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptions", reflect.TypeOf((*MockSession)(nil).GetSubscriptions))
 }
 
-// HasKey mocks base method
+// HasKey mocks base method.
 func (m *MockSession) HasKey(arg0 string) bool {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "HasKey", arg0)
@@ -209,13 +239,13 @@ func (m *MockSession) HasKey(arg0 string) bool {
 	return ret0
 }
 
-// HasKey indicates an expected call of HasKey
+// HasKey indicates an expected call of HasKey.
 func (mr *MockSessionMockRecorder) HasKey(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasKey", reflect.TypeOf((*MockSession)(nil).HasKey), arg0)
 }
 
-// ID mocks base method
+// ID mocks base method.
 func (m *MockSession) ID() int64 {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ID")
@@ -223,13 +253,13 @@ func (m *MockSession) ID() int64 {
 	return ret0
 }
 
-// ID indicates an expected call of ID
+// ID indicates an expected call of ID.
 func (mr *MockSessionMockRecorder) ID() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ID", reflect.TypeOf((*MockSession)(nil).ID))
 }
 
-// Int mocks base method
+// Int mocks base method.
 func (m *MockSession) Int(arg0 string) int {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Int", arg0)
@@ -237,13 +267,13 @@ func (m *MockSession) Int(arg0 string) int {
 	return ret0
 }
 
-// Int indicates an expected call of Int
+// Int indicates an expected call of Int.
 func (mr *MockSessionMockRecorder) Int(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Int", reflect.TypeOf((*MockSession)(nil).Int), arg0)
 }
 
-// Int16 mocks base method
+// Int16 mocks base method.
 func (m *MockSession) Int16(arg0 string) int16 {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Int16", arg0)
@@ -251,13 +281,13 @@ func (m *MockSession) Int16(arg0 string) int16 {
 	return ret0
 }
 
-// Int16 indicates an expected call of Int16
+// Int16 indicates an expected call of Int16.
 func (mr *MockSessionMockRecorder) Int16(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Int16", reflect.TypeOf((*MockSession)(nil).Int16), arg0)
 }
 
-// Int32 mocks base method
+// Int32 mocks base method.
 func (m *MockSession) Int32(arg0 string) int32 {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Int32", arg0)
@@ -265,13 +295,13 @@ func (m *MockSession) Int32(arg0 string) int32 {
 	return ret0
 }
 
-// Int32 indicates an expected call of Int32
+// Int32 indicates an expected call of Int32.
 func (mr *MockSessionMockRecorder) Int32(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Int32", reflect.TypeOf((*MockSession)(nil).Int32), arg0)
 }
 
-// Int64 mocks base method
+// Int64 mocks base method.
 func (m *MockSession) Int64(arg0 string) int64 {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Int64", arg0)
@@ -279,13 +309,13 @@ func (m *MockSession) Int64(arg0 string) int64 {
 	return ret0
 }
 
-// Int64 indicates an expected call of Int64
+// Int64 indicates an expected call of Int64.
 func (mr *MockSessionMockRecorder) Int64(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Int64", reflect.TypeOf((*MockSession)(nil).Int64), arg0)
 }
 
-// Int8 mocks base method
+// Int8 mocks base method.
 func (m *MockSession) Int8(arg0 string) int8 {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Int8", arg0)
@@ -293,13 +323,13 @@ func (m *MockSession) Int8(arg0 string) int8 {
 	return ret0
 }
 
-// Int8 indicates an expected call of Int8
+// Int8 indicates an expected call of Int8.
 func (mr *MockSessionMockRecorder) Int8(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Int8", reflect.TypeOf((*MockSession)(nil).Int8), arg0)
 }
 
-// Kick mocks base method
+// Kick mocks base method.
 func (m *MockSession) Kick(arg0 context.Context) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Kick", arg0)
@@ -307,13 +337,13 @@ func (m *MockSession) Kick(arg0 context.Context) error {
 	return ret0
 }
 
-// Kick indicates an expected call of Kick
+// Kick indicates an expected call of Kick.
 func (mr *MockSessionMockRecorder) Kick(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Kick", reflect.TypeOf((*MockSession)(nil).Kick), arg0)
 }
 
-// OnClose mocks base method
+// OnClose mocks base method.
 func (m *MockSession) OnClose(arg0 func()) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "OnClose", arg0)
@@ -321,27 +351,27 @@ func (m *MockSession) OnClose(arg0 func()) error {
 	return ret0
 }
 
-// OnClose indicates an expected call of OnClose
+// OnClose indicates an expected call of OnClose.
 func (mr *MockSessionMockRecorder) OnClose(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnClose", reflect.TypeOf((*MockSession)(nil).OnClose), arg0)
 }
 
-// Push mocks base method
-func (m *MockSession) Push(arg0 string, arg1 interface{}) error {
+// Push mocks base method.
+func (m *MockSession) Push(arg0 context.Context, arg1 string, arg2 interface{}) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Push", arg0, arg1)
+	ret := m.ctrl.Call(m, "Push", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Push indicates an expected call of Push
-func (mr *MockSessionMockRecorder) Push(arg0, arg1 interface{}) *gomock.Call {
+// Push indicates an expected call of Push.
+func (mr *MockSessionMockRecorder) Push(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Push", reflect.TypeOf((*MockSession)(nil).Push), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Push", reflect.TypeOf((*MockSession)(nil).Push), arg0, arg1, arg2)
 }
 
-// PushToFront mocks base method
+// PushToFront mocks base method.
 func (m *MockSession) PushToFront(arg0 context.Context) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "PushToFront", arg0)
@@ -349,13 +379,13 @@ func (m *MockSession) PushToFront(arg0 context.Context) error {
 	return ret0
 }
 
-// PushToFront indicates an expected call of PushToFront
+// PushToFront indicates an expected call of PushToFront.
 func (mr *MockSessionMockRecorder) PushToFront(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PushToFront", reflect.TypeOf((*MockSession)(nil).PushToFront), arg0)
 }
 
-// RemoteAddr mocks base method
+// RemoteAddr mocks base method.
 func (m *MockSession) RemoteAddr() net.Addr {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "RemoteAddr")
@@ -363,13 +393,13 @@ func (m *MockSession) RemoteAddr() net.Addr {
 	return ret0
 }
 
-// RemoteAddr indicates an expected call of RemoteAddr
+// RemoteAddr indicates an expected call of RemoteAddr.
 func (mr *MockSessionMockRecorder) RemoteAddr() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoteAddr", reflect.TypeOf((*MockSession)(nil).RemoteAddr))
 }
 
-// Remove mocks base method
+// Remove mocks base method.
 func (m *MockSession) Remove(arg0 string) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Remove", arg0)
@@ -377,13 +407,13 @@ func (m *MockSession) Remove(arg0 string) error {
 	return ret0
 }
 
-// Remove indicates an expected call of Remove
+// Remove indicates an expected call of Remove.
 func (mr *MockSessionMockRecorder) Remove(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockSession)(nil).Remove), arg0)
 }
 
-// ResponseMID mocks base method
+// ResponseMID mocks base method.
 func (m *MockSession) ResponseMID(arg0 context.Context, arg1 uint, arg2 interface{}, arg3 ...bool) error {
 	m.ctrl.T.Helper()
 	varargs := []interface{}{arg0, arg1, arg2}
@@ -395,14 +425,14 @@ func (m *MockSession) ResponseMID(arg0 context.Context, arg1 uint, arg2 interfac
 	return ret0
 }
 
-// ResponseMID indicates an expected call of ResponseMID
+// ResponseMID indicates an expected call of ResponseMID.
 func (mr *MockSessionMockRecorder) ResponseMID(arg0, arg1, arg2 interface{}, arg3 ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	varargs := append([]interface{}{arg0, arg1, arg2}, arg3...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResponseMID", reflect.TypeOf((*MockSession)(nil).ResponseMID), varargs...)
 }
 
-// Set mocks base method
+// Set mocks base method.
 func (m *MockSession) Set(arg0 string, arg1 interface{}) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Set", arg0, arg1)
@@ -410,13 +440,13 @@ func (m *MockSession) Set(arg0 string, arg1 interface{}) error {
 	return ret0
 }
 
-// Set indicates an expected call of Set
+// Set indicates an expected call of Set.
 func (mr *MockSessionMockRecorder) Set(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockSession)(nil).Set), arg0, arg1)
 }
 
-// SetData mocks base method
+// SetData mocks base method.
 func (m *MockSession) SetData(arg0 map[string]interface{}) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SetData", arg0)
@@ -424,13 +454,13 @@ func (m *MockSession) SetData(arg0 map[string]interface{}) error {
 	return ret0
 }
 
-// SetData indicates an expected call of SetData
+// SetData indicates an expected call of SetData.
 func (mr *MockSessionMockRecorder) SetData(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetData", reflect.TypeOf((*MockSession)(nil).SetData), arg0)
 }
 
-// SetDataEncoded mocks base method
+// SetDataEncoded mocks base method.
 func (m *MockSession) SetDataEncoded(arg0 []byte) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SetDataEncoded", arg0)
@@ -438,73 +468,97 @@ func (m *MockSession) SetDataEncoded(arg0 []byte) error {
 	return ret0
 }
 
-// SetDataEncoded indicates an expected call of SetDataEncoded
+// SetDataEncoded indicates an expected call of SetDataEncoded.
 func (mr *MockSessionMockRecorder) SetDataEncoded(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDataEncoded", reflect.TypeOf((*MockSession)(nil).SetDataEncoded), arg0)
 }
 
-// SetFrontendData mocks base method
+// SetEntity mocks base method.
+func (m *MockSession) SetEntity(arg0 networkentity.NetworkEntity) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetEntity", arg0)
+}
+
+// SetEntity indicates an expected call of SetEntity.
+func (mr *MockSessionMockRecorder) SetEntity(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEntity", reflect.TypeOf((*MockSession)(nil).SetEntity), arg0)
+}
+
+// SetFrontendData mocks base method.
 func (m *MockSession) SetFrontendData(arg0 string, arg1 int64) {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "SetFrontendData", arg0, arg1)
 }
 
-// SetFrontendData indicates an expected call of SetFrontendData
+// SetFrontendData indicates an expected call of SetFrontendData.
 func (mr *MockSessionMockRecorder) SetFrontendData(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFrontendData", reflect.TypeOf((*MockSession)(nil).SetFrontendData), arg0, arg1)
 }
 
-// SetHandshakeData mocks base method
+// SetHandshakeData mocks base method.
 func (m *MockSession) SetHandshakeData(arg0 *session.HandshakeData) {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "SetHandshakeData", arg0)
 }
 
-// SetHandshakeData indicates an expected call of SetHandshakeData
+// SetHandshakeData indicates an expected call of SetHandshakeData.
 func (mr *MockSessionMockRecorder) SetHandshakeData(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHandshakeData", reflect.TypeOf((*MockSession)(nil).SetHandshakeData), arg0)
 }
 
-// SetIsFrontend mocks base method
+// SetIsFrontend mocks base method.
 func (m *MockSession) SetIsFrontend(arg0 bool) {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "SetIsFrontend", arg0)
 }
 
-// SetIsFrontend indicates an expected call of SetIsFrontend
+// SetIsFrontend indicates an expected call of SetIsFrontend.
 func (mr *MockSessionMockRecorder) SetIsFrontend(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetIsFrontend", reflect.TypeOf((*MockSession)(nil).SetIsFrontend), arg0)
 }
 
-// SetOnCloseCallbacks mocks base method
+// SetOnCloseCallbacks mocks base method.
 func (m *MockSession) SetOnCloseCallbacks(arg0 []func()) {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "SetOnCloseCallbacks", arg0)
 }
 
-// SetOnCloseCallbacks indicates an expected call of SetOnCloseCallbacks
+// SetOnCloseCallbacks indicates an expected call of SetOnCloseCallbacks.
 func (mr *MockSessionMockRecorder) SetOnCloseCallbacks(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOnCloseCallbacks", reflect.TypeOf((*MockSession)(nil).SetOnCloseCallbacks), arg0)
 }
 
-// SetSubscriptions mocks base method
+// SetSerializer mocks base method.
+func (m *MockSession) SetSerializer(arg0 serialize.Serializer) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetSerializer", arg0)
+}
+
+// SetSerializer indicates an expected call of SetSerializer.
+func (mr *MockSessionMockRecorder) SetSerializer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSerializer", reflect.TypeOf((*MockSession)(nil).SetSerializer), arg0)
+}
+
+// SetSubscriptions mocks base method.
 func (m *MockSession) SetSubscriptions(arg0 []*nats.Subscription) {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "SetSubscriptions", arg0)
 }
 
-// SetSubscriptions indicates an expected call of SetSubscriptions
+// SetSubscriptions indicates an expected call of SetSubscriptions.
 func (mr *MockSessionMockRecorder) SetSubscriptions(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSubscriptions", reflect.TypeOf((*MockSession)(nil).SetSubscriptions), arg0)
 }
 
-// String mocks base method
+// String mocks base method.
 func (m *MockSession) String(arg0 string) string {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "String", arg0)
@@ -512,13 +566,13 @@ func (m *MockSession) String(arg0 string) string {
 	return ret0
 }
 
-// String indicates an expected call of String
+// String indicates an expected call of String.
 func (mr *MockSessionMockRecorder) String(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "String", reflect.TypeOf((*MockSession)(nil).String), arg0)
 }
 
-// UID mocks base method
+// UID mocks base method.
 func (m *MockSession) UID() string {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UID")
@@ -526,13 +580,13 @@ func (m *MockSession) UID() string {
 	return ret0
 }
 
-// UID indicates an expected call of UID
+// UID indicates an expected call of UID.
 func (mr *MockSessionMockRecorder) UID() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UID", reflect.TypeOf((*MockSession)(nil).UID))
 }
 
-// Uint mocks base method
+// Uint mocks base method.
 func (m *MockSession) Uint(arg0 string) uint {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Uint", arg0)
@@ -540,13 +594,13 @@ func (m *MockSession) Uint(arg0 string) uint {
 	return ret0
 }
 
-// Uint indicates an expected call of Uint
+// Uint indicates an expected call of Uint.
 func (mr *MockSessionMockRecorder) Uint(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Uint", reflect.TypeOf((*MockSession)(nil).Uint), arg0)
 }
 
-// Uint16 mocks base method
+// Uint16 mocks base method.
 func (m *MockSession) Uint16(arg0 string) uint16 {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Uint16", arg0)
@@ -554,13 +608,13 @@ func (m *MockSession) Uint16(arg0 string) uint16 {
 	return ret0
 }
 
-// Uint16 indicates an expected call of Uint16
+// Uint16 indicates an expected call of Uint16.
 func (mr *MockSessionMockRecorder) Uint16(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Uint16", reflect.TypeOf((*MockSession)(nil).Uint16), arg0)
 }
 
-// Uint32 mocks base method
+// Uint32 mocks base method.
 func (m *MockSession) Uint32(arg0 string) uint32 {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Uint32", arg0)
@@ -568,13 +622,13 @@ func (m *MockSession) Uint32(arg0 string) uint32 {
 	return ret0
 }
 
-// Uint32 indicates an expected call of Uint32
+// Uint32 indicates an expected call of Uint32.
 func (mr *MockSessionMockRecorder) Uint32(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Uint32", reflect.TypeOf((*MockSession)(nil).Uint32), arg0)
 }
 
-// Uint64 mocks base method
+// Uint64 mocks base method.
 func (m *MockSession) Uint64(arg0 string) uint64 {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Uint64", arg0)
@@ -582,13 +636,13 @@ func (m *MockSession) Uint64(arg0 string) uint64 {
 	return ret0
 }
 
-// Uint64 indicates an expected call of Uint64
+// Uint64 indicates an expected call of Uint64.
 func (mr *MockSessionMockRecorder) Uint64(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Uint64", reflect.TypeOf((*MockSession)(nil).Uint64), arg0)
 }
 
-// Uint8 mocks base method
+// Uint8 mocks base method.
 func (m *MockSession) Uint8(arg0 string) byte {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Uint8", arg0)
@@ -596,13 +650,13 @@ func (m *MockSession) Uint8(arg0 string) byte {
 	return ret0
 }
 
-// Uint8 indicates an expected call of Uint8
+// Uint8 indicates an expected call of Uint8.
 func (mr *MockSessionMockRecorder) Uint8(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Uint8", reflect.TypeOf((*MockSession)(nil).Uint8), arg0)
 }
 
-// Value mocks base method
+// Value mocks base method.
 func (m *MockSession) Value(arg0 string) interface{} {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Value", arg0)
@@ -610,48 +664,72 @@ func (m *MockSession) Value(arg0 string) interface{} {
 	return ret0
 }
 
-// Value indicates an expected call of Value
+// Value indicates an expected call of Value.
 func (mr *MockSessionMockRecorder) Value(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Value", reflect.TypeOf((*MockSession)(nil).Value), arg0)
 }
 
-// MockSessionPool is a mock of SessionPool interface
+// MockSessionPool is a mock of SessionPool interface.
 type MockSessionPool struct {
 	ctrl     *gomock.Controller
 	recorder *MockSessionPoolMockRecorder
 }
 
-// MockSessionPoolMockRecorder is the mock recorder for MockSessionPool
+// MockSessionPoolMockRecorder is the mock recorder for MockSessionPool.
 type MockSessionPoolMockRecorder struct {
 	mock *MockSessionPool
 }
 
-// NewMockSessionPool creates a new mock instance
+// NewMockSessionPool creates a new mock instance.
 func NewMockSessionPool(ctrl *gomock.Controller) *MockSessionPool {
 	mock := &MockSessionPool{ctrl: ctrl}
 	mock.recorder = &MockSessionPoolMockRecorder{mock}
 	return mock
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use
+// EXPECT returns an object that allows the caller to indicate expected use.
 func (m *MockSessionPool) EXPECT() *MockSessionPoolMockRecorder {
 	return m.recorder
 }
 
-// CloseAll mocks base method
+// CloseAll mocks base method.
 func (m *MockSessionPool) CloseAll() {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "CloseAll")
 }
 
-// CloseAll indicates an expected call of CloseAll
+// CloseAll indicates an expected call of CloseAll.
 func (mr *MockSessionPoolMockRecorder) CloseAll() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseAll", reflect.TypeOf((*MockSessionPool)(nil).CloseAll))
 }
 
-// GetSessionByID mocks base method
+// DiscardSession mocks base method.
+func (m *MockSessionPool) DiscardSession(arg0 session.Session) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DiscardSession", arg0)
+}
+
+// DiscardSession indicates an expected call of DiscardSession.
+func (mr *MockSessionPoolMockRecorder) DiscardSession(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiscardSession", reflect.TypeOf((*MockSessionPool)(nil).DiscardSession), arg0)
+}
+
+// ForEachSession mocks base method.
+func (m *MockSessionPool) ForEachSession(arg0 func(session.Session)) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ForEachSession", arg0)
+}
+
+// ForEachSession indicates an expected call of ForEachSession.
+func (mr *MockSessionPoolMockRecorder) ForEachSession(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForEachSession", reflect.TypeOf((*MockSessionPool)(nil).ForEachSession), arg0)
+}
+
+// GetSessionByID mocks base method.
 func (m *MockSessionPool) GetSessionByID(arg0 int64) session.Session {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetSessionByID", arg0)
@@ -659,13 +737,13 @@ func (m *MockSessionPool) GetSessionByID(arg0 int64) session.Session {
 	return ret0
 }
 
-// GetSessionByID indicates an expected call of GetSessionByID
+// GetSessionByID indicates an expected call of GetSessionByID.
 func (mr *MockSessionPoolMockRecorder) GetSessionByID(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionByID", reflect.TypeOf((*MockSessionPool)(nil).GetSessionByID), arg0)
 }
 
-// GetSessionByUID mocks base method
+// GetSessionByUID mocks base method.
 func (m *MockSessionPool) GetSessionByUID(arg0 string) session.Session {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetSessionByUID", arg0)
@@ -673,13 +751,13 @@ func (m *MockSessionPool) GetSessionByUID(arg0 string) session.Session {
 	return ret0
 }
 
-// GetSessionByUID indicates an expected call of GetSessionByUID
+// GetSessionByUID indicates an expected call of GetSessionByUID.
 func (mr *MockSessionPoolMockRecorder) GetSessionByUID(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionByUID", reflect.TypeOf((*MockSessionPool)(nil).GetSessionByUID), arg0)
 }
 
-// GetSessionCloseCallbacks mocks base method
+// GetSessionCloseCallbacks mocks base method.
 func (m *MockSessionPool) GetSessionCloseCallbacks() []func(session.Session) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetSessionCloseCallbacks")
@@ -687,13 +765,13 @@ func (m *MockSessionPool) GetSessionCloseCallbacks() []func(session.Session) {
 	return ret0
 }
 
-// GetSessionCloseCallbacks indicates an expected call of GetSessionCloseCallbacks
+// GetSessionCloseCallbacks indicates an expected call of GetSessionCloseCallbacks.
 func (mr *MockSessionPoolMockRecorder) GetSessionCloseCallbacks() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionCloseCallbacks", reflect.TypeOf((*MockSessionPool)(nil).GetSessionCloseCallbacks))
 }
 
-// GetSessionCount mocks base method
+// GetSessionCount mocks base method.
 func (m *MockSessionPool) GetSessionCount() int64 {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetSessionCount")
@@ -701,13 +779,13 @@ func (m *MockSessionPool) GetSessionCount() int64 {
 	return ret0
 }
 
-// GetSessionCount indicates an expected call of GetSessionCount
+// GetSessionCount indicates an expected call of GetSessionCount.
 func (mr *MockSessionPoolMockRecorder) GetSessionCount() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionCount", reflect.TypeOf((*MockSessionPool)(nil).GetSessionCount))
 }
 
-// NewSession mocks base method
+// NewSession mocks base method.
 func (m *MockSessionPool) NewSession(arg0 networkentity.NetworkEntity, arg1 bool, arg2 ...string) session.Session {
 	m.ctrl.T.Helper()
 	varargs := []interface{}{arg0, arg1}
@@ -719,44 +797,44 @@ func (m *MockSessionPool) NewSession(arg0 networkentity.NetworkEntity, arg1 bool
 	return ret0
 }
 
-// NewSession indicates an expected call of NewSession
+// NewSession indicates an expected call of NewSession.
 func (mr *MockSessionPoolMockRecorder) NewSession(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	varargs := append([]interface{}{arg0, arg1}, arg2...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewSession", reflect.TypeOf((*MockSessionPool)(nil).NewSession), varargs...)
 }
 
-// OnAfterSessionBind mocks base method
+// OnAfterSessionBind mocks base method.
 func (m *MockSessionPool) OnAfterSessionBind(arg0 func(context.Context, session.Session) error) {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "OnAfterSessionBind", arg0)
 }
 
-// OnAfterSessionBind indicates an expected call of OnAfterSessionBind
+// OnAfterSessionBind indicates an expected call of OnAfterSessionBind.
 func (mr *MockSessionPoolMockRecorder) OnAfterSessionBind(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnAfterSessionBind", reflect.TypeOf((*MockSessionPool)(nil).OnAfterSessionBind), arg0)
 }
 
-// OnSessionBind mocks base method
+// OnSessionBind mocks base method.
 func (m *MockSessionPool) OnSessionBind(arg0 func(context.Context, session.Session) error) {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "OnSessionBind", arg0)
 }
 
-// OnSessionBind indicates an expected call of OnSessionBind
+// OnSessionBind indicates an expected call of OnSessionBind.
 func (mr *MockSessionPoolMockRecorder) OnSessionBind(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnSessionBind", reflect.TypeOf((*MockSessionPool)(nil).OnSessionBind), arg0)
 }
 
-// OnSessionClose mocks base method
+// OnSessionClose mocks base method.
 func (m *MockSessionPool) OnSessionClose(arg0 func(session.Session)) {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "OnSessionClose", arg0)
 }
 
-// OnSessionClose indicates an expected call of OnSessionClose
+// OnSessionClose indicates an expected call of OnSessionClose.
 func (mr *MockSessionPoolMockRecorder) OnSessionClose(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnSessionClose", reflect.TypeOf((*MockSessionPool)(nil).OnSessionClose), arg0)
@@ -36,6 +36,7 @@ import (
 	"github.com/topfreegames/pitaya/v2/logger"
 	"github.com/topfreegames/pitaya/v2/networkentity"
 	"github.com/topfreegames/pitaya/v2/protos"
+	"github.com/topfreegames/pitaya/v2/serialize"
 )
 
 type sessionPoolImpl struct {
@@ -60,15 +61,25 @@ type SessionPool interface {
 	OnSessionBind(f func(ctx context.Context, s Session) error)
 	OnAfterSessionBind(f func(ctx context.Context, s Session) error)
 	OnSessionClose(f func(s Session))
+	DiscardSession(s Session)
+	ForEachSession(f func(s Session))
 	CloseAll()
 }
 
 // HandshakeClientData represents information about the client sent on the handshake.
+// Serializers and Compressions are the ones accepted by the client, in order of
+// preference, the server defaults are used when they are empty. DictionaryID is
+// the id of the compression dictionary the client already has, if any.
 type HandshakeClientData struct {
-	Platform    string `json:"platform"`
-	LibVersion  string `json:"libVersion"`
-	BuildNumber string `json:"clientBuildNumber"`
-	Version     string `json:"clientVersion"`
+	Platform        string   `json:"platform"`
+	LibVersion      string   `json:"libVersion"`
+	BuildNumber     string   `json:"clientBuildNumber"`
+	Version         string   `json:"clientVersion"`
+	ResumeToken     string   `json:"resumeToken,omitempty"`
+	ProtocolVersion int      `json:"protocolVersion,omitempty"`
+	Serializers     []string `json:"serializers,omitempty"`
+	Compressions    []string `json:"compressions,omitempty"`
+	DictionaryID    uint32   `json:"dictionaryId,omitempty"`
 }
 
 // HandshakeData represents information about the handshake sent by the client.
@@ -87,6 +98,7 @@ type sessionImpl struct {
 	entity            networkentity.NetworkEntity // low-level network entity
 	data              map[string]interface{}      // session data store
 	handshakeData     *HandshakeData              // handshake data received by the client
+	serializer        serialize.Serializer        // serializer negotiated by the client at handshake
 	encodedData       []byte                      // session data encoded as a byte array
 	OnCloseCallbacks  []func()                    // onClose callbacks
 	IsFrontend        bool                        // if session is a frontend session
@@ -107,6 +119,8 @@ type Session interface {
 	SetOnCloseCallbacks(callbacks []func())
 	SetIsFrontend(isFrontend bool)
 	SetSubscriptions(subscriptions []*nats.Subscription)
+	SetEntity(entity networkentity.NetworkEntity)
+	GetEntity() networkentity.NetworkEntity
 
 	Push(ctx context.Context, route string, v interface{}) error
 	ResponseMID(ctx context.Context, mid uint, v interface{}, err ...bool) error
@@ -144,6 +158,8 @@ type Session interface {
 	Clear()
 	SetHandshakeData(data *HandshakeData)
 	GetHandshakeData() *HandshakeData
+	SetSerializer(serializer serialize.Serializer)
+	GetSerializer() serialize.Serializer
 }
 
 type sessionIDService struct {
@@ -195,7 +211,7 @@ func NewSessionPool() SessionPool {
 }
 
 func (pool *sessionPoolImpl) GetSessionCount() int64 {
-	return pool.SessionCount
+	return atomic.LoadInt64(&pool.SessionCount)
 }
 
 func (pool *sessionPoolImpl) GetSessionCloseCallbacks() []func(s Session) {
@@ -259,9 +275,30 @@ func (pool *sessionPoolImpl) OnSessionClose(f func(s Session)) {
 	pool.SessionCloseCallbacks = append(pool.SessionCloseCallbacks, f)
 }
 
+// DiscardSession removes a session from the pool without closing its
+// network entity nor calling the close callbacks
+func (pool *sessionPoolImpl) DiscardSession(s Session) {
+	if _, ok := pool.sessionsByID.LoadAndDelete(s.ID()); ok {
+		atomic.AddInt64(&pool.SessionCount, -1)
+	}
+	if s.UID() != "" {
+		if val, ok := pool.sessionsByUID.Load(s.UID()); ok && val.(Session) == s {
+			pool.sessionsByUID.Delete(s.UID())
+		}
+	}
+}
+
+// ForEachSession calls f for every session in the pool
+func (pool *sessionPoolImpl) ForEachSession(f func(s Session)) {
+	pool.sessionsByID.Range(func(_, value interface{}) bool {
+		f(value.(Session))
+		return true
+	})
+}
+
 // CloseAll calls Close on all sessions
 func (pool *sessionPoolImpl) CloseAll() {
-	logger.Log.Debugf("closing all sessions, %d sessions", pool.SessionCount)
+	logger.Log.Debugf("closing all sessions, %d sessions", pool.GetSessionCount())
 	pool.sessionsByID.Range(func(_, value interface{}) bool {
 		s := value.(Session)
 		s.Close()
@@ -310,15 +347,30 @@ func (s *sessionImpl) SetSubscriptions(subscriptions []*nats.Subscription) {
 	s.Subscriptions = subscriptions
 }
 
+// SetEntity replaces the network entity of the session, used when a
+// suspended session is resumed by a new connection
+func (s *sessionImpl) SetEntity(entity networkentity.NetworkEntity) {
+	s.Lock()
+	defer s.Unlock()
+	s.entity = entity
+}
+
+// GetEntity returns the network entity of the session
+func (s *sessionImpl) GetEntity() networkentity.NetworkEntity {
+	s.RLock()
+	defer s.RUnlock()
+	return s.entity
+}
+
 // Push message to client
 func (s *sessionImpl) Push(ctx context.Context, route string, v interface{}) error {
-	return s.entity.Push(ctx, route, v)
+	return s.GetEntity().Push(ctx, route, v)
 }
 
 // ResponseMID responses message to client, mid is
 // request message ID
 func (s *sessionImpl) ResponseMID(ctx context.Context, mid uint, v interface{}, err ...bool) error {
-	return s.entity.ResponseMID(ctx, mid, v, err...)
+	return s.GetEntity().ResponseMID(ctx, mid, v, err...)
 }
 
 // ID returns the session id
@@ -442,11 +494,12 @@ func (s *sessionImpl) Bind(ctx context.Context, uid string) error {
 
 // Kick kicks the user
 func (s *sessionImpl) Kick(ctx context.Context) error {
-	err := s.entity.Kick(ctx)
+	entity := s.GetEntity()
+	err := entity.Kick(ctx)
 	if err != nil {
 		return err
 	}
-	err = s.entity.Close()
+	err = entity.Close()
 	if errors.Is(err, constants.ErrCloseClosedSession) {
 		err = nil
 	}
@@ -486,12 +539,12 @@ func (s *sessionImpl) Close() {
 			}
 		}
 	}
-	s.entity.Close()
+	s.GetEntity().Close()
 }
 
 // RemoteAddr returns the remote network address.
 func (s *sessionImpl) RemoteAddr() net.Addr {
-	return s.entity.RemoteAddr()
+	return s.GetEntity().RemoteAddr()
 }
 
 // Remove delete data associated with the key from session storage
@@ -797,6 +850,23 @@ func (s *sessionImpl) GetHandshakeData() *HandshakeData {
 	return s.handshakeData
 }
 
+// SetSerializer sets the serializer negotiated by the client.
+func (s *sessionImpl) SetSerializer(serializer serialize.Serializer) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.serializer = serializer
+}
+
+// GetSerializer gets the serializer negotiated by the client, nil before
+// the handshake.
+func (s *sessionImpl) GetSerializer() serialize.Serializer {
+	s.RLock()
+	defer s.RUnlock()
+
+	return s.serializer
+}
+
 func (s *sessionImpl) sendRequestToFront(ctx context.Context, route string, includeData bool) error {
 	sessionData := &protos.Session{
 		Id:  s.frontendSessionID,
@@ -809,7 +879,7 @@ func (s *sessionImpl) sendRequestToFront(ctx context.Context, route string, incl
 	if err != nil {
 		return err
 	}
-	res, err := s.entity.SendRequest(ctx, s.frontendID, route, b)
+	res, err := s.GetEntity().SendRequest(ctx, s.frontendID, route, b)
 	if err != nil {
 		return err
 	}
@@ -261,8 +261,8 @@ func TestSessionPush(t *testing.T) {
 	route := uuid.New().String()
 	v := someStruct{A: 1, B: "aaa"}
 
-	mockEntity.EXPECT().Push(route, v)
-	err := ss.Push(route, v)
+	mockEntity.EXPECT().Push(gomock.Any(), route, v)
+	err := ss.Push(context.Background(), route, v)
 	assert.NoError(t, err)
 }
 
@@ -136,6 +136,10 @@ func IsRunning() bool {
 	return DefaultApp.IsRunning()
 }
 
+func IsDraining() bool {
+	return DefaultApp.IsDraining()
+}
+
 func RPC(ctx context.Context, routeStr string, reply proto.Message, arg proto.Message) error {
 	return DefaultApp.RPC(ctx, routeStr, reply, arg)
 }
@@ -144,6 +148,14 @@ func RPCTo(ctx context.Context, serverID, routeStr string, reply proto.Message,
 	return DefaultApp.RPCTo(ctx, serverID, routeStr, reply, arg)
 }
 
+func RPCStream(ctx context.Context, routeStr string, arg proto.Message) (component.ClientStream, error) {
+	return DefaultApp.RPCStream(ctx, routeStr, arg)
+}
+
+func RPCStreamTo(ctx context.Context, serverID, routeStr string, arg proto.Message) (component.ClientStream, error) {
+	return DefaultApp.RPCStreamTo(ctx, serverID, routeStr, arg)
+}
+
 func ReliableRPC(routeStr string, metadata map[string]interface{}, reply, arg proto.Message) (jid string, err error) {
 	return DefaultApp.ReliableRPC(routeStr, metadata, reply, arg)
 }
@@ -152,6 +164,34 @@ func ReliableRPCWithOptions(routeStr string, metadata map[string]interface{}, re
 	return DefaultApp.ReliableRPCWithOptions(routeStr, metadata, reply, arg, opts)
 }
 
+func ScheduleReliableRPC(routeStr string, metadata map[string]interface{}, reply, arg proto.Message, opts *worker.ScheduleOpts) (jid string, err error) {
+	return DefaultApp.ScheduleReliableRPC(routeStr, metadata, reply, arg, opts)
+}
+
+func CancelReliableRPC(jid string) error {
+	return DefaultApp.CancelReliableRPC(jid)
+}
+
+func ReliableRPCStatus(jid string) (*worker.RPCJobInfo, error) {
+	return DefaultApp.ReliableRPCStatus(jid)
+}
+
+func FailedReliableRPCs() ([]*worker.RPCJobInfo, error) {
+	return DefaultApp.FailedReliableRPCs()
+}
+
+func RequeueReliableRPC(jid string) error {
+	return DefaultApp.RequeueReliableRPC(jid)
+}
+
+func PurgeReliableRPC(jid string) error {
+	return DefaultApp.PurgeReliableRPC(jid)
+}
+
+func PurgeReliableRPCs() (int, error) {
+	return DefaultApp.PurgeReliableRPCs()
+}
+
 func SendPushToUsers(ctx context.Context, route string, v interface{}, uids []string, frontendType string) ([]string, error) {
 	return DefaultApp.SendPushToUsers(ctx, route, v, uids, frontendType)
 }
@@ -343,6 +343,28 @@ func TestStaticIsRunning(t *testing.T) {
 	}
 }
 
+func TestStaticIsDraining(t *testing.T) {
+	tables := []struct {
+		name     string
+		returned bool
+	}{
+		{"Draining", true},
+		{"NotDraining", false},
+	}
+
+	for _, row := range tables {
+		t.Run(row.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			app := mocks.NewMockPitaya(ctrl)
+			app.EXPECT().IsDraining().Return(row.returned)
+
+			DefaultApp = app
+			require.Equal(t, row.returned, IsDraining())
+		})
+	}
+}
+
 func TestStaticRPC(t *testing.T) {
 	ctx := context.Background()
 	routeStr := "route"
@@ -398,6 +420,61 @@ func TestStaticRPCTo(t *testing.T) {
 	}
 }
 
+func TestStaticRPCStream(t *testing.T) {
+	ctx := context.Background()
+	routeStr := "route"
+	var arg protoiface.MessageV1
+
+	tables := []struct {
+		name     string
+		returned error
+	}{
+		{"Success", nil},
+		{"Error", errors.New("error")},
+	}
+
+	for _, row := range tables {
+		t.Run(row.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			app := mocks.NewMockPitaya(ctrl)
+			app.EXPECT().RPCStream(ctx, routeStr, arg).Return(nil, row.returned)
+
+			DefaultApp = app
+			_, err := RPCStream(ctx, routeStr, arg)
+			require.Equal(t, row.returned, err)
+		})
+	}
+}
+
+func TestStaticRPCStreamTo(t *testing.T) {
+	ctx := context.Background()
+	routeStr := "route"
+	serverId := uuid.New().String()
+	var arg protoiface.MessageV1
+
+	tables := []struct {
+		name     string
+		returned error
+	}{
+		{"Success", nil},
+		{"Error", errors.New("error")},
+	}
+
+	for _, row := range tables {
+		t.Run(row.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			app := mocks.NewMockPitaya(ctrl)
+			app.EXPECT().RPCStreamTo(ctx, serverId, routeStr, arg).Return(nil, row.returned)
+
+			DefaultApp = app
+			_, err := RPCStreamTo(ctx, serverId, routeStr, arg)
+			require.Equal(t, row.returned, err)
+		})
+	}
+}
+
 func TestStaticReliableRPC(t *testing.T) {
 	tables := []struct {
 		name     string
@@ -458,6 +535,7 @@ func TestStaticReliableRPCWithOptions(t *testing.T) {
 }
 
 func TestStaticSendPushToUsers(t *testing.T) {
+	ctx := context.Background()
 	tables := []struct {
 		name         string
 		route        string
@@ -476,10 +554,10 @@ func TestStaticSendPushToUsers(t *testing.T) {
 			ctrl := gomock.NewController(t)
 
 			app := mocks.NewMockPitaya(ctrl)
-			app.EXPECT().SendPushToUsers(row.route, row.v, row.uids, row.frontendType).Return(row.returned, row.err)
+			app.EXPECT().SendPushToUsers(ctx, row.route, row.v, row.uids, row.frontendType).Return(row.returned, row.err)
 
 			DefaultApp = app
-			returned, err := SendPushToUsers(row.route, row.v, row.uids, row.frontendType)
+			returned, err := SendPushToUsers(ctx, row.route, row.v, row.uids, row.frontendType)
 			require.Equal(t, row.err, err)
 			require.Equal(t, row.returned, returned)
 		})
@@ -487,6 +565,7 @@ func TestStaticSendPushToUsers(t *testing.T) {
 }
 
 func TestStaticSendKickToUsers(t *testing.T) {
+	ctx := context.Background()
 	tables := []struct {
 		name         string
 		uids         []string
@@ -503,10 +582,10 @@ func TestStaticSendKickToUsers(t *testing.T) {
 			ctrl := gomock.NewController(t)
 
 			app := mocks.NewMockPitaya(ctrl)
-			app.EXPECT().SendKickToUsers(row.uids, row.frontendType).Return(row.returned, row.err)
+			app.EXPECT().SendKickToUsers(ctx, row.uids, row.frontendType).Return(row.returned, row.err)
 
 			DefaultApp = app
-			returned, err := SendKickToUsers(row.uids, row.frontendType)
+			returned, err := SendKickToUsers(ctx, row.uids, row.frontendType)
 			require.Equal(t, row.err, err)
 			require.Equal(t, row.returned, returned)
 		})
@@ -50,10 +50,7 @@ func NewCountTimer(interval time.Duration, count int, fn timer.Func) *timer.Time
 		panic("non-positive interval for NewTimer")
 	}
 
-	t := timer.NewTimer(fn, interval, count)
-	// add to manager
-	timer.Manager.ChCreatedTimer <- t
-	return t
+	return timer.NewTimer(fn, interval, count)
 }
 
 // NewAfterTimer returns a new Timer containing a function that will be called
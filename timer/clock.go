@@ -0,0 +1,109 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package timer
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the source of time of the timers and of the heartbeats of the
+// agents, tests replace it with Builder.Clock to step time manually
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers the ticks of a Clock
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTicker struct {
+	ticker *time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+func (t realTicker) Stop() {
+	t.ticker.Stop()
+}
+
+var (
+	clockMutex sync.RWMutex
+	clock      Clock = realClock{}
+)
+
+// SetClock replaces the clock, nil restores the system clock. It must be
+// called before the app starts.
+func SetClock(c Clock) {
+	clockMutex.Lock()
+	defer clockMutex.Unlock()
+	if c == nil {
+		c = realClock{}
+	}
+	clock = c
+}
+
+func getClock() Clock {
+	clockMutex.RLock()
+	defer clockMutex.RUnlock()
+	return clock
+}
+
+type globalClock struct{}
+
+func (globalClock) Now() time.Time {
+	return Now()
+}
+
+func (globalClock) NewTicker(d time.Duration) Ticker {
+	return NewTicker(d)
+}
+
+// GlobalClock returns a Clock reading the clock set with SetClock, which is
+// the default clock of the Apps
+func GlobalClock() Clock {
+	return globalClock{}
+}
+
+// Now returns the current time of the clock
+func Now() time.Time {
+	return getClock().Now()
+}
+
+// NewTicker returns a ticker of the clock
+func NewTicker(d time.Duration) Ticker {
+	return getClock().NewTicker(d)
+}
@@ -28,7 +28,11 @@ import (
 	"github.com/topfreegames/pitaya/v2/logger"
 )
 
-var timerBacklog int
+var (
+	timerBacklog int
+	cronMutex    sync.Mutex
+	pending      int64 // timers created and not added by an App yet
+)
 
 const (
 	// LoopForever is a constant indicating that timer should loop forever
@@ -49,6 +53,9 @@ var (
 
 	// GlobalTicker represents global ticker that all cron job will be executed
 	// in globalTicker.
+	//
+	// Deprecated: the Apps run the timers with a ticker of their own clock and
+	// no longer set it.
 	GlobalTicker *time.Ticker
 )
 
@@ -73,6 +80,7 @@ type (
 		elapse    int64         // total elapse time
 		closed    int32         // is timer closed
 		counter   int           // counter
+		clock     Clock         // clock the timer is due by
 	}
 )
 
@@ -88,6 +96,21 @@ func AddTimer(t *Timer) {
 	Manager.timers.Store(t.ID, t)
 }
 
+// AddClockTimer adds a timer to the manager, the timer is then due by clock
+// and its interval starts at the current time of clock. Timers already added
+// are left as they are.
+func AddClockTimer(t *Timer, clock Clock) {
+	cronMutex.Lock()
+	defer cronMutex.Unlock()
+	defer atomic.AddInt64(&pending, -1)
+	if _, ok := Manager.timers.Load(t.ID); ok {
+		return
+	}
+	t.clock = clock
+	t.createAt = clock.Now().UnixNano()
+	AddTimer(t)
+}
+
 // RemoveTimer removes a timer to the manager
 func RemoveTimer(id int64) {
 	Manager.timers.Delete(id)
@@ -99,17 +122,23 @@ func NewTimer(fn Func, interval time.Duration, counter int) *Timer {
 	t := &Timer{
 		ID:       id,
 		fn:       fn,
-		createAt: time.Now().UnixNano(),
+		createAt: Now().UnixNano(),
 		interval: interval,
 		elapse:   int64(interval), // first execution will be after interval
 		counter:  counter,
 	}
 
 	// add to manager
+	atomic.AddInt64(&pending, 1)
 	Manager.ChCreatedTimer <- t
 	return t
 }
 
+// Pending returns the number of timers created that no App added yet
+func Pending() int64 {
+	return atomic.LoadInt64(&pending)
+}
+
 // SetCondition sets the condition used for verifying when the cron job should run
 func (t *Timer) SetCondition(condition Condition) {
 	t.condition = condition
@@ -141,14 +170,26 @@ func pexec(id int64, fn Func) {
 	fn()
 }
 
-// Cron executes scheduled tasks
+// now returns the current time of the clock of the timer
+func (t *Timer) now() time.Time {
+	if t.clock == nil {
+		return Now()
+	}
+	return t.clock.Now()
+}
+
+// Cron executes scheduled tasks, each timer being due by its own clock. The
+// Apps running in the same process call it from their own tickers so only one
+// call runs at a time
 // TODO: if closing Timers'count in single cron call more than timerBacklog will case problem.
 func Cron() {
-	now := time.Now()
-	unn := now.UnixNano()
+	cronMutex.Lock()
+	defer cronMutex.Unlock()
 	Manager.timers.Range(func(idInterface, tInterface interface{}) bool {
 		t := tInterface.(*Timer)
 		id := idInterface.(int64)
+		now := t.now()
+		unn := now.UnixNano()
 		// prevent ChClosingTimer exceed
 		if t.counter == 0 {
 			if len(Manager.ChClosingTimer) < timerBacklog {
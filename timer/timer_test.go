@@ -83,6 +83,38 @@ func TestAddTimer(t *testing.T) {
 	}
 }
 
+type stepClock struct {
+	now time.Time
+}
+
+func (c *stepClock) Now() time.Time {
+	return c.now
+}
+
+func (c *stepClock) NewTicker(d time.Duration) Ticker {
+	return nil
+}
+
+func TestAddClockTimer(t *testing.T) {
+	clock := &stepClock{now: time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	fired := 0
+	tm := NewTimer(func() { fired++ }, time.Minute, 1)
+	AddClockTimer(tm, clock)
+	defer RemoveTimer(tm.ID)
+	assert.Equal(t, clock.now.UnixNano(), tm.createAt)
+
+	// adding it again doesn't restart its interval
+	clock.now = clock.now.Add(30 * time.Second)
+	AddClockTimer(tm, clock)
+	assert.Equal(t, clock.now.Add(-30*time.Second).UnixNano(), tm.createAt)
+
+	Cron()
+	assert.Equal(t, 0, fired)
+	clock.now = clock.now.Add(30 * time.Second)
+	Cron()
+	assert.Equal(t, 1, fired)
+}
+
 func TestRemoveTimer(t *testing.T) {
 	for _, table := range tables {
 		t.Run(table.name, func(t *testing.T) {
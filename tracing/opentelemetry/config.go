@@ -0,0 +1,107 @@
+/*
+ * Copyright (c) 2018 TFG Co <backend@tfgco.com>
+ * Author: TFG Co <backend@tfgco.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of
+ * this software and associated documentation files (the "Software"), to deal in
+ * the Software without restriction, including without limitation the rights to
+ * use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+ * the Software, and to permit persons to whom the Software is furnished to do so,
+ * subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+ * FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+ * COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+ * IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+ * CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package opentelemetry
+
+import (
+	"context"
+	"io"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+
+	"github.com/topfreegames/pitaya/v2/tracing"
+)
+
+// Options holds configuration options for OpenTelemetry
+type Options struct {
+	Disabled    bool
+	Probability float64
+	ServiceName string
+	// Endpoint is the address of the OTLP gRPC collector, the OTEL_EXPORTER_OTLP_*
+	// environment variables are used if empty
+	Endpoint string
+	Insecure bool
+}
+
+type closer struct {
+	provider *sdktrace.TracerProvider
+}
+
+func (c *closer) Close() error {
+	return c.provider.Shutdown(context.Background())
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+// Configure configures a global OpenTelemetry tracer provider exporting the
+// spans to an OTLP collector, or to the given exporter, and makes pitaya
+// create its spans with it
+func Configure(options Options, exporterOrNil ...sdktrace.SpanExporter) (io.Closer, error) {
+	if options.Disabled {
+		tracing.UseOpenTelemetry(false)
+		return nopCloser{}, nil
+	}
+
+	var exporter sdktrace.SpanExporter
+	if len(exporterOrNil) > 0 {
+		exporter = exporterOrNil[0]
+	} else {
+		opts := []otlptracegrpc.Option{}
+		if options.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(options.Endpoint))
+		}
+		if options.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		var err error
+		exporter, err = otlptracegrpc.New(context.Background(), opts...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(options.ServiceName)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(options.Probability))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	tracing.UseOpenTelemetry(true)
+
+	return &closer{provider: provider}, nil
+}
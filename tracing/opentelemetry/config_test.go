@@ -0,0 +1,61 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package opentelemetry
+
+import (
+	"context"
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+
+	"github.com/topfreegames/pitaya/v2/tracing"
+)
+
+func TestConfigure(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	closer, err := Configure(Options{ServiceName: "test-svc", Probability: 1}, exporter)
+	assert.NoError(t, err)
+	defer tracing.UseOpenTelemetry(false)
+	assert.True(t, tracing.OpenTelemetryEnabled())
+
+	ctx := tracing.StartSpan(context.Background(), "op", opentracing.Tags{})
+	tracing.FinishSpan(ctx, nil)
+	provider := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+	assert.NoError(t, provider.ForceFlush(context.Background()))
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "op", spans[0].Name)
+	assert.Contains(t, spans[0].Resource.Attributes(), semconv.ServiceName("test-svc"))
+	assert.NoError(t, closer.Close())
+}
+
+func TestConfigureDisabled(t *testing.T) {
+	closer, err := Configure(Options{Disabled: true})
+	assert.NoError(t, err)
+	assert.False(t, tracing.OpenTelemetryEnabled())
+	assert.NoError(t, closer.Close())
+}
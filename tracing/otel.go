@@ -0,0 +1,162 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/topfreegames/pitaya/v2/constants"
+	pcontext "github.com/topfreegames/pitaya/v2/context"
+	"github.com/topfreegames/pitaya/v2/logger"
+)
+
+// tracerName is the instrumentation name of the OpenTelemetry spans
+const tracerName = "github.com/topfreegames/pitaya/v2"
+
+var (
+	openTelemetry int32
+
+	// propagator encodes the span context as a W3C trace context, along with
+	// the W3C baggage
+	propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+)
+
+// UseOpenTelemetry sets whether the spans are created with the global
+// OpenTelemetry tracer provider instead of the global opentracing tracer
+func UseOpenTelemetry(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&openTelemetry, v)
+}
+
+// OpenTelemetryEnabled returns whether the spans are created with OpenTelemetry
+func OpenTelemetryEnabled() bool {
+	return atomic.LoadInt32(&openTelemetry) == 1
+}
+
+// ExtractOpenTelemetrySpan retrieves an OpenTelemetry span context from the given
+// context.Context, either from the span inside it or from the trace context
+// received via an RPC call
+func ExtractOpenTelemetrySpan(ctx context.Context) (trace.SpanContext, error) {
+	if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.IsValid() {
+		return spanCtx, nil
+	}
+	ctx, err := extractOpenTelemetryRemote(ctx)
+	if err != nil {
+		return trace.SpanContext{}, err
+	}
+	return trace.SpanContextFromContext(ctx), nil
+}
+
+// extractOpenTelemetryRemote returns a context with the remote span context
+// and baggage propagated by another server, if any
+func extractOpenTelemetryRemote(ctx context.Context) (context.Context, error) {
+	s := pcontext.GetFromPropagateCtx(ctx, constants.SpanPropagateCtxKey)
+	if s == nil {
+		return ctx, nil
+	}
+	carrier, err := castValueToCarrier(s)
+	if err != nil {
+		return ctx, err
+	}
+	return propagator.Extract(ctx, propagation.MapCarrier(carrier)), nil
+}
+
+// injectOpenTelemetrySpan adds the trace context of the OpenTelemetry span to
+// the carrier
+func injectOpenTelemetrySpan(ctx context.Context, carrier opentracing.TextMapCarrier) bool {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return false
+	}
+	propagator.Inject(ctx, propagation.MapCarrier(carrier))
+	return true
+}
+
+func startOpenTelemetrySpan(parentCtx context.Context, opName string, tags opentracing.Tags) context.Context {
+	if !trace.SpanContextFromContext(parentCtx).IsValid() {
+		var err error
+		parentCtx, err = extractOpenTelemetryRemote(parentCtx)
+		if err != nil {
+			logger.Log.Warnf("failed to retrieve parent span: %s", err.Error())
+		}
+	}
+
+	opts := []trace.SpanStartOption{trace.WithAttributes(attributesFromTags(tags)...)}
+	switch tags["span.kind"] {
+	case "server":
+		opts = append(opts, trace.WithSpanKind(trace.SpanKindServer))
+	case "client":
+		opts = append(opts, trace.WithSpanKind(trace.SpanKindClient))
+	}
+	ctx, _ := otel.Tracer(tracerName).Start(parentCtx, opName, opts...)
+	return ctx
+}
+
+// finishOpenTelemetrySpan ends the OpenTelemetry span of ctx, it returns false
+// if there is none
+func finishOpenTelemetrySpan(ctx context.Context, err error) bool {
+	span := trace.SpanFromContext(ctx)
+	if !span.SpanContext().IsValid() {
+		return false
+	}
+	defer span.End()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return true
+}
+
+func attributesFromTags(tags opentracing.Tags) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(tags))
+	for k, v := range tags {
+		if k == "span.kind" {
+			continue
+		}
+		switch val := v.(type) {
+		case nil:
+		case string:
+			attrs = append(attrs, attribute.String(k, val))
+		case bool:
+			attrs = append(attrs, attribute.Bool(k, val))
+		case int:
+			attrs = append(attrs, attribute.Int(k, val))
+		case int64:
+			attrs = append(attrs, attribute.Int64(k, val))
+		case float64:
+			attrs = append(attrs, attribute.Float64(k, val))
+		default:
+			attrs = append(attrs, attribute.String(k, fmt.Sprint(val)))
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,122 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/topfreegames/pitaya/v2/constants"
+	pcontext "github.com/topfreegames/pitaya/v2/context"
+)
+
+func useOpenTelemetry(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	UseOpenTelemetry(true)
+	t.Cleanup(func() {
+		UseOpenTelemetry(false)
+		otel.SetTracerProvider(previous)
+	})
+	return recorder
+}
+
+func TestOpenTelemetryStartAndFinishSpan(t *testing.T) {
+	recorder := useOpenTelemetry(t)
+
+	ctx := StartSpan(context.Background(), "my-op", opentracing.Tags{
+		"span.kind": "server",
+		"local.id":  "sv1",
+		"peer.id":   nil,
+	})
+	assert.True(t, trace.SpanContextFromContext(ctx).IsValid())
+	assert.Nil(t, opentracing.SpanFromContext(ctx))
+
+	FinishSpan(ctx, errors.New("failed"))
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "my-op", spans[0].Name())
+	assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind())
+	assert.Equal(t, []attribute.KeyValue{attribute.String("local.id", "sv1")}, spans[0].Attributes())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "failed", spans[0].Status().Description)
+}
+
+func TestOpenTelemetryPropagation(t *testing.T) {
+	recorder := useOpenTelemetry(t)
+
+	ctx := StartSpan(context.Background(), "caller", opentracing.Tags{"span.kind": "client"})
+	injected, err := InjectSpan(ctx)
+	assert.NoError(t, err)
+	carrier := pcontext.GetFromPropagateCtx(injected, constants.SpanPropagateCtxKey)
+	assert.Contains(t, carrier, "traceparent")
+
+	// the propagated context goes through the same encoding of the rpcs
+	encoded, err := pcontext.Encode(injected)
+	assert.NoError(t, err)
+	remoteCtx, err := pcontext.Decode(encoded)
+	assert.NoError(t, err)
+
+	spanCtx, err := ExtractOpenTelemetrySpan(remoteCtx)
+	assert.NoError(t, err)
+	assert.True(t, spanCtx.IsRemote())
+
+	remoteCtx = StartSpan(remoteCtx, "remote", opentracing.Tags{"span.kind": "server"})
+	FinishSpan(remoteCtx, nil)
+	FinishSpan(ctx, nil)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, spans[1].SpanContext().TraceID(), spans[0].SpanContext().TraceID())
+	assert.Equal(t, spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID())
+}
+
+func TestOpenTelemetryExtractSpanNoSpan(t *testing.T) {
+	useOpenTelemetry(t)
+	spanCtx, err := ExtractOpenTelemetrySpan(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, spanCtx.IsValid())
+}
+
+func TestOpenTelemetryDisabledKeepsOtherSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+	ctx, _ := tracer.Start(context.Background(), "app")
+
+	FinishSpan(ctx, nil)
+	assert.Empty(t, recorder.Ended())
+	injected, err := InjectSpan(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, ctx, injected)
+}
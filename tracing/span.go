@@ -76,28 +76,38 @@ func ExtractSpan(ctx context.Context) (opentracing.SpanContext, error) {
 
 // InjectSpan retrieves an opentrancing span from the current context and creates a new context
 // with it encoded in binary format inside the propagatable context content
+// If OpenTelemetry is enabled its span is encoded as a W3C trace context in the same carrier
 func InjectSpan(ctx context.Context) (context.Context, error) {
+	spanData := opentracing.TextMapCarrier{}
+	injected := OpenTelemetryEnabled() && injectOpenTelemetrySpan(ctx, spanData)
 	span := opentracing.SpanFromContext(ctx)
-	if span == nil {
-		return ctx, nil
+	if span != nil {
+		tracer := opentracing.GlobalTracer()
+		err := tracer.Inject(span.Context(), opentracing.TextMap, spanData)
+		if err != nil {
+			return nil, err
+		}
+		injected = true
 	}
-	spanData := opentracing.TextMapCarrier{}
-	tracer := opentracing.GlobalTracer()
-	err := tracer.Inject(span.Context(), opentracing.TextMap, spanData)
-	if err != nil {
-		return nil, err
+	if !injected {
+		return ctx, nil
 	}
 	return pcontext.AddToPropagateCtx(ctx, constants.SpanPropagateCtxKey, spanData), nil
 }
 
 // StartSpan starts a new span with a given parent context, operation name, tags and
 // optional parent span. It returns a context with the created span.
+// If OpenTelemetry is enabled the span is created with it instead, as a child
+// of the span in the context or of the one propagated by another server
 func StartSpan(
 	parentCtx context.Context,
 	opName string,
 	tags opentracing.Tags,
 	reference ...opentracing.SpanContext,
 ) context.Context {
+	if OpenTelemetryEnabled() {
+		return startOpenTelemetrySpan(parentCtx, opName, tags)
+	}
 	var ref opentracing.SpanContext
 	if len(reference) > 0 {
 		ref = reference[0]
@@ -111,6 +121,9 @@ func FinishSpan(ctx context.Context, err error) {
 	if ctx == nil {
 		return
 	}
+	if OpenTelemetryEnabled() && finishOpenTelemetrySpan(ctx, err) {
+		return
+	}
 	span := opentracing.SpanFromContext(ctx)
 	if span == nil {
 		return
@@ -3,9 +3,14 @@ package compression
 import (
 	"bytes"
 	"compress/zlib"
+	"errors"
+	"io"
 	"io/ioutil"
 )
 
+// ErrInflatedTooLarge is returned when the inflated data exceeds the limit
+var ErrInflatedTooLarge = errors.New("inflated data exceeds the size limit")
+
 func DeflateData(data []byte) ([]byte, error) {
 	var bb bytes.Buffer
 	z := zlib.NewWriter(&bb)
@@ -27,6 +32,25 @@ func InflateData(data []byte) ([]byte, error) {
 	return ioutil.ReadAll(zr)
 }
 
+// InflateDataWithLimit inflates the data, failing if it gets larger than
+// limit bytes
+func InflateDataWithLimit(data []byte, limit int) ([]byte, error) {
+	zr, err := zlib.NewReader(bytes.NewBuffer(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	inflated, err := ioutil.ReadAll(io.LimitReader(zr, int64(limit)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(inflated) > limit {
+		return nil, ErrInflatedTooLarge
+	}
+	return inflated, nil
+}
+
 func IsCompressed(data []byte) bool {
 	return len(data) > 2 &&
 	(
@@ -61,3 +61,16 @@ func TestCompressionInflateIncorrectData(t *testing.T) {
 		assert.Nil(t, result)
 	})
 }
+
+func TestCompressionInflateWithLimit(t *testing.T) {
+	input, err := DeflateData(make([]byte, 1024))
+	require.NoError(t, err)
+
+	result, err := InflateDataWithLimit(input, 1024)
+	require.NoError(t, err)
+	assert.Len(t, result, 1024)
+
+	result, err = InflateDataWithLimit(input, 1023)
+	assert.Equal(t, ErrInflatedTooLarge, err)
+	assert.Nil(t, result)
+}
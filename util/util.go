@@ -86,9 +86,13 @@ func Pcall(method reflect.Method, args []reflect.Value) (rets interface{}, err e
 	}()
 
 	r := method.Func.Call(args)
-	// r can have 0 length in case of notify handlers
-	// otherwise it will have 2 outputs: an interface and an error
-	if len(r) == 2 {
+	// r can have 0 length in case of notify handlers, 1 output in case of
+	// stream remotes, otherwise it will have 2 outputs: an interface and an error
+	if len(r) == 1 {
+		if v := r[0].Interface(); v != nil {
+			err = v.(error)
+		}
+	} else if len(r) == 2 {
 		if v := r[1].Interface(); v != nil {
 			err = v.(error)
 		} else if !r[0].IsNil() {
@@ -0,0 +1,72 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/topfreegames/pitaya/v2/constants"
+)
+
+func TestParseCronInvalid(t *testing.T) {
+	t.Parallel()
+
+	specs := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"1-a * * * *",
+		"@every",
+	}
+	for _, spec := range specs {
+		_, err := parseCron(spec)
+		assert.Equal(t, constants.ErrInvalidCronSpec, err, spec)
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	t.Parallel()
+
+	// a wednesday
+	now := time.Date(2026, time.January, 7, 10, 30, 15, 0, time.UTC)
+	tables := map[string]struct {
+		spec string
+		next time.Time
+	}{
+		"every_minute":      {"* * * * *", time.Date(2026, time.January, 7, 10, 31, 0, 0, time.UTC)},
+		"every_15_minutes":  {"*/15 * * * *", time.Date(2026, time.January, 7, 10, 45, 0, 0, time.UTC)},
+		"offset_step":       {"5/20 * * * *", time.Date(2026, time.January, 7, 10, 45, 0, 0, time.UTC)},
+		"list":              {"10,40 * * * *", time.Date(2026, time.January, 7, 10, 40, 0, 0, time.UTC)},
+		"next_hour":         {"0 * * * *", time.Date(2026, time.January, 7, 11, 0, 0, 0, time.UTC)},
+		"next_day":          {"0 9 * * *", time.Date(2026, time.January, 8, 9, 0, 0, 0, time.UTC)},
+		"weekdays":          {"0 9 * * 1-5", time.Date(2026, time.January, 8, 9, 0, 0, 0, time.UTC)},
+		"sunday_as_7":       {"0 0 * * 7", time.Date(2026, time.January, 11, 0, 0, 0, 0, time.UTC)},
+		"day_of_month":      {"0 0 1 * *", time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		"dom_or_dow":        {"0 0 20 * 5", time.Date(2026, time.January, 9, 0, 0, 0, 0, time.UTC)},
+		"dom_step_and_dow":  {"0 0 */2 * 1", time.Date(2026, time.January, 19, 0, 0, 0, 0, time.UTC)},
+		"dom_and_dow_step":  {"0 0 13 * */3", time.Date(2026, time.May, 13, 0, 0, 0, 0, time.UTC)},
+		"month":             {"30 12 15 6 *", time.Date(2026, time.June, 15, 12, 30, 0, 0, time.UTC)},
+		"leap_day":          {"0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		"descriptor_hourly": {"@hourly", time.Date(2026, time.January, 7, 11, 0, 0, 0, time.UTC)},
+		"descriptor_yearly": {"@yearly", time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		"never":             {"0 0 31 2 *", time.Time{}},
+	}
+
+	for name, table := range tables {
+		table := table
+		t.Run(name, func(t *testing.T) {
+			schedule, err := parseCron(table.spec)
+			require.NoError(t, err)
+			assert.Equal(t, table.next, schedule.next(now))
+		})
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package worker
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/topfreegames/pitaya/v2/constants"
+	"github.com/topfreegames/pitaya/v2/logger"
+)
+
+// fileStoreCompactMin is the number of records the log must have before it
+// is compacted
+const fileStoreCompactMin = 1024
+
+// FileQueue is a queue backend persisting the jobs in a local append-only
+// log, so the jobs not finished are executed again when the process restarts
+type FileQueue struct {
+	*localQueue
+	store *fileStore
+}
+
+// NewFileQueue opens the log at path, creating it if it doesn't exist, and
+// returns a *FileQueue with the jobs it holds
+func NewFileQueue(path string) (*FileQueue, error) {
+	store, err := openFileStore(path)
+	if err != nil {
+		return nil, err
+	}
+	q, err := newLocalQueue(store)
+	if err != nil {
+		store.close()
+		return nil, err
+	}
+	return &FileQueue{localQueue: q, store: store}, nil
+}
+
+// Close stops executing the jobs and closes the log
+func (q *FileQueue) Close() error {
+	q.stop()
+	return q.store.close()
+}
+
+// fileRecord is a line of the log, it either puts or deletes a job
+type fileRecord struct {
+	Put    *Job   `json:"put,omitempty"`
+	Delete string `json:"delete,omitempty"`
+}
+
+// fileStore is a jobStore writing a record per change to a log, the log is
+// replayed when opened and compacted when most of its records are obsolete
+type fileStore struct {
+	mutex   sync.Mutex
+	path    string
+	file    *os.File
+	jobs    map[string]*Job
+	order   []string
+	records int
+}
+
+func openFileStore(path string) (*fileStore, error) {
+	s := &fileStore{path: path, jobs: map[string]*Job{}}
+	if err := s.replay(); err != nil {
+		return nil, err
+	}
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *fileStore) replay() error {
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 64*1024*1024)
+	for scanner.Scan() {
+		record := &fileRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			// the last record is incomplete if the process died writing it
+			logger.Log.Warnf("ignoring invalid record of worker log %s: %s", s.path, err.Error())
+			continue
+		}
+		s.apply(record)
+	}
+	return scanner.Err()
+}
+
+func (s *fileStore) apply(record *fileRecord) {
+	if record.Put != nil {
+		if _, ok := s.jobs[record.Put.ID]; !ok {
+			s.order = append(s.order, record.Put.ID)
+		}
+		s.jobs[record.Put.ID] = record.Put
+	} else if record.Delete != "" {
+		delete(s.jobs, record.Delete)
+	}
+}
+
+// compact rewrites the log with only the jobs not deleted
+func (s *fileStore) compact() error {
+	tmp := s.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	order := make([]string, 0, len(s.jobs))
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, id := range s.order {
+		job, ok := s.jobs[id]
+		if !ok {
+			continue
+		}
+		order = append(order, id)
+		if err := encoder.Encode(&fileRecord{Put: job}); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return err
+	}
+
+	if s.file != nil {
+		s.file.Close()
+	}
+	s.file, err = os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	s.order = order
+	s.records = len(order)
+	return nil
+}
+
+func (s *fileStore) write(record *fileRecord) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.file == nil {
+		return constants.ErrWorkerQueueClosed
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	if err := s.file.Sync(); err != nil {
+		return err
+	}
+
+	s.apply(record)
+	s.records++
+	if s.records >= fileStoreCompactMin && s.records > 2*len(s.jobs) {
+		// the record is already written, compaction is retried on the next write
+		if err := s.compact(); err != nil {
+			logger.Log.Errorf("failed to compact worker log %s: %s", s.path, err.Error())
+		}
+	}
+	return nil
+}
+
+func (s *fileStore) put(job *Job) error {
+	stored := *job
+	return s.write(&fileRecord{Put: &stored})
+}
+
+func (s *fileStore) delete(id string) error {
+	return s.write(&fileRecord{Delete: id})
+}
+
+func (s *fileStore) load() ([]*Job, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	jobs := make([]*Job, 0, len(s.jobs))
+	for _, id := range s.order {
+		if job, ok := s.jobs[id]; ok {
+			stored := *job
+			jobs = append(jobs, &stored)
+		}
+	}
+	return jobs, nil
+}
+
+func (s *fileStore) close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
@@ -0,0 +1,134 @@
+package worker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/topfreegames/pitaya/v2/config"
+)
+
+func TestFileQueuePersistsJobs(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "worker.log")
+	opts := &config.EnqueueOpts{Enabled: true, Max: 1, MinDelay: 60, MaxDelay: 60}
+
+	q, err := NewFileQueue(path)
+	require.NoError(t, err)
+	q.Process("queue", func(args []byte) error {
+		return fmt.Errorf("failed")
+	}, 1)
+
+	_, err = q.Enqueue("queue", "pending", opts)
+	require.NoError(t, err)
+	q.Start()
+	assert.Eventually(t, func() bool {
+		return q.Stats().Retries == 1
+	}, time.Second, 10*time.Millisecond)
+	_, err = q.Enqueue("other", "other", opts)
+	require.NoError(t, err)
+	require.NoError(t, q.Close())
+
+	_, err = q.Enqueue("queue", "closed", opts)
+	assert.Error(t, err)
+
+	q, err = NewFileQueue(path)
+	require.NoError(t, err)
+	defer q.Close()
+
+	jobs, err := q.store.load()
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, "queue", jobs[0].Queue)
+	assert.Equal(t, `"pending"`, string(jobs[0].Args))
+	assert.Equal(t, *opts, jobs[0].Opts)
+	assert.False(t, jobs[0].FailedAt.IsZero())
+	assert.Equal(t, "failed", jobs[0].Error)
+	assert.Equal(t, "other", jobs[1].Queue)
+
+	stats := q.Stats()
+	assert.Equal(t, int64(1), stats.Retries)
+	assert.Equal(t, map[string]string{"queue": "0", "other": "1"}, stats.Enqueued)
+
+	executed := make(chan string, 1)
+	q.Process("other", func(args []byte) error {
+		executed <- string(args)
+		return nil
+	}, 1)
+	q.Start()
+	select {
+	case args := <-executed:
+		assert.Equal(t, `"other"`, args)
+	case <-time.After(time.Second):
+		t.Fatal("job was not executed")
+	}
+}
+
+func TestFileQueuePersistsDeadJobs(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "worker.log")
+	q, err := NewFileQueue(path)
+	require.NoError(t, err)
+	q.Process("queue", func(args []byte) error {
+		return fmt.Errorf("failed")
+	}, 1)
+	q.Start()
+
+	jid, err := q.Enqueue("queue", "arg", &config.EnqueueOpts{})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return q.Stats().Dead == 1
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, q.Close())
+
+	q, err = NewFileQueue(path)
+	require.NoError(t, err)
+	defer q.Close()
+
+	job, err := q.Job(jid)
+	require.NoError(t, err)
+	assert.Equal(t, JobDead, job.Status)
+	assert.Equal(t, "failed", job.Error)
+	require.Len(t, job.Attempts, 1)
+	assert.Equal(t, "failed", job.Attempts[0].Error)
+
+	require.NoError(t, q.Purge(jid))
+	jobs, err := q.store.load()
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+}
+
+func TestFileStoreCompact(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "worker.log")
+	store, err := openFileStore(path)
+	require.NoError(t, err)
+	defer store.close()
+
+	for i := 0; i < fileStoreCompactMin; i++ {
+		job := &Job{ID: fmt.Sprintf("job%d", i), Queue: "queue"}
+		require.NoError(t, store.put(job))
+		if i > 0 {
+			require.NoError(t, store.delete(job.ID))
+		}
+	}
+	assert.Less(t, store.records, fileStoreCompactMin)
+
+	require.NoError(t, store.close())
+	store, err = openFileStore(path)
+	require.NoError(t, err)
+	jobs, err := store.load()
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "job0", jobs[0].ID)
+
+	_, err = os.Stat(path + ".tmp")
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,520 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package worker
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/topfreegames/pitaya/v2/config"
+	"github.com/topfreegames/pitaya/v2/constants"
+	"github.com/topfreegames/pitaya/v2/logger"
+)
+
+// jobStore persists the jobs of a localQueue, jobs are put when enqueued and
+// when scheduled for retry, and deleted when done
+type jobStore interface {
+	put(job *Job) error
+	delete(id string) error
+	load() ([]*Job, error)
+}
+
+// localQueue executes the jobs in this process, keeping them in a jobStore
+// until they succeed or are purged after running out of retries
+type localQueue struct {
+	store     jobStore
+	mutex     sync.Mutex
+	queues    map[string]*localJobs
+	running   map[string]*Job
+	dead      map[string]*Job
+	keys      map[string]string
+	canceled  map[string]bool
+	started   bool
+	stopped   chan struct{}
+	processed int
+	failed    int
+}
+
+// localJobs are the jobs of a queue waiting to be executed
+type localJobs struct {
+	name        string
+	pending     pendingJobs
+	seq         uint64
+	notify      chan struct{}
+	handler     JobHandler
+	concurrency int
+	dispatching bool
+}
+
+// pendingJob is a job waiting to be executed, seq keeps the jobs due at the
+// same time in the order they were scheduled
+type pendingJob struct {
+	job *Job
+	seq uint64
+}
+
+// pendingJobs is a heap of the jobs waiting to be executed, the job due
+// first is at the top
+type pendingJobs []*pendingJob
+
+func (p pendingJobs) Len() int { return len(p) }
+
+func (p pendingJobs) Less(i, j int) bool {
+	if p[i].job.At.Equal(p[j].job.At) {
+		return p[i].seq < p[j].seq
+	}
+	return p[i].job.At.Before(p[j].job.At)
+}
+
+func (p pendingJobs) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+
+func (p *pendingJobs) Push(x interface{}) { *p = append(*p, x.(*pendingJob)) }
+
+func (p *pendingJobs) Pop() interface{} {
+	old := *p
+	last := old[len(old)-1]
+	old[len(old)-1] = nil
+	*p = old[:len(old)-1]
+	return last
+}
+
+// push adds the job to the pending jobs, must be called with the mutex of
+// the queue locked
+func (j *localJobs) push(job *Job) {
+	j.seq++
+	heap.Push(&j.pending, &pendingJob{job: job, seq: j.seq})
+}
+
+func newLocalQueue(store jobStore) (*localQueue, error) {
+	q := &localQueue{
+		store:    store,
+		queues:   map[string]*localJobs{},
+		running:  map[string]*Job{},
+		dead:     map[string]*Job{},
+		keys:     map[string]string{},
+		canceled: map[string]bool{},
+		stopped:  make(chan struct{}),
+	}
+	jobs, err := store.load()
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range jobs {
+		switch job.Status {
+		case JobDead:
+			q.dead[job.ID] = job
+		case JobRunning:
+			// the process stopped while executing it
+			job.Status = JobEnqueued
+			fallthrough
+		default:
+			q.jobs(job.Queue).push(job)
+			if job.Key != "" {
+				q.keys[job.Key] = job.ID
+			}
+		}
+	}
+	return q, nil
+}
+
+// jobs returns the jobs of the queue, must be called with the mutex locked
+func (q *localQueue) jobs(queue string) *localJobs {
+	jobs, ok := q.queues[queue]
+	if !ok {
+		jobs = &localJobs{name: queue, notify: make(chan struct{}, 1)}
+		q.queues[queue] = jobs
+	}
+	return jobs
+}
+
+// Enqueue stores the job and schedules it to be executed right away
+func (q *localQueue) Enqueue(queue string, args interface{}, opts *config.EnqueueOpts) (string, error) {
+	return q.Schedule(queue, args, &ScheduleOpts{Retry: opts})
+}
+
+// Schedule stores the job and schedules it to be executed at its time
+func (q *localQueue) Schedule(queue string, args interface{}, opts *ScheduleOpts) (string, error) {
+	job, err := newJob(queue, args, opts, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	q.mutex.Lock()
+	if id, ok := q.keys[job.Key]; ok && job.Key != "" {
+		q.mutex.Unlock()
+		return id, nil
+	}
+	if err := q.store.put(job); err != nil {
+		q.mutex.Unlock()
+		return "", err
+	}
+	if job.Key != "" {
+		q.keys[job.Key] = job.ID
+	}
+	jobs := q.schedulePending(job)
+	q.mutex.Unlock()
+
+	q.notify(jobs)
+	return job.ID, nil
+}
+
+// Cancel deletes a job waiting to be executed, a running job is deleted when
+// it finishes instead of being retried or scheduled again
+func (q *localQueue) Cancel(id string) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if _, ok := q.running[id]; ok {
+		q.canceled[id] = true
+		return nil
+	}
+	for _, jobs := range q.queues {
+		for i, p := range jobs.pending {
+			if p.job.ID != id {
+				continue
+			}
+			if err := q.store.delete(id); err != nil {
+				return err
+			}
+			heap.Remove(&jobs.pending, i)
+			q.releaseKey(p.job)
+			return nil
+		}
+	}
+	return constants.ErrJobNotFound
+}
+
+// releaseKey lets another job with the key of job be scheduled, must be
+// called with the mutex locked
+func (q *localQueue) releaseKey(job *Job) {
+	if job.Key != "" && q.keys[job.Key] == job.ID {
+		delete(q.keys, job.Key)
+	}
+}
+
+// schedulePending adds the job to the pending jobs of its queue, must be
+// called with the mutex locked
+func (q *localQueue) schedulePending(job *Job) *localJobs {
+	jobs := q.jobs(job.Queue)
+	jobs.push(job)
+	return jobs
+}
+
+func (q *localQueue) notify(jobs *localJobs) {
+	select {
+	case jobs.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Process registers the handler of the queue, which is executed by
+// concurrency goroutines once the queue is started. Registering the queue
+// again only replaces the handler of the jobs executed afterwards.
+func (q *localQueue) Process(queue string, handler JobHandler, concurrency int) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	jobs := q.jobs(queue)
+	jobs.handler = handler
+	if jobs.dispatching {
+		return
+	}
+	jobs.concurrency = concurrency
+	if q.started {
+		q.startDispatch(jobs)
+	}
+}
+
+// Start starts executing the jobs of the queues with handlers
+func (q *localQueue) Start() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.started {
+		return
+	}
+	q.started = true
+	for _, jobs := range q.queues {
+		if jobs.handler != nil {
+			q.startDispatch(jobs)
+		}
+	}
+}
+
+// startDispatch starts dispatching the jobs of the queue, must be called with
+// the mutex locked
+func (q *localQueue) startDispatch(jobs *localJobs) {
+	jobs.dispatching = true
+	go q.dispatch(jobs)
+}
+
+// Stats returns the counters of the jobs executed by this queue
+func (q *localQueue) Stats() *Stats {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	stats := &Stats{
+		Processed: q.processed,
+		Failed:    q.failed,
+		Enqueued:  map[string]string{},
+	}
+	for name, jobs := range q.queues {
+		enqueued := 0
+		for _, p := range jobs.pending {
+			switch p.job.Status {
+			case JobEnqueued:
+				enqueued++
+			case JobRetrying:
+				stats.Retries++
+			}
+		}
+		stats.Enqueued[name] = strconv.Itoa(enqueued)
+	}
+	stats.Dead = int64(len(q.dead))
+	return stats
+}
+
+// Job returns a copy of the job if it is pending, running or dead
+func (q *localQueue) Job(id string) (*Job, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if job, ok := q.running[id]; ok {
+		return copyJob(job), nil
+	}
+	if job, ok := q.dead[id]; ok {
+		return copyJob(job), nil
+	}
+	for _, jobs := range q.queues {
+		for _, p := range jobs.pending {
+			if p.job.ID == id {
+				return copyJob(p.job), nil
+			}
+		}
+	}
+	return nil, constants.ErrJobNotFound
+}
+
+// DeadJobs returns copies of the dead jobs of the queue, the ones that failed
+// first come first
+func (q *localQueue) DeadJobs(queue string) ([]*Job, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	jobs := []*Job{}
+	for _, job := range q.dead {
+		if job.Queue == queue {
+			jobs = append(jobs, copyJob(job))
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].FailedAt.Before(jobs[j].FailedAt)
+	})
+	return jobs, nil
+}
+
+// Requeue schedules a dead job to be executed right away
+func (q *localQueue) Requeue(id string) error {
+	q.mutex.Lock()
+	job, ok := q.dead[id]
+	if !ok {
+		q.mutex.Unlock()
+		return constants.ErrJobNotFound
+	}
+	job.requeue(time.Now())
+	if err := q.store.put(job); err != nil {
+		job.Status = JobDead
+		q.mutex.Unlock()
+		return err
+	}
+	delete(q.dead, id)
+	if _, ok := q.keys[job.Key]; !ok && job.Key != "" {
+		q.keys[job.Key] = job.ID
+	}
+	jobs := q.schedulePending(job)
+	q.mutex.Unlock()
+
+	q.notify(jobs)
+	return nil
+}
+
+// Purge deletes a dead job
+func (q *localQueue) Purge(id string) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if _, ok := q.dead[id]; !ok {
+		return constants.ErrJobNotFound
+	}
+	if err := q.store.delete(id); err != nil {
+		return err
+	}
+	delete(q.dead, id)
+	return nil
+}
+
+func copyJob(job *Job) *Job {
+	copied := *job
+	copied.Attempts = append([]Attempt(nil), job.Attempts...)
+	return &copied
+}
+
+// stop stops dispatching jobs, the jobs being executed are finished
+func (q *localQueue) stop() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	select {
+	case <-q.stopped:
+	default:
+		close(q.stopped)
+	}
+}
+
+// dispatch sends the jobs of the queue to its workers as they are due
+func (q *localQueue) dispatch(jobs *localJobs) {
+	ready := make(chan *Job)
+	for i := 0; i < jobs.concurrency; i++ {
+		go q.work(jobs, ready)
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		job, wait := q.next(jobs)
+		if job != nil {
+			select {
+			case ready <- job:
+				continue
+			case <-q.stopped:
+				close(ready)
+				return
+			}
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		if wait > 0 {
+			timer.Reset(wait)
+		}
+		select {
+		case <-jobs.notify:
+		case <-timer.C:
+		case <-q.stopped:
+			close(ready)
+			return
+		}
+	}
+}
+
+// next removes the due job scheduled first from the pending jobs, if there is
+// none it returns how long until the next one is due, or 0 if there are no jobs
+func (q *localQueue) next(jobs *localJobs) (*Job, time.Duration) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if len(jobs.pending) == 0 {
+		return nil, 0
+	}
+	job := jobs.pending[0].job
+	if wait := time.Until(job.At); wait > 0 {
+		return nil, wait
+	}
+	heap.Pop(&jobs.pending)
+	job.Status = JobRunning
+	q.running[job.ID] = job
+	return job, 0
+}
+
+func (q *localQueue) work(jobs *localJobs, ready <-chan *Job) {
+	for job := range ready {
+		err := q.execute(q.handler(jobs), job)
+		if err != nil {
+			logger.Log.Errorf("job %s of queue %s failed: %s", job.ID, job.Queue, err.Error())
+		}
+		if q.finish(job, err) {
+			q.notify(jobs)
+		}
+	}
+}
+
+// handler returns the handler of the queue, which Process can replace
+func (q *localQueue) handler(jobs *localJobs) JobHandler {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return jobs.handler
+}
+
+// finish updates the store with the result of the job: a failed job is
+// retried, a periodic job is scheduled again, a dead job is kept to be
+// inspected and the others are deleted; it returns whether the job was
+// scheduled again
+func (q *localQueue) finish(job *Job, err error) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	delete(q.running, job.ID)
+	canceled := q.canceled[job.ID]
+	delete(q.canceled, job.ID)
+
+	now := time.Now()
+	if err == nil {
+		q.processed++
+	} else {
+		q.failed++
+		if job.failed(err, now) && !canceled {
+			q.put(job)
+			q.schedulePending(job)
+			return true
+		}
+	}
+
+	if job.Cron != "" && !canceled && job.reschedule(now) {
+		q.put(job)
+		q.schedulePending(job)
+		return true
+	}
+
+	q.releaseKey(job)
+	if err == nil || canceled {
+		if err := q.store.delete(job.ID); err != nil {
+			logger.Log.Errorf("failed to delete job %s: %s", job.ID, err.Error())
+		}
+		return false
+	}
+	q.put(job)
+	q.dead[job.ID] = job
+	return false
+}
+
+func (q *localQueue) put(job *Job) {
+	if err := q.store.put(job); err != nil {
+		logger.Log.Errorf("failed to store job %s: %s", job.ID, err.Error())
+	}
+}
+
+// execute calls the handler with the job args, returning an error if it panics
+func (q *localQueue) execute(handler JobHandler, job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return handler(job.Args)
+}
@@ -0,0 +1,386 @@
+package worker
+
+import (
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/topfreegames/pitaya/v2/config"
+	"github.com/topfreegames/pitaya/v2/constants"
+)
+
+func TestJobFailed(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	opts := config.EnqueueOpts{Enabled: true, Max: 2, Exponential: 2, MinDelay: 1, MaxDelay: 100}
+	job := &Job{Opts: opts}
+	testErr := errors.New("error")
+
+	// the first failure sets when the job failed and keeps the retry count
+	assert.True(t, job.failed(testErr, now))
+	assert.Equal(t, JobRetrying, job.Status)
+	assert.Equal(t, now, job.FailedAt)
+	assert.Equal(t, 0, job.RetryCount)
+	assert.Equal(t, now.Add(time.Second), job.At)
+	assert.Equal(t, "error", job.Error)
+
+	assert.True(t, job.failed(testErr, now))
+	assert.Equal(t, 1, job.RetryCount)
+	assert.Equal(t, now.Add(2*time.Second), job.At)
+
+	assert.True(t, job.failed(testErr, now))
+	assert.Equal(t, 2, job.RetryCount)
+	assert.Equal(t, now.Add(5*time.Second), job.At)
+
+	assert.False(t, job.failed(testErr, now))
+	assert.Equal(t, JobDead, job.Status)
+	assert.Equal(t, 2, job.RetryCount)
+	assert.Len(t, job.Attempts, 4)
+	assert.Equal(t, Attempt{At: now, Error: "error"}, job.Attempts[3])
+
+	job.requeue(now)
+	assert.Equal(t, JobEnqueued, job.Status)
+	assert.Equal(t, 0, job.RetryCount)
+	assert.True(t, job.FailedAt.IsZero())
+	assert.Empty(t, job.Error)
+	assert.Len(t, job.Attempts, 4)
+
+	job = &Job{Opts: config.EnqueueOpts{Enabled: false, Max: 2}}
+	assert.False(t, job.failed(testErr, now))
+	assert.Equal(t, JobDead, job.Status)
+}
+
+func TestSecondsToDelay(t *testing.T) {
+	t.Parallel()
+
+	opts := &config.EnqueueOpts{Exponential: 2, MinDelay: 3, MaxDelay: 20}
+	assert.Equal(t, 3, secondsToDelay(0, opts))
+	assert.Equal(t, 12, secondsToDelay(3, opts))
+	assert.Equal(t, 20, secondsToDelay(5, opts))
+}
+
+func TestMemoryQueueProcess(t *testing.T) {
+	t.Parallel()
+
+	q := NewMemoryQueue()
+	defer q.Stop()
+
+	received := make(chan []byte, 1)
+	q.Process("queue", func(args []byte) error {
+		received <- args
+		return nil
+	}, 1)
+
+	jid, err := q.Enqueue("queue", map[string]string{"key": "value"}, &config.EnqueueOpts{})
+	require.NoError(t, err)
+	assert.Len(t, jid, 24)
+	assert.Equal(t, map[string]string{"queue": "1"}, q.Stats().Enqueued)
+
+	q.Start()
+	select {
+	case args := <-received:
+		var decoded map[string]string
+		require.NoError(t, json.Unmarshal(args, &decoded))
+		assert.Equal(t, map[string]string{"key": "value"}, decoded)
+	case <-time.After(time.Second):
+		t.Fatal("job was not executed")
+	}
+
+	assert.Eventually(t, func() bool {
+		return q.Stats().Processed == 1
+	}, time.Second, 10*time.Millisecond)
+	stats := q.Stats()
+	assert.Equal(t, 0, stats.Failed)
+	assert.Equal(t, map[string]string{"queue": "0"}, stats.Enqueued)
+}
+
+func TestMemoryQueueProcessAfterStart(t *testing.T) {
+	t.Parallel()
+
+	q := NewMemoryQueue()
+	defer q.Stop()
+	q.Start()
+
+	q.Process("queue", func(args []byte) error { return nil }, 1)
+	received := make(chan []byte, 1)
+	q.Process("queue", func(args []byte) error {
+		received <- args
+		return nil
+	}, 2)
+
+	// registering the queue again replaces the handler without dispatching
+	// the jobs twice
+	q.mutex.Lock()
+	assert.True(t, q.queues["queue"].dispatching)
+	assert.Equal(t, 1, q.queues["queue"].concurrency)
+	q.mutex.Unlock()
+
+	_, err := q.Enqueue("queue", "args", &config.EnqueueOpts{})
+	require.NoError(t, err)
+	select {
+	case <-received:
+	case <-time.After(time.Second):
+		t.Fatal("job was not executed by the last handler")
+	}
+}
+
+func TestMemoryQueueRetry(t *testing.T) {
+	t.Parallel()
+
+	tables := map[string]struct {
+		opts       config.EnqueueOpts
+		failures   int32
+		executions int32
+		processed  int
+	}{
+		"retry_disabled": {
+			opts:       config.EnqueueOpts{Enabled: false, Max: 2},
+			failures:   10,
+			executions: 1,
+		},
+		"retry_until_success": {
+			opts:       config.EnqueueOpts{Enabled: true, Max: 5},
+			failures:   2,
+			executions: 3,
+			processed:  1,
+		},
+		"retry_until_max": {
+			opts:       config.EnqueueOpts{Enabled: true, Max: 2},
+			failures:   10,
+			executions: 4,
+		},
+	}
+
+	for name, table := range tables {
+		table := table
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			q := NewMemoryQueue()
+			defer q.Stop()
+
+			var executions int32
+			q.Process("queue", func(args []byte) error {
+				if atomic.AddInt32(&executions, 1) <= table.failures {
+					return errors.New("error")
+				}
+				return nil
+			}, 2)
+			q.Start()
+
+			_, err := q.Enqueue("queue", "arg", &table.opts)
+			require.NoError(t, err)
+
+			assert.Eventually(t, func() bool {
+				stats := q.Stats()
+				return stats.Processed+stats.Failed == int(table.executions)
+			}, time.Second, 10*time.Millisecond)
+			time.Sleep(20 * time.Millisecond)
+
+			stats := q.Stats()
+			assert.Equal(t, table.executions, atomic.LoadInt32(&executions))
+			assert.Equal(t, table.processed, stats.Processed)
+			assert.Equal(t, int(table.executions)-table.processed, stats.Failed)
+			assert.Equal(t, int64(0), stats.Retries)
+		})
+	}
+}
+
+func TestMemoryQueueScheduledRetry(t *testing.T) {
+	t.Parallel()
+
+	q := NewMemoryQueue()
+	defer q.Stop()
+
+	q.Process("queue", func(args []byte) error {
+		panic("failed")
+	}, 1)
+	q.Start()
+
+	_, err := q.Enqueue("queue", "arg", &config.EnqueueOpts{Enabled: true, Max: 1, MinDelay: 60, MaxDelay: 60})
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return q.Stats().Retries == 1
+	}, time.Second, 10*time.Millisecond)
+	stats := q.Stats()
+	assert.Equal(t, 1, stats.Failed)
+	assert.Equal(t, map[string]string{"queue": "0"}, stats.Enqueued)
+}
+
+func TestMemoryQueueDeadJobs(t *testing.T) {
+	t.Parallel()
+
+	q := NewMemoryQueue()
+	defer q.Stop()
+
+	var fail int32 = 1
+	q.Process("queue", func(args []byte) error {
+		if atomic.LoadInt32(&fail) == 1 {
+			return errors.New("failed")
+		}
+		return nil
+	}, 1)
+	q.Start()
+
+	jid, err := q.Enqueue("queue", "arg", &config.EnqueueOpts{Enabled: true, Max: 0})
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return q.Stats().Dead == 1
+	}, time.Second, 10*time.Millisecond)
+
+	job, err := q.Job(jid)
+	require.NoError(t, err)
+	assert.Equal(t, JobDead, job.Status)
+	assert.Equal(t, "failed", job.Error)
+	assert.Len(t, job.Attempts, 1)
+
+	dead, err := q.DeadJobs("queue")
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, jid, dead[0].ID)
+	dead, err = q.DeadJobs("other")
+	require.NoError(t, err)
+	assert.Empty(t, dead)
+
+	atomic.StoreInt32(&fail, 0)
+	require.NoError(t, q.Requeue(jid))
+	assert.Equal(t, constants.ErrJobNotFound, q.Requeue(jid))
+	assert.Eventually(t, func() bool {
+		return q.Stats().Processed == 1
+	}, time.Second, 10*time.Millisecond)
+	_, err = q.Job(jid)
+	assert.Equal(t, constants.ErrJobNotFound, err)
+	assert.Equal(t, int64(0), q.Stats().Dead)
+
+	atomic.StoreInt32(&fail, 1)
+	jid, err = q.Enqueue("queue", "arg", &config.EnqueueOpts{})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return q.Stats().Dead == 1
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, q.Purge(jid))
+	assert.Equal(t, constants.ErrJobNotFound, q.Purge(jid))
+	_, err = q.Job(jid)
+	assert.Equal(t, constants.ErrJobNotFound, err)
+}
+
+func TestMemoryQueueSchedule(t *testing.T) {
+	t.Parallel()
+
+	q := NewMemoryQueue()
+	defer q.Stop()
+
+	executed := make(chan time.Time, 2)
+	q.Process("queue", func(args []byte) error {
+		executed <- time.Now()
+		return nil
+	}, 1)
+	q.Start()
+
+	at := time.Now().Add(100 * time.Millisecond)
+	jid, err := q.Schedule("queue", "arg", &ScheduleOpts{At: at, Key: "key"})
+	require.NoError(t, err)
+
+	job, err := q.Job(jid)
+	require.NoError(t, err)
+	assert.Equal(t, JobScheduled, job.Status)
+	assert.Equal(t, "key", job.Key)
+	assert.Equal(t, map[string]string{"queue": "0"}, q.Stats().Enqueued)
+
+	// the same key returns the job already scheduled
+	dup, err := q.Schedule("queue", "other", &ScheduleOpts{At: at, Key: "key"})
+	require.NoError(t, err)
+	assert.Equal(t, jid, dup)
+
+	select {
+	case executedAt := <-executed:
+		assert.False(t, executedAt.Before(at))
+	case <-time.After(time.Second):
+		t.Fatal("job was not executed")
+	}
+	select {
+	case <-executed:
+		t.Fatal("job with duplicated key was executed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// the key is released once the job finishes
+	assert.Eventually(t, func() bool {
+		other, err := q.Schedule("queue", "arg", &ScheduleOpts{At: time.Now().Add(time.Hour), Key: "key"})
+		return err == nil && other != jid
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestMemoryQueueCancel(t *testing.T) {
+	t.Parallel()
+
+	q := NewMemoryQueue()
+	defer q.Stop()
+
+	jid, err := q.Schedule("queue", "arg", &ScheduleOpts{At: time.Now().Add(time.Hour), Key: "key"})
+	require.NoError(t, err)
+
+	require.NoError(t, q.Cancel(jid))
+	assert.Equal(t, constants.ErrJobNotFound, q.Cancel(jid))
+	_, err = q.Job(jid)
+	assert.Equal(t, constants.ErrJobNotFound, err)
+
+	other, err := q.Schedule("queue", "arg", &ScheduleOpts{At: time.Now().Add(time.Hour), Key: "key"})
+	require.NoError(t, err)
+	assert.NotEqual(t, jid, other)
+}
+
+func TestMemoryQueueCron(t *testing.T) {
+	t.Parallel()
+
+	q := NewMemoryQueue()
+	defer q.Stop()
+
+	_, err := q.Schedule("queue", "arg", &ScheduleOpts{Cron: "invalid"})
+	assert.Equal(t, constants.ErrInvalidCronSpec, err)
+	_, err = q.Schedule("queue", "arg", &ScheduleOpts{Cron: "0 0 30 2 *"})
+	assert.Equal(t, constants.ErrCronWithoutNext, err)
+
+	now := time.Now()
+	jid, err := q.Schedule("queue", "arg", &ScheduleOpts{Cron: "@hourly", Key: "key"})
+	require.NoError(t, err)
+	job, err := q.Job(jid)
+	require.NoError(t, err)
+	assert.Equal(t, JobScheduled, job.Status)
+	assert.Equal(t, 0, job.At.Minute())
+	assert.True(t, job.At.After(now))
+
+	// a periodic job is scheduled again after it finishes, even if it fails
+	for _, execErr := range []error{nil, errors.New("failed")} {
+		q.mutex.Lock()
+		running := q.queues["queue"].pending[0].job
+		q.queues["queue"].pending = nil
+		q.running[jid] = running
+		q.mutex.Unlock()
+
+		assert.True(t, q.finish(running, execErr))
+		job, err = q.Job(jid)
+		require.NoError(t, err)
+		assert.Equal(t, JobScheduled, job.Status)
+		assert.True(t, job.At.After(time.Now()))
+	}
+	assert.Equal(t, int64(0), q.Stats().Dead)
+
+	// a canceled periodic job being executed is not scheduled again
+	q.mutex.Lock()
+	running := q.queues["queue"].pending[0].job
+	q.queues["queue"].pending = nil
+	q.running[jid] = running
+	q.mutex.Unlock()
+	require.NoError(t, q.Cancel(jid))
+	assert.False(t, q.finish(running, nil))
+	_, err = q.Job(jid)
+	assert.Equal(t, constants.ErrJobNotFound, err)
+}
@@ -0,0 +1,45 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package worker
+
+// MemoryQueue is a queue backend keeping the jobs in memory, the jobs are lost
+// when the process exits so it is meant for tests and development
+type MemoryQueue struct {
+	*localQueue
+}
+
+// NewMemoryQueue returns a *MemoryQueue
+func NewMemoryQueue() *MemoryQueue {
+	q, _ := newLocalQueue(memoryStore{})
+	return &MemoryQueue{localQueue: q}
+}
+
+// Stop stops executing the jobs
+func (q *MemoryQueue) Stop() {
+	q.stop()
+}
+
+// memoryStore doesn't persist the jobs, the pending ones are kept by the queue
+type memoryStore struct{}
+
+func (memoryStore) put(job *Job) error     { return nil }
+func (memoryStore) delete(id string) error { return nil }
+func (memoryStore) load() ([]*Job, error)  { return nil, nil }
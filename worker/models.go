@@ -20,7 +20,12 @@
 
 package worker
 
-import "github.com/golang/protobuf/proto"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+)
 
 type rpcInfo struct {
 	Route    string
@@ -32,3 +37,39 @@ type rpcInfo struct {
 type rpcRoute struct {
 	Route string
 }
+
+// RPCJobInfo describes a reliable rpc job, it holds the route, metadata and
+// arg the rpc was enqueued with and its failed attempts
+type RPCJobInfo struct {
+	ID         string                 `json:"id"`
+	Status     JobStatus              `json:"status"`
+	Route      string                 `json:"route"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	Arg        json.RawMessage        `json:"arg,omitempty"`
+	RetryCount int                    `json:"retryCount"`
+	FailedAt   time.Time              `json:"failedAt,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+	Attempts   []Attempt              `json:"attempts,omitempty"`
+}
+
+func newRPCJobInfo(job *Job) (*RPCJobInfo, error) {
+	args := &struct {
+		Route    string
+		Metadata map[string]interface{}
+		Arg      json.RawMessage
+	}{}
+	if err := json.Unmarshal(job.Args, args); err != nil {
+		return nil, err
+	}
+	return &RPCJobInfo{
+		ID:         job.ID,
+		Status:     job.Status,
+		Route:      args.Route,
+		Metadata:   args.Metadata,
+		Arg:        args.Arg,
+		RetryCount: job.RetryCount,
+		FailedAt:   job.FailedAt,
+		Error:      job.Error,
+		Attempts:   job.Attempts,
+	}, nil
+}
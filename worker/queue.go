@@ -0,0 +1,237 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package worker
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	mrand "math/rand"
+	"time"
+
+	"github.com/topfreegames/pitaya/v2/config"
+	"github.com/topfreegames/pitaya/v2/constants"
+)
+
+// Queue is the backend of the worker, it stores the jobs and executes them
+// with the registered handlers, retrying the ones that fail according to the
+// options they were enqueued with
+type Queue interface {
+	// Enqueue stores a job with the args encoded as json
+	Enqueue(queue string, args interface{}, opts *config.EnqueueOpts) (jid string, err error)
+	// Schedule stores a job to be executed later or periodically, if a job
+	// with the same key is waiting or running its id is returned instead
+	Schedule(queue string, args interface{}, opts *ScheduleOpts) (jid string, err error)
+	// Cancel deletes a job waiting to be executed, a periodic job being
+	// executed is not scheduled again
+	Cancel(id string) error
+	// Process registers the handler executing the jobs of the queue
+	Process(queue string, handler JobHandler, concurrency int)
+	// Start starts executing the jobs
+	Start()
+	// Stats returns the counters of the jobs
+	Stats() *Stats
+	// Job returns the job with the id, if it wasn't executed successfully yet
+	Job(id string) (*Job, error)
+	// DeadJobs returns the jobs of the queue that ran out of retries
+	DeadJobs(queue string) ([]*Job, error)
+	// Requeue enqueues a job that ran out of retries again, with its retry
+	// count reset
+	Requeue(id string) error
+	// Purge deletes a job that ran out of retries
+	Purge(id string) error
+}
+
+// JobHandler executes a job with its args encoded as json, the job fails if
+// an error is returned
+type JobHandler func(args []byte) error
+
+// Stats holds the counters of the jobs of a queue backend
+type Stats struct {
+	Processed int
+	Failed    int
+	Enqueued  map[string]string
+	Retries   int64
+	Dead      int64
+}
+
+// ScheduleOpts are the options of a scheduled job
+type ScheduleOpts struct {
+	// At is when the job is executed, right away if zero or in the past
+	At time.Time
+	// Cron is a spec like "0 12 * * 1" (minute, hour, day of month, month and
+	// day of week, in UTC) to execute the job at every matching time after At,
+	// instead of once
+	Cron string
+	// Key identifies the logical job, at most one job with the key waits or
+	// runs at any time
+	Key string
+	// Retry has the retry options of every execution
+	Retry *config.EnqueueOpts
+}
+
+// JobStatus is the state of a job in its queue
+type JobStatus string
+
+const (
+	// JobEnqueued is the status of a job waiting to be executed
+	JobEnqueued JobStatus = "enqueued"
+	// JobScheduled is the status of a job waiting for its time to be executed
+	JobScheduled JobStatus = "scheduled"
+	// JobRunning is the status of a job being executed
+	JobRunning JobStatus = "running"
+	// JobRetrying is the status of a job waiting to be retried
+	JobRetrying JobStatus = "retrying"
+	// JobDead is the status of a job that ran out of retries
+	JobDead JobStatus = "dead"
+)
+
+// Attempt is a failed execution of a job
+type Attempt struct {
+	At    time.Time `json:"at"`
+	Error string    `json:"error"`
+}
+
+// Job is a job of a queue, with the failed executions if any
+type Job struct {
+	ID         string
+	Queue      string
+	Status     JobStatus
+	Args       json.RawMessage
+	Opts       config.EnqueueOpts
+	RetryCount int
+	FailedAt   time.Time
+	RetriedAt  time.Time
+	At         time.Time
+	Error      string
+	Attempts   []Attempt
+	Key        string
+	Cron       string
+}
+
+// newJob creates a job with the args encoded as json, scheduled according to
+// opts
+func newJob(queue string, args interface{}, opts *ScheduleOpts, now time.Time) (*Job, error) {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
+	job := &Job{
+		ID:     newJobID(),
+		Queue:  queue,
+		Status: JobEnqueued,
+		Args:   data,
+		At:     now,
+		Key:    opts.Key,
+		Cron:   opts.Cron,
+	}
+	if opts.Retry != nil {
+		job.Opts = *opts.Retry
+	}
+	if opts.At.After(now) {
+		job.Status = JobScheduled
+		job.At = opts.At
+	}
+	if opts.Cron != "" {
+		schedule, err := parseCron(opts.Cron)
+		if err != nil {
+			return nil, err
+		}
+		// the first execution can be at At itself
+		next := schedule.next(job.At.Add(-time.Nanosecond))
+		if next.IsZero() {
+			return nil, constants.ErrCronWithoutNext
+		}
+		job.Status = JobScheduled
+		job.At = next
+	}
+	return job, nil
+}
+
+// reschedule schedules a periodic job for its next execution after now,
+// returning false if there is none
+func (j *Job) reschedule(now time.Time) bool {
+	schedule, err := parseCron(j.Cron)
+	if err != nil {
+		return false
+	}
+	next := schedule.next(now)
+	if next.IsZero() {
+		return false
+	}
+	j.requeue(next)
+	j.Status = JobScheduled
+	j.Attempts = nil
+	return true
+}
+
+// failed updates the job after an execution failure and returns whether it
+// must be retried, following the semantics of the redis backend: a job is
+// retried while the number of retries is lower than the max, after waiting
+// retries^exponential + minDelay + random*(retries+1) seconds, up to maxDelay
+func (j *Job) failed(err error, now time.Time) bool {
+	j.Error = err.Error()
+	j.Attempts = append(j.Attempts, Attempt{At: now, Error: j.Error})
+	if !j.Opts.Enabled || j.RetryCount >= j.Opts.Max {
+		j.Status = JobDead
+		return false
+	}
+	j.Status = JobRetrying
+	if j.FailedAt.IsZero() {
+		j.FailedAt = now
+	} else {
+		j.RetriedAt = now
+		j.RetryCount++
+	}
+	j.At = now.Add(time.Duration(secondsToDelay(j.RetryCount, &j.Opts)) * time.Second)
+	return true
+}
+
+func secondsToDelay(count int, opts *config.EnqueueOpts) int {
+	power := math.Pow(float64(count), float64(opts.Exponential))
+	randN := 0
+	if opts.MaxRandom > 0 {
+		randN = mrand.Intn(opts.MaxRandom)
+	}
+	return int(math.Min(power+float64(opts.MinDelay)+float64(randN*(count+1)), float64(opts.MaxDelay)))
+}
+
+// requeue resets the retries of a dead job to execute it again, keeping the
+// failed attempts
+func (j *Job) requeue(now time.Time) {
+	j.Status = JobEnqueued
+	j.RetryCount = 0
+	j.FailedAt = time.Time{}
+	j.RetriedAt = time.Time{}
+	j.Error = ""
+	j.At = now
+}
+
+// newJobID returns 12 random bytes as 24 hex characters, like the ids of the
+// redis backend
+func newJobID() string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
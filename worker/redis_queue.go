@@ -0,0 +1,606 @@
+// Copyright (c) TFG Co. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package worker
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	workers "github.com/topfreegames/go-workers"
+	"github.com/topfreegames/pitaya/v2/config"
+	"github.com/topfreegames/pitaya/v2/constants"
+	"github.com/topfreegames/pitaya/v2/logger"
+)
+
+// keys of the redis backend in the worker namespace, besides the go-workers ones
+const (
+	redisDeadKey     = "pitaya:dead"     // hash of the dead jobs by id
+	redisKeysKey     = "pitaya:keys"     // hash of the ids of the jobs by dedup key
+	redisCanceledKey = "pitaya:canceled" // set of the ids of the running jobs canceled
+	redisScanCount   = 100               // keys examined by each scan call
+)
+
+// RedisQueue is the queue backend storing the jobs in redis with go-workers,
+// the jobs that run out of retries are kept in a hash until purged
+type RedisQueue struct{}
+
+// NewRedisQueue configures go-workers and returns a *RedisQueue
+func NewRedisQueue(config config.WorkerConfig) (*RedisQueue, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+
+	workers.Configure(map[string]string{
+		"server":    config.Redis.ServerURL,
+		"pool":      config.Redis.Pool,
+		"password":  config.Redis.Password,
+		"namespace": config.Namespace,
+		"process":   hostname,
+	})
+
+	return &RedisQueue{}, nil
+}
+
+// Enqueue enqueues the job in redis
+func (r *RedisQueue) Enqueue(queue string, args interface{}, opts *config.EnqueueOpts) (string, error) {
+	return workers.EnqueueWithOptions(queue, class, args, enqueueOptions(opts))
+}
+
+// Schedule enqueues the job in redis, in the go-workers schedule if it
+// must wait for its time
+func (r *RedisQueue) Schedule(queue string, args interface{}, opts *ScheduleOpts) (string, error) {
+	job, err := newJob(queue, args, opts, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	conn := workers.Config.Pool.Get()
+	defer conn.Close()
+
+	if job.Key != "" {
+		set, err := redis.Bool(conn.Do("hsetnx", workers.Config.Namespace+redisKeysKey, job.Key, job.ID))
+		if err != nil {
+			return "", err
+		}
+		if !set {
+			return redis.String(conn.Do("hget", workers.Config.Namespace+redisKeysKey, job.Key))
+		}
+	}
+
+	if err := redisPush(conn, job); err != nil {
+		if job.Key != "" {
+			conn.Do("hdel", workers.Config.Namespace+redisKeysKey, job.Key)
+		}
+		return "", err
+	}
+	return job.ID, nil
+}
+
+// Cancel removes a job from the go-workers queues, schedule or retries, a
+// running job is marked so it is not retried or scheduled again
+func (r *RedisQueue) Cancel(id string) error {
+	conn := workers.Config.Pool.Get()
+	defer conn.Close()
+
+	entry, err := redisFindWaiting(conn, id)
+	if err != nil {
+		return err
+	}
+	if entry != nil {
+		if entry.zset {
+			_, err = conn.Do("zrem", entry.key, entry.member)
+		} else {
+			_, err = conn.Do("lrem", entry.key, 0, entry.member)
+		}
+		if err != nil {
+			return err
+		}
+		return redisReleaseKey(conn, entry.msg.Key, id)
+	}
+
+	running, err := redisFindRunning(conn, id)
+	if err != nil {
+		return err
+	}
+	if running == nil {
+		return constants.ErrJobNotFound
+	}
+	_, err = conn.Do("sadd", workers.Config.Namespace+redisCanceledKey, id)
+	return err
+}
+
+// Process registers the handler in go-workers, which retries the job if
+// the handler panics
+func (r *RedisQueue) Process(queue string, handler JobHandler, concurrency int) {
+	workers.Process(queue, redisJob(handler), concurrency, &redisJobs{})
+}
+
+// Start starts go-workers in another goroutine
+func (r *RedisQueue) Start() {
+	go workers.Start()
+}
+
+// Stop stops go-workers, waiting for the jobs being executed
+func (r *RedisQueue) Stop() {
+	workers.Quit()
+}
+
+// Stats returns the go-workers stats
+func (r *RedisQueue) Stats() *Stats {
+	stats := workers.GetStats()
+	return &Stats{
+		Processed: stats.Processed,
+		Failed:    stats.Failed,
+		Enqueued:  stats.Enqueued,
+		Retries:   stats.Retries,
+		Dead:      r.deadCount(),
+	}
+}
+
+func (r *RedisQueue) deadCount() int64 {
+	conn := workers.Config.Pool.Get()
+	defer conn.Close()
+	count, err := redis.Int64(conn.Do("hlen", workers.Config.Namespace+redisDeadKey))
+	if err != nil {
+		logger.Log.Errorf("failed to count dead jobs: %s", err.Error())
+	}
+	return count
+}
+
+// Job looks for the job in the dead jobs, the schedule, the retries, the
+// queues and the jobs in progress, it scans them all so it is meant for
+// inspection only
+func (r *RedisQueue) Job(id string) (*Job, error) {
+	conn := workers.Config.Pool.Get()
+	defer conn.Close()
+
+	data, err := redis.Bytes(conn.Do("hget", workers.Config.Namespace+redisDeadKey, id))
+	if err == nil {
+		return decodeDeadJob(data)
+	}
+	if err != redis.ErrNil {
+		return nil, err
+	}
+
+	entry, err := redisFindWaiting(conn, id)
+	if err != nil {
+		return nil, err
+	}
+	if entry != nil {
+		return entry.msg.job(entry.status), nil
+	}
+
+	running, err := redisFindRunning(conn, id)
+	if err != nil {
+		return nil, err
+	}
+	if running != nil {
+		return running.job(JobRunning), nil
+	}
+	return nil, constants.ErrJobNotFound
+}
+
+// DeadJobs returns the dead jobs of the queue, the ones that failed first
+// come first
+func (r *RedisQueue) DeadJobs(queue string) ([]*Job, error) {
+	conn := workers.Config.Pool.Get()
+	defer conn.Close()
+
+	values, err := redis.ByteSlices(conn.Do("hvals", workers.Config.Namespace+redisDeadKey))
+	if err != nil {
+		return nil, err
+	}
+	jobs := []*Job{}
+	for _, data := range values {
+		job, err := decodeDeadJob(data)
+		if err != nil {
+			return nil, err
+		}
+		if job.Queue == queue {
+			jobs = append(jobs, job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].FailedAt.Before(jobs[j].FailedAt)
+	})
+	return jobs, nil
+}
+
+// Requeue pushes a dead job to its queue again, with the same id
+func (r *RedisQueue) Requeue(id string) error {
+	conn := workers.Config.Pool.Get()
+	defer conn.Close()
+
+	data, err := redis.Bytes(conn.Do("hget", workers.Config.Namespace+redisDeadKey, id))
+	if err == redis.ErrNil {
+		return constants.ErrJobNotFound
+	}
+	if err != nil {
+		return err
+	}
+	job, err := decodeDeadJob(data)
+	if err != nil {
+		return err
+	}
+	job.requeue(time.Now())
+	if job.Key != "" {
+		if _, err := conn.Do("hsetnx", workers.Config.Namespace+redisKeysKey, job.Key, job.ID); err != nil {
+			return err
+		}
+	}
+	if err := redisPush(conn, job); err != nil {
+		return err
+	}
+	_, err = conn.Do("hdel", workers.Config.Namespace+redisDeadKey, id)
+	return err
+}
+
+// Purge deletes a dead job
+func (r *RedisQueue) Purge(id string) error {
+	conn := workers.Config.Pool.Get()
+	defer conn.Close()
+
+	deleted, err := redis.Int(conn.Do("hdel", workers.Config.Namespace+redisDeadKey, id))
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return constants.ErrJobNotFound
+	}
+	return nil
+}
+
+func redisJob(handler JobHandler) func(*workers.Msg) {
+	return func(jobArg *workers.Msg) {
+		bts, err := jobArg.Args().MarshalJSON()
+		if err != nil {
+			panic(err)
+		}
+		if err := handler(bts); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func enqueueOptions(
+	opts *config.EnqueueOpts,
+) workers.EnqueueOptions {
+	return workers.EnqueueOptions{
+		Retry:    opts.Enabled,
+		RetryMax: opts.Max,
+		RetryOptions: workers.RetryOptions{
+			Exp:      opts.Exponential,
+			MinDelay: opts.MinDelay,
+			MaxDelay: opts.MaxDelay,
+			MaxRand:  opts.MaxRandom,
+		},
+	}
+}
+
+// redisMsg is the message of a job in go-workers, with the fields of the
+// jobs of this package
+type redisMsg struct {
+	Jid          string          `json:"jid"`
+	Queue        string          `json:"queue,omitempty"`
+	Class        string          `json:"class"`
+	Args         json.RawMessage `json:"args"`
+	EnqueuedAt   float64         `json:"enqueued_at"`
+	ErrorMessage string          `json:"error_message,omitempty"`
+	FailedAt     string          `json:"failed_at,omitempty"`
+	RetriedAt    string          `json:"retried_at,omitempty"`
+	Attempts     []Attempt       `json:"attempts,omitempty"`
+	Key          string          `json:"key,omitempty"`
+	Cron         string          `json:"cron,omitempty"`
+	workers.EnqueueOptions
+}
+
+func newRedisMsg(job *Job) *redisMsg {
+	msg := &redisMsg{
+		Jid:            job.ID,
+		Queue:          job.Queue,
+		Class:          class,
+		Args:           job.Args,
+		EnqueuedAt:     float64(time.Now().UnixNano()) / workers.NanoSecondPrecision,
+		Attempts:       job.Attempts,
+		Key:            job.Key,
+		Cron:           job.Cron,
+		EnqueueOptions: enqueueOptions(&job.Opts),
+	}
+	if job.Status == JobScheduled {
+		msg.At = float64(job.At.UnixNano()) / workers.NanoSecondPrecision
+	}
+	return msg
+}
+
+func (m *redisMsg) job(status JobStatus) *Job {
+	job := &Job{
+		ID:     m.Jid,
+		Queue:  m.Queue,
+		Status: status,
+		Args:   m.Args,
+		Opts: config.EnqueueOpts{
+			Enabled:     m.Retry,
+			Max:         m.RetryMax,
+			Exponential: m.RetryOptions.Exp,
+			MinDelay:    m.RetryOptions.MinDelay,
+			MaxDelay:    m.RetryOptions.MaxDelay,
+			MaxRandom:   m.RetryOptions.MaxRand,
+		},
+		RetryCount: m.RetryCount,
+		Error:      m.ErrorMessage,
+		Attempts:   m.Attempts,
+		Key:        m.Key,
+		Cron:       m.Cron,
+	}
+	job.FailedAt, _ = time.Parse(workers.LAYOUT, m.FailedAt)
+	job.RetriedAt, _ = time.Parse(workers.LAYOUT, m.RetriedAt)
+	if m.At > 0 {
+		job.At = time.Unix(0, int64(m.At*workers.NanoSecondPrecision))
+	}
+	return job
+}
+
+// redisPush adds the job to its go-workers queue, or to the schedule if it
+// must wait for its time
+func redisPush(conn redis.Conn, job *Job) error {
+	msg := newRedisMsg(job)
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if msg.At > 0 {
+		_, err = conn.Do("zadd", workers.Config.Namespace+workers.SCHEDULED_JOBS_KEY, msg.At, data)
+		return err
+	}
+	if _, err := conn.Do("sadd", workers.Config.Namespace+"queues", job.Queue); err != nil {
+		return err
+	}
+	_, err = conn.Do("lpush", workers.Config.Namespace+"queue:"+job.Queue, data)
+	return err
+}
+
+// redisReleaseKey lets another job with the key be scheduled, if it is still
+// held by the job with the id
+func redisReleaseKey(conn redis.Conn, key, id string) error {
+	if key == "" {
+		return nil
+	}
+	holder, err := redis.String(conn.Do("hget", workers.Config.Namespace+redisKeysKey, key))
+	if err == redis.ErrNil || holder != id {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = conn.Do("hdel", workers.Config.Namespace+redisKeysKey, key)
+	return err
+}
+
+// redisEntry is a message waiting in a go-workers list or sorted set
+type redisEntry struct {
+	key    string
+	zset   bool
+	member []byte
+	msg    *redisMsg
+	status JobStatus
+}
+
+func redisFindWaiting(conn redis.Conn, id string) (*redisEntry, error) {
+	sets := []struct {
+		key    string
+		status JobStatus
+	}{
+		{workers.Config.Namespace + workers.SCHEDULED_JOBS_KEY, JobScheduled},
+		{workers.Config.Namespace + workers.RETRY_KEY, JobRetrying},
+	}
+	for _, set := range sets {
+		members, err := redis.ByteSlices(conn.Do("zrange", set.key, 0, -1))
+		if err != nil {
+			return nil, err
+		}
+		if msg, member := findRedisMsg(members, id); msg != nil {
+			return &redisEntry{key: set.key, zset: true, member: member, msg: msg, status: set.status}, nil
+		}
+	}
+
+	queues, err := redis.Strings(conn.Do("smembers", workers.Config.Namespace+"queues"))
+	if err != nil {
+		return nil, err
+	}
+	for _, queue := range queues {
+		key := workers.Config.Namespace + "queue:" + queue
+		msgs, err := redis.ByteSlices(conn.Do("lrange", key, 0, -1))
+		if err != nil {
+			return nil, err
+		}
+		if msg, member := findRedisMsg(msgs, id); msg != nil {
+			return &redisEntry{key: key, member: member, msg: msg, status: JobEnqueued}, nil
+		}
+	}
+	return nil, nil
+}
+
+// redisFindRunning looks for the job in the in progress lists of go-workers
+func redisFindRunning(conn redis.Conn, id string) (*redisMsg, error) {
+	keys, err := redisScan(conn, workers.Config.Namespace+"queue:*:inprogress")
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		msgs, err := redis.ByteSlices(conn.Do("lrange", key, 0, -1))
+		if err != nil {
+			return nil, err
+		}
+		if msg, _ := findRedisMsg(msgs, id); msg != nil {
+			return msg, nil
+		}
+	}
+	return nil, nil
+}
+
+// redisScan returns the keys matching the pattern, iterating with scan so
+// that redis isn't blocked like with keys
+func redisScan(conn redis.Conn, pattern string) ([]string, error) {
+	keys := []string{}
+	cursor := 0
+	for {
+		values, err := redis.Values(conn.Do("scan", cursor, "match", pattern, "count", redisScanCount))
+		if err != nil {
+			return nil, err
+		}
+		var page []string
+		if _, err := redis.Scan(values, &cursor, &page); err != nil {
+			return nil, err
+		}
+		keys = append(keys, page...)
+		if cursor == 0 {
+			return keys, nil
+		}
+	}
+}
+
+func findRedisMsg(msgs [][]byte, id string) (*redisMsg, []byte) {
+	for _, data := range msgs {
+		msg := &redisMsg{}
+		if err := json.Unmarshal(data, msg); err != nil {
+			continue
+		}
+		if msg.Jid == id {
+			return msg, data
+		}
+	}
+	return nil, nil
+}
+
+func decodeDeadJob(data []byte) (*Job, error) {
+	job := &Job{}
+	if err := json.Unmarshal(data, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// redisJobs is a go-workers middleware recording the failed attempts of the
+// jobs and handling the jobs that finished: the ones that ran out of retries
+// are kept in the dead hash, the periodic ones are scheduled again and the
+// dedup keys are released; it must run inside the retry middleware
+type redisJobs struct{}
+
+func (r *redisJobs) Call(queue string, message *workers.Msg, next func() bool) (acknowledge bool) {
+	defer func() {
+		if e := recover(); e != nil {
+			errMsg := fmt.Sprintf("%v", e)
+			if err := r.failed(queue, message, errMsg, time.Now()); err != nil {
+				logger.Log.Errorf("failed to record failure of job %s: %s", message.Jid(), err.Error())
+			}
+			panic(e)
+		}
+	}()
+	acknowledge = next()
+	if err := r.finished(queue, message, "", time.Now()); err != nil {
+		logger.Log.Errorf("failed to finish job %s: %s", message.Jid(), err.Error())
+	}
+	return acknowledge
+}
+
+func (r *redisJobs) failed(queue string, message *workers.Msg, errMsg string, now time.Time) error {
+	attempts, _ := message.Get("attempts").Array()
+	message.Set("attempts", append(attempts, map[string]interface{}{
+		"at":    now.Format(time.RFC3339Nano),
+		"error": errMsg,
+	}))
+	canceled, err := redisCanceled(message.Jid())
+	if err != nil {
+		return err
+	}
+	if canceled {
+		// keeps the retry middleware from scheduling the job again
+		message.Set("retry", false)
+	}
+	if redisRetry(message) {
+		return nil
+	}
+	return r.finished(queue, message, errMsg, now)
+}
+
+// redisCanceled returns whether the running job with the id was canceled
+func redisCanceled(id string) (bool, error) {
+	conn := workers.Config.Pool.Get()
+	defer conn.Close()
+	return redis.Bool(conn.Do("sismember", workers.Config.Namespace+redisCanceledKey, id))
+}
+
+// finished handles a job that succeeded, if errMsg is empty, or that ran out
+// of retries
+func (r *redisJobs) finished(queue string, message *workers.Msg, errMsg string, now time.Time) error {
+	msg := &redisMsg{}
+	if err := json.Unmarshal([]byte(message.ToJson()), msg); err != nil {
+		return err
+	}
+	msg.Queue = queue
+	if errMsg != "" {
+		msg.ErrorMessage = errMsg
+	}
+
+	conn := workers.Config.Pool.Get()
+	defer conn.Close()
+
+	canceled, err := redis.Bool(conn.Do("srem", workers.Config.Namespace+redisCanceledKey, msg.Jid))
+	if err != nil {
+		return err
+	}
+	job := msg.job(JobDead)
+	if job.Cron != "" && !canceled && job.reschedule(now) {
+		return redisPush(conn, job)
+	}
+	if err := redisReleaseKey(conn, job.Key, job.ID); err != nil {
+		return err
+	}
+	if errMsg == "" || canceled {
+		return nil
+	}
+
+	if job.FailedAt.IsZero() {
+		job.FailedAt = now
+	}
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Do("hset", workers.Config.Namespace+redisDeadKey, job.ID, data)
+	return err
+}
+
+// redisRetry returns whether go-workers will retry the failed job
+func redisRetry(message *workers.Msg) bool {
+	retry, _ := message.Get("retry").Bool()
+	max, err := message.Get("retry_max").Int()
+	if err != nil {
+		max = workers.DEFAULT_MAX_RETRY
+	}
+	count, _ := message.Get("retry_count").Int()
+	return retry && count < max
+}
@@ -0,0 +1,121 @@
+package worker
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	workers "github.com/topfreegames/go-workers"
+	"github.com/topfreegames/pitaya/v2/config"
+)
+
+func TestRedisRetry(t *testing.T) {
+	t.Parallel()
+
+	tables := map[string]struct {
+		msg   string
+		retry bool
+	}{
+		"retry_disabled":   {`{"jid": "a"}`, false},
+		"first_failure":    {`{"jid": "a", "retry": true, "retry_max": 2}`, true},
+		"below_max":        {`{"jid": "a", "retry": true, "retry_max": 2, "retry_count": 1}`, true},
+		"retries_exceeded": {`{"jid": "a", "retry": true, "retry_max": 2, "retry_count": 2}`, false},
+		"default_max":      {`{"jid": "a", "retry": true, "retry_count": 24}`, true},
+	}
+
+	for name, table := range tables {
+		table := table
+		t.Run(name, func(t *testing.T) {
+			msg, err := workers.NewMsg(table.msg)
+			require.NoError(t, err)
+			assert.Equal(t, table.retry, redisRetry(msg))
+		})
+	}
+}
+
+func TestRedisMsg(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	opts := config.EnqueueOpts{Enabled: true, Max: 3, Exponential: 2, MinDelay: 1, MaxDelay: 10, MaxRandom: 4}
+	job := &Job{
+		ID:       "jid",
+		Queue:    "rpc",
+		Status:   JobEnqueued,
+		Args:     json.RawMessage(`{"route":"sv.svc.method"}`),
+		Opts:     opts,
+		At:       now,
+		Attempts: []Attempt{{At: now.UTC(), Error: "failed"}},
+	}
+
+	data, err := json.Marshal(newRedisMsg(job))
+	require.NoError(t, err)
+	msg, err := workers.NewMsg(string(data))
+	require.NoError(t, err)
+	assert.Equal(t, "jid", msg.Jid())
+	assert.Equal(t, "rpc", msg.Get("queue").MustString())
+	assert.True(t, redisRetry(msg))
+
+	decoded := &redisMsg{}
+	require.NoError(t, json.Unmarshal(data, decoded))
+	got := decoded.job(JobRetrying)
+	assert.Equal(t, "jid", got.ID)
+	assert.Equal(t, "rpc", got.Queue)
+	assert.Equal(t, JobRetrying, got.Status)
+	assert.JSONEq(t, string(job.Args), string(got.Args))
+	assert.Equal(t, opts, got.Opts)
+	assert.Equal(t, job.Attempts, got.Attempts)
+
+	found, member := findRedisMsg([][]byte{[]byte("invalid"), data}, "jid")
+	require.NotNil(t, found)
+	assert.Equal(t, data, member)
+	found, _ = findRedisMsg([][]byte{data}, "other")
+	assert.Nil(t, found)
+
+	job.Status = JobScheduled
+	job.Key = "key"
+	job.Cron = "@daily"
+	data, err = json.Marshal(newRedisMsg(job))
+	require.NoError(t, err)
+	decoded = &redisMsg{}
+	require.NoError(t, json.Unmarshal(data, decoded))
+	got = decoded.job(JobScheduled)
+	assert.Equal(t, "key", got.Key)
+	assert.Equal(t, "@daily", got.Cron)
+	assert.WithinDuration(t, job.At, got.At, time.Millisecond)
+}
+
+// scanConn answers the scan calls with pages of keys
+type scanConn struct {
+	redis.Conn
+	pages   [][]string
+	cursors []int
+}
+
+func (c *scanConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	c.cursors = append(c.cursors, args[0].(int))
+	page := c.pages[0]
+	c.pages = c.pages[1:]
+	next := "0"
+	if len(c.pages) > 0 {
+		next = "7"
+	}
+	keys := make([]interface{}, 0, len(page))
+	for _, key := range page {
+		keys = append(keys, []byte(key))
+	}
+	return []interface{}{[]byte(next), keys}, nil
+}
+
+func TestRedisScan(t *testing.T) {
+	t.Parallel()
+
+	conn := &scanConn{pages: [][]string{{"queue:rpc:1:inprogress"}, {}, {"queue:rpc:2:inprogress"}}}
+	keys, err := redisScan(conn, "queue:*:inprogress")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"queue:rpc:1:inprogress", "queue:rpc:2:inprogress"}, keys)
+	assert.Equal(t, []int{0, 7, 7}, conn.cursors)
+}
@@ -6,18 +6,17 @@ import (
 
 	"github.com/topfreegames/pitaya/v2/logger"
 	"github.com/topfreegames/pitaya/v2/metrics"
-
-	workers "github.com/topfreegames/go-workers"
 )
 
 // Report sends periodic of worker reports
-func Report(reporters []metrics.Reporter, period time.Duration) {
+func (w *Worker) Report(reporters []metrics.Reporter, period time.Duration) {
 	for {
 		time.Sleep(period)
 
-		workerStats := workers.GetStats()
+		workerStats := w.queue.Stats()
 		for _, r := range reporters {
 			reportJobsRetry(r, workerStats.Retries)
+			reportJobsDead(r, workerStats.Dead)
 			reportQueueSizes(r, workerStats.Enqueued)
 			reportJobsTotal(r, workerStats.Failed, workerStats.Processed)
 		}
@@ -29,6 +28,11 @@ func reportJobsRetry(r metrics.Reporter, retries int64) {
 	checkReportErr(metrics.WorkerJobsRetry, err)
 }
 
+func reportJobsDead(r metrics.Reporter, dead int64) {
+	err := r.ReportGauge(metrics.WorkerJobsDead, map[string]string{}, float64(dead))
+	checkReportErr(metrics.WorkerJobsDead, err)
+}
+
 func reportQueueSizes(r metrics.Reporter, queues map[string]string) {
 	for queue, size := range queues {
 		tags := map[string]string{"queue": queue}
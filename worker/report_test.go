@@ -39,6 +39,21 @@ func TestReportJobsRetry(t *testing.T) {
 	})
 }
 
+func TestReportJobsDead(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockReporter := mocks.NewMockReporter(ctrl)
+	mockReporter.EXPECT().ReportGauge(
+		metrics.WorkerJobsDead,
+		map[string]string{},
+		float64(3))
+
+	reportJobsDead(mockReporter, 3)
+}
+
 func TestReportQueueSizes(t *testing.T) {
 	t.Parallel()
 
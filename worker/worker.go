@@ -23,7 +23,6 @@ package worker
 import (
 	"context"
 	"encoding/json"
-	"os"
 
 	"github.com/golang/protobuf/proto"
 	workers "github.com/topfreegames/go-workers"
@@ -35,31 +34,41 @@ import (
 
 // Worker executes RPCs with retry and backoff time
 type Worker struct {
+	queue       Queue
 	concurrency int
 	registered  bool
 	opts        *config.EnqueueOpts
 	started     bool
 }
 
-// NewWorker configures and returns a *Worker
+// NewWorker creates the queue backend set in the config and returns a *Worker
 func NewWorker(config config.WorkerConfig, opts config.EnqueueOpts) (*Worker, error) {
-	hostname, err := os.Hostname()
+	var queue Queue
+	var err error
+	switch config.Backend {
+	case "", "redis":
+		queue, err = NewRedisQueue(config)
+	case "file":
+		queue, err = NewFileQueue(config.File.Path)
+	case "memory":
+		queue = NewMemoryQueue()
+	default:
+		return nil, constants.ErrUnknownWorkerBackend
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	workers.Configure(map[string]string{
-		"server":    config.Redis.ServerURL,
-		"pool":      config.Redis.Pool,
-		"password":  config.Redis.Password,
-		"namespace": config.Namespace,
-		"process":   hostname,
-	})
+	return NewWorkerWithQueue(queue, config, opts), nil
+}
 
+// NewWorkerWithQueue returns a *Worker storing the jobs in queue
+func NewWorkerWithQueue(queue Queue, config config.WorkerConfig, opts config.EnqueueOpts) *Worker {
 	return &Worker{
+		queue:       queue,
 		concurrency: config.Concurrency,
 		opts:        &opts,
-	}, nil
+	}
 }
 
 // SetLogger overwrites worker logger
@@ -69,10 +78,25 @@ func (w *Worker) SetLogger(logger interfaces.Logger) {
 
 // Start starts worker in another gorotine
 func (w *Worker) Start() {
-	go workers.Start()
+	w.queue.Start()
 	w.started = true
 }
 
+// Stop stops executing the jobs and releases the resources of the queue, such
+// as the log of the file queue, the worker can't be used afterwards
+func (w *Worker) Stop() error {
+	if w == nil {
+		return nil
+	}
+	switch q := w.queue.(type) {
+	case interface{ Close() error }:
+		return q.Close()
+	case interface{ Stop() }:
+		q.Stop()
+	}
+	return nil
+}
+
 // Started returns true if worker was started
 func (w *Worker) Started() bool {
 	return w != nil && w.started
@@ -84,8 +108,17 @@ func (w *Worker) EnqueueRPC(
 	metadata map[string]interface{},
 	reply, arg proto.Message,
 ) (jid string, err error) {
-	opts := w.enqueueOptions(w.opts)
-	return workers.EnqueueWithOptions(rpcQueue, class, &rpcInfo{
+	return w.EnqueueRPCWithOptions(routeStr, metadata, reply, arg, w.opts)
+}
+
+// EnqueueRPCWithOptions enqueues rpc job to worker
+func (w *Worker) EnqueueRPCWithOptions(
+	routeStr string,
+	metadata map[string]interface{},
+	reply, arg proto.Message,
+	opts *config.EnqueueOpts,
+) (jid string, err error) {
+	return w.queue.Enqueue(rpcQueue, &rpcInfo{
 		Route:    routeStr,
 		Metadata: metadata,
 		Arg:      arg,
@@ -93,19 +126,31 @@ func (w *Worker) EnqueueRPC(
 	}, opts)
 }
 
-// EnqueueRPCWithOptions enqueues rpc job to worker
-func (w *Worker) EnqueueRPCWithOptions(
+// ScheduleRPC enqueues rpc job to worker to be executed later, at opts.At,
+// or periodically, following opts.Cron; the default retry options are used if
+// opts.Retry is nil
+func (w *Worker) ScheduleRPC(
 	routeStr string,
 	metadata map[string]interface{},
 	reply, arg proto.Message,
-	opts *config.EnqueueOpts,
+	opts *ScheduleOpts,
 ) (jid string, err error) {
-	return workers.EnqueueWithOptions(rpcQueue, class, &rpcInfo{
+	scheduleOpts := *opts
+	if scheduleOpts.Retry == nil {
+		scheduleOpts.Retry = w.opts
+	}
+	return w.queue.Schedule(rpcQueue, &rpcInfo{
 		Route:    routeStr,
 		Metadata: metadata,
 		Arg:      arg,
 		Reply:    reply,
-	}, w.enqueueOptions(opts))
+	}, &scheduleOpts)
+}
+
+// CancelRPC cancels a rpc job waiting to be executed, a periodic rpc job being
+// executed is not scheduled again
+func (w *Worker) CancelRPC(jid string) error {
+	return w.queue.Cancel(jid)
 }
 
 // RegisterRPCJob registers a RPC job
@@ -114,26 +159,88 @@ func (w *Worker) RegisterRPCJob(rpcJob RPCJob) error {
 		return constants.ErrRPCJobAlreadyRegistered
 	}
 
-	job := w.parsedRPCJob(rpcJob)
-	workers.Process(rpcQueue, job, w.concurrency)
+	w.queue.Process(rpcQueue, w.rpcJobHandler(rpcJob), w.concurrency)
 	w.registered = true
 	return nil
 }
 
+// RPCJob returns the rpc job with the jid returned when it was enqueued, if
+// it wasn't executed successfully yet
+func (w *Worker) RPCJob(jid string) (*RPCJobInfo, error) {
+	job, err := w.queue.Job(jid)
+	if err != nil {
+		return nil, err
+	}
+	return newRPCJobInfo(job)
+}
+
+// FailedRPCs returns the rpc jobs that ran out of retries
+func (w *Worker) FailedRPCs() ([]*RPCJobInfo, error) {
+	jobs, err := w.queue.DeadJobs(rpcQueue)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]*RPCJobInfo, 0, len(jobs))
+	for _, job := range jobs {
+		info, err := newRPCJobInfo(job)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// RequeueFailedRPC enqueues again a rpc job that ran out of retries
+func (w *Worker) RequeueFailedRPC(jid string) error {
+	return w.queue.Requeue(jid)
+}
+
+// PurgeFailedRPC deletes a rpc job that ran out of retries
+func (w *Worker) PurgeFailedRPC(jid string) error {
+	return w.queue.Purge(jid)
+}
+
+// PurgeFailedRPCs deletes all the rpc jobs that ran out of retries and
+// returns how many were deleted
+func (w *Worker) PurgeFailedRPCs() (int, error) {
+	jobs, err := w.queue.DeadJobs(rpcQueue)
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	for _, job := range jobs {
+		err := w.queue.Purge(job.ID)
+		if err == constants.ErrJobNotFound {
+			// purged concurrently
+			continue
+		}
+		if err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
 func (w *Worker) parsedRPCJob(rpcJob RPCJob) func(*workers.Msg) {
-	return func(jobArg *workers.Msg) {
+	return redisJob(w.rpcJobHandler(rpcJob))
+}
+
+func (w *Worker) rpcJobHandler(rpcJob RPCJob) JobHandler {
+	return func(bts []byte) error {
 		logger.Log.Debug("executing rpc job")
-		bts, rpcRoute, err := w.unmarshalRouteMetadata(jobArg)
+		rpcRoute, err := w.unmarshalRouteMetadata(bts)
 		if err != nil {
 			logger.Log.Errorf("failed to get job arg: %q", err)
-			panic(err)
+			return err
 		}
 
 		logger.Log.Debug("getting route arg and reply")
 		arg, reply, err := rpcJob.GetArgReply(rpcRoute.Route)
 		if err != nil {
 			logger.Log.Errorf("failed to get methods arg and reply: %q", err)
-			panic(err)
+			return err
 		}
 		rpcInfo := &rpcInfo{
 			Arg:   arg,
@@ -144,14 +251,14 @@ func (w *Worker) parsedRPCJob(rpcJob RPCJob) func(*workers.Msg) {
 		err = json.Unmarshal(bts, rpcInfo)
 		if err != nil {
 			logger.Log.Errorf("failed to unmarshal rpc info: %q", err)
-			panic(err)
+			return err
 		}
 
 		logger.Log.Debug("choosing server to make rpc")
 		serverID, err := rpcJob.ServerDiscovery(rpcInfo.Route, rpcInfo.Metadata)
 		if err != nil {
 			logger.Log.Errorf("failed get server: %q", err)
-			panic(err)
+			return err
 		}
 
 		ctx := context.Background()
@@ -160,41 +267,20 @@ func (w *Worker) parsedRPCJob(rpcJob RPCJob) func(*workers.Msg) {
 		err = rpcJob.RPC(ctx, serverID, rpcInfo.Route, reply, arg)
 		if err != nil {
 			logger.Log.Errorf("failed make rpc: %q", err)
-			panic(err)
+			return err
 		}
 
 		logger.Log.Debug("finished executing rpc job")
+		return nil
 	}
 }
 
-func (w *Worker) enqueueOptions(
-	opts *config.EnqueueOpts,
-) workers.EnqueueOptions {
-	return workers.EnqueueOptions{
-		Retry:    opts.Enabled,
-		RetryMax: opts.Max,
-		RetryOptions: workers.RetryOptions{
-			Exp:      opts.Exponential,
-			MinDelay: opts.MinDelay,
-			MaxDelay: opts.MaxDelay,
-			MaxRand:  opts.MaxRandom,
-		},
-	}
-}
-
-func (w *Worker) unmarshalRouteMetadata(
-	jobArg *workers.Msg,
-) ([]byte, *rpcRoute, error) {
-	bts, err := jobArg.Args().MarshalJSON()
-	if err != nil {
-		return nil, nil, err
-	}
-
+func (w *Worker) unmarshalRouteMetadata(bts []byte) (*rpcRoute, error) {
 	rpcRoute := new(rpcRoute)
-	err = json.Unmarshal(bts, rpcRoute)
+	err := json.Unmarshal(bts, rpcRoute)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	return bts, rpcRoute, nil
+	return rpcRoute, nil
 }
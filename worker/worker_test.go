@@ -3,11 +3,15 @@ package worker
 import (
 	"context"
 	"errors"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	workers "github.com/topfreegames/go-workers"
+	"github.com/topfreegames/pitaya/v2/config"
+	"github.com/topfreegames/pitaya/v2/constants"
 	"github.com/topfreegames/pitaya/v2/worker/mocks"
 )
 
@@ -177,3 +181,167 @@ func TestParsedRPCJob(t *testing.T) {
 		})
 	}
 }
+
+func TestWorkerWithMemoryQueue(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	route := "server.svc.method"
+	opts := config.EnqueueOpts{Enabled: true, Max: 2}
+	queue := NewMemoryQueue()
+	defer queue.Stop()
+	w := NewWorkerWithQueue(queue, config.WorkerConfig{Concurrency: 1}, opts)
+
+	done := make(chan struct{})
+	mockRPCJob := mocks.NewMockRPCJob(ctrl)
+	mockRPCJob.EXPECT().
+		GetArgReply(route).
+		Return(&fakeProtoMessage{}, &fakeProtoMessage{}, nil).Times(2)
+	mockRPCJob.EXPECT().
+		ServerDiscovery(route, map[string]interface{}{"stack": "a"}).
+		Return("serverid", nil).Times(2)
+	gomock.InOrder(
+		mockRPCJob.EXPECT().
+			RPC(gomock.Any(), "serverid", route, &fakeProtoMessage{}, &fakeProtoMessage{Field: "arg"}).
+			Return(errors.New("error")),
+		mockRPCJob.EXPECT().
+			RPC(gomock.Any(), "serverid", route, &fakeProtoMessage{}, &fakeProtoMessage{Field: "arg"}).
+			DoAndReturn(func(_, _, _, _, _ interface{}) error {
+				close(done)
+				return nil
+			}),
+	)
+
+	assert.NoError(t, w.RegisterRPCJob(mockRPCJob))
+	assert.Equal(t, constants.ErrRPCJobAlreadyRegistered, w.RegisterRPCJob(mockRPCJob))
+	w.Start()
+	assert.True(t, w.Started())
+
+	jid, err := w.EnqueueRPC(route, map[string]interface{}{"stack": "a"}, &fakeProtoMessage{}, &fakeProtoMessage{Field: "arg"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, jid)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("rpc job was not retried")
+	}
+}
+
+func TestNewWorkerBackends(t *testing.T) {
+	t.Parallel()
+
+	conf := *config.NewDefaultWorkerConfig()
+	opts := *config.NewDefaultEnqueueOpts()
+
+	conf.Backend = "memory"
+	w, err := NewWorker(conf, opts)
+	assert.NoError(t, err)
+	assert.IsType(t, &MemoryQueue{}, w.queue)
+	assert.NoError(t, w.Stop())
+
+	conf.Backend = "file"
+	conf.File.Path = filepath.Join(t.TempDir(), "worker.log")
+	w, err = NewWorker(conf, opts)
+	assert.NoError(t, err)
+	assert.IsType(t, &FileQueue{}, w.queue)
+	assert.NoError(t, w.Stop())
+	// the log is closed
+	assert.Error(t, w.queue.(*FileQueue).store.file.Close())
+
+	conf.Backend = "unknown"
+	_, err = NewWorker(conf, opts)
+	assert.Equal(t, constants.ErrUnknownWorkerBackend, err)
+}
+
+func TestWorkerFailedRPCs(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	route := "server.svc.method"
+	queue := NewMemoryQueue()
+	defer queue.Stop()
+	w := NewWorkerWithQueue(queue, config.WorkerConfig{Concurrency: 1}, config.EnqueueOpts{})
+
+	mockRPCJob := mocks.NewMockRPCJob(ctrl)
+	mockRPCJob.EXPECT().GetArgReply(route).Return(nil, nil, errors.New("failed")).Times(2)
+	assert.NoError(t, w.RegisterRPCJob(mockRPCJob))
+	w.Start()
+
+	jids := []string{}
+	for i := 0; i < 2; i++ {
+		jid, err := w.EnqueueRPC(route, map[string]interface{}{"stack": "a"}, &fakeProtoMessage{}, &fakeProtoMessage{Field: "arg"})
+		assert.NoError(t, err)
+		jids = append(jids, jid)
+	}
+	assert.Eventually(t, func() bool {
+		jobs, err := w.FailedRPCs()
+		return err == nil && len(jobs) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	job, err := w.RPCJob(jids[0])
+	assert.NoError(t, err)
+	assert.Equal(t, JobDead, job.Status)
+	assert.Equal(t, route, job.Route)
+	assert.Equal(t, map[string]interface{}{"stack": "a"}, job.Metadata)
+	assert.JSONEq(t, `{"Field": "arg"}`, string(job.Arg))
+	assert.Equal(t, "failed", job.Error)
+	assert.Len(t, job.Attempts, 1)
+
+	assert.NoError(t, w.PurgeFailedRPC(jids[0]))
+	purged, err := w.PurgeFailedRPCs()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	_, err = w.RPCJob(jids[1])
+	assert.Equal(t, constants.ErrJobNotFound, err)
+}
+
+func TestWorkerScheduleRPC(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	route := "server.svc.method"
+	queue := NewMemoryQueue()
+	defer queue.Stop()
+	w := NewWorkerWithQueue(queue, config.WorkerConfig{Concurrency: 1}, config.EnqueueOpts{Enabled: true, Max: 3})
+
+	done := make(chan struct{})
+	mockRPCJob := mocks.NewMockRPCJob(ctrl)
+	mockRPCJob.EXPECT().GetArgReply(route).Return(&fakeProtoMessage{}, &fakeProtoMessage{}, nil)
+	mockRPCJob.EXPECT().ServerDiscovery(route, nil).Return("serverid", nil)
+	mockRPCJob.EXPECT().
+		RPC(gomock.Any(), "serverid", route, &fakeProtoMessage{}, &fakeProtoMessage{Field: "arg"}).
+		DoAndReturn(func(_, _, _, _, _ interface{}) error {
+			close(done)
+			return nil
+		})
+	assert.NoError(t, w.RegisterRPCJob(mockRPCJob))
+	w.Start()
+
+	jid, err := w.ScheduleRPC(route, nil, &fakeProtoMessage{}, &fakeProtoMessage{Field: "arg"}, &ScheduleOpts{
+		At: time.Now().Add(50 * time.Millisecond),
+	})
+	assert.NoError(t, err)
+	job, err := w.RPCJob(jid)
+	assert.NoError(t, err)
+	assert.Equal(t, JobScheduled, job.Status)
+
+	canceled, err := w.ScheduleRPC(route, nil, &fakeProtoMessage{}, &fakeProtoMessage{Field: "other"}, &ScheduleOpts{
+		At: time.Now().Add(50 * time.Millisecond),
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, w.CancelRPC(canceled))
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduled rpc job was not executed")
+	}
+	time.Sleep(100 * time.Millisecond)
+}